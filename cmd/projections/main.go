@@ -0,0 +1,118 @@
+// Command projections runs the event store administration commands against a running server:
+//
+//	projections [flags] events [-after sequence]   exports the account events, one JSON event per line
+//	projections [flags] diff                        lists the fields the live projection differs from the events in
+//	projections [flags] rebuild                     rebuilds the projection from the events and lists what it fixed
+//
+// diff and rebuild exit with status 1 when they find differences, so that they can be scheduled as checks.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+type client struct {
+	baseURL  string
+	adminKey string
+	apiKey   string
+	http     *http.Client
+}
+
+func main() {
+	c := &client{http: &http.Client{Timeout: time.Minute}}
+	flag.StringVar(&c.baseURL, "url", "http://localhost:5000", "address of the server")
+	flag.StringVar(&c.adminKey, "admin-key", os.Getenv("ADMIN_API_KEY"), "admin key of the server, ADMIN_API_KEY by default")
+	flag.StringVar(&c.apiKey, "api-key", os.Getenv("TENANT_API_KEY"), "API key of the tenant, TENANT_API_KEY by default")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] events [-after sequence] | diff | rebuild\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+	differences := 0
+	switch flag.Arg(0) {
+	case "events":
+		err = c.events(flag.Args()[1:])
+	case "diff":
+		differences, err = c.diff(http.MethodGet, "/admin/projections/accounts/diff")
+	case "rebuild":
+		differences, err = c.diff(http.MethodPost, "/admin/projections/accounts/rebuild")
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if differences > 0 {
+		os.Exit(1)
+	}
+}
+
+// events writes the events recorded after the sequence to the standard output, one per line
+func (c *client) events(args []string) error {
+	fs := flag.NewFlagSet("events", flag.ExitOnError)
+	after := fs.Int64("after", 0, "sequence of the last event already exported")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var events []json.RawMessage
+	if err := c.do(http.MethodGet, "/admin/events?after="+url.QueryEscape(strconv.FormatInt(*after, 10)), &events); err != nil {
+		return err
+	}
+	for _, event := range events {
+		fmt.Println(string(event))
+	}
+	return nil
+}
+
+// diff writes the differences returned by the route to the standard output and returns how many there are
+func (c *client) diff(method string, path string) (int, error) {
+	var diffs []json.RawMessage
+	if err := c.do(method, path, &diffs); err != nil {
+		return 0, err
+	}
+	for _, diff := range diffs {
+		fmt.Println(string(diff))
+	}
+	return len(diffs), nil
+}
+
+// do sends the request with the keys of the client and decodes the response into out,
+// problems returned by the server are returned as errors
+func (c *client) do(method string, path string, out interface{}) error {
+	req, err := http.NewRequest(method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Admin-Key", c.adminKey)
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s %s", method, path, res.Status, body)
+	}
+	return json.Unmarshal(body, out)
+}
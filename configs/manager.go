@@ -1,18 +1,114 @@
 package configs
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"io"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var Manager manager
 
 type manager struct {
 	HostCredentials *hostCredentials
+	Limits          []*models.LimitDTO
+	Policies        []*models.PolicyRule
+	Fees            []*models.FeeScheduleDTO
+	Holds           *holdSettings
+	Escrow          *escrowSettings
+	StandingOrders  *standingOrderSettings
+	Overdraft       *overdraftSettings
+	Interest        *interestSettings
+	// CheckpointInterval is how often the balances of accounts are checkpointed
+	CheckpointInterval time.Duration
+	// InvoiceSweepInterval is how often the invoices past their due date are expired
+	InvoiceSweepInterval time.Duration
+	Reconciliation       *reconciliationSettings
+	Screening            *screeningSettings
+	// FraudRules are evaluated before every payment and withdrawal
+	FraudRules []*models.FraudRule
+	// AccountNumbers are the ranges new accounts are numbered from, by account type
+	AccountNumbers []*models.AccountNumberRange
+	// ExchangeRates convert the transfers between the wallets of customers
+	ExchangeRates []*models.ExchangeRateDTO
+	// Tenants are the partner brands served from separate ledgers, without any every request shares one ledger
+	Tenants []*models.Tenant
+	// AdminAPIKey authenticates the operators calling the /admin routes, without one the routes cannot be called
+	AdminAPIKey string
+	// IBANKey is the secret the account identifiers of IBANs are derived with, without one a default key is used
+	IBANKey string
+	// EventsDir keeps the event log of the accounts of every tenant, without one the events are kept in memory only
+	EventsDir string
+}
+
+type holdSettings struct {
+	// TTL is the lifetime of holds placed without one
+	TTL time.Duration
+	// SweepInterval is how often expired holds are released
+	SweepInterval time.Duration
+}
+
+type escrowSettings struct {
+	// TTL is how long escrows funded without one wait for an instruction
+	TTL time.Duration
+	// SweepInterval is how often timed out escrows are settled
+	SweepInterval time.Duration
+}
+
+type standingOrderSettings struct {
+	// RunInterval is how often due standing orders are run
+	RunInterval time.Duration
+	// MaxRetries is how many times a run failing for insufficient balance is retried
+	MaxRetries int
+	// RetryDelay is the time between the retries of a run
+	RetryDelay time.Duration
+}
+
+type overdraftSettings struct {
+	// AnnualRate is the interest charged on negative balances in percent
+	AnnualRate decimal.Decimal
+	// MaxLimit is the highest overdraft limit an account can have
+	MaxLimit decimal.Decimal
+	// AccrualInterval is how often the accrual job looks for a new day to charge
+	AccrualInterval time.Duration
+}
+
+type interestSettings struct {
+	Rates []*models.InterestRateDTO
+	// AccrualInterval is how often the accrual job looks for a new day to accrue
+	AccrualInterval time.Duration
+}
+
+type reconciliationSettings struct {
+	// Interval is how often the balances are reconciled with the history
+	Interval time.Duration
+	// Correct makes the scheduled reconciliation write adjustment lines for the discrepancies it finds
+	Correct bool
+}
+
+type screeningSettings struct {
+	// Entities are the entries of the sanctions list names are screened against
+	Entities []*models.SanctionedEntity
+	// AccountThreshold is the lowest similarity between 0 and 1 an opened or renamed account is held for review with
+	AccountThreshold float64
+	// PaymentThreshold is the lowest similarity between 0 and 1 a payment is held for review with
+	PaymentThreshold float64
 }
 
 type hostCredentials struct {
 	PORT string
+	// ShutdownTimeout is how long in-flight requests are given to finish
+	// before the server is forcibly closed
+	ShutdownTimeout time.Duration
 }
 
 func (m *manager) Setup() {
@@ -23,6 +119,323 @@ func (m *manager) Setup() {
 		port = defaultPort
 	}
 
-	m.HostCredentials = &hostCredentials{PORT: fmt.Sprintf(":%s", port)}
+	m.HostCredentials = &hostCredentials{
+		PORT:            fmt.Sprintf(":%s", port),
+		ShutdownTimeout: durationEnv("SHUTDOWN_TIMEOUT", 15*time.Second),
+	}
+
+	m.Holds = &holdSettings{
+		TTL:           durationEnv("HOLD_TTL", 7*24*time.Hour),
+		SweepInterval: durationEnv("HOLD_SWEEP_INTERVAL", time.Minute),
+	}
+
+	m.Escrow = &escrowSettings{
+		TTL:           durationEnv("ESCROW_TTL", 14*24*time.Hour),
+		SweepInterval: durationEnv("ESCROW_SWEEP_INTERVAL", time.Minute),
+	}
+
+	m.InvoiceSweepInterval = durationEnv("INVOICE_SWEEP_INTERVAL", time.Minute)
+
+	m.StandingOrders = &standingOrderSettings{
+		RunInterval: durationEnv("STANDING_ORDER_INTERVAL", time.Minute),
+		MaxRetries:  intEnv("STANDING_ORDER_MAX_RETRIES", 3),
+		RetryDelay:  durationEnv("STANDING_ORDER_RETRY_DELAY", time.Hour),
+	}
+
+	m.Overdraft = &overdraftSettings{
+		AnnualRate:      decimalEnv("OVERDRAFT_RATE", decimal.NewFromInt(24)),
+		MaxLimit:        decimalEnv("OVERDRAFT_MAX_LIMIT", decimal.NewFromInt(10000)),
+		AccrualInterval: durationEnv("OVERDRAFT_ACCRUAL_INTERVAL", time.Hour),
+	}
+
+	m.CheckpointInterval = durationEnv("BALANCE_CHECKPOINT_INTERVAL", time.Hour)
 
+	m.Reconciliation = &reconciliationSettings{
+		Interval: durationEnv("RECONCILIATION_INTERVAL", time.Hour),
+		Correct:  boolEnv("RECONCILIATION_CORRECT", false),
+	}
+
+	accountThreshold, _ := decimalEnv("SCREENING_ACCOUNT_THRESHOLD", decimal.NewFromFloat(0.85)).Float64()
+	paymentThreshold, _ := decimalEnv("SCREENING_PAYMENT_THRESHOLD", decimal.NewFromFloat(0.9)).Float64()
+	m.Screening = &screeningSettings{
+		AccountThreshold: accountThreshold,
+		PaymentThreshold: paymentThreshold,
+	}
+
+	m.AdminAPIKey = os.Getenv("ADMIN_API_KEY")
+	m.EventsDir = os.Getenv("EVENTS_DIR")
+	m.IBANKey = os.Getenv("IBAN_KEY")
+
+	m.Interest = &interestSettings{
+		AccrualInterval: durationEnv("INTEREST_ACCRUAL_INTERVAL", time.Hour),
+	}
+
+	limitsPath := os.Getenv("LIMITS_CONFIG")
+	if limitsPath == "" {
+		limitsPath = "configs/limits.json"
+	}
+	if err := loadJSON(limitsPath, &m.Limits); err != nil {
+		panic(err)
+	}
+
+	policiesPath := os.Getenv("POLICIES_CONFIG")
+	if policiesPath == "" {
+		policiesPath = "configs/policies.json"
+	}
+	if err := loadJSON(policiesPath, &m.Policies); err != nil {
+		panic(err)
+	}
+
+	feesPath := os.Getenv("FEES_CONFIG")
+	if feesPath == "" {
+		feesPath = "configs/fees.json"
+	}
+	if err := loadJSON(feesPath, &m.Fees); err != nil {
+		panic(err)
+	}
+
+	interestPath := os.Getenv("INTEREST_CONFIG")
+	if interestPath == "" {
+		interestPath = "configs/interest.json"
+	}
+	if err := loadJSON(interestPath, &m.Interest.Rates); err != nil {
+		panic(err)
+	}
+	for _, r := range m.Interest.Rates {
+		if r.DayCount != types.Actual360 && r.DayCount != types.Actual365 {
+			panic(fmt.Errorf("cannot read %s: unknown day count %q for %s", interestPath, r.DayCount, r.Currency))
+		}
+	}
+
+	fxPath := os.Getenv("FX_CONFIG")
+	if fxPath == "" {
+		fxPath = "configs/fx.json"
+	}
+	if err := loadJSON(fxPath, &m.ExchangeRates); err != nil {
+		panic(err)
+	}
+	for _, r := range m.ExchangeRates {
+		if r.Rate <= 0 {
+			panic(fmt.Errorf("cannot read %s: rate from %s to %s has to be positive", fxPath, r.From, r.To))
+		}
+	}
+
+	accountNumbersPath := os.Getenv("ACCOUNT_NUMBERS_CONFIG")
+	if accountNumbersPath == "" {
+		accountNumbersPath = "configs/account_numbers.json"
+	}
+	var accountNumbers []*models.AccountNumberRangeDTO
+	if err := loadJSON(accountNumbersPath, &accountNumbers); err != nil {
+		panic(err)
+	}
+	for _, r := range accountNumbers {
+		m.AccountNumbers = append(m.AccountNumbers, r.Normal())
+	}
+	if err := models.ValidateAccountNumberRanges(m.AccountNumbers); err != nil {
+		panic(fmt.Errorf("cannot read %s: %w", accountNumbersPath, err))
+	}
+
+	sanctionsPath := os.Getenv("SANCTIONS_LIST")
+	if sanctionsPath == "" {
+		sanctionsPath = "configs/sanctions.csv"
+	}
+	entities, err := loadSanctionsList(sanctionsPath)
+	if err != nil {
+		panic(err)
+	}
+	m.Screening.Entities = entities
+
+	fraudPath := os.Getenv("FRAUD_CONFIG")
+	if fraudPath == "" {
+		fraudPath = "configs/fraud.json"
+	}
+	var fraudRules []*models.FraudRuleDTO
+	if err := loadJSON(fraudPath, &fraudRules); err != nil {
+		panic(err)
+	}
+	for _, r := range fraudRules {
+		switch r.Kind {
+		case types.VelocityRule, types.UnusualAmountRule, types.NewAccountRule:
+		default:
+			panic(fmt.Errorf("cannot read %s: unknown kind %q of fraud rule %q", fraudPath, r.Kind, r.Name))
+		}
+		switch r.Action {
+		case types.FraudAllow, types.FraudReview, types.FraudBlock:
+		default:
+			panic(fmt.Errorf("cannot read %s: unknown action %q of fraud rule %q", fraudPath, r.Action, r.Name))
+		}
+		switch r.TransactionType {
+		case "", types.Payment, types.Withdraw:
+		default:
+			panic(fmt.Errorf("cannot read %s: fraud rule %q can only score payments and withdrawals", fraudPath, r.Name))
+		}
+		m.FraudRules = append(m.FraudRules, r.Normal())
+	}
+
+	tenantsPath := os.Getenv("TENANTS_CONFIG")
+	if tenantsPath == "" {
+		tenantsPath = "configs/tenants.json"
+	}
+	var tenants []*models.TenantDTO
+	if err := loadJSON(tenantsPath, &tenants); err != nil {
+		panic(err)
+	}
+	ids := make(map[string]bool)
+	keys := make(map[string]bool)
+	for _, t := range tenants {
+		if t.ID == "" || ids[t.ID] {
+			panic(fmt.Errorf("cannot read %s: tenant ids have to be set and unique, got %q", tenantsPath, t.ID))
+		}
+		ids[t.ID] = true
+		if len(t.APIKeys) == 0 {
+			panic(fmt.Errorf("cannot read %s: tenant %q needs at least one api key", tenantsPath, t.ID))
+		}
+		for _, key := range t.APIKeys {
+			if key == "" || keys[key] {
+				panic(fmt.Errorf("cannot read %s: api keys of tenant %q have to be set and unique", tenantsPath, t.ID))
+			}
+			keys[key] = true
+		}
+		m.Tenants = append(m.Tenants, t.Normal())
+	}
+
+}
+
+// durationEnv reads a positive duration such as "90s" from the environment variable,
+// it returns defaultValue if the variable is not set or not valid
+func durationEnv(key string, defaultValue time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return defaultValue
+	}
+	return d
+}
+
+// intEnv reads a non-negative integer from the environment variable,
+// it returns defaultValue if the variable is not set or not valid
+func intEnv(key string, defaultValue int) int {
+	i, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || i < 0 {
+		return defaultValue
+	}
+	return i
+}
+
+// decimalEnv reads a non-negative decimal from the environment variable,
+// it returns defaultValue if the variable is not set or not valid
+func decimalEnv(key string, defaultValue decimal.Decimal) decimal.Decimal {
+	d, err := decimal.NewFromString(os.Getenv(key))
+	if err != nil || d.IsNegative() {
+		return defaultValue
+	}
+	return d
+}
+
+// boolEnv reads a boolean such as "true" or "1" from the environment variable,
+// it returns defaultValue if the variable is not set or not valid
+func boolEnv(key string, defaultValue bool) bool {
+	b, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
+
+// loadJSON decodes the json file at path into v,
+// a missing file leaves v untouched since every config file is optional
+func loadJSON(path string, v interface{}) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return nil
+}
+
+// sdnColumns are the columns of the OFAC SDN list in CSV format that are read,
+// the list has no header and marks empty fields with -0-
+const (
+	sdnEntNum = iota
+	sdnName
+	sdnType
+	sdnProgram
+	sdnRemarks = 11
+)
+
+// sdnEmpty marks an empty field of the SDN list
+const sdnEmpty = "-0-"
+
+// sdnAliases finds the aliases in the remarks of an SDN entry, such as a.k.a. 'NAME'
+var sdnAliases = regexp.MustCompile(`[af]\.k\.a\. '([^']+)'`)
+
+// loadSanctionsList reads the sanctions list in the CSV format of the OFAC SDN list at path,
+// a missing file leaves the list empty so that nothing is screened
+func loadSanctionsList(path string) ([]*models.SanctionedEntity, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var entities []*models.SanctionedEntity
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s: %w", path, err)
+		}
+		// Lines without a numeric entry number, such as a header or the end of file marker, are not entries
+		if len(record) <= sdnName {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimSpace(record[sdnEntNum])); err != nil {
+			continue
+		}
+
+		entity := &models.SanctionedEntity{ID: strings.TrimSpace(record[sdnEntNum])}
+		if name := sdnField(record, sdnName); name != "" {
+			entity.Names = append(entity.Names, name)
+		}
+		entity.Type = sdnField(record, sdnType)
+		if programs := sdnField(record, sdnProgram); programs != "" {
+			for _, p := range strings.Split(strings.Trim(programs, "[]"), "] [") {
+				entity.Programs = append(entity.Programs, strings.TrimSpace(p))
+			}
+		}
+		for _, alias := range sdnAliases.FindAllStringSubmatch(sdnField(record, sdnRemarks), -1) {
+			entity.Names = append(entity.Names, alias[1])
+		}
+		if len(entity.Names) > 0 {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// sdnField returns the trimmed field of the record, empty if the record is shorter or the field is marked empty
+func sdnField(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	field := strings.TrimSpace(record[i])
+	if field == sdnEmpty {
+		return ""
+	}
+	return field
 }
@@ -1,51 +1,200 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"github.com/ahmetberke/tringle-candidate-project/configs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/api/controllers"
-	"github.com/ahmetberke/tringle-candidate-project/internal/cache"
-	"github.com/ahmetberke/tringle-candidate-project/internal/services"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 // this struct has a PORT for custom host setting
 // and it has a router that distributes the routes to the relevant controllers
 // the server is run through this router
 type api struct {
-	PORT   string
-	Router *gin.Engine
+	PORT            string
+	Router          *gin.Engine
+	ShutdownTimeout time.Duration
+
+	server       *http.Server
+	storages     []storage
+	tenants      *tenants
+	shuttingDown int32
+	jobs         []*job
+	runningJobs  sync.WaitGroup
+	stopJobs     chan struct{}
+	stopJobsOnce sync.Once
+}
+
+// storage is a persistence layer that has to be
+// checked for readiness and flushed before the process exits
+type storage interface {
+	Ready() error
+	Flush() error
 }
 
 func NewAPI() *api {
 	a := &api{
-		PORT:   configs.Manager.HostCredentials.PORT,
-		Router: gin.Default(),
+		PORT:            configs.Manager.HostCredentials.PORT,
+		Router:          gin.Default(),
+		ShutdownTimeout: configs.Manager.HostCredentials.ShutdownTimeout,
+		stopJobs:        make(chan struct{}),
 	}
 
-	// Creating cache layers
-	accountCache := cache.NewAccountCache()
-	transactionCache := cache.NewTransactionCache()
+	types.SetIBANKey(configs.Manager.IBANKey)
 
-	// Creating services
-	accountService := services.NewAccountService(accountCache)
-	transactionService := services.NewTransactionService(accountCache, transactionCache)
+	// Every request other than the health probes is served by the routes of its tenant
+	a.tenants = newTenants(configs.Manager.Tenants)
+	a.storages = append(a.storages, a.tenants)
 
 	// Creating controllers
-	accountController := controllers.NewAccountController(accountService)
-	transactionController := controllers.NewTransactionController(transactionService)
+	tenantController := controllers.NewTenantController(a.tenants)
+	healthController := controllers.NewHealthController()
+	healthController.AddCheck("server", a)
+	healthController.AddCheck("tenants", a.tenants)
 
 	// Initializing routes
-	a.AccountRoutesInitialize(accountController)
-	a.TransactionRoutesInitialize(transactionController)
+	a.HealthRoutesInitialize(healthController)
+	a.TenantRoutesInitialize(tenantController)
+
+	// Registering background jobs, every job runs for the ledgers of all tenants
+	a.addJob("hold expiry", configs.Manager.Holds.SweepInterval, func() {
+		for _, t := range a.tenants.all() {
+			if expired := t.holdService.ExpireDue(); expired > 0 {
+				log.Printf("expired %d holds of tenant %s", expired, t.ID)
+			}
+		}
+	})
+	a.addJob("escrow timeout", configs.Manager.Escrow.SweepInterval, func() {
+		for _, t := range a.tenants.all() {
+			if settled := t.escrowService.SettleDue(); settled > 0 {
+				log.Printf("settled %d timed out escrows of tenant %s", settled, t.ID)
+			}
+		}
+	})
+	a.addJob("invoice expiry", configs.Manager.InvoiceSweepInterval, func() {
+		for _, t := range a.tenants.all() {
+			if expired := t.invoiceService.ExpireDue(); expired > 0 {
+				log.Printf("expired %d invoices of tenant %s", expired, t.ID)
+			}
+		}
+	})
+	a.addJob("standing orders", configs.Manager.StandingOrders.RunInterval, func() {
+		for _, t := range a.tenants.all() {
+			if ran := t.standingOrderService.RunDue(); ran > 0 {
+				log.Printf("ran %d standing orders of tenant %s", ran, t.ID)
+			}
+		}
+	})
+	a.addJob("overdraft interest", configs.Manager.Overdraft.AccrualInterval, func() {
+		for _, t := range a.tenants.all() {
+			if charged := t.overdraftService.AccrueInterest(); charged > 0 {
+				log.Printf("charged overdraft interest to %d accounts of tenant %s", charged, t.ID)
+			}
+		}
+	})
+	a.addJob("interest accrual", configs.Manager.Interest.AccrualInterval, func() {
+		for _, t := range a.tenants.all() {
+			if accrued, posted := t.interestService.Accrue(); accrued > 0 || posted > 0 {
+				log.Printf("accrued interest of %d accounts, posted interest of %d accounts of tenant %s", accrued, posted, t.ID)
+			}
+		}
+	})
+	a.addJob("balance checkpoints", configs.Manager.CheckpointInterval, func() {
+		for _, t := range a.tenants.all() {
+			if taken := t.balanceService.Checkpoint(); taken > 0 {
+				log.Printf("took balance checkpoints of %d accounts of tenant %s", taken, t.ID)
+			}
+		}
+	})
+	a.addJob("reconciliation", configs.Manager.Reconciliation.Interval, func() {
+		for _, t := range a.tenants.all() {
+			report := t.reconciliationService.Reconcile(configs.Manager.Reconciliation.Correct)
+			for _, total := range report.Currencies {
+				log.Printf("reconciliation found %d accounts of tenant %s in %s off by %s", total.Accounts, t.ID, total.CurrencyCode, total.Difference)
+			}
+		}
+	})
 
 	return a
 }
 
 // Run starts the server with the port set in the api
+// and blocks until the server fails or the process receives SIGINT or SIGTERM,
+// in which case the server is shut down gracefully
 func (a *api) Run() error {
-	err := a.Router.Run(a.PORT)
-	if err != nil {
+	a.server = &http.Server{
+		Addr:    a.PORT,
+		Handler: a.Router,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		err := a.server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	a.startJobs()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	select {
+	case err := <-serverErr:
 		return err
+	case sig := <-quit:
+		log.Printf("received %s, shutting down", sig)
+	}
+
+	return a.Shutdown()
+}
+
+// Shutdown marks the api as not ready, waits for in-flight requests
+// to finish within ShutdownTimeout and flushes every storage
+func (a *api) Shutdown() error {
+	atomic.StoreInt32(&a.shuttingDown, 1)
+	a.stopAllJobs()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.ShutdownTimeout)
+	defer cancel()
+
+	var shutdownErr error
+	if a.server != nil {
+		shutdownErr = a.server.Shutdown(ctx)
+		if shutdownErr != nil {
+			log.Printf("server did not drain in %s: %v", a.ShutdownTimeout, shutdownErr)
+		}
+	}
+
+	// Storages are flushed even if draining timed out
+	// so that completed requests are not lost
+	for _, s := range a.storages {
+		if err := s.Flush(); err != nil && shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+
+	return shutdownErr
+}
+
+// Ready implements the readiness check of the server itself,
+// it fails as soon as the shutdown has started so that load balancers stop routing to it
+func (a *api) Ready() error {
+	if atomic.LoadInt32(&a.shuttingDown) == 1 {
+		return errors.New("server is shutting down")
 	}
 	return nil
 }
@@ -1,11 +1,11 @@
 package controllers
 
 import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strconv"
 )
 
 type AccountController struct {
@@ -16,6 +16,10 @@ type accountService interface {
 	FindByAccountNumber(accountNumber types.AccountNumber) (*models.Account, error)
 	Create(account *models.Account) (*models.Account, error)
 	Delete(accountNumber types.AccountNumber)
+	FindHolds(accountNumber types.AccountNumber) []*models.Hold
+	Search(filter *models.AccountFilter) (*models.AccountPage, error)
+	Update(accountNumber types.AccountNumber, version int, patch *models.AccountPatch) (*models.Account, error)
+	Changes(accountNumber types.AccountNumber) ([]*models.AccountChange, error)
 }
 
 func NewAccountController(s accountService) *AccountController {
@@ -25,71 +29,123 @@ func NewAccountController(s accountService) *AccountController {
 }
 
 func (ac *AccountController) Get(c *gin.Context) {
-	accountNumber, ok := c.Params.Get("accountNumber")
+	accountNumber, ok := accountNumberParam(c)
 	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid argument",
-		})
 		return
 	}
 
-	// account number's type must be integer so string value converting to integer type
-	accountNumberI, err := strconv.ParseInt(accountNumber, 10, 64)
+	account, err := ac.service.FindByAccountNumber(accountNumber)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid argument",
-		})
-		return
-	}
-
-	account, err := ac.service.FindByAccountNumber(types.AccountNumber(accountNumberI))
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "account not found",
-		})
+		abortWithProblem(c, err)
 		return
 	}
 
 	accountDTO := account.DTO()
 	if accountDTO == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "something is wrong",
-		})
+		abortWithProblem(c, errs.New(errs.Internal))
 		return
 	}
 
+	for _, hold := range ac.service.FindHolds(account.AccountNumber) {
+		accountDTO.Holds = append(accountDTO.Holds, hold.DTO())
+	}
+
+	c.Header("ETag", etag(account.Version))
 	c.JSON(http.StatusOK, accountDTO)
 	return
 }
 
 func (ac *AccountController) Create(c *gin.Context) {
 	var accountDTO *models.AccountDTO
-	err := c.BindJSON(&accountDTO)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "cannot bind json",
-		})
+	if !bindJSON(c, &accountDTO) {
 		return
 	}
 
 	account := accountDTO.Normal()
 
-	account, err = ac.service.Create(account)
+	account, err := ac.service.Create(account)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithProblem(c, err)
 		return
 	}
 
 	respAccountDTO := account.DTO()
 	if respAccountDTO == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "something is wrong",
-		})
+		abortWithProblem(c, errs.New(errs.Internal))
+		return
 	}
 
 	c.JSON(http.StatusCreated, respAccountDTO)
 
 	return
 }
+
+// Search returns a page of the accounts matching the ownerName, fuzzy, accountType, currencyCode,
+// minBalance, maxBalance, createdFrom and createdTo query parameters, paged by offset and limit
+func (ac *AccountController) Search(c *gin.Context) {
+	var filterDTO models.AccountFilterDTO
+	err := c.ShouldBindQuery(&filterDTO)
+	if err != nil {
+		abortWithProblem(c, errs.Newf(errs.InvalidArgument, "%v", err))
+		return
+	}
+
+	page, err := ac.service.Search(filterDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page.DTO())
+	return
+}
+
+// Update changes the owner name, contact info and metadata of the account.
+// With an If-Match header the update is only made if the account is still at that version.
+func (ac *AccountController) Update(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var patch *models.AccountPatch
+	if !bindJSON(c, &patch) {
+		return
+	}
+
+	account, err := ac.service.Update(accountNumber, version, patch)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(account.Version))
+	c.JSON(http.StatusOK, account.DTO())
+	return
+}
+
+// GetChanges returns the versions of the profile of the account, oldest first
+func (ac *AccountController) GetChanges(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	changes, err := ac.service.Changes(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	changeDTOs := []*models.AccountChangeDTO{}
+	for _, change := range changes {
+		changeDTOs = append(changeDTOs, change.DTO())
+	}
+
+	c.JSON(http.StatusOK, changeDTOs)
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/gin-gonic/gin"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -18,6 +20,10 @@ type mockAccountService struct {
 	FindByAccountNumberMock func(accountNumber types.AccountNumber) (*models.Account, error)
 	CreateMock              func(account *models.Account) (*models.Account, error)
 	DeleteMock              func(accountNumber types.AccountNumber)
+	FindHoldsMock           func(accountNumber types.AccountNumber) []*models.Hold
+	SearchMock              func(filter *models.AccountFilter) (*models.AccountPage, error)
+	UpdateMock              func(accountNumber types.AccountNumber, version int, patch *models.AccountPatch) (*models.Account, error)
+	ChangesMock             func(accountNumber types.AccountNumber) ([]*models.AccountChange, error)
 }
 
 func (m mockAccountService) FindByAccountNumber(accountNumber types.AccountNumber) (*models.Account, error) {
@@ -32,6 +38,25 @@ func (m *mockAccountService) Delete(accountNumber types.AccountNumber) {
 	m.DeleteMock(accountNumber)
 }
 
+func (m mockAccountService) FindHolds(accountNumber types.AccountNumber) []*models.Hold {
+	if m.FindHoldsMock == nil {
+		return nil
+	}
+	return m.FindHoldsMock(accountNumber)
+}
+
+func (m mockAccountService) Search(filter *models.AccountFilter) (*models.AccountPage, error) {
+	return m.SearchMock(filter)
+}
+
+func (m mockAccountService) Update(accountNumber types.AccountNumber, version int, patch *models.AccountPatch) (*models.Account, error) {
+	return m.UpdateMock(accountNumber, version, patch)
+}
+
+func (m mockAccountService) Changes(accountNumber types.AccountNumber) ([]*models.AccountChange, error) {
+	return m.ChangesMock(accountNumber)
+}
+
 func TestAccountController_Create(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
@@ -249,11 +274,71 @@ func TestAccountController_Get(t *testing.T) {
 		assert.Equal(t, types.AccountNumber(1), incomingAccount.AccountNumber)
 		assert.Equal(t, http.StatusOK, rr.Code)
 
+	})
+
+	t.Run("IBAN", func(t *testing.T) {
+
+		mockAccountServ := mockAccountService{
+			FindByAccountNumberMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				assert.Equal(t, types.AccountNumber(123456), accountNumber)
+				return &models.Account{
+					AccountNumber: accountNumber,
+					CurrencyCode:  types.TRY,
+					OwnerName:     "Ahmet Berke",
+					AccountType:   types.Individual,
+				}, nil
+			},
+		}
+
+		mockAccountController := NewAccountController(&mockAccountServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber", mockAccountController.Get)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/"+types.AccountNumber(123456).IBAN(), nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var accountDTO models.AccountDTO
+		err = json.NewDecoder(rr.Body).Decode(&accountDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.AccountNumber(123456).IBAN(), accountDTO.IBAN)
+		// The IBAN does not carry the account number itself
+		assert.False(t, strings.HasSuffix(accountDTO.IBAN, "0000000000123456"))
+
+	})
+
+	t.Run("WrongCheckDigits", func(t *testing.T) {
+
+		mockAccountController := NewAccountController(&mockAccountService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber", mockAccountController.Get)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/TR670099900000000000123456", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, string(errs.InvalidIBAN), problem["code"])
+
 	})
 	t.Run("AccountNotFound", func(t *testing.T) {
 		mockAccountServ := mockAccountService{
 			FindByAccountNumberMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
-				return nil, errors.New("account not found")
+				return nil, errs.New(errs.AccountNotFound)
 			},
 		}
 
@@ -281,8 +366,171 @@ func TestAccountController_Get(t *testing.T) {
 		err = json.NewDecoder(rr.Body).Decode(&incomingAccount)
 		assert.NoError(t, err)
 
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "application/problem+json; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	})
+
+}
+
+func TestAccountController_Search(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountServ := mockAccountService{
+			SearchMock: func(filter *models.AccountFilter) (*models.AccountPage, error) {
+				assert.Equal(t, "ahmet", filter.OwnerName)
+				assert.True(t, filter.Fuzzy)
+				assert.Equal(t, types.Individual, filter.AccountType)
+				assert.Equal(t, types.TRY, filter.CurrencyCode)
+				assert.True(t, filter.MinBalance.Equal(decimal.NewFromFloat(10)))
+				assert.Nil(t, filter.MaxBalance)
+				assert.Equal(t, 2022, filter.CreatedFrom.Year())
+				assert.Equal(t, 10, filter.Offset)
+				assert.Equal(t, 5, filter.Limit)
+				return &models.AccountPage{
+					Accounts: []*models.Account{
+						{AccountNumber: 11, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual},
+					},
+					Total:  11,
+					Offset: filter.Offset,
+					Limit:  filter.Limit,
+				}, nil
+			},
+		}
+		mockAccountController := NewAccountController(&mockAccountServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/accounts", mockAccountController.Search)
+
+		req, err := http.NewRequest(http.MethodGet, "/accounts?ownerName=ahmet&fuzzy=true&accountType=individual"+
+			"&currencyCode=TRY&minBalance=10&createdFrom=2022-05-01T00:00:00Z&offset=10&limit=5", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var pageDTO models.AccountPageDTO
+		err = json.NewDecoder(rr.Body).Decode(&pageDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 11, pageDTO.Total)
+		assert.Equal(t, 1, len(pageDTO.Accounts))
+	})
+	t.Run("InvalidQuery", func(t *testing.T) {
+		mockAccountController := NewAccountController(&mockAccountService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/accounts", mockAccountController.Search)
+
+		req, err := http.NewRequest(http.MethodGet, "/accounts?createdFrom=yesterday", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestAccountController_Update(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockAccountServ := mockAccountService{
+			UpdateMock: func(accountNumber types.AccountNumber, version int, patch *models.AccountPatch) (*models.Account, error) {
+				assert.Equal(t, 2, version)
+				assert.Equal(t, "Ahmet Berke", *patch.OwnerName)
+				return &models.Account{
+					AccountNumber: accountNumber,
+					CurrencyCode:  types.TRY,
+					OwnerName:     *patch.OwnerName,
+					AccountType:   types.Individual,
+					Version:       3,
+				}, nil
+			},
+		}
+		mockAccountController := NewAccountController(&mockAccountServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PATCH("/account/:accountNumber", mockAccountController.Update)
+
+		req, err := http.NewRequest(http.MethodPatch, "/account/1", bytes.NewBufferString(`{"ownerName":"Ahmet Berke"}`))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", `"2"`)
+
+		router.ServeHTTP(rr, req)
+
+		var accountDTO models.AccountDTO
+		err = json.NewDecoder(rr.Body).Decode(&accountDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+		assert.Equal(t, "Ahmet Berke", accountDTO.OwnerName)
+	})
+	t.Run("NullBody", func(t *testing.T) {
+		mockAccountController := NewAccountController(&mockAccountService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PATCH("/account/:accountNumber", mockAccountController.Update)
+
+		req, err := http.NewRequest(http.MethodPatch, "/account/1", bytes.NewBufferString(`null`))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, string(errs.InvalidRequestBody), problem["code"])
+	})
+	t.Run("InvalidIfMatch", func(t *testing.T) {
+		mockAccountController := NewAccountController(&mockAccountService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PATCH("/account/:accountNumber", mockAccountController.Update)
+
+		req, err := http.NewRequest(http.MethodPatch, "/account/1", bytes.NewBufferString(`{}`))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", `W/"2"`)
+
+		router.ServeHTTP(rr, req)
 
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
+	t.Run("VersionMismatch", func(t *testing.T) {
+		mockAccountServ := mockAccountService{
+			UpdateMock: func(accountNumber types.AccountNumber, version int, patch *models.AccountPatch) (*models.Account, error) {
+				return nil, errs.New(errs.VersionMismatch)
+			},
+		}
+		mockAccountController := NewAccountController(&mockAccountServ)
 
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PATCH("/account/:accountNumber", mockAccountController.Update)
+
+		req, err := http.NewRequest(http.MethodPatch, "/account/1", bytes.NewBufferString(`{"contact":{"email":"a@b.co"}}`))
+		assert.NoError(t, err)
+		req.Header.Set("If-Match", `"1"`)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	})
 }
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+const adminKeyHeader = "X-Admin-Key"
+
+// RequireAdminKey only lets the requests carrying the admin key through,
+// without a key configured every request is refused so that the admin routes are never left open
+func RequireAdminKey(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := c.GetHeader(adminKeyHeader)
+		if key == "" || subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+			abortWithProblem(c, errs.New(errs.AdminKeyInvalid))
+			return
+		}
+		c.Next()
+	}
+}
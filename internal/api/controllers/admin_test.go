@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireAdminKey(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	serve := func(key string, header string) (int, map[string]interface{}) {
+		router := gin.Default()
+		router.GET("/admin/events", RequireAdminKey(key), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{})
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/admin/events", nil)
+		assert.NoError(t, err)
+		if header != "" {
+			req.Header.Set("X-Admin-Key", header)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+		return rr.Code, body
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		status, _ := serve("secret", "secret")
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("AdminKeyInvalid", func(t *testing.T) {
+		for _, c := range []struct {
+			key    string
+			header string
+		}{
+			{"secret", ""},
+			{"secret", "guess"},
+			// Without a configured key the routes are closed
+			{"", ""},
+			{"", "anything"},
+		} {
+			status, body := serve(c.key, c.header)
+			assert.Equal(t, http.StatusUnauthorized, status)
+			assert.Equal(t, string(errs.AdminKeyInvalid), body["code"])
+		}
+	})
+}
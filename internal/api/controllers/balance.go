@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type BalanceController struct {
+	service balanceService
+}
+
+type balanceService interface {
+	BalanceAt(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error)
+}
+
+func NewBalanceController(s balanceService) *BalanceController {
+	return &BalanceController{service: s}
+}
+
+// GetAt returns the balance of the account at the RFC 3339 timestamp of the at query parameter,
+// without the parameter it returns the current balance
+func (bc *BalanceController) GetAt(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	var at time.Time
+	if atQuery := c.Query("at"); atQuery != "" {
+		var err error
+		at, err = time.Parse(time.RFC3339, atQuery)
+		if err != nil {
+			abortWithProblem(c, errs.Newf(errs.InvalidArgument, "at must be an RFC 3339 timestamp"))
+			return
+		}
+	}
+
+	balance, err := bc.service.BalanceAt(accountNumber, at)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balance.DTO())
+	return
+}
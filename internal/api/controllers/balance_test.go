@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockBalanceService struct {
+	BalanceAtMock func(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error)
+}
+
+func (m mockBalanceService) BalanceAt(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error) {
+	return m.BalanceAtMock(accountNumber, at)
+}
+
+func TestBalanceController_GetAt(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockBalanceServ := mockBalanceService{
+			BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error) {
+				assert.Equal(t, time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC), at.UTC())
+				return &models.HistoricalBalance{
+					AccountNumber: accountNumber,
+					CurrencyCode:  types.TRY,
+					Balance:       decimal.NewFromFloat(69.5),
+					At:            at,
+				}, nil
+			},
+		}
+		mockBalanceController := NewBalanceController(mockBalanceServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/balance", mockBalanceController.GetAt)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/1/balance?at=2022-05-01T12:00:00Z", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var balanceDTO models.HistoricalBalanceDTO
+		err = json.NewDecoder(rr.Body).Decode(&balanceDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 69.5, balanceDTO.Balance)
+	})
+	t.Run("InvalidTimestamp", func(t *testing.T) {
+		mockBalanceController := NewBalanceController(mockBalanceService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/balance", mockBalanceController.GetAt)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/1/balance?at=yesterday", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type CustomerController struct {
+	service customerService
+}
+
+type customerService interface {
+	Create(customer *models.Customer) (*models.Customer, error)
+	Get(id types.CustomerID) (*models.Customer, error)
+	UpdateKYC(id types.CustomerID, kyc *models.KYC) (*models.Customer, error)
+	OpenWallet(id types.CustomerID, currency types.Currency) (*models.Account, error)
+	Balances(id types.CustomerID, currency types.Currency) (*models.CustomerBalances, error)
+	Transfer(transfer *models.Transfer) (*models.TransferResult, error)
+}
+
+func NewCustomerController(s customerService) *CustomerController {
+	return &CustomerController{service: s}
+}
+
+// customerIDParam reads the customerID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func customerIDParam(c *gin.Context) (types.CustomerID, bool) {
+	id, err := strconv.ParseInt(c.Param("customerID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.CustomerID(id), true
+}
+
+func (cc *CustomerController) Create(c *gin.Context) {
+	var customerDTO models.CustomerDTO
+	if !bindJSON(c, &customerDTO) {
+		return
+	}
+
+	customer, err := customerDTO.Normal()
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	customer, err = cc.service.Create(customer)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, customer.DTO())
+	return
+}
+
+func (cc *CustomerController) Get(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	customer, err := cc.service.Get(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customer.DTO())
+	return
+}
+
+func (cc *CustomerController) UpdateKYC(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var kycDTO models.KYCDTO
+	if !bindJSON(c, &kycDTO) {
+		return
+	}
+
+	kyc, err := kycDTO.Normal()
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	customer, err := cc.service.UpdateKYC(id, kyc)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customer.DTO())
+	return
+}
+
+func (cc *CustomerController) OpenWallet(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var walletDTO models.WalletDTO
+	if !bindJSON(c, &walletDTO) {
+		return
+	}
+
+	account, err := cc.service.OpenWallet(id, walletDTO.CurrencyCode)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, account.DTO())
+	return
+}
+
+// GetBalances adds up the wallets of the customer in the currency query parameter
+func (cc *CustomerController) GetBalances(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	balances, err := cc.service.Balances(id, types.Currency(c.Query("currency")))
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, balances.DTO())
+	return
+}
+
+func (cc *CustomerController) Transfer(c *gin.Context) {
+	id, ok := customerIDParam(c)
+	if !ok {
+		return
+	}
+
+	var transferDTO models.TransferDTO
+	if !bindJSON(c, &transferDTO) {
+		return
+	}
+
+	result, err := cc.service.Transfer(transferDTO.Normal(id))
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.DTO())
+	return
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockCustomerService struct {
+	CreateMock     func(customer *models.Customer) (*models.Customer, error)
+	GetMock        func(id types.CustomerID) (*models.Customer, error)
+	UpdateKYCMock  func(id types.CustomerID, kyc *models.KYC) (*models.Customer, error)
+	OpenWalletMock func(id types.CustomerID, currency types.Currency) (*models.Account, error)
+	BalancesMock   func(id types.CustomerID, currency types.Currency) (*models.CustomerBalances, error)
+	TransferMock   func(transfer *models.Transfer) (*models.TransferResult, error)
+}
+
+func (m mockCustomerService) Create(customer *models.Customer) (*models.Customer, error) {
+	return m.CreateMock(customer)
+}
+
+func (m mockCustomerService) Get(id types.CustomerID) (*models.Customer, error) {
+	return m.GetMock(id)
+}
+
+func (m mockCustomerService) UpdateKYC(id types.CustomerID, kyc *models.KYC) (*models.Customer, error) {
+	return m.UpdateKYCMock(id, kyc)
+}
+
+func (m mockCustomerService) OpenWallet(id types.CustomerID, currency types.Currency) (*models.Account, error) {
+	return m.OpenWalletMock(id, currency)
+}
+
+func (m mockCustomerService) Balances(id types.CustomerID, currency types.Currency) (*models.CustomerBalances, error) {
+	return m.BalancesMock(id, currency)
+}
+
+func (m mockCustomerService) Transfer(transfer *models.Transfer) (*models.TransferResult, error) {
+	return m.TransferMock(transfer)
+}
+
+func TestCustomerController_Create(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerServ := mockCustomerService{
+			CreateMock: func(customer *models.Customer) (*models.Customer, error) {
+				assert.Equal(t, "1990-05-17", customer.KYC.DateOfBirth.Format("2006-01-02"))
+				customer.ID = 1
+				return customer, nil
+			},
+		}
+		mockCustomerController := NewCustomerController(mockCustomerServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/customers", mockCustomerController.Create)
+
+		reqBody, err := json.Marshal(models.CustomerDTO{
+			Name:         "Ada Lovelace",
+			CustomerType: types.Individual,
+			KYC:          &models.KYCDTO{DateOfBirth: "1990-05-17", Nationality: "TR"},
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/customers", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var customerDTO models.CustomerDTO
+		err = json.NewDecoder(rr.Body).Decode(&customerDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, types.CustomerID(1), customerDTO.ID)
+		assert.Equal(t, "1990-05-17", customerDTO.KYC.DateOfBirth)
+	})
+
+	t.Run("InvalidDateOfBirth", func(t *testing.T) {
+		mockCustomerController := NewCustomerController(mockCustomerService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/customers", mockCustomerController.Create)
+
+		reqBody, err := json.Marshal(models.CustomerDTO{
+			Name:         "Ada Lovelace",
+			CustomerType: types.Individual,
+			KYC:          &models.KYCDTO{DateOfBirth: "17.05.1990"},
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/customers", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestCustomerController_Get(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("CustomerNotFound", func(t *testing.T) {
+		mockCustomerServ := mockCustomerService{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				assert.Equal(t, types.CustomerID(7), id)
+				return nil, errs.New(errs.CustomerNotFound)
+			},
+		}
+		mockCustomerController := NewCustomerController(mockCustomerServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/customers/:customerID", mockCustomerController.Get)
+
+		req, err := http.NewRequest(http.MethodGet, "/customers/7", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		mockCustomerController := NewCustomerController(mockCustomerService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/customers/:customerID", mockCustomerController.Get)
+
+		req, err := http.NewRequest(http.MethodGet, "/customers/abc", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestCustomerController_OpenWallet(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("WalletAlreadyExists", func(t *testing.T) {
+		mockCustomerServ := mockCustomerService{
+			OpenWalletMock: func(id types.CustomerID, currency types.Currency) (*models.Account, error) {
+				assert.Equal(t, types.USD, currency)
+				return nil, errs.New(errs.WalletAlreadyExists)
+			},
+		}
+		mockCustomerController := NewCustomerController(mockCustomerServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/customers/:customerID/wallets", mockCustomerController.OpenWallet)
+
+		reqBody, err := json.Marshal(models.WalletDTO{CurrencyCode: types.USD})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/customers/1/wallets", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestCustomerController_GetBalances(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockCustomerServ := mockCustomerService{
+		BalancesMock: func(id types.CustomerID, currency types.Currency) (*models.CustomerBalances, error) {
+			assert.Equal(t, types.USD, currency)
+			return &models.CustomerBalances{
+				CustomerID:   id,
+				CurrencyCode: currency,
+				Total:        decimal.NewFromFloat(40.77),
+				Available:    decimal.NewFromFloat(40.77),
+			}, nil
+		},
+	}
+	mockCustomerController := NewCustomerController(mockCustomerServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/customers/:customerID/balances", mockCustomerController.GetBalances)
+
+	req, err := http.NewRequest(http.MethodGet, "/customers/1/balances?currency=USD", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	var balancesDTO models.CustomerBalancesDTO
+	err = json.NewDecoder(rr.Body).Decode(&balancesDTO)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, types.CustomerID(1), balancesDTO.CustomerID)
+	assert.Equal(t, 40.77, balancesDTO.Total)
+}
+
+func TestCustomerController_Transfer(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockCustomerServ := mockCustomerService{
+			TransferMock: func(transfer *models.Transfer) (*models.TransferResult, error) {
+				assert.Equal(t, types.CustomerID(1), transfer.CustomerID)
+				return &models.TransferResult{
+					Debit:  &models.Transaction{ID: 1, AccountNumber: transfer.FromAccount, Amount: transfer.Amount},
+					Credit: &models.Transaction{ID: 2, ParentID: 1, AccountNumber: transfer.ToAccount, Amount: decimal.NewFromFloat(130)},
+					Rate:   decimal.NewFromFloat(32.5),
+				}, nil
+			},
+		}
+		mockCustomerController := NewCustomerController(mockCustomerServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/customers/:customerID/transfers", mockCustomerController.Transfer)
+
+		reqBody, err := json.Marshal(models.TransferDTO{FromAccount: 2, ToAccount: 1, Amount: 4})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/customers/1/transfers", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var resultDTO models.TransferResultDTO
+		err = json.NewDecoder(rr.Body).Decode(&resultDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, 32.5, resultDTO.Rate)
+		assert.Equal(t, 130.0, resultDTO.Credit.Amount)
+	})
+
+	t.Run("RateNotFound", func(t *testing.T) {
+		mockCustomerServ := mockCustomerService{
+			TransferMock: func(transfer *models.Transfer) (*models.TransferResult, error) {
+				return nil, errs.New(errs.RateNotFound)
+			},
+		}
+		mockCustomerController := NewCustomerController(mockCustomerServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/customers/:customerID/transfers", mockCustomerController.Transfer)
+
+		reqBody, err := json.Marshal(models.TransferDTO{FromAccount: 2, ToAccount: 1, Amount: 4})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/customers/1/transfers", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
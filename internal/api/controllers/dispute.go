@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type DisputeController struct {
+	service disputeService
+}
+
+type disputeService interface {
+	Open(dispute *models.Dispute, full bool) (*models.Dispute, error)
+	Resolve(id types.DisputeID, status types.DisputeStatus, note string) (*models.Dispute, error)
+	Get(id types.DisputeID) (*models.Dispute, error)
+	FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Dispute, error)
+	FindOpen() []*models.Dispute
+}
+
+func NewDisputeController(s disputeService) *DisputeController {
+	return &DisputeController{service: s}
+}
+
+// disputeIDParam reads the disputeID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func disputeIDParam(c *gin.Context) (types.DisputeID, bool) {
+	id, err := strconv.ParseInt(c.Param("disputeID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.DisputeID(id), true
+}
+
+func (dc *DisputeController) Open(c *gin.Context) {
+	var disputeDTO models.DisputeOpenDTO
+	if !bindJSON(c, &disputeDTO) {
+		return
+	}
+
+	dispute, err := dc.service.Open(disputeDTO.Normal(), disputeDTO.Full)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dispute.DTO())
+	return
+}
+
+func (dc *DisputeController) Get(c *gin.Context) {
+	id, ok := disputeIDParam(c)
+	if !ok {
+		return
+	}
+
+	dispute, err := dc.service.Get(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute.DTO())
+	return
+}
+
+// Resolve closes the dispute in favour of the customer with charged_back or of the merchant with released
+func (dc *DisputeController) Resolve(c *gin.Context) {
+	id, ok := disputeIDParam(c)
+	if !ok {
+		return
+	}
+
+	var resolutionDTO *models.DisputeResolutionDTO
+	if !bindJSON(c, &resolutionDTO) {
+		return
+	}
+
+	dispute, err := dc.service.Resolve(id, resolutionDTO.Status, resolutionDTO.Note)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute.DTO())
+	return
+}
+
+// GetByAccountNumber returns the disputes the account opened or is the merchant of
+func (dc *DisputeController) GetByAccountNumber(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	disputes, err := dc.service.FindByAccountNumber(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	disputesDTO := []*models.DisputeDTO{}
+	for _, d := range disputes {
+		disputesDTO = append(disputesDTO, d.DTO())
+	}
+
+	c.JSON(http.StatusOK, disputesDTO)
+	return
+}
+
+// GetOpen returns the disputes waiting for the decision of an operator
+func (dc *DisputeController) GetOpen(c *gin.Context) {
+	disputesDTO := []*models.DisputeDTO{}
+	for _, d := range dc.service.FindOpen() {
+		disputesDTO = append(disputesDTO, d.DTO())
+	}
+
+	c.JSON(http.StatusOK, disputesDTO)
+	return
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockDisputeService struct {
+	OpenMock                func(dispute *models.Dispute, full bool) (*models.Dispute, error)
+	ResolveMock             func(id types.DisputeID, status types.DisputeStatus, note string) (*models.Dispute, error)
+	GetMock                 func(id types.DisputeID) (*models.Dispute, error)
+	FindByAccountNumberMock func(accountNumber types.AccountNumber) ([]*models.Dispute, error)
+	FindOpenMock            func() []*models.Dispute
+}
+
+func (m mockDisputeService) Open(dispute *models.Dispute, full bool) (*models.Dispute, error) {
+	return m.OpenMock(dispute, full)
+}
+
+func (m mockDisputeService) Resolve(id types.DisputeID, status types.DisputeStatus, note string) (*models.Dispute, error) {
+	return m.ResolveMock(id, status, note)
+}
+
+func (m mockDisputeService) Get(id types.DisputeID) (*models.Dispute, error) {
+	return m.GetMock(id)
+}
+
+func (m mockDisputeService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Dispute, error) {
+	return m.FindByAccountNumberMock(accountNumber)
+}
+
+func (m mockDisputeService) FindOpen() []*models.Dispute {
+	return m.FindOpenMock()
+}
+
+func TestDisputeController_Open(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockDisputeServ := mockDisputeService{
+			OpenMock: func(dispute *models.Dispute, full bool) (*models.Dispute, error) {
+				assert.Equal(t, types.TransactionID(7), dispute.PaymentID)
+				assert.True(t, full)
+				assert.Equal(t, "not delivered", dispute.Reason)
+				dispute.ID = 1
+				dispute.Status = types.DisputeOpen
+				dispute.History = []*models.DisputeTransition{{Status: types.DisputeOpen, Note: dispute.Reason}}
+				return dispute, nil
+			},
+		}
+		mockDisputeController := NewDisputeController(mockDisputeServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/disputes", mockDisputeController.Open)
+
+		reqBody, err := json.Marshal(models.DisputeOpenDTO{
+			CustomerAccount: 1,
+			PaymentID:       7,
+			Full:            true,
+			Reason:          "not delivered",
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/disputes", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var disputeDTO models.DisputeDTO
+		err = json.NewDecoder(rr.Body).Decode(&disputeDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, types.DisputeOpen, disputeDTO.Status)
+		assert.Equal(t, 1, len(disputeDTO.History))
+		assert.Nil(t, disputeDTO.ResolvedAt)
+	})
+
+	t.Run("DisputeExceedsPayment", func(t *testing.T) {
+		mockDisputeServ := mockDisputeService{
+			OpenMock: func(dispute *models.Dispute, full bool) (*models.Dispute, error) {
+				return nil, errs.Newf(errs.DisputeExceedsPayment, "undisputed amount is 40.00")
+			},
+		}
+		mockDisputeController := NewDisputeController(mockDisputeServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/disputes", mockDisputeController.Open)
+
+		reqBody, err := json.Marshal(models.DisputeOpenDTO{CustomerAccount: 1, PaymentID: 7, Amount: 50})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/disputes", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestDisputeController_Resolve(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		resolvedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		mockDisputeServ := mockDisputeService{
+			ResolveMock: func(id types.DisputeID, status types.DisputeStatus, note string) (*models.Dispute, error) {
+				assert.Equal(t, types.DisputeChargedBack, status)
+				assert.Equal(t, "merchant did not answer", note)
+				return &models.Dispute{ID: id, Amount: decimal.NewFromInt(100), Status: status, ResolutionID: 5, ResolvedAt: resolvedAt}, nil
+			},
+		}
+		mockDisputeController := NewDisputeController(mockDisputeServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/disputes/:disputeID/resolve", mockDisputeController.Resolve)
+
+		reqBody, err := json.Marshal(models.DisputeResolutionDTO{Status: types.DisputeChargedBack, Note: "merchant did not answer"})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/disputes/1/resolve", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var disputeDTO models.DisputeDTO
+		err = json.NewDecoder(rr.Body).Decode(&disputeDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.DisputeChargedBack, disputeDTO.Status)
+		assert.Equal(t, types.TransactionID(5), disputeDTO.ResolutionID)
+		assert.True(t, resolvedAt.Equal(*disputeDTO.ResolvedAt))
+	})
+
+	t.Run("DisputeNotOpen", func(t *testing.T) {
+		mockDisputeServ := mockDisputeService{
+			ResolveMock: func(id types.DisputeID, status types.DisputeStatus, note string) (*models.Dispute, error) {
+				return nil, errs.Newf(errs.DisputeNotOpen, "dispute is released")
+			},
+		}
+		mockDisputeController := NewDisputeController(mockDisputeServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/disputes/:disputeID/resolve", mockDisputeController.Resolve)
+
+		reqBody, err := json.Marshal(models.DisputeResolutionDTO{Status: types.DisputeReleased})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/disputes/1/resolve", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestDisputeController_Get(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockDisputeServ := mockDisputeService{
+		GetMock: func(id types.DisputeID) (*models.Dispute, error) {
+			return nil, errs.New(errs.DisputeNotFound)
+		},
+	}
+	mockDisputeController := NewDisputeController(mockDisputeServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/disputes/:disputeID", mockDisputeController.Get)
+
+	req, err := http.NewRequest(http.MethodGet, "/disputes/9", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestDisputeController_GetOpen(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockDisputeServ := mockDisputeService{
+		FindOpenMock: func() []*models.Dispute {
+			return []*models.Dispute{
+				{ID: 1, CustomerAccount: 1, MerchantAccount: 2, Status: types.DisputeOpen},
+				{ID: 3, CustomerAccount: 4, MerchantAccount: 2, Status: types.DisputeOpen},
+			}
+		},
+	}
+	mockDisputeController := NewDisputeController(mockDisputeServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/admin/disputes", mockDisputeController.GetOpen)
+
+	req, err := http.NewRequest(http.MethodGet, "/admin/disputes", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	var disputesDTO []*models.DisputeDTO
+	err = json.NewDecoder(rr.Body).Decode(&disputesDTO)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 2, len(disputesDTO))
+}
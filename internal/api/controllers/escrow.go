@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type EscrowController struct {
+	service escrowService
+}
+
+type escrowService interface {
+	Fund(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error)
+	Release(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error)
+	Refund(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error)
+	Get(id types.EscrowID) (*models.Escrow, error)
+	FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Escrow, error)
+}
+
+func NewEscrowController(s escrowService) *EscrowController {
+	return &EscrowController{service: s}
+}
+
+// escrowIDParam reads the escrowID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func escrowIDParam(c *gin.Context) (types.EscrowID, bool) {
+	id, err := strconv.ParseInt(c.Param("escrowID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.EscrowID(id), true
+}
+
+func (ec *EscrowController) Fund(c *gin.Context) {
+	var escrowDTO models.EscrowFundDTO
+	if !bindJSON(c, &escrowDTO) {
+		return
+	}
+
+	escrowTTL, ok := ttl(c, escrowDTO.TTLSeconds)
+	if !ok {
+		return
+	}
+
+	escrow, err := ec.service.Fund(escrowDTO.Normal(), escrowTTL)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, escrow.DTO())
+	return
+}
+
+func (ec *EscrowController) Get(c *gin.Context) {
+	id, ok := escrowIDParam(c)
+	if !ok {
+		return
+	}
+
+	escrow, err := ec.service.Get(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow.DTO())
+	return
+}
+
+// Release pays the escrowed funds to the receiver on the instruction of the sender
+func (ec *EscrowController) Release(c *gin.Context) {
+	id, ok := escrowIDParam(c)
+	if !ok {
+		return
+	}
+
+	var settleDTO models.EscrowSettleDTO
+	if !bindJSON(c, &settleDTO) {
+		return
+	}
+
+	escrow, err := ec.service.Release(id, settleDTO.AccountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow.DTO())
+	return
+}
+
+// Refund pays the escrowed funds back to the sender on the instruction of the receiver
+func (ec *EscrowController) Refund(c *gin.Context) {
+	id, ok := escrowIDParam(c)
+	if !ok {
+		return
+	}
+
+	var settleDTO models.EscrowSettleDTO
+	if !bindJSON(c, &settleDTO) {
+		return
+	}
+
+	escrow, err := ec.service.Refund(id, settleDTO.AccountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, escrow.DTO())
+	return
+}
+
+// GetByAccountNumber returns the escrows the account is the sender or the receiver of
+func (ec *EscrowController) GetByAccountNumber(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	escrows, err := ec.service.FindByAccountNumber(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	escrowsDTO := []*models.EscrowDTO{}
+	for _, e := range escrows {
+		escrowsDTO = append(escrowsDTO, e.DTO())
+	}
+
+	c.JSON(http.StatusOK, escrowsDTO)
+	return
+}
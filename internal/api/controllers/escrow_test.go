@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockEscrowService struct {
+	FundMock                func(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error)
+	ReleaseMock             func(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error)
+	RefundMock              func(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error)
+	GetMock                 func(id types.EscrowID) (*models.Escrow, error)
+	FindByAccountNumberMock func(accountNumber types.AccountNumber) ([]*models.Escrow, error)
+}
+
+func (m mockEscrowService) Fund(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error) {
+	return m.FundMock(escrow, ttl)
+}
+
+func (m mockEscrowService) Release(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+	return m.ReleaseMock(id, accountNumber)
+}
+
+func (m mockEscrowService) Refund(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+	return m.RefundMock(id, accountNumber)
+}
+
+func (m mockEscrowService) Get(id types.EscrowID) (*models.Escrow, error) {
+	return m.GetMock(id)
+}
+
+func (m mockEscrowService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Escrow, error) {
+	return m.FindByAccountNumberMock(accountNumber)
+}
+
+func TestEscrowController_Fund(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockEscrowServ := mockEscrowService{
+			FundMock: func(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error) {
+				assert.Equal(t, 24*time.Hour, ttl)
+				assert.Equal(t, types.EscrowReleased, escrow.OnTimeout)
+				escrow.ID = 1
+				escrow.Status = types.EscrowFunded
+				return escrow, nil
+			},
+		}
+		mockEscrowController := NewEscrowController(mockEscrowServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/escrows", mockEscrowController.Fund)
+
+		reqBody, err := json.Marshal(models.EscrowFundDTO{
+			SenderAccount:   1,
+			ReceiverAccount: 2,
+			Amount:          100,
+			OnTimeout:       types.EscrowReleased,
+			TTLSeconds:      86400,
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/escrows", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var escrowDTO models.EscrowDTO
+		err = json.NewDecoder(rr.Body).Decode(&escrowDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, types.EscrowID(1), escrowDTO.ID)
+		assert.Equal(t, types.EscrowFunded, escrowDTO.Status)
+		assert.Nil(t, escrowDTO.SettledAt)
+	})
+
+	t.Run("NegativeTTL", func(t *testing.T) {
+		mockEscrowController := NewEscrowController(mockEscrowService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/escrows", mockEscrowController.Fund)
+
+		req, err := http.NewRequest(http.MethodPost, "/escrows", bytes.NewBufferString(`{"senderAccount":1,"receiverAccount":2,"amount":100,"ttlSeconds":-9223372036854775807}`))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, string(errs.InvalidArgument), problem["code"])
+	})
+}
+
+func TestEscrowController_Release(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		settledAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		mockEscrowServ := mockEscrowService{
+			ReleaseMock: func(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+				assert.Equal(t, types.AccountNumber(1), accountNumber)
+				return &models.Escrow{ID: id, Status: types.EscrowReleased, SettledAt: settledAt}, nil
+			},
+		}
+		mockEscrowController := NewEscrowController(mockEscrowServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/escrows/:escrowID/release", mockEscrowController.Release)
+
+		reqBody, err := json.Marshal(models.EscrowSettleDTO{AccountNumber: 1})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/escrows/1/release", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var escrowDTO models.EscrowDTO
+		err = json.NewDecoder(rr.Body).Decode(&escrowDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.EscrowReleased, escrowDTO.Status)
+		assert.True(t, settledAt.Equal(*escrowDTO.SettledAt))
+	})
+
+	t.Run("EscrowNotFunded", func(t *testing.T) {
+		mockEscrowServ := mockEscrowService{
+			ReleaseMock: func(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+				return nil, errs.Newf(errs.EscrowNotFunded, "escrow is refunded")
+			},
+		}
+		mockEscrowController := NewEscrowController(mockEscrowServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/escrows/:escrowID/release", mockEscrowController.Release)
+
+		reqBody, err := json.Marshal(models.EscrowSettleDTO{AccountNumber: 1})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/escrows/1/release", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestEscrowController_Refund(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockEscrowServ := mockEscrowService{
+		RefundMock: func(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+			return nil, errs.New(errs.EscrowNotFound)
+		},
+	}
+	mockEscrowController := NewEscrowController(mockEscrowServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.POST("/escrows/:escrowID/refund", mockEscrowController.Refund)
+
+	reqBody, err := json.Marshal(models.EscrowSettleDTO{AccountNumber: 2})
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, "/escrows/9/refund", bytes.NewBuffer(reqBody))
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestEscrowController_GetByAccountNumber(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockEscrowServ := mockEscrowService{
+		FindByAccountNumberMock: func(accountNumber types.AccountNumber) ([]*models.Escrow, error) {
+			return []*models.Escrow{
+				{ID: 1, SenderAccount: accountNumber, ReceiverAccount: 2, Status: types.EscrowFunded},
+				{ID: 2, SenderAccount: 3, ReceiverAccount: accountNumber, Status: types.EscrowReleased},
+			}, nil
+		},
+	}
+	mockEscrowController := NewEscrowController(mockEscrowServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/account/:accountNumber/escrows", mockEscrowController.GetByAccountNumber)
+
+	req, err := http.NewRequest(http.MethodGet, "/account/1/escrows", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	var escrowsDTO []*models.EscrowDTO
+	err = json.NewDecoder(rr.Body).Decode(&escrowsDTO)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 2, len(escrowsDTO))
+}
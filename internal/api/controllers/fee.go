@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type FeeController struct {
+	service feeService
+}
+
+type feeService interface {
+	Schedules() []*models.FeeSchedule
+	HouseAccounts() map[types.Currency]types.AccountNumber
+}
+
+func NewFeeController(s feeService) *FeeController {
+	return &FeeController{service: s}
+}
+
+// GetSchedules returns the fee schedules and the accounts collecting the fees
+func (fc *FeeController) GetSchedules(c *gin.Context) {
+	schedulesDTO := []*models.FeeScheduleDTO{}
+	for _, s := range fc.service.Schedules() {
+		schedulesDTO = append(schedulesDTO, s.DTO())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"schedules":     schedulesDTO,
+		"houseAccounts": fc.service.HouseAccounts(),
+	})
+	return
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockFeeService struct {
+	SchedulesMock     func() []*models.FeeSchedule
+	HouseAccountsMock func() map[types.Currency]types.AccountNumber
+}
+
+func (m mockFeeService) Schedules() []*models.FeeSchedule {
+	return m.SchedulesMock()
+}
+
+func (m mockFeeService) HouseAccounts() map[types.Currency]types.AccountNumber {
+	return m.HouseAccountsMock()
+}
+
+func TestFeeController_GetSchedules(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockFeeServ := mockFeeService{
+			SchedulesMock: func() []*models.FeeSchedule {
+				return []*models.FeeSchedule{
+					{Name: "merchant", TransactionType: types.Payment, Payer: types.ReceiverPays, Percentage: decimal.NewFromFloat(1.5)},
+				}
+			},
+			HouseAccountsMock: func() map[types.Currency]types.AccountNumber {
+				return map[types.Currency]types.AccountNumber{types.TRY: 3}
+			},
+		}
+		mockFeeController := NewFeeController(mockFeeServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/fees", mockFeeController.GetSchedules)
+
+		req, err := http.NewRequest(http.MethodGet, "/fees", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var resp struct {
+			Schedules     []*models.FeeScheduleDTO               `json:"schedules"`
+			HouseAccounts map[types.Currency]types.AccountNumber `json:"houseAccounts"`
+		}
+		err = json.NewDecoder(rr.Body).Decode(&resp)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1.5, resp.Schedules[0].Percentage)
+		assert.Equal(t, types.AccountNumber(3), resp.HouseAccounts[types.TRY])
+	})
+}
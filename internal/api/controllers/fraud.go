@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type FraudController struct {
+	service fraudService
+}
+
+type fraudService interface {
+	GetAlert(id types.FraudAlertID) (*models.FraudAlert, error)
+	FindAlerts(status types.FraudAlertStatus) ([]*models.FraudAlert, error)
+	Decide(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error)
+}
+
+func NewFraudController(s fraudService) *FraudController {
+	return &FraudController{service: s}
+}
+
+// alertIDParam reads the alertID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func alertIDParam(c *gin.Context) (types.FraudAlertID, bool) {
+	id, err := strconv.ParseInt(c.Param("alertID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.FraudAlertID(id), true
+}
+
+// GetAlerts returns every alert, or only the ones in the status given with the status query parameter
+func (fc *FraudController) GetAlerts(c *gin.Context) {
+	alerts, err := fc.service.FindAlerts(types.FraudAlertStatus(c.Query("status")))
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	alertsDTO := []*models.FraudAlertDTO{}
+	for _, alert := range alerts {
+		alertsDTO = append(alertsDTO, alert.DTO())
+	}
+
+	c.JSON(http.StatusOK, alertsDTO)
+	return
+}
+
+func (fc *FraudController) GetAlert(c *gin.Context) {
+	id, ok := alertIDParam(c)
+	if !ok {
+		return
+	}
+
+	alert, err := fc.service.GetAlert(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert.DTO())
+	return
+}
+
+// Decide approves the transaction held by a pending alert or rejects it
+func (fc *FraudController) Decide(c *gin.Context) {
+	id, ok := alertIDParam(c)
+	if !ok {
+		return
+	}
+
+	var decisionDTO *models.FraudDecisionDTO
+	if !bindJSON(c, &decisionDTO) {
+		return
+	}
+
+	alert, err := fc.service.Decide(id, decisionDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert.DTO())
+	return
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockFraudService struct {
+	GetAlertMock   func(id types.FraudAlertID) (*models.FraudAlert, error)
+	FindAlertsMock func(status types.FraudAlertStatus) ([]*models.FraudAlert, error)
+	DecideMock     func(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error)
+}
+
+func (m mockFraudService) GetAlert(id types.FraudAlertID) (*models.FraudAlert, error) {
+	return m.GetAlertMock(id)
+}
+
+func (m mockFraudService) FindAlerts(status types.FraudAlertStatus) ([]*models.FraudAlert, error) {
+	return m.FindAlertsMock(status)
+}
+
+func (m mockFraudService) Decide(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error) {
+	return m.DecideMock(id, decision)
+}
+
+func TestFraudController_GetAlerts(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockFraudServ := mockFraudService{
+			FindAlertsMock: func(status types.FraudAlertStatus) ([]*models.FraudAlert, error) {
+				assert.Equal(t, types.FraudAlertPending, status)
+				return []*models.FraudAlert{
+					{
+						ID:              1,
+						AccountNumber:   1,
+						TransactionType: types.Withdraw,
+						Amount:          decimal.NewFromInt(10),
+						Hits:            []*models.FraudHit{{Rule: "frequent withdrawals", Kind: types.VelocityRule, Action: types.FraudReview}},
+						Action:          types.FraudReview,
+						Status:          types.FraudAlertPending,
+						Withdraw:        &models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(10)},
+					},
+				}, nil
+			},
+		}
+		mockFraudController := NewFraudController(mockFraudServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/admin/fraud/alerts", mockFraudController.GetAlerts)
+
+		req, err := http.NewRequest(http.MethodGet, "/admin/fraud/alerts?status=pending", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var alertsDTO []*models.FraudAlertDTO
+		err = json.NewDecoder(rr.Body).Decode(&alertsDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1, len(alertsDTO))
+		assert.Equal(t, "frequent withdrawals", alertsDTO[0].Hits[0].Rule)
+		assert.Equal(t, float64(10), alertsDTO[0].Withdraw.Amount)
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		mockFraudServ := mockFraudService{
+			FindAlertsMock: func(status types.FraudAlertStatus) ([]*models.FraudAlert, error) {
+				return nil, errs.Newf(errs.InvalidArgument, "unknown status %q", status)
+			},
+		}
+		mockFraudController := NewFraudController(mockFraudServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/admin/fraud/alerts", mockFraudController.GetAlerts)
+
+		req, err := http.NewRequest(http.MethodGet, "/admin/fraud/alerts?status=open", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestFraudController_GetAlert(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockFraudServ := mockFraudService{
+		GetAlertMock: func(id types.FraudAlertID) (*models.FraudAlert, error) {
+			return nil, errs.New(errs.FraudAlertNotFound)
+		},
+	}
+	mockFraudController := NewFraudController(mockFraudServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/admin/fraud/alerts/:alertID", mockFraudController.GetAlert)
+
+	req, err := http.NewRequest(http.MethodGet, "/admin/fraud/alerts/9", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestFraudController_Decide(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockFraudServ := mockFraudService{
+			DecideMock: func(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error) {
+				assert.Equal(t, types.FraudAlertID(1), id)
+				assert.Equal(t, types.FraudAlertApproved, decision.Status)
+				return &models.FraudAlert{ID: id, Status: decision.Status, Reviewer: decision.Reviewer, TransactionID: 7}, nil
+			},
+		}
+		mockFraudController := NewFraudController(mockFraudServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/fraud/alerts/:alertID/decision", mockFraudController.Decide)
+
+		reqBody, err := json.Marshal(models.FraudDecisionDTO{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/fraud/alerts/1/decision", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var alertDTO models.FraudAlertDTO
+		err = json.NewDecoder(rr.Body).Decode(&alertDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.FraudAlertApproved, alertDTO.Status)
+		assert.Equal(t, types.TransactionID(7), alertDTO.TransactionID)
+	})
+
+	t.Run("FraudAlertDecided", func(t *testing.T) {
+		mockFraudServ := mockFraudService{
+			DecideMock: func(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error) {
+				return nil, errs.Newf(errs.FraudAlertDecided, "alert is rejected")
+			},
+		}
+		mockFraudController := NewFraudController(mockFraudServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/fraud/alerts/:alertID/decision", mockFraudController.Decide)
+
+		reqBody, err := json.Marshal(models.FraudDecisionDTO{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/fraud/alerts/1/decision", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type HealthController struct {
+	checks map[string]readinessChecker
+}
+
+type readinessChecker interface {
+	Ready() error
+}
+
+func NewHealthController() *HealthController {
+	return &HealthController{checks: make(map[string]readinessChecker)}
+}
+
+// AddCheck registers a named dependency
+// that must be available before the api accepts traffic
+func (hc *HealthController) AddCheck(name string, checker readinessChecker) {
+	hc.checks[name] = checker
+}
+
+// Health reports that the process is alive, it never touches the storage
+func (hc *HealthController) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+	return
+}
+
+// Ready reports whether every registered dependency is available
+func (hc *HealthController) Ready(c *gin.Context) {
+	ready := true
+	checks := gin.H{}
+	for name, checker := range hc.checks {
+		if err := checker.Ready(); err != nil {
+			ready = false
+			checks[name] = err.Error()
+			continue
+		}
+		checks[name] = "ok"
+	}
+
+	if !ready {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unavailable",
+			"checks": checks,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"checks": checks,
+	})
+	return
+}
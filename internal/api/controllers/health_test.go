@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockReadinessChecker struct {
+	ReadyMock func() error
+}
+
+func (m mockReadinessChecker) Ready() error {
+	return m.ReadyMock()
+}
+
+func TestHealthController_Health(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		healthController := NewHealthController()
+		healthController.AddCheck("accounts", mockReadinessChecker{
+			ReadyMock: func() error {
+				return errors.New("account cache is not initialized")
+			},
+		})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/healthz", healthController.Health)
+
+		req, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
+
+func TestHealthController_Ready(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		healthController := NewHealthController()
+		healthController.AddCheck("accounts", mockReadinessChecker{
+			ReadyMock: func() error {
+				return nil
+			},
+		})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/readyz", healthController.Ready)
+
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("StorageUnavailable", func(t *testing.T) {
+		healthController := NewHealthController()
+		healthController.AddCheck("accounts", mockReadinessChecker{
+			ReadyMock: func() error {
+				return nil
+			},
+		})
+		healthController.AddCheck("transactions", mockReadinessChecker{
+			ReadyMock: func() error {
+				return errors.New("transaction cache is not initialized")
+			},
+		})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/readyz", healthController.Ready)
+
+		req, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+}
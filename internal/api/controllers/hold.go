@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type HoldController struct {
+	service holdService
+}
+
+type holdService interface {
+	Place(hold *models.Hold, ttl time.Duration) (*models.Hold, error)
+	Capture(id types.HoldID, amount decimal.Decimal, full bool) (*models.Hold, error)
+	Void(id types.HoldID) (*models.Hold, error)
+	Get(id types.HoldID) (*models.Hold, error)
+}
+
+func NewHoldController(s holdService) *HoldController {
+	return &HoldController{service: s}
+}
+
+// holdIDParam reads the holdID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func holdIDParam(c *gin.Context) (types.HoldID, bool) {
+	id, err := strconv.ParseInt(c.Param("holdID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.HoldID(id), true
+}
+
+func (hc *HoldController) Place(c *gin.Context) {
+	var holdDTO models.HoldPlaceDTO
+	if !bindJSON(c, &holdDTO) {
+		return
+	}
+
+	holdTTL, ok := ttl(c, holdDTO.TTLSeconds)
+	if !ok {
+		return
+	}
+
+	hold, err := hc.service.Place(holdDTO.Normal(), holdTTL)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold.DTO())
+	return
+}
+
+func (hc *HoldController) Get(c *gin.Context) {
+	id, ok := holdIDParam(c)
+	if !ok {
+		return
+	}
+
+	hold, err := hc.service.Get(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold.DTO())
+	return
+}
+
+func (hc *HoldController) Capture(c *gin.Context) {
+	id, ok := holdIDParam(c)
+	if !ok {
+		return
+	}
+
+	var captureDTO models.HoldCaptureDTO
+	if !bindJSON(c, &captureDTO) {
+		return
+	}
+
+	hold, err := hc.service.Capture(id, decimal.NewFromFloat(captureDTO.Amount), captureDTO.Full)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold.DTO())
+	return
+}
+
+func (hc *HoldController) Void(c *gin.Context) {
+	id, ok := holdIDParam(c)
+	if !ok {
+		return
+	}
+
+	hold, err := hc.service.Void(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold.DTO())
+	return
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockHoldService struct {
+	PlaceMock   func(hold *models.Hold, ttl time.Duration) (*models.Hold, error)
+	CaptureMock func(id types.HoldID, amount decimal.Decimal, full bool) (*models.Hold, error)
+	VoidMock    func(id types.HoldID) (*models.Hold, error)
+	GetMock     func(id types.HoldID) (*models.Hold, error)
+}
+
+func (m mockHoldService) Place(hold *models.Hold, ttl time.Duration) (*models.Hold, error) {
+	return m.PlaceMock(hold, ttl)
+}
+
+func (m mockHoldService) Capture(id types.HoldID, amount decimal.Decimal, full bool) (*models.Hold, error) {
+	return m.CaptureMock(id, amount, full)
+}
+
+func (m mockHoldService) Void(id types.HoldID) (*models.Hold, error) {
+	return m.VoidMock(id)
+}
+
+func (m mockHoldService) Get(id types.HoldID) (*models.Hold, error) {
+	return m.GetMock(id)
+}
+
+func TestHoldController_Place(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockHoldServ := mockHoldService{
+			PlaceMock: func(hold *models.Hold, ttl time.Duration) (*models.Hold, error) {
+				assert.Equal(t, time.Hour, ttl)
+				hold.ID = 1
+				hold.Status = types.HoldActive
+				return hold, nil
+			},
+		}
+		mockHoldController := NewHoldController(mockHoldServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds", mockHoldController.Place)
+
+		reqBody, err := json.Marshal(models.HoldPlaceDTO{
+			AccountNumber:   1,
+			ReceiverAccount: 2,
+			Amount:          100,
+			TTLSeconds:      3600,
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var holdDTO models.HoldDTO
+		err = json.NewDecoder(rr.Body).Decode(&holdDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, types.HoldID(1), holdDTO.ID)
+		assert.Equal(t, types.HoldActive, holdDTO.Status)
+	})
+
+	t.Run("TTLTooLong", func(t *testing.T) {
+		mockHoldController := NewHoldController(mockHoldService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds", mockHoldController.Place)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds", bytes.NewBufferString(`{"accountNumber":1,"receiverAccount":2,"amount":100,"ttlSeconds":9223372036854775807}`))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, string(errs.InvalidArgument), problem["code"])
+	})
+}
+
+func TestHoldController_Capture(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Full", func(t *testing.T) {
+		mockHoldServ := mockHoldService{
+			CaptureMock: func(id types.HoldID, amount decimal.Decimal, full bool) (*models.Hold, error) {
+				assert.True(t, amount.IsZero())
+				assert.True(t, full)
+				return &models.Hold{ID: id, Amount: decimal.NewFromFloat(100), Captured: decimal.NewFromFloat(100), Status: types.HoldCaptured}, nil
+			},
+		}
+		mockHoldController := NewHoldController(mockHoldServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds/:holdID/capture", mockHoldController.Capture)
+
+		reqBody, err := json.Marshal(models.HoldCaptureDTO{Full: true})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds/1/capture", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var holdDTO models.HoldDTO
+		err = json.NewDecoder(rr.Body).Decode(&holdDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.HoldCaptured, holdDTO.Status)
+		assert.Equal(t, float64(100), holdDTO.Captured)
+	})
+	t.Run("WithoutBody", func(t *testing.T) {
+		mockHoldController := NewHoldController(mockHoldService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds/:holdID/capture", mockHoldController.Capture)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds/1/capture", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+	t.Run("ExceedsHold", func(t *testing.T) {
+		mockHoldServ := mockHoldService{
+			CaptureMock: func(id types.HoldID, amount decimal.Decimal, full bool) (*models.Hold, error) {
+				return nil, errs.New(errs.CaptureExceedsHold)
+			},
+		}
+		mockHoldController := NewHoldController(mockHoldServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds/:holdID/capture", mockHoldController.Capture)
+
+		reqBody, err := json.Marshal(models.HoldCaptureDTO{Amount: 150})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds/1/capture", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
+
+func TestHoldController_Void(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("NotActive", func(t *testing.T) {
+		mockHoldServ := mockHoldService{
+			VoidMock: func(id types.HoldID) (*models.Hold, error) {
+				return nil, errs.New(errs.HoldNotActive)
+			},
+		}
+		mockHoldController := NewHoldController(mockHoldServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds/:holdID/void", mockHoldController.Void)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds/1/void", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+	t.Run("InvalidHoldID", func(t *testing.T) {
+		mockHoldController := NewHoldController(mockHoldService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/holds/:holdID/void", mockHoldController.Void)
+
+		req, err := http.NewRequest(http.MethodPost, "/holds/abc/void", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
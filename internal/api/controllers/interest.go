@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type InterestController struct {
+	service interestService
+}
+
+type interestService interface {
+	Get(accountNumber types.AccountNumber) (*models.InterestAccrual, error)
+	Rates() []*models.InterestRate
+}
+
+func NewInterestController(s interestService) *InterestController {
+	return &InterestController{service: s}
+}
+
+// Get returns the interest the account earned since its last posting
+func (ic *InterestController) Get(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	accrual, err := ic.service.Get(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accrual.DTO())
+	return
+}
+
+// GetRates returns the interest rates paid per currency
+func (ic *InterestController) GetRates(c *gin.Context) {
+	ratesDTO := []*models.InterestRateDTO{}
+	for _, r := range ic.service.Rates() {
+		ratesDTO = append(ratesDTO, r.DTO())
+	}
+
+	c.JSON(http.StatusOK, ratesDTO)
+	return
+}
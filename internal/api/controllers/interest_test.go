@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockInterestService struct {
+	GetMock   func(accountNumber types.AccountNumber) (*models.InterestAccrual, error)
+	RatesMock func() []*models.InterestRate
+}
+
+func (m mockInterestService) Get(accountNumber types.AccountNumber) (*models.InterestAccrual, error) {
+	return m.GetMock(accountNumber)
+}
+
+func (m mockInterestService) Rates() []*models.InterestRate {
+	return m.RatesMock()
+}
+
+func TestInterestController_Get(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockInterestServ := mockInterestService{
+			GetMock: func(accountNumber types.AccountNumber) (*models.InterestAccrual, error) {
+				return &models.InterestAccrual{
+					AccountNumber: accountNumber,
+					Accrued:       decimal.NewFromFloat(12.345),
+					Rate:          &models.InterestRate{Currency: types.TRY, AnnualRate: decimal.NewFromInt(30), DayCount: types.Actual365},
+				}, nil
+			},
+		}
+		mockInterestController := NewInterestController(mockInterestServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/interest", mockInterestController.Get)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/1/interest", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var accrualDTO models.InterestAccrualDTO
+		err = json.NewDecoder(rr.Body).Decode(&accrualDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 12.34, accrualDTO.Accrued)
+		assert.Equal(t, types.Actual365, accrualDTO.Rate.DayCount)
+	})
+	t.Run("AccountNotFound", func(t *testing.T) {
+		mockInterestServ := mockInterestService{
+			GetMock: func(accountNumber types.AccountNumber) (*models.InterestAccrual, error) {
+				return nil, errs.New(errs.AccountNotFound)
+			},
+		}
+		mockInterestController := NewInterestController(mockInterestServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/interest", mockInterestController.Get)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/1/interest", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"net/http"
+	"strconv"
+)
+
+type InvoiceController struct {
+	service invoiceService
+}
+
+type invoiceService interface {
+	Create(invoice *models.Invoice) (*models.Invoice, error)
+	Pay(id types.InvoiceID, amount decimal.Decimal, full bool) (*models.Invoice, error)
+	Cancel(id types.InvoiceID) (*models.Invoice, error)
+	Get(id types.InvoiceID) (*models.Invoice, error)
+	FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Invoice, error)
+}
+
+func NewInvoiceController(s invoiceService) *InvoiceController {
+	return &InvoiceController{service: s}
+}
+
+// invoiceIDParam reads the invoiceID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func invoiceIDParam(c *gin.Context) (types.InvoiceID, bool) {
+	id, err := strconv.ParseInt(c.Param("invoiceID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.InvoiceID(id), true
+}
+
+func (ic *InvoiceController) Create(c *gin.Context) {
+	var invoiceDTO *models.InvoiceDTO
+	if !bindJSON(c, &invoiceDTO) {
+		return
+	}
+
+	invoice, err := ic.service.Create(invoiceDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice.DTO())
+	return
+}
+
+func (ic *InvoiceController) Get(c *gin.Context) {
+	id, ok := invoiceIDParam(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := ic.service.Get(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice.DTO())
+	return
+}
+
+func (ic *InvoiceController) Pay(c *gin.Context) {
+	id, ok := invoiceIDParam(c)
+	if !ok {
+		return
+	}
+
+	var paymentDTO models.InvoicePaymentDTO
+	if !bindJSON(c, &paymentDTO) {
+		return
+	}
+
+	invoice, err := ic.service.Pay(id, decimal.NewFromFloat(paymentDTO.Amount), paymentDTO.Full)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice.DTO())
+	return
+}
+
+func (ic *InvoiceController) Cancel(c *gin.Context) {
+	id, ok := invoiceIDParam(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := ic.service.Cancel(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice.DTO())
+	return
+}
+
+// GetByAccountNumber returns the invoices the account issued or is asked to pay
+func (ic *InvoiceController) GetByAccountNumber(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := ic.service.FindByAccountNumber(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	invoicesDTO := []*models.InvoiceDTO{}
+	for _, i := range invoices {
+		invoicesDTO = append(invoicesDTO, i.DTO())
+	}
+
+	c.JSON(http.StatusOK, invoicesDTO)
+	return
+}
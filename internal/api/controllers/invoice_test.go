@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockInvoiceService struct {
+	CreateMock              func(invoice *models.Invoice) (*models.Invoice, error)
+	PayMock                 func(id types.InvoiceID, amount decimal.Decimal, full bool) (*models.Invoice, error)
+	CancelMock              func(id types.InvoiceID) (*models.Invoice, error)
+	GetMock                 func(id types.InvoiceID) (*models.Invoice, error)
+	FindByAccountNumberMock func(accountNumber types.AccountNumber) ([]*models.Invoice, error)
+}
+
+func (m mockInvoiceService) Create(invoice *models.Invoice) (*models.Invoice, error) {
+	return m.CreateMock(invoice)
+}
+
+func (m mockInvoiceService) Pay(id types.InvoiceID, amount decimal.Decimal, full bool) (*models.Invoice, error) {
+	return m.PayMock(id, amount, full)
+}
+
+func (m mockInvoiceService) Cancel(id types.InvoiceID) (*models.Invoice, error) {
+	return m.CancelMock(id)
+}
+
+func (m mockInvoiceService) Get(id types.InvoiceID) (*models.Invoice, error) {
+	return m.GetMock(id)
+}
+
+func (m mockInvoiceService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Invoice, error) {
+	return m.FindByAccountNumberMock(accountNumber)
+}
+
+func TestInvoiceController_Create(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		dueDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		mockInvoiceServ := mockInvoiceService{
+			CreateMock: func(invoice *models.Invoice) (*models.Invoice, error) {
+				assert.True(t, dueDate.Equal(invoice.DueDate))
+				assert.Equal(t, "INV-2024-001", invoice.Reference)
+				invoice.ID = 1
+				invoice.Status = types.InvoiceOpen
+				return invoice, nil
+			},
+		}
+		mockInvoiceController := NewInvoiceController(mockInvoiceServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/invoices", mockInvoiceController.Create)
+
+		reqBody, err := json.Marshal(models.InvoiceDTO{
+			IssuerAccount:      2,
+			PayerAccount:       1,
+			CurrencyCode:       types.TRY,
+			Amount:             300,
+			DueDate:            dueDate,
+			TransactionDetails: models.TransactionDetails{Reference: "INV-2024-001"},
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/invoices", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var invoiceDTO models.InvoiceDTO
+		err = json.NewDecoder(rr.Body).Decode(&invoiceDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, types.InvoiceID(1), invoiceDTO.ID)
+		assert.Equal(t, types.InvoiceOpen, invoiceDTO.Status)
+		assert.Equal(t, 300.0, invoiceDTO.Unpaid)
+	})
+}
+
+func TestInvoiceController_Pay(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Partial", func(t *testing.T) {
+		mockInvoiceServ := mockInvoiceService{
+			PayMock: func(id types.InvoiceID, amount decimal.Decimal, full bool) (*models.Invoice, error) {
+				assert.True(t, amount.Equal(decimal.NewFromFloat(100)))
+				assert.False(t, full)
+				return &models.Invoice{ID: id, Amount: decimal.NewFromFloat(300), Paid: amount, Status: types.InvoiceOpen, PaymentIDs: []types.TransactionID{7}}, nil
+			},
+		}
+		mockInvoiceController := NewInvoiceController(mockInvoiceServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/invoices/:invoiceID/pay", mockInvoiceController.Pay)
+
+		reqBody, err := json.Marshal(models.InvoicePaymentDTO{Amount: 100})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/invoices/1/pay", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var invoiceDTO models.InvoiceDTO
+		err = json.NewDecoder(rr.Body).Decode(&invoiceDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 200.0, invoiceDTO.Unpaid)
+		assert.Equal(t, []types.TransactionID{7}, invoiceDTO.PaymentIDs)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		mockInvoiceServ := mockInvoiceService{
+			PayMock: func(id types.InvoiceID, amount decimal.Decimal, full bool) (*models.Invoice, error) {
+				assert.True(t, amount.IsZero())
+				assert.True(t, full)
+				return nil, errs.Newf(errs.InvoiceNotOpen, "invoice is expired")
+			},
+		}
+		mockInvoiceController := NewInvoiceController(mockInvoiceServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/invoices/:invoiceID/pay", mockInvoiceController.Pay)
+
+		reqBody, err := json.Marshal(models.InvoicePaymentDTO{Full: true})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/invoices/1/pay", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("WithoutBody", func(t *testing.T) {
+		mockInvoiceController := NewInvoiceController(mockInvoiceService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/invoices/:invoiceID/pay", mockInvoiceController.Pay)
+
+		req, err := http.NewRequest(http.MethodPost, "/invoices/1/pay", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestInvoiceController_Cancel(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockInvoiceServ := mockInvoiceService{
+		CancelMock: func(id types.InvoiceID) (*models.Invoice, error) {
+			return nil, errs.New(errs.InvoiceNotFound)
+		},
+	}
+	mockInvoiceController := NewInvoiceController(mockInvoiceServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.POST("/invoices/:invoiceID/cancel", mockInvoiceController.Cancel)
+
+	req, err := http.NewRequest(http.MethodPost, "/invoices/3/cancel", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestInvoiceController_GetByAccountNumber(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockInvoiceServ := mockInvoiceService{
+		FindByAccountNumberMock: func(accountNumber types.AccountNumber) ([]*models.Invoice, error) {
+			return []*models.Invoice{{ID: 1, IssuerAccount: 2, PayerAccount: accountNumber, Status: types.InvoiceOpen}}, nil
+		},
+	}
+	mockInvoiceController := NewInvoiceController(mockInvoiceServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/account/:accountNumber/invoices", mockInvoiceController.GetByAccountNumber)
+
+	req, err := http.NewRequest(http.MethodGet, "/account/1/invoices", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	var invoicesDTO []*models.InvoiceDTO
+	err = json.NewDecoder(rr.Body).Decode(&invoicesDTO)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 1, len(invoicesDTO))
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type LimitController struct {
+	service limitService
+}
+
+type limitService interface {
+	Allowances(accountNumber types.AccountNumber) ([]*models.Allowance, error)
+	SetOverride(limit *models.Limit) (*models.Limit, error)
+}
+
+func NewLimitController(s limitService) *LimitController {
+	return &LimitController{service: s}
+}
+
+// GetAllowances returns the remaining allowance of every limit of the account
+func (lc *LimitController) GetAllowances(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	allowances, err := lc.service.Allowances(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	allowancesDTO := []*models.AllowanceDTO{}
+	for _, a := range allowances {
+		allowancesDTO = append(allowancesDTO, a.DTO())
+	}
+
+	c.JSON(http.StatusOK, allowancesDTO)
+	return
+}
+
+// SetOverride replaces the limit of the account for one transaction type
+func (lc *LimitController) SetOverride(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	var limitDTO *models.LimitDTO
+	if !bindJSON(c, &limitDTO) {
+		return
+	}
+
+	limit := limitDTO.Normal()
+	limit.AccountNumber = accountNumber
+
+	limit, err := lc.service.SetOverride(limit)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limit.DTO())
+	return
+}
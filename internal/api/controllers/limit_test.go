@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockLimitService struct {
+	AllowancesMock  func(accountNumber types.AccountNumber) ([]*models.Allowance, error)
+	SetOverrideMock func(limit *models.Limit) (*models.Limit, error)
+}
+
+func (m mockLimitService) Allowances(accountNumber types.AccountNumber) ([]*models.Allowance, error) {
+	return m.AllowancesMock(accountNumber)
+}
+
+func (m mockLimitService) SetOverride(limit *models.Limit) (*models.Limit, error) {
+	return m.SetOverrideMock(limit)
+}
+
+func TestLimitController_GetAllowances(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		remaining := decimal.NewFromFloat(150)
+		mockLimitServ := mockLimitService{
+			AllowancesMock: func(accountNumber types.AccountNumber) ([]*models.Allowance, error) {
+				return []*models.Allowance{
+					{TransactionType: types.Withdraw, DailyVolumeRemaining: &remaining},
+				}, nil
+			},
+		}
+		mockLimitController := NewLimitController(mockLimitServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/limits", mockLimitController.GetAllowances)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/1/limits", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var allowances []*models.AllowanceDTO
+		err = json.NewDecoder(rr.Body).Decode(&allowances)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, float64(150), *allowances[0].DailyVolumeRemaining)
+		assert.Nil(t, allowances[0].MonthlyCountRemaining)
+	})
+
+	t.Run("AccountNotFound", func(t *testing.T) {
+		mockLimitServ := mockLimitService{
+			AllowancesMock: func(accountNumber types.AccountNumber) ([]*models.Allowance, error) {
+				return nil, errs.New(errs.AccountNotFound)
+			},
+		}
+		mockLimitController := NewLimitController(mockLimitServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/limits", mockLimitController.GetAllowances)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/1/limits", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestLimitController_SetOverride(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockLimitServ := mockLimitService{
+			SetOverrideMock: func(limit *models.Limit) (*models.Limit, error) {
+				return limit, nil
+			},
+		}
+		mockLimitController := NewLimitController(mockLimitServ)
+
+		limitJSON, err := json.Marshal(&models.LimitDTO{
+			TransactionType: types.Withdraw,
+			DailyCount:      5,
+		})
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PUT("/account/:accountNumber/limits", mockLimitController.SetOverride)
+
+		req, err := http.NewRequest(http.MethodPut, "/account/7/limits", bytes.NewBuffer(limitJSON))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var limit *models.LimitDTO
+		err = json.NewDecoder(rr.Body).Decode(&limit)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.AccountNumber(7), limit.AccountNumber)
+		assert.Equal(t, 5, limit.DailyCount)
+	})
+
+	t.Run("InvalidAccountNumber", func(t *testing.T) {
+		mockLimitController := NewLimitController(mockLimitService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PUT("/account/:accountNumber/limits", mockLimitController.SetOverride)
+
+		req, err := http.NewRequest(http.MethodPut, "/account/abc/limits", bytes.NewBufferString("{}"))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"net/http"
+)
+
+type OverdraftController struct {
+	service overdraftService
+}
+
+type overdraftService interface {
+	Get(accountNumber types.AccountNumber) (*models.Overdraft, error)
+	SetLimit(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Overdraft, error)
+}
+
+func NewOverdraftController(s overdraftService) *OverdraftController {
+	return &OverdraftController{service: s}
+}
+
+// Get returns the overdraft facility of the account and how much of it is used
+func (oc *OverdraftController) Get(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	overdraft, err := oc.service.Get(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, overdraft.DTO())
+	return
+}
+
+// SetLimit changes the overdraft limit of the account, only the limit of the body is read
+func (oc *OverdraftController) SetLimit(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	var overdraftDTO *models.OverdraftDTO
+	if !bindJSON(c, &overdraftDTO) {
+		return
+	}
+
+	overdraft, err := oc.service.SetLimit(accountNumber, overdraftDTO.Normal().Limit)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, overdraft.DTO())
+	return
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockOverdraftService struct {
+	GetMock      func(accountNumber types.AccountNumber) (*models.Overdraft, error)
+	SetLimitMock func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Overdraft, error)
+}
+
+func (m mockOverdraftService) Get(accountNumber types.AccountNumber) (*models.Overdraft, error) {
+	return m.GetMock(accountNumber)
+}
+
+func (m mockOverdraftService) SetLimit(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Overdraft, error) {
+	return m.SetLimitMock(accountNumber, limit)
+}
+
+func TestOverdraftController_SetLimit(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockOverdraftServ := mockOverdraftService{
+			SetLimitMock: func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Overdraft, error) {
+				return &models.Overdraft{AccountNumber: accountNumber, Limit: limit, AnnualRate: decimal.NewFromInt(24)}, nil
+			},
+		}
+		mockOverdraftController := NewOverdraftController(mockOverdraftServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PUT("/account/:accountNumber/overdraft", mockOverdraftController.SetLimit)
+
+		reqBody, err := json.Marshal(models.OverdraftDTO{Limit: 500})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPut, "/account/1/overdraft", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var overdraftDTO models.OverdraftDTO
+		err = json.NewDecoder(rr.Body).Decode(&overdraftDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.AccountNumber(1), overdraftDTO.AccountNumber)
+		assert.Equal(t, float64(500), overdraftDTO.Limit)
+		assert.Equal(t, float64(24), overdraftDTO.AnnualRate)
+	})
+	t.Run("TooHigh", func(t *testing.T) {
+		mockOverdraftServ := mockOverdraftService{
+			SetLimitMock: func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Overdraft, error) {
+				return nil, errs.New(errs.OverdraftLimitTooHigh)
+			},
+		}
+		mockOverdraftController := NewOverdraftController(mockOverdraftServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.PUT("/account/:accountNumber/overdraft", mockOverdraftController.SetLimit)
+
+		reqBody, err := json.Marshal(models.OverdraftDTO{Limit: 1000000})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPut, "/account/1/overdraft", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
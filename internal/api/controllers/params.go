@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"bytes"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxTTLSeconds is the longest lifetime in seconds that still fits into a time.Duration
+const maxTTLSeconds = math.MaxInt64 / int64(time.Second)
+
+// accountNumberParam reads the accountNumber path parameter, either the account number or the IBAN of the account.
+// It answers the request with invalid_argument or invalid_iban and returns false if the parameter is not valid.
+func accountNumberParam(c *gin.Context) (types.AccountNumber, bool) {
+	accountNumber, ok := c.Params.Get("accountNumber")
+	if !ok {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+
+	accountNumberI, err := types.ParseAccountNumber(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return 0, false
+	}
+
+	return accountNumberI, true
+}
+
+// bindJSON decodes the request body into obj. It answers the request and returns false if the body cannot be read,
+// with the domain error of a field that rejected its value such as an IBAN, or with invalid_request_body otherwise.
+// A null body would leave a pointer nil, so it is answered with invalid_request_body as well.
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.Body != nil {
+		body, err := c.GetRawData()
+		if err == nil && bytes.Equal(bytes.TrimSpace(body), []byte("null")) {
+			abortWithProblem(c, errs.Newf(errs.InvalidRequestBody, "request body cannot be null"))
+			return false
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	err := c.BindJSON(obj)
+	if err == nil {
+		return true
+	}
+	if errs.CodeOf(err) != "" {
+		abortWithProblem(c, err)
+		return false
+	}
+	abortWithProblem(c, errs.Newf(errs.InvalidRequestBody, "%v", err))
+	return false
+}
+
+// ttl converts the ttlSeconds of a request body into a lifetime. It answers the request with invalid_argument
+// and returns false if the seconds are negative or do not fit into a time.Duration.
+func ttl(c *gin.Context, seconds int64) (time.Duration, bool) {
+	if seconds < 0 || seconds > maxTTLSeconds {
+		abortWithProblem(c, errs.Newf(errs.InvalidArgument, "ttlSeconds must be between 0 and %d", maxTTLSeconds))
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// etag returns the entity tag of a version of a resource
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion reads the version from the If-Match header, zero if the header is missing or *.
+// It answers the request with invalid_argument and returns false if the header is not a single entity tag.
+func ifMatchVersion(c *gin.Context) (int, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, true
+	}
+
+	unquoted, err := strconv.Unquote(ifMatch)
+	if err != nil {
+		abortWithProblem(c, errs.Newf(errs.InvalidArgument, "If-Match must be a single entity tag"))
+		return 0, false
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		abortWithProblem(c, errs.Newf(errs.InvalidArgument, "If-Match must be a single entity tag"))
+		return 0, false
+	}
+	return version, true
+}
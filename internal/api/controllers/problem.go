@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// problemTypeBase prefixes the code of a domain error to build the RFC 7807 type URI
+const problemTypeBase = "https://tringle-payment-rest-api.herokuapp.com/problems/"
+
+// statuses maps every domain error code to the HTTP status it is answered with,
+// codes that are not listed are answered with 400
+var statuses = map[errs.Code]int{
+	errs.AccountNotFound:         http.StatusNotFound,
+	errs.HistoryNotFound:         http.StatusNotFound,
+	errs.HistoryAlreadyExists:    http.StatusConflict,
+	errs.AccountTypeNotAllowed:   http.StatusUnprocessableEntity,
+	errs.CurrencyMismatch:        http.StatusUnprocessableEntity,
+	errs.InsufficientBalance:     http.StatusUnprocessableEntity,
+	errs.LimitExceeded:           http.StatusUnprocessableEntity,
+	errs.HoldNotFound:            http.StatusNotFound,
+	errs.HoldNotActive:           http.StatusConflict,
+	errs.CaptureExceedsHold:      http.StatusUnprocessableEntity,
+	errs.StandingOrderNotFound:   http.StatusNotFound,
+	errs.StandingOrderNotActive:  http.StatusConflict,
+	errs.OverdraftLimitTooHigh:   http.StatusUnprocessableEntity,
+	errs.ReconciliationNotRun:    http.StatusNotFound,
+	errs.VersionMismatch:         http.StatusPreconditionFailed,
+	errs.AccountNumbersExhausted: http.StatusServiceUnavailable,
+	errs.UnknownTenant:           http.StatusUnauthorized,
+	errs.CustomerNotFound:        http.StatusNotFound,
+	errs.WalletAlreadyExists:     http.StatusConflict,
+	errs.WalletNotFound:          http.StatusNotFound,
+	errs.RateNotFound:            http.StatusUnprocessableEntity,
+	errs.EscrowNotFound:          http.StatusNotFound,
+	errs.EscrowNotFunded:         http.StatusConflict,
+	errs.EscrowPartyNotAllowed:   http.StatusForbidden,
+	errs.InvoiceNotFound:         http.StatusNotFound,
+	errs.InvoiceNotOpen:          http.StatusConflict,
+	errs.PaymentExceedsInvoice:   http.StatusUnprocessableEntity,
+	errs.DisputeNotFound:         http.StatusNotFound,
+	errs.DisputeNotOpen:          http.StatusConflict,
+	errs.DisputeExceedsPayment:   http.StatusUnprocessableEntity,
+	errs.ScreeningCaseNotFound:   http.StatusNotFound,
+	errs.ScreeningCaseDecided:    http.StatusConflict,
+	errs.PaymentUnderReview:      http.StatusAccepted,
+	errs.SanctionsMatch:          http.StatusForbidden,
+	errs.FraudAlertNotFound:      http.StatusNotFound,
+	errs.FraudAlertDecided:       http.StatusConflict,
+	errs.TransactionUnderReview:  http.StatusAccepted,
+	errs.TransactionBlocked:      http.StatusForbidden,
+	errs.AdminKeyInvalid:         http.StatusUnauthorized,
+	errs.Internal:                http.StatusInternalServerError,
+}
+
+func statusOf(code errs.Code) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+// abortWithProblem answers the request with an RFC 7807 problem+json body built from err.
+// Errors that are not domain errors keep their message as detail and are answered with 400.
+func abortWithProblem(c *gin.Context, err error) {
+	var domainErr *errs.Error
+	if !errors.As(err, &domainErr) {
+		domainErr = errs.Newf(errs.InvalidArgument, "%v", err)
+	}
+
+	lang := errs.ParseLanguage(c.GetHeader("Accept-Language"))
+	status := statusOf(domainErr.Code)
+
+	body := gin.H{}
+	for k, v := range domainErr.Extensions {
+		body[k] = v
+	}
+	body["type"] = problemTypeBase + string(domainErr.Code)
+	body["title"] = errs.Message(domainErr.Code, lang)
+	body["status"] = status
+	body["code"] = domainErr.Code
+	body["instance"] = c.Request.URL.Path
+	if domainErr.Detail != "" {
+		body["detail"] = domainErr.Detail
+	}
+
+	c.Header("Content-Language", string(lang))
+	c.Header("Content-Type", "application/problem+json; charset=utf-8")
+	c.AbortWithStatusJSON(status, body)
+}
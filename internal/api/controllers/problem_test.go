@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAbortWithProblem(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("DomainError", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/problem", func(c *gin.Context) {
+			abortWithProblem(c, errs.New(errs.InsufficientBalance).With("balance", 10))
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/problem", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		assert.Equal(t, "application/problem+json; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, string(errs.InsufficientBalance), problem["code"])
+		assert.Equal(t, problemTypeBase+string(errs.InsufficientBalance), problem["type"])
+		assert.Equal(t, "insufficient balance", problem["title"])
+		assert.Equal(t, float64(10), problem["balance"])
+	})
+
+	t.Run("LocalizedTitle", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/problem", func(c *gin.Context) {
+			abortWithProblem(c, errs.New(errs.AccountNotFound))
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/problem", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Accept-Language", "tr-TR,tr;q=0.9,en;q=0.8")
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, "geçersiz hesap numarası", problem["title"])
+		assert.Equal(t, "tr", rr.Header().Get("Content-Language"))
+	})
+
+	t.Run("UntypedError", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/problem", func(c *gin.Context) {
+			abortWithProblem(c, errors.New("unexpected"))
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/problem", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, string(errs.InvalidArgument), problem["code"])
+		assert.Equal(t, "unexpected", problem["detail"])
+	})
+}
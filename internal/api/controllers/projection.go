@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ProjectionController struct {
+	service projectionService
+}
+
+type projectionService interface {
+	Events(after types.EventSequence) []*models.Event
+	Diff() []*models.ProjectionDiff
+	Rebuild() []*models.ProjectionDiff
+}
+
+func NewProjectionController(s projectionService) *ProjectionController {
+	return &ProjectionController{service: s}
+}
+
+// GetEvents returns the account events recorded after the sequence of the after query parameter
+func (pc *ProjectionController) GetEvents(c *gin.Context) {
+	var after int64
+	if afterQuery := c.Query("after"); afterQuery != "" {
+		var err error
+		after, err = strconv.ParseInt(afterQuery, 10, 64)
+		if err != nil || after < 0 {
+			abortWithProblem(c, errs.Newf(errs.InvalidArgument, "after must be a non-negative event sequence"))
+			return
+		}
+	}
+
+	var dtos []*models.EventDTO
+	for _, event := range pc.service.Events(types.EventSequence(after)) {
+		dtos = append(dtos, event.DTO())
+	}
+
+	c.JSON(http.StatusOK, dtos)
+	return
+}
+
+// Diff returns the differences between the live account projection and one rebuilt from the events
+func (pc *ProjectionController) Diff(c *gin.Context) {
+	c.JSON(http.StatusOK, pc.service.Diff())
+	return
+}
+
+// Rebuild rebuilds the account projection from the events and returns the differences it fixed
+func (pc *ProjectionController) Rebuild(c *gin.Context) {
+	c.JSON(http.StatusOK, pc.service.Rebuild())
+	return
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockProjectionService struct {
+	EventsMock  func(after types.EventSequence) []*models.Event
+	DiffMock    func() []*models.ProjectionDiff
+	RebuildMock func() []*models.ProjectionDiff
+}
+
+func (m mockProjectionService) Events(after types.EventSequence) []*models.Event {
+	return m.EventsMock(after)
+}
+
+func (m mockProjectionService) Diff() []*models.ProjectionDiff {
+	return m.DiffMock()
+}
+
+func (m mockProjectionService) Rebuild() []*models.ProjectionDiff {
+	return m.RebuildMock()
+}
+
+func TestProjectionController_GetEvents(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockProjectionServ := mockProjectionService{
+			EventsMock: func(after types.EventSequence) []*models.Event {
+				assert.Equal(t, types.EventSequence(1), after)
+				return []*models.Event{
+					{Sequence: 2, Type: types.Deposited, AccountNumber: 1},
+				}
+			},
+		}
+		mockProjectionController := NewProjectionController(mockProjectionServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/admin/events", mockProjectionController.GetEvents)
+
+		req, err := http.NewRequest(http.MethodGet, "/admin/events?after=1", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var eventDTOs []*models.EventDTO
+		err = json.NewDecoder(rr.Body).Decode(&eventDTOs)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1, len(eventDTOs))
+		assert.Equal(t, types.Deposited, eventDTOs[0].Type)
+	})
+	t.Run("InvalidSequence", func(t *testing.T) {
+		mockProjectionController := NewProjectionController(mockProjectionService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/admin/events", mockProjectionController.GetEvents)
+
+		req, err := http.NewRequest(http.MethodGet, "/admin/events?after=-1", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestProjectionController_Rebuild(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockProjectionServ := mockProjectionService{
+		RebuildMock: func() []*models.ProjectionDiff {
+			return []*models.ProjectionDiff{
+				{AccountNumber: 1, Field: "balance", Live: "1000", Rebuilt: "120"},
+			}
+		},
+	}
+	mockProjectionController := NewProjectionController(mockProjectionServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.POST("/admin/projections/accounts/rebuild", mockProjectionController.Rebuild)
+
+	req, err := http.NewRequest(http.MethodPost, "/admin/projections/accounts/rebuild", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	var diffs []*models.ProjectionDiff
+	err = json.NewDecoder(rr.Body).Decode(&diffs)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 1, len(diffs))
+	assert.Equal(t, "120", diffs[0].Rebuilt)
+}
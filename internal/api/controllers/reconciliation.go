@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ReconciliationController struct {
+	service reconciliationService
+}
+
+type reconciliationService interface {
+	Reconcile(correct bool) *models.ReconciliationReport
+	Last() (*models.ReconciliationReport, error)
+}
+
+func NewReconciliationController(s reconciliationService) *ReconciliationController {
+	return &ReconciliationController{service: s}
+}
+
+// Run reconciles every account now, with the correct query parameter set
+// it also writes the corrective adjustment lines
+func (rc *ReconciliationController) Run(c *gin.Context) {
+	var correct bool
+	if correctQuery := c.Query("correct"); correctQuery != "" {
+		var err error
+		correct, err = strconv.ParseBool(correctQuery)
+		if err != nil {
+			abortWithProblem(c, errs.Newf(errs.InvalidArgument, "correct must be true or false"))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, rc.service.Reconcile(correct).DTO())
+	return
+}
+
+// GetLast returns the report of the last reconciliation, scheduled or not
+func (rc *ReconciliationController) GetLast(c *gin.Context) {
+	report, err := rc.service.Last()
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report.DTO())
+	return
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockReconciliationService struct {
+	ReconcileMock func(correct bool) *models.ReconciliationReport
+	LastMock      func() (*models.ReconciliationReport, error)
+}
+
+func (m mockReconciliationService) Reconcile(correct bool) *models.ReconciliationReport {
+	return m.ReconcileMock(correct)
+}
+
+func (m mockReconciliationService) Last() (*models.ReconciliationReport, error) {
+	return m.LastMock()
+}
+
+func TestReconciliationController_Run(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockReconciliationServ := mockReconciliationService{
+			ReconcileMock: func(correct bool) *models.ReconciliationReport {
+				assert.True(t, correct)
+				return &models.ReconciliationReport{
+					Accounts:  2,
+					Corrected: correct,
+					Discrepancies: []*models.Discrepancy{
+						{AccountNumber: 2, CurrencyCode: types.TRY, Difference: decimal.NewFromInt(-20), AdjustmentID: 5},
+					},
+				}
+			},
+		}
+		mockReconciliationController := NewReconciliationController(mockReconciliationServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/reconciliation", mockReconciliationController.Run)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/reconciliation?correct=true", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var reportDTO models.ReconciliationReportDTO
+		err = json.NewDecoder(rr.Body).Decode(&reportDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1, len(reportDTO.Discrepancies))
+		assert.Equal(t, float64(-20), reportDTO.Discrepancies[0].Difference)
+		assert.Equal(t, types.TransactionID(5), reportDTO.Discrepancies[0].AdjustmentID)
+	})
+	t.Run("InvalidCorrect", func(t *testing.T) {
+		mockReconciliationController := NewReconciliationController(mockReconciliationService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/reconciliation", mockReconciliationController.Run)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/reconciliation?correct=maybe", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestReconciliationController_GetLast(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockReconciliationServ := mockReconciliationService{
+		LastMock: func() (*models.ReconciliationReport, error) {
+			return nil, errs.New(errs.ReconciliationNotRun)
+		},
+	}
+	mockReconciliationController := NewReconciliationController(mockReconciliationServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/admin/reconciliation", mockReconciliationController.GetLast)
+
+	req, err := http.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
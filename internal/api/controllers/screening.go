@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ScreeningController struct {
+	service screeningService
+}
+
+type screeningService interface {
+	GetCase(id types.ScreeningCaseID) (*models.ScreeningCase, error)
+	FindCases(status types.ScreeningStatus) ([]*models.ScreeningCase, error)
+	Decide(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error)
+	Decisions() []*models.ScreeningDecision
+}
+
+func NewScreeningController(s screeningService) *ScreeningController {
+	return &ScreeningController{service: s}
+}
+
+// caseIDParam reads the caseID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func caseIDParam(c *gin.Context) (types.ScreeningCaseID, bool) {
+	id, err := strconv.ParseInt(c.Param("caseID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.ScreeningCaseID(id), true
+}
+
+// GetCases returns the review queue, the pending cases unless another status is asked for with the status query parameter
+func (sc *ScreeningController) GetCases(c *gin.Context) {
+	status := types.ScreeningStatus(c.DefaultQuery("status", string(types.ScreeningPending)))
+	if status == "all" {
+		status = ""
+	}
+
+	cases, err := sc.service.FindCases(status)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	casesDTO := []*models.ScreeningCaseDTO{}
+	for _, screeningCase := range cases {
+		casesDTO = append(casesDTO, screeningCase.DTO())
+	}
+
+	c.JSON(http.StatusOK, casesDTO)
+	return
+}
+
+func (sc *ScreeningController) GetCase(c *gin.Context) {
+	id, ok := caseIDParam(c)
+	if !ok {
+		return
+	}
+
+	screeningCase, err := sc.service.GetCase(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, screeningCase.DTO())
+	return
+}
+
+// Decide clears the case as a false positive or confirms it as a match of the sanctions list
+func (sc *ScreeningController) Decide(c *gin.Context) {
+	id, ok := caseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var decisionDTO *models.ScreeningDecisionDTO
+	if !bindJSON(c, &decisionDTO) {
+		return
+	}
+
+	screeningCase, err := sc.service.Decide(id, decisionDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, screeningCase.DTO())
+	return
+}
+
+// GetDecisions returns the audit trail of the decisions in the order they were made
+func (sc *ScreeningController) GetDecisions(c *gin.Context) {
+	decisionsDTO := []*models.ScreeningDecisionDTO{}
+	for _, decision := range sc.service.Decisions() {
+		decisionsDTO = append(decisionsDTO, decision.DTO())
+	}
+
+	c.JSON(http.StatusOK, decisionsDTO)
+	return
+}
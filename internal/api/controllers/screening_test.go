@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockScreeningService struct {
+	GetCaseMock   func(id types.ScreeningCaseID) (*models.ScreeningCase, error)
+	FindCasesMock func(status types.ScreeningStatus) ([]*models.ScreeningCase, error)
+	DecideMock    func(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error)
+	DecisionsMock func() []*models.ScreeningDecision
+}
+
+func (m mockScreeningService) GetCase(id types.ScreeningCaseID) (*models.ScreeningCase, error) {
+	return m.GetCaseMock(id)
+}
+
+func (m mockScreeningService) FindCases(status types.ScreeningStatus) ([]*models.ScreeningCase, error) {
+	return m.FindCasesMock(status)
+}
+
+func (m mockScreeningService) Decide(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error) {
+	return m.DecideMock(id, decision)
+}
+
+func (m mockScreeningService) Decisions() []*models.ScreeningDecision {
+	return m.DecisionsMock()
+}
+
+func TestScreeningController_GetCases(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query  string
+		status types.ScreeningStatus
+	}{
+		{"", types.ScreeningPending},
+		{"?status=cleared", types.ScreeningCleared},
+		{"?status=all", ""},
+	}
+	for _, test := range tests {
+		mockScreeningServ := mockScreeningService{
+			FindCasesMock: func(status types.ScreeningStatus) ([]*models.ScreeningCase, error) {
+				assert.Equal(t, test.status, status)
+				return []*models.ScreeningCase{
+					{ID: 1, AccountNumber: 1, Hit: &models.ScreeningHit{EntityID: "101", Score: 0.93}, Status: types.ScreeningPending},
+				}, nil
+			},
+		}
+		mockScreeningController := NewScreeningController(mockScreeningServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/admin/screening/cases", mockScreeningController.GetCases)
+
+		req, err := http.NewRequest(http.MethodGet, "/admin/screening/cases"+test.query, nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var casesDTO []*models.ScreeningCaseDTO
+		err = json.NewDecoder(rr.Body).Decode(&casesDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1, len(casesDTO))
+		assert.Equal(t, "101", casesDTO[0].Hit.EntityID)
+	}
+}
+
+func TestScreeningController_Decide(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		decidedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		mockScreeningServ := mockScreeningService{
+			DecideMock: func(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error) {
+				assert.Equal(t, types.ScreeningCleared, decision.Status)
+				assert.Equal(t, "compliance", decision.Reviewer)
+				return &models.ScreeningCase{ID: id, Status: decision.Status, Reviewer: decision.Reviewer, PaymentID: 4, DecidedAt: decidedAt}, nil
+			},
+		}
+		mockScreeningController := NewScreeningController(mockScreeningServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/screening/cases/:caseID/decision", mockScreeningController.Decide)
+
+		reqBody, err := json.Marshal(models.ScreeningDecisionDTO{Status: types.ScreeningCleared, Reviewer: "compliance", Note: "different birth date"})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/screening/cases/1/decision", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var caseDTO models.ScreeningCaseDTO
+		err = json.NewDecoder(rr.Body).Decode(&caseDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, types.ScreeningCleared, caseDTO.Status)
+		assert.Equal(t, types.TransactionID(4), caseDTO.PaymentID)
+		assert.True(t, decidedAt.Equal(*caseDTO.DecidedAt))
+	})
+
+	t.Run("ScreeningCaseDecided", func(t *testing.T) {
+		mockScreeningServ := mockScreeningService{
+			DecideMock: func(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error) {
+				return nil, errs.Newf(errs.ScreeningCaseDecided, "case is cleared")
+			},
+		}
+		mockScreeningController := NewScreeningController(mockScreeningServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/admin/screening/cases/:caseID/decision", mockScreeningController.Decide)
+
+		reqBody, err := json.Marshal(models.ScreeningDecisionDTO{Status: types.ScreeningConfirmed, Reviewer: "compliance"})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/admin/screening/cases/1/decision", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestScreeningController_GetCase(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockScreeningServ := mockScreeningService{
+		GetCaseMock: func(id types.ScreeningCaseID) (*models.ScreeningCase, error) {
+			return nil, errs.New(errs.ScreeningCaseNotFound)
+		},
+	}
+	mockScreeningController := NewScreeningController(mockScreeningServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/admin/screening/cases/:caseID", mockScreeningController.GetCase)
+
+	req, err := http.NewRequest(http.MethodGet, "/admin/screening/cases/9", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestScreeningController_GetDecisions(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	mockScreeningServ := mockScreeningService{
+		DecisionsMock: func() []*models.ScreeningDecision {
+			return []*models.ScreeningDecision{
+				{CaseID: 1, AccountNumber: 1, EntityID: "101", Status: types.ScreeningCleared, Reviewer: "compliance"},
+			}
+		},
+	}
+	mockScreeningController := NewScreeningController(mockScreeningServ)
+
+	rr := httptest.NewRecorder()
+
+	router := gin.Default()
+	router.GET("/admin/screening/decisions", mockScreeningController.GetDecisions)
+
+	req, err := http.NewRequest(http.MethodGet, "/admin/screening/decisions", nil)
+	assert.NoError(t, err)
+
+	router.ServeHTTP(rr, req)
+
+	var decisionsDTO []*models.ScreeningDecisionDTO
+	err = json.NewDecoder(rr.Body).Decode(&decisionsDTO)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, 1, len(decisionsDTO))
+	assert.Equal(t, "compliance", decisionsDTO[0].Reviewer)
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type StandingOrderController struct {
+	service standingOrderService
+}
+
+type standingOrderService interface {
+	Create(order *models.StandingOrder) (*models.StandingOrder, error)
+	Update(id types.StandingOrderID, changes *models.StandingOrder) (*models.StandingOrder, error)
+	Cancel(id types.StandingOrderID) (*models.StandingOrder, error)
+	Get(id types.StandingOrderID) (*models.StandingOrder, error)
+	FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.StandingOrder, error)
+}
+
+func NewStandingOrderController(s standingOrderService) *StandingOrderController {
+	return &StandingOrderController{service: s}
+}
+
+// standingOrderIDParam reads the orderID path parameter,
+// it answers the request with invalid_argument and returns false if the parameter is not valid
+func standingOrderIDParam(c *gin.Context) (types.StandingOrderID, bool) {
+	id, err := strconv.ParseInt(c.Param("orderID"), 10, 64)
+	if err != nil {
+		abortWithProblem(c, errs.New(errs.InvalidArgument))
+		return 0, false
+	}
+	return types.StandingOrderID(id), true
+}
+
+func (sc *StandingOrderController) Create(c *gin.Context) {
+	var orderDTO *models.StandingOrderDTO
+	if !bindJSON(c, &orderDTO) {
+		return
+	}
+
+	order, err := sc.service.Create(orderDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order.DTO())
+	return
+}
+
+func (sc *StandingOrderController) Get(c *gin.Context) {
+	id, ok := standingOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := sc.service.Get(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order.DTO())
+	return
+}
+
+// Update replaces the payment and the schedule of the standing order
+func (sc *StandingOrderController) Update(c *gin.Context) {
+	id, ok := standingOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	var orderDTO *models.StandingOrderDTO
+	if !bindJSON(c, &orderDTO) {
+		return
+	}
+
+	order, err := sc.service.Update(id, orderDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order.DTO())
+	return
+}
+
+// Cancel stops the standing order, it stays readable with its executions
+func (sc *StandingOrderController) Cancel(c *gin.Context) {
+	id, ok := standingOrderIDParam(c)
+	if !ok {
+		return
+	}
+
+	order, err := sc.service.Cancel(id)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order.DTO())
+	return
+}
+
+// GetByAccountNumber returns the standing orders paying from the account
+func (sc *StandingOrderController) GetByAccountNumber(c *gin.Context) {
+	accountNumber, ok := accountNumberParam(c)
+	if !ok {
+		return
+	}
+
+	orders, err := sc.service.FindByAccountNumber(accountNumber)
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	ordersDTO := []*models.StandingOrderDTO{}
+	for _, o := range orders {
+		ordersDTO = append(ordersDTO, o.DTO())
+	}
+
+	c.JSON(http.StatusOK, ordersDTO)
+	return
+}
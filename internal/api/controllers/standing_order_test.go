@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockStandingOrderService struct {
+	CreateMock              func(order *models.StandingOrder) (*models.StandingOrder, error)
+	UpdateMock              func(id types.StandingOrderID, changes *models.StandingOrder) (*models.StandingOrder, error)
+	CancelMock              func(id types.StandingOrderID) (*models.StandingOrder, error)
+	GetMock                 func(id types.StandingOrderID) (*models.StandingOrder, error)
+	FindByAccountNumberMock func(accountNumber types.AccountNumber) ([]*models.StandingOrder, error)
+}
+
+func (m mockStandingOrderService) Create(order *models.StandingOrder) (*models.StandingOrder, error) {
+	return m.CreateMock(order)
+}
+
+func (m mockStandingOrderService) Update(id types.StandingOrderID, changes *models.StandingOrder) (*models.StandingOrder, error) {
+	return m.UpdateMock(id, changes)
+}
+
+func (m mockStandingOrderService) Cancel(id types.StandingOrderID) (*models.StandingOrder, error) {
+	return m.CancelMock(id)
+}
+
+func (m mockStandingOrderService) Get(id types.StandingOrderID) (*models.StandingOrder, error) {
+	return m.GetMock(id)
+}
+
+func (m mockStandingOrderService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.StandingOrder, error) {
+	return m.FindByAccountNumberMock(accountNumber)
+}
+
+func TestStandingOrderController_Create(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockStandingOrderServ := mockStandingOrderService{
+			CreateMock: func(order *models.StandingOrder) (*models.StandingOrder, error) {
+				assert.True(t, order.Payment.Amount.Equal(decimal.NewFromFloat(25)))
+				order.ID = 1
+				order.Status = types.StandingOrderActive
+				return order, nil
+			},
+		}
+		mockStandingOrderController := NewStandingOrderController(mockStandingOrderServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/standing-orders", mockStandingOrderController.Create)
+
+		reqBody, err := json.Marshal(models.StandingOrderDTO{
+			Payment:   &models.PaymentDTO{SenderAccount: 1, ReceiverAccount: 2, Amount: 25},
+			Frequency: types.Monthly,
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/standing-orders", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var orderDTO models.StandingOrderDTO
+		err = json.NewDecoder(rr.Body).Decode(&orderDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, types.StandingOrderID(1), orderDTO.ID)
+		assert.Equal(t, types.Monthly, orderDTO.Frequency)
+		assert.Equal(t, float64(25), orderDTO.Payment.Amount)
+	})
+	t.Run("InvalidSchedule", func(t *testing.T) {
+		mockStandingOrderServ := mockStandingOrderService{
+			CreateMock: func(order *models.StandingOrder) (*models.StandingOrder, error) {
+				return nil, errs.New(errs.InvalidSchedule)
+			},
+		}
+		mockStandingOrderController := NewStandingOrderController(mockStandingOrderServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/standing-orders", mockStandingOrderController.Create)
+
+		reqBody, err := json.Marshal(models.StandingOrderDTO{Frequency: "hourly"})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/standing-orders", bytes.NewBuffer(reqBody))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestStandingOrderController_Cancel(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("NotActive", func(t *testing.T) {
+		mockStandingOrderServ := mockStandingOrderService{
+			CancelMock: func(id types.StandingOrderID) (*models.StandingOrder, error) {
+				return nil, errs.New(errs.StandingOrderNotActive)
+			},
+		}
+		mockStandingOrderController := NewStandingOrderController(mockStandingOrderServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.DELETE("/standing-orders/:orderID", mockStandingOrderController.Cancel)
+
+		req, err := http.NewRequest(http.MethodDelete, "/standing-orders/1", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestStandingOrderController_GetByAccountNumber(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockStandingOrderServ := mockStandingOrderService{
+			FindByAccountNumberMock: func(accountNumber types.AccountNumber) ([]*models.StandingOrder, error) {
+				return []*models.StandingOrder{
+					{ID: 1, Payment: &models.Payment{SenderAccount: accountNumber}, Status: types.StandingOrderCancelled},
+				}, nil
+			},
+		}
+		mockStandingOrderController := NewStandingOrderController(mockStandingOrderServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/account/:accountNumber/standing-orders", mockStandingOrderController.GetByAccountNumber)
+
+		req, err := http.NewRequest(http.MethodGet, "/account/3/standing-orders", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var ordersDTO []*models.StandingOrderDTO
+		err = json.NewDecoder(rr.Body).Decode(&ordersDTO)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 1, len(ordersDTO))
+		assert.Equal(t, types.AccountNumber(3), ordersDTO[0].Payment.SenderAccount)
+		assert.Nil(t, ordersDTO[0].NextRunAt)
+	})
+}
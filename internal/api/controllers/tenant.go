@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	apiKeyHeader   = "X-API-Key"
+	tenantIDHeader = "X-Tenant-ID"
+)
+
+type TenantController struct {
+	tenants tenantResolver
+}
+
+// tenantResolver finds the tenant of a request from its API key or tenant ID
+// and returns the handler serving the routes of that tenant
+type tenantResolver interface {
+	Resolve(apiKey string, tenantID string) (http.Handler, error)
+}
+
+func NewTenantController(tenants tenantResolver) *TenantController {
+	return &TenantController{tenants: tenants}
+}
+
+// Serve hands the request to the routes of its tenant
+func (tc *TenantController) Serve(c *gin.Context) {
+	handler, err := tc.tenants.Resolve(c.GetHeader(apiKeyHeader), c.GetHeader(tenantIDHeader))
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+	handler.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockTenantResolver struct {
+	ResolveMock func(apiKey string, tenantID string) (http.Handler, error)
+}
+
+func (m mockTenantResolver) Resolve(apiKey string, tenantID string) (http.Handler, error) {
+	return m.ResolveMock(apiKey, tenantID)
+}
+
+func TestTenantController_Serve(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		tenantController := NewTenantController(mockTenantResolver{
+			ResolveMock: func(apiKey string, tenantID string) (http.Handler, error) {
+				assert.Equal(t, "key-a", apiKey)
+				assert.Equal(t, "", tenantID)
+				tenantRouter := gin.New()
+				tenantRouter.GET("/accounts", func(c *gin.Context) {
+					c.JSON(http.StatusOK, gin.H{"tenant": "brand-a"})
+				})
+				return tenantRouter, nil
+			},
+		})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.NoRoute(tenantController.Serve)
+
+		req, err := http.NewRequest(http.MethodGet, "/accounts", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-API-Key", "key-a")
+
+		router.ServeHTTP(rr, req)
+
+		var body map[string]string
+		err = json.NewDecoder(rr.Body).Decode(&body)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "brand-a", body["tenant"])
+	})
+
+	t.Run("UnknownTenant", func(t *testing.T) {
+		tenantController := NewTenantController(mockTenantResolver{
+			ResolveMock: func(apiKey string, tenantID string) (http.Handler, error) {
+				assert.Equal(t, "brand-c", tenantID)
+				return nil, errs.New(errs.UnknownTenant)
+			},
+		})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.NoRoute(tenantController.Serve)
+
+		req, err := http.NewRequest(http.MethodGet, "/accounts", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Tenant-ID", "brand-c")
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, string(errs.UnknownTenant), problem["code"])
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/gin-gonic/gin"
@@ -14,38 +15,40 @@ type TransactionController struct {
 
 type transactionService interface {
 	NewPayment(payment *models.Payment) (*models.Transaction, error)
+	NewSplitPayment(split *models.SplitPayment) (*models.SplitPaymentResult, error)
 	NewDeposit(deposit *models.Deposit) (*models.Transaction, error)
 	NewWithdraw(withdraw *models.Withdraw) (*models.Transaction, error)
-	GetTransactionHistory(accountNumber types.AccountNumber) ([]*models.Transaction, error)
+	GetTransactionHistory(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error)
 }
 
 func NewTransactionController(s transactionService) *TransactionController {
 	return &TransactionController{service: s}
 }
 
+// GetTransactionHistory returns the history of the account, narrowed by the counterparty, reference,
+// q (free text in the description or reference) and metadata[key]=value query parameters
 func (tc *TransactionController) GetTransactionHistory(c *gin.Context) {
-	accountNumber, ok := c.Params.Get("accountNumber")
+	accountNumber, ok := accountNumberParam(c)
 	if !ok {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid argument",
-		})
 		return
 	}
 
-	// account number's type must be integer so string value converting to integer type
-	accountNumberI, err := strconv.ParseInt(accountNumber, 10, 64)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid argument",
-		})
-		return
+	// Counterparties inside the bank are stored by their account number, so their IBANs are searched as numbers
+	counterparty := c.Query("counterparty")
+	if counterpartyAccount, err := types.ParseAccountNumber(counterparty); err == nil {
+		counterparty = strconv.FormatInt(int64(counterpartyAccount), 10)
 	}
 
-	transactionHistory, err := tc.service.GetTransactionHistory(types.AccountNumber(accountNumberI))
+	filter := &models.TransactionFilter{
+		Counterparty: counterparty,
+		Reference:    c.Query("reference"),
+		Text:         c.Query("q"),
+		Metadata:     c.QueryMap("metadata"),
+	}
+
+	transactionHistory, err := tc.service.GetTransactionHistory(accountNumber, filter)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithProblem(c, err)
 		return
 	}
 
@@ -61,11 +64,7 @@ func (tc *TransactionController) GetTransactionHistory(c *gin.Context) {
 
 func (tc *TransactionController) Payment(c *gin.Context) {
 	var paymentDTO *models.PaymentDTO
-	err := c.BindJSON(&paymentDTO)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "cannot bind json",
-		})
+	if !bindJSON(c, &paymentDTO) {
 		return
 	}
 
@@ -73,9 +72,7 @@ func (tc *TransactionController) Payment(c *gin.Context) {
 
 	transaction, err := tc.service.NewPayment(payment)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithProblem(c, err)
 		return
 	}
 
@@ -85,13 +82,26 @@ func (tc *TransactionController) Payment(c *gin.Context) {
 	return
 }
 
+// SplitPayment pays several receivers from one sender, all of them or none
+func (tc *TransactionController) SplitPayment(c *gin.Context) {
+	var splitDTO *models.SplitPaymentDTO
+	if !bindJSON(c, &splitDTO) {
+		return
+	}
+
+	result, err := tc.service.NewSplitPayment(splitDTO.Normal())
+	if err != nil {
+		abortWithProblem(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result.DTO())
+	return
+}
+
 func (tc *TransactionController) Deposit(c *gin.Context) {
 	var depositDTO *models.DepositDTO
-	err := c.BindJSON(&depositDTO)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "cannot bind json",
-		})
+	if !bindJSON(c, &depositDTO) {
 		return
 	}
 
@@ -99,17 +109,13 @@ func (tc *TransactionController) Deposit(c *gin.Context) {
 
 	transaction, err := tc.service.NewDeposit(deposit)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithProblem(c, err)
 		return
 	}
 
 	transactionDTO := transaction.DTO()
 	if transactionDTO == nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "something is wrong",
-		})
+		abortWithProblem(c, errs.New(errs.Internal))
 		return
 	}
 
@@ -119,11 +125,7 @@ func (tc *TransactionController) Deposit(c *gin.Context) {
 
 func (tc *TransactionController) Withdraw(c *gin.Context) {
 	var withdrawDTO *models.WithdrawDTO
-	err := c.BindJSON(&withdrawDTO)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "cannot bind json",
-		})
+	if !bindJSON(c, &withdrawDTO) {
 		return
 	}
 
@@ -131,9 +133,7 @@ func (tc *TransactionController) Withdraw(c *gin.Context) {
 
 	transaction, err := tc.service.NewWithdraw(withdraw)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithProblem(c, err)
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/gin-gonic/gin"
@@ -18,15 +19,20 @@ import (
 
 type mockTransactionService struct {
 	NewPaymentMock               func(payment *models.Payment) (*models.Transaction, error)
+	NewSplitPaymentMock          func(split *models.SplitPayment) (*models.SplitPaymentResult, error)
 	NewDepositMock               func(deposit *models.Deposit) (*models.Transaction, error)
 	NewWithdrawMock              func(withdraw *models.Withdraw) (*models.Transaction, error)
-	NewGetTransactionHistoryMock func(accountNumber types.AccountNumber) ([]*models.Transaction, error)
+	NewGetTransactionHistoryMock func(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error)
 }
 
 func (m mockTransactionService) NewPayment(payment *models.Payment) (*models.Transaction, error) {
 	return m.NewPaymentMock(payment)
 }
 
+func (m mockTransactionService) NewSplitPayment(split *models.SplitPayment) (*models.SplitPaymentResult, error) {
+	return m.NewSplitPaymentMock(split)
+}
+
 func (m mockTransactionService) NewDeposit(deposit *models.Deposit) (*models.Transaction, error) {
 	return m.NewDepositMock(deposit)
 }
@@ -35,8 +41,8 @@ func (m mockTransactionService) NewWithdraw(withdraw *models.Withdraw) (*models.
 	return m.NewWithdrawMock(withdraw)
 }
 
-func (m mockTransactionService) GetTransactionHistory(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
-	return m.NewGetTransactionHistoryMock(accountNumber)
+func (m mockTransactionService) GetTransactionHistory(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	return m.NewGetTransactionHistoryMock(accountNumber, filter)
 }
 
 func TestTransactionController_GetTransactionHistory(t *testing.T) {
@@ -46,7 +52,7 @@ func TestTransactionController_GetTransactionHistory(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 
 		mockTransactionServ := mockTransactionService{
-			NewGetTransactionHistoryMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			NewGetTransactionHistoryMock: func(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
 				testData := []*models.Transaction{
 					{
 						AccountNumber:   1,
@@ -88,10 +94,38 @@ func TestTransactionController_GetTransactionHistory(t *testing.T) {
 
 	})
 
+	t.Run("Filter", func(t *testing.T) {
+
+		mockTransactionServ := mockTransactionService{
+			NewGetTransactionHistoryMock: func(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+				assert.Equal(t, "2", filter.Counterparty)
+				assert.Equal(t, "E2E-1042", filter.Reference)
+				assert.Equal(t, "coffee", filter.Text)
+				assert.Equal(t, map[string]string{"orderId": "1042"}, filter.Metadata)
+				return []*models.Transaction{}, nil
+			},
+		}
+
+		mockTransactionController := NewTransactionController(mockTransactionServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.GET("/accounting/:accountNumber", mockTransactionController.GetTransactionHistory)
+
+		req, err := http.NewRequest(http.MethodGet, "/accounting/1?counterparty=2&reference=E2E-1042&q=coffee&metadata[orderId]=1042", nil)
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+	})
+
 	t.Run("invalid account number", func(t *testing.T) {
 
 		mockTransactionServ := mockTransactionService{
-			NewGetTransactionHistoryMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			NewGetTransactionHistoryMock: func(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
 				return nil, errors.New("invalid account number")
 			},
 		}
@@ -329,7 +363,7 @@ func TestTransactionController_Withdraw(t *testing.T) {
 
 		mockTransactionServ := mockTransactionService{
 			NewWithdrawMock: func(withdraw *models.Withdraw) (*models.Transaction, error) {
-				return nil, errors.New("invalid insufficient balance")
+				return nil, errs.New(errs.InsufficientBalance)
 			},
 		}
 		mockTransactionController := NewTransactionController(mockTransactionServ)
@@ -354,7 +388,7 @@ func TestTransactionController_Withdraw(t *testing.T) {
 		err = json.NewDecoder(rr.Body).Decode(&actualT)
 		assert.NoError(t, err)
 
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
 
 	})
 
@@ -405,6 +439,61 @@ func TestTransactionController_Payment(t *testing.T) {
 
 	})
 
+	t.Run("IBAN", func(t *testing.T) {
+
+		mockTransactionServ := mockTransactionService{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				assert.Equal(t, types.AccountNumber(1), payment.SenderAccount)
+				assert.Equal(t, types.AccountNumber(2), payment.ReceiverAccount)
+				return &models.Transaction{
+					AccountNumber:   payment.SenderAccount,
+					Amount:          payment.Amount,
+					TransactionType: types.Payment,
+					CreatedAt:       time.Now(),
+				}, nil
+			},
+		}
+		mockTransactionController := NewTransactionController(mockTransactionServ)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/payment", mockTransactionController.Payment)
+
+		body := `{"senderAccount":"1","receiverAccount":"` + types.AccountNumber(2).IBAN() + `","amount":100}`
+		req, err := http.NewRequest(http.MethodPost, "/payment", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+	})
+
+	t.Run("InvalidIBAN", func(t *testing.T) {
+
+		mockTransactionController := NewTransactionController(mockTransactionService{})
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/payment", mockTransactionController.Payment)
+
+		body := `{"senderAccount":1,"receiverAccount":"TR000099900000000000000002","amount":100}`
+		req, err := http.NewRequest(http.MethodPost, "/payment", bytes.NewBufferString(body))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var problem map[string]interface{}
+		err = json.NewDecoder(rr.Body).Decode(&problem)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, string(errs.InvalidIBAN), problem["code"])
+
+	})
+
 	t.Run("InvalidAccountNumberForSender", func(t *testing.T) {
 
 		mockTransactionServ := mockTransactionService{
@@ -610,7 +699,7 @@ func TestTransactionController_Payment(t *testing.T) {
 
 		mockTransactionServ := mockTransactionService{
 			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
-				return nil, errors.New("insufficient balance")
+				return nil, errs.New(errs.InsufficientBalance)
 			},
 		}
 		mockTransactionController := NewTransactionController(mockTransactionServ)
@@ -636,8 +725,91 @@ func TestTransactionController_Payment(t *testing.T) {
 		err = json.NewDecoder(rr.Body).Decode(&actualT)
 		assert.NoError(t, err)
 
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
 
 	})
 
 }
+
+func TestTransactionController_SplitPayment(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+
+		mockTransactionServ := mockTransactionService{
+			NewSplitPaymentMock: func(split *models.SplitPayment) (*models.SplitPaymentResult, error) {
+				assert.Equal(t, 2, len(split.Receivers))
+				assert.True(t, split.Receivers[1].Percentage.Equal(decimal.NewFromFloat(20)))
+				return &models.SplitPaymentResult{
+					Payment: &models.Transaction{ID: 1, AccountNumber: split.SenderAccount, Amount: split.Amount, TransactionType: types.Payment, Direction: types.Debit},
+					Legs: []*models.Transaction{
+						{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromFloat(80), TransactionType: types.Payment, Direction: types.Credit},
+						{ID: 3, ParentID: 1, AccountNumber: 3, Amount: decimal.NewFromFloat(20), TransactionType: types.Payment, Direction: types.Credit},
+					},
+				}, nil
+			},
+		}
+		mockTransactionController := NewTransactionController(mockTransactionServ)
+
+		splitJSON, err := json.Marshal(&models.SplitPaymentDTO{
+			SenderAccount: 1,
+			Amount:        100,
+			Receivers: []*models.SplitReceiverDTO{
+				{AccountNumber: 2, Percentage: 80},
+				{AccountNumber: 3, Percentage: 20},
+			},
+		})
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/payment/split", mockTransactionController.SplitPayment)
+
+		req, err := http.NewRequest(http.MethodPost, "/payment/split", bytes.NewBuffer(splitJSON))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		var actual *models.SplitPaymentResultDTO
+		err = json.NewDecoder(rr.Body).Decode(&actual)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, 100.0, actual.Payment.Amount)
+		assert.Equal(t, 2, len(actual.Legs))
+		assert.Equal(t, types.TransactionID(1), actual.Legs[1].ParentID)
+	})
+
+	t.Run("InsufficientBalance", func(t *testing.T) {
+
+		mockTransactionServ := mockTransactionService{
+			NewSplitPaymentMock: func(split *models.SplitPayment) (*models.SplitPaymentResult, error) {
+				return nil, errs.New(errs.InsufficientBalance)
+			},
+		}
+		mockTransactionController := NewTransactionController(mockTransactionServ)
+
+		splitJSON, err := json.Marshal(&models.SplitPaymentDTO{
+			SenderAccount: 1,
+			Receivers: []*models.SplitReceiverDTO{
+				{AccountNumber: 2, Amount: 80},
+				{AccountNumber: 3, Amount: 20},
+			},
+		})
+		assert.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+
+		router := gin.Default()
+		router.POST("/payment/split", mockTransactionController.SplitPayment)
+
+		req, err := http.NewRequest(http.MethodPost, "/payment/split", bytes.NewBuffer(splitJSON))
+		assert.NoError(t, err)
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"log"
+	"time"
+)
+
+// job is a task the api runs in the background every interval while it is serving
+type job struct {
+	name     string
+	interval time.Duration
+	run      func()
+}
+
+// addJob registers a background job, jobs start with Run and stop with Shutdown
+func (a *api) addJob(name string, interval time.Duration, run func()) {
+	a.jobs = append(a.jobs, &job{name: name, interval: interval, run: run})
+}
+
+func (a *api) startJobs() {
+	for _, j := range a.jobs {
+		a.runningJobs.Add(1)
+		go func(j *job) {
+			defer a.runningJobs.Done()
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-a.stopJobs:
+					return
+				case <-ticker.C:
+					j.run()
+				}
+			}
+		}(j)
+		log.Printf("started %s job every %s", j.name, j.interval)
+	}
+}
+
+// stopAllJobs stops the background jobs and waits for a running job to finish
+func (a *api) stopAllJobs() {
+	a.stopJobsOnce.Do(func() {
+		close(a.stopJobs)
+	})
+	a.runningJobs.Wait()
+}
@@ -4,19 +4,185 @@ import "github.com/ahmetberke/tringle-candidate-project/internal/api/controllers
 
 // AccountRoutesInitialize takes the AccountController as a parameter
 // and implements the relevant handlers to the account routes.
-func (a *api) AccountRoutesInitialize(c *controllers.AccountController) {
-	ag := a.Router.Group("/account")
+func (t *tenant) AccountRoutesInitialize(c *controllers.AccountController) {
+	ag := t.Router.Group("/account")
 	{
 		ag.POST("/", c.Create)
 		ag.GET("/:accountNumber", c.Get)
+		ag.PATCH("/:accountNumber", c.Update)
+		ag.GET("/:accountNumber/changes", c.GetChanges)
 	}
+	t.Router.GET("/accounts", c.Search)
 }
 
 // TransactionRoutesInitialize takes the TransactionController as a parameter
 // and implements the relevant handlers to the transaction routes.
-func (a *api) TransactionRoutesInitialize(c *controllers.TransactionController) {
-	a.Router.POST("/payment", c.Payment)
-	a.Router.POST("/deposit", c.Deposit)
-	a.Router.POST("/withdraw", c.Withdraw)
-	a.Router.GET("/accounting/:accountNumber", c.GetTransactionHistory)
+func (t *tenant) TransactionRoutesInitialize(c *controllers.TransactionController) {
+	t.Router.POST("/payment", c.Payment)
+	t.Router.POST("/payment/split", c.SplitPayment)
+	t.Router.POST("/deposit", c.Deposit)
+	t.Router.POST("/withdraw", c.Withdraw)
+	t.Router.GET("/accounting/:accountNumber", c.GetTransactionHistory)
+}
+
+// FeeRoutesInitialize takes the FeeController as a parameter
+// and implements the relevant handlers to the fee routes.
+func (t *tenant) FeeRoutesInitialize(c *controllers.FeeController) {
+	t.Router.GET("/fees", c.GetSchedules)
+}
+
+// HealthRoutesInitialize takes the HealthController as a parameter
+// and implements the liveness and readiness probes.
+func (a *api) HealthRoutesInitialize(c *controllers.HealthController) {
+	a.Router.GET("/healthz", c.Health)
+	a.Router.GET("/readyz", c.Ready)
+}
+
+// TenantRoutesInitialize takes the TenantController as a parameter
+// and hands every request the api does not serve itself to the routes of its tenant.
+func (a *api) TenantRoutesInitialize(c *controllers.TenantController) {
+	a.Router.NoRoute(c.Serve)
+}
+
+// LimitRoutesInitialize takes the LimitController as a parameter
+// and implements the relevant handlers to the limit routes.
+func (t *tenant) LimitRoutesInitialize(c *controllers.LimitController) {
+	t.Router.GET("/account/:accountNumber/limits", c.GetAllowances)
+	t.Admin.PUT("/account/:accountNumber/limits", c.SetOverride)
+}
+
+// HoldRoutesInitialize takes the HoldController as a parameter
+// and implements the relevant handlers to the hold routes.
+func (t *tenant) HoldRoutesInitialize(c *controllers.HoldController) {
+	hg := t.Router.Group("/holds")
+	{
+		hg.POST("/", c.Place)
+		hg.GET("/:holdID", c.Get)
+		hg.POST("/:holdID/capture", c.Capture)
+		hg.POST("/:holdID/void", c.Void)
+	}
+}
+
+// StandingOrderRoutesInitialize takes the StandingOrderController as a parameter
+// and implements the relevant handlers to the standing order routes.
+func (t *tenant) StandingOrderRoutesInitialize(c *controllers.StandingOrderController) {
+	sg := t.Router.Group("/standing-orders")
+	{
+		sg.POST("/", c.Create)
+		sg.GET("/:orderID", c.Get)
+		sg.PUT("/:orderID", c.Update)
+		sg.DELETE("/:orderID", c.Cancel)
+	}
+	t.Router.GET("/account/:accountNumber/standing-orders", c.GetByAccountNumber)
+}
+
+// OverdraftRoutesInitialize takes the OverdraftController as a parameter
+// and implements the relevant handlers to the overdraft routes.
+func (t *tenant) OverdraftRoutesInitialize(c *controllers.OverdraftController) {
+	t.Router.GET("/account/:accountNumber/overdraft", c.Get)
+	t.Admin.PUT("/account/:accountNumber/overdraft", c.SetLimit)
+}
+
+// InterestRoutesInitialize takes the InterestController as a parameter
+// and implements the relevant handlers to the interest routes.
+func (t *tenant) InterestRoutesInitialize(c *controllers.InterestController) {
+	t.Router.GET("/account/:accountNumber/interest", c.Get)
+	t.Router.GET("/interest/rates", c.GetRates)
+}
+
+// BalanceRoutesInitialize takes the BalanceController as a parameter
+// and implements the relevant handlers to the historical balance routes.
+func (t *tenant) BalanceRoutesInitialize(c *controllers.BalanceController) {
+	t.Router.GET("/account/:accountNumber/balance", c.GetAt)
+}
+
+// ProjectionRoutesInitialize takes the ProjectionController as a parameter
+// and implements the relevant handlers to the event store administration routes.
+func (t *tenant) ProjectionRoutesInitialize(c *controllers.ProjectionController) {
+	t.Admin.GET("/events", c.GetEvents)
+	t.Admin.GET("/projections/accounts/diff", c.Diff)
+	t.Admin.POST("/projections/accounts/rebuild", c.Rebuild)
+}
+
+// ReconciliationRoutesInitialize takes the ReconciliationController as a parameter
+// and implements the relevant handlers to the reconciliation routes.
+func (t *tenant) ReconciliationRoutesInitialize(c *controllers.ReconciliationController) {
+	t.Admin.GET("/reconciliation", c.GetLast)
+	t.Admin.POST("/reconciliation", c.Run)
+}
+
+// CustomerRoutesInitialize takes the CustomerController as a parameter
+// and implements the relevant handlers to the customer routes.
+func (t *tenant) CustomerRoutesInitialize(c *controllers.CustomerController) {
+	cg := t.Router.Group("/customers")
+	{
+		cg.POST("/", c.Create)
+		cg.GET("/:customerID", c.Get)
+		cg.PUT("/:customerID/kyc", c.UpdateKYC)
+		cg.POST("/:customerID/wallets", c.OpenWallet)
+		cg.GET("/:customerID/balances", c.GetBalances)
+		cg.POST("/:customerID/transfers", c.Transfer)
+	}
+}
+
+// EscrowRoutesInitialize takes the EscrowController as a parameter
+// and implements the relevant handlers to the escrow routes.
+func (t *tenant) EscrowRoutesInitialize(c *controllers.EscrowController) {
+	eg := t.Router.Group("/escrows")
+	{
+		eg.POST("/", c.Fund)
+		eg.GET("/:escrowID", c.Get)
+		eg.POST("/:escrowID/release", c.Release)
+		eg.POST("/:escrowID/refund", c.Refund)
+	}
+	t.Router.GET("/account/:accountNumber/escrows", c.GetByAccountNumber)
+}
+
+// InvoiceRoutesInitialize takes the InvoiceController as a parameter
+// and implements the relevant handlers to the invoice routes.
+func (t *tenant) InvoiceRoutesInitialize(c *controllers.InvoiceController) {
+	ig := t.Router.Group("/invoices")
+	{
+		ig.POST("/", c.Create)
+		ig.GET("/:invoiceID", c.Get)
+		ig.POST("/:invoiceID/pay", c.Pay)
+		ig.POST("/:invoiceID/cancel", c.Cancel)
+	}
+	t.Router.GET("/account/:accountNumber/invoices", c.GetByAccountNumber)
+}
+
+// DisputeRoutesInitialize takes the DisputeController as a parameter
+// and implements the relevant handlers to the dispute routes, disputes are resolved by operators under /admin.
+func (t *tenant) DisputeRoutesInitialize(c *controllers.DisputeController) {
+	dg := t.Router.Group("/disputes")
+	{
+		dg.POST("/", c.Open)
+		dg.GET("/:disputeID", c.Get)
+	}
+	t.Router.GET("/account/:accountNumber/disputes", c.GetByAccountNumber)
+	t.Admin.GET("/disputes", c.GetOpen)
+	t.Admin.POST("/disputes/:disputeID/resolve", c.Resolve)
+}
+
+// ScreeningRoutesInitialize takes the ScreeningController as a parameter
+// and implements the relevant handlers to the sanctions screening review routes.
+func (t *tenant) ScreeningRoutesInitialize(c *controllers.ScreeningController) {
+	sg := t.Admin.Group("/screening")
+	{
+		sg.GET("/cases", c.GetCases)
+		sg.GET("/cases/:caseID", c.GetCase)
+		sg.POST("/cases/:caseID/decision", c.Decide)
+		sg.GET("/decisions", c.GetDecisions)
+	}
+}
+
+// FraudRoutesInitialize takes the FraudController as a parameter
+// and implements the relevant handlers to the fraud alert routes.
+func (t *tenant) FraudRoutesInitialize(c *controllers.FraudController) {
+	fg := t.Admin.Group("/fraud")
+	{
+		fg.GET("/alerts", c.GetAlerts)
+		fg.GET("/alerts/:alertID", c.GetAlert)
+		fg.POST("/alerts/:alertID/decision", c.Decide)
+	}
 }
@@ -0,0 +1,314 @@
+package api
+
+import (
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/configs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/api/controllers"
+	"github.com/ahmetberke/tringle-candidate-project/internal/cache"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/services"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"path/filepath"
+	"sort"
+)
+
+// tenant is the ledger of a single tenant, it has its own caches, services and routes
+// so nothing of one tenant can be read or changed through another
+type tenant struct {
+	ID     string
+	Router *gin.Engine
+	// Admin serves the operator routes, only requests with the admin key reach them
+	Admin *gin.RouterGroup
+
+	storages []storage
+
+	// services run by the background jobs
+	holdService           *services.HoldService
+	standingOrderService  *services.StandingOrderService
+	overdraftService      *services.OverdraftService
+	interestService       *services.InterestService
+	balanceService        *services.BalanceService
+	reconciliationService *services.ReconciliationService
+	escrowService         *services.EscrowService
+	invoiceService        *services.InvoiceService
+}
+
+func newTenant(settings *models.Tenant) *tenant {
+	t := &tenant{
+		ID:     settings.ID,
+		Router: gin.New(),
+	}
+	t.Admin = t.Router.Group("/admin", controllers.RequireAdminKey(configs.Manager.AdminAPIKey))
+
+	// Creating cache layers
+	accountNumbers, err := cache.NewAccountNumberAllocator(configs.Manager.AccountNumbers)
+	if err != nil {
+		panic(err)
+	}
+	eventStore := cache.NewEventStore()
+	interestCache := cache.NewInterestCache()
+	overdraftCursor := cache.NewAccrualCursor()
+	if configs.Manager.EventsDir != "" {
+		eventStore, err = cache.OpenEventStore(filepath.Join(configs.Manager.EventsDir, settings.ID+".jsonl"))
+		if err != nil {
+			panic(err)
+		}
+		interestCache, err = cache.OpenInterestCache(filepath.Join(configs.Manager.EventsDir, settings.ID+".interest.json"))
+		if err != nil {
+			panic(err)
+		}
+		overdraftCursor, err = cache.OpenAccrualCursor(filepath.Join(configs.Manager.EventsDir, settings.ID+".overdraft.json"))
+		if err != nil {
+			panic(err)
+		}
+	}
+	// Every cache is filled again from the records it saved to the event store
+	accountCache := cache.NewAccountCacheFromEvents(eventStore, accountNumbers)
+	transactionCache, err := cache.NewTransactionCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	limits := settings.Limits
+	if len(limits) == 0 {
+		for _, l := range configs.Manager.Limits {
+			limits = append(limits, l.Normal())
+		}
+	}
+	limitCache, err := cache.NewLimitCacheFromEvents(limits, eventStore)
+	if err != nil {
+		panic(err)
+	}
+	holdCache, err := cache.NewHoldCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	standingOrderCache, err := cache.NewStandingOrderCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	checkpointCache, err := cache.NewCheckpointCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	customerCache, err := cache.NewCustomerCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	escrowCache, err := cache.NewEscrowCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	invoiceCache, err := cache.NewInvoiceCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	disputeCache, err := cache.NewDisputeCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	screeningCache, err := cache.NewScreeningCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	fraudCache, err := cache.NewFraudCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	systemAccountCache, err := cache.NewSystemAccountCacheFromEvents(eventStore)
+	if err != nil {
+		panic(err)
+	}
+	t.storages = append(t.storages, accountCache, transactionCache)
+
+	// Creating services
+	limitService := services.NewLimitService(limitCache, accountCache, transactionCache)
+	policyRules := configs.Manager.Policies
+	if len(policyRules) == 0 {
+		policyRules = services.DefaultPolicyRules()
+	}
+	policyService := services.NewPolicyService(policyRules)
+	var feeSchedules []*models.FeeSchedule
+	for _, f := range configs.Manager.Fees {
+		feeSchedules = append(feeSchedules, f.Normal())
+	}
+	feeService := services.NewFeeService(feeSchedules, accountCache, systemAccountCache)
+	houseCurrencies := settings.Currencies
+	if len(houseCurrencies) == 0 {
+		houseCurrencies = []types.Currency{types.TRY, types.USD, types.EUR}
+	}
+	err = feeService.OpenHouseAccounts(houseCurrencies)
+	if err != nil {
+		panic(err)
+	}
+	screeningService := services.NewScreeningService(screeningCache, accountCache, configs.Manager.Screening.Entities,
+		configs.Manager.Screening.AccountThreshold, configs.Manager.Screening.PaymentThreshold)
+	fraudService := services.NewFraudService(fraudCache, accountCache, transactionCache, configs.Manager.FraudRules)
+	transactionService := services.NewTransactionService(accountCache, transactionCache,
+		services.WithLimits(limitService),
+		services.WithPolicy(policyService),
+		services.WithFees(feeService),
+		services.WithPaymentScreening(screeningService),
+		services.WithFraudScoring(fraudService))
+	screeningService.UsePayments(transactionService)
+	fraudService.UseTransactions(transactionService)
+	t.holdService = services.NewHoldService(holdCache, accountCache, transactionService, policyService,
+		configs.Manager.Holds.TTL)
+	t.holdService.UseFraudScoring(fraudService)
+	fraudService.UseHolds(t.holdService)
+	t.standingOrderService = services.NewStandingOrderService(standingOrderCache, accountCache, transactionService, policyService,
+		services.RetryPolicy{
+			MaxRetries: configs.Manager.StandingOrders.MaxRetries,
+			Delay:      configs.Manager.StandingOrders.RetryDelay,
+		})
+	t.balanceService = services.NewBalanceService(accountCache, transactionCache, checkpointCache)
+	t.overdraftService = services.NewOverdraftService(accountCache, overdraftCursor, transactionCache, t.balanceService, feeService,
+		configs.Manager.Overdraft.AnnualRate, configs.Manager.Overdraft.MaxLimit)
+	var interestRates []*models.InterestRate
+	for _, r := range configs.Manager.Interest.Rates {
+		interestRates = append(interestRates, r.Normal())
+	}
+	t.interestService = services.NewInterestService(accountCache, interestCache, transactionCache, t.balanceService, feeService, interestRates)
+	accountService := services.NewAccountService(accountCache,
+		services.WithHolds(t.holdService),
+		services.WithCurrencies(settings.Currencies),
+		services.WithAccountScreening(screeningService),
+		services.WithOpeningDeposits(transactionService))
+	projectionService := services.NewProjectionService(accountCache)
+	t.reconciliationService = services.NewReconciliationService(accountCache, transactionCache)
+	var exchangeRates []*models.ExchangeRate
+	for _, r := range configs.Manager.ExchangeRates {
+		exchangeRates = append(exchangeRates, r.Normal())
+	}
+	fxService := services.NewFXService(exchangeRates)
+	customerService := services.NewCustomerService(customerCache, accountService, accountCache, transactionCache, fxService)
+	t.escrowService = services.NewEscrowService(escrowCache, accountCache, transactionCache, systemAccountCache, policyService,
+		configs.Manager.Escrow.TTL,
+		services.WithEscrowControls(limitService, screeningService, feeService))
+	err = t.escrowService.OpenEscrowAccounts(houseCurrencies)
+	if err != nil {
+		panic(err)
+	}
+	t.escrowService.UseFraudScoring(fraudService)
+	fraudService.UseEscrows(t.escrowService)
+	t.invoiceService = services.NewInvoiceService(invoiceCache, accountCache, transactionService, policyService)
+	disputeService := services.NewDisputeService(disputeCache, accountCache, transactionCache, feeService)
+
+	// Creating controllers
+	accountController := controllers.NewAccountController(accountService)
+	transactionController := controllers.NewTransactionController(transactionService)
+	limitController := controllers.NewLimitController(limitService)
+	feeController := controllers.NewFeeController(feeService)
+	holdController := controllers.NewHoldController(t.holdService)
+	standingOrderController := controllers.NewStandingOrderController(t.standingOrderService)
+	overdraftController := controllers.NewOverdraftController(t.overdraftService)
+	interestController := controllers.NewInterestController(t.interestService)
+	balanceController := controllers.NewBalanceController(t.balanceService)
+	projectionController := controllers.NewProjectionController(projectionService)
+	reconciliationController := controllers.NewReconciliationController(t.reconciliationService)
+	customerController := controllers.NewCustomerController(customerService)
+	escrowController := controllers.NewEscrowController(t.escrowService)
+	invoiceController := controllers.NewInvoiceController(t.invoiceService)
+	disputeController := controllers.NewDisputeController(disputeService)
+	screeningController := controllers.NewScreeningController(screeningService)
+	fraudController := controllers.NewFraudController(fraudService)
+
+	// Initializing routes
+	t.AccountRoutesInitialize(accountController)
+	t.TransactionRoutesInitialize(transactionController)
+	t.LimitRoutesInitialize(limitController)
+	t.FeeRoutesInitialize(feeController)
+	t.HoldRoutesInitialize(holdController)
+	t.StandingOrderRoutesInitialize(standingOrderController)
+	t.OverdraftRoutesInitialize(overdraftController)
+	t.InterestRoutesInitialize(interestController)
+	t.BalanceRoutesInitialize(balanceController)
+	t.ProjectionRoutesInitialize(projectionController)
+	t.ReconciliationRoutesInitialize(reconciliationController)
+	t.CustomerRoutesInitialize(customerController)
+	t.EscrowRoutesInitialize(escrowController)
+	t.InvoiceRoutesInitialize(invoiceController)
+	t.DisputeRoutesInitialize(disputeController)
+	t.ScreeningRoutesInitialize(screeningController)
+	t.FraudRoutesInitialize(fraudController)
+
+	return t
+}
+
+// tenants keeps the ledgers of the configured tenants, every ledger is created when the api starts
+type tenants struct {
+	byAPIKey map[string]*tenant
+	// ledgers are ordered by tenant ID
+	ledgers []*tenant
+	// single is set when no tenants are configured, every request then belongs to it
+	single *tenant
+}
+
+func newTenants(settings []*models.Tenant) *tenants {
+	ts := &tenants{
+		byAPIKey: make(map[string]*tenant),
+	}
+	if len(settings) == 0 {
+		ts.single = newTenant(&models.Tenant{ID: models.DefaultTenant})
+		ts.ledgers = append(ts.ledgers, ts.single)
+		return ts
+	}
+	for _, s := range settings {
+		t := newTenant(s)
+		for _, key := range s.APIKeys {
+			ts.byAPIKey[key] = t
+		}
+		ts.ledgers = append(ts.ledgers, t)
+	}
+	sort.Slice(ts.ledgers, func(i, j int) bool { return ts.ledgers[i].ID < ts.ledgers[j].ID })
+	return ts
+}
+
+// Resolve returns the routes of the tenant of the API key, every tenant can only be reached with one of its keys.
+// A tenant ID sent along with the key has to be the ID of that tenant.
+func (ts *tenants) Resolve(apiKey string, tenantID string) (http.Handler, error) {
+	if ts.single != nil {
+		return ts.single.Router, nil
+	}
+	if apiKey == "" {
+		return nil, errs.Newf(errs.UnknownTenant, "an API key is required")
+	}
+	t, ok := ts.byAPIKey[apiKey]
+	if !ok || (tenantID != "" && tenantID != t.ID) {
+		return nil, errs.New(errs.UnknownTenant)
+	}
+	return t.Router, nil
+}
+
+// all returns the ledgers of every tenant, ordered by tenant ID
+func (ts *tenants) all() []*tenant {
+	return ts.ledgers
+}
+
+// Ready reports whether the storages of every ledger are ready
+func (ts *tenants) Ready() error {
+	for _, t := range ts.all() {
+		for _, s := range t.storages {
+			if err := s.Ready(); err != nil {
+				return fmt.Errorf("tenant %s: %w", t.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Flush flushes the storages of every ledger, the first error is returned
+// but the remaining storages are still flushed
+func (ts *tenants) Flush() error {
+	var flushErr error
+	for _, t := range ts.all() {
+		for _, s := range t.storages {
+			if err := s.Flush(); err != nil && flushErr == nil {
+				flushErr = fmt.Errorf("tenant %s: %w", t.ID, err)
+			}
+		}
+	}
+	return flushErr
+}
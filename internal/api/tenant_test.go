@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/configs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTenantTestAPI(t *testing.T) *api {
+	configs.Manager.Setup()
+	configs.Manager.Tenants = []*models.Tenant{
+		{ID: "brand-a", APIKeys: []string{"key-a"}, Currencies: []types.Currency{types.TRY}},
+		{ID: "brand-b", APIKeys: []string{"key-b"}},
+	}
+	t.Cleanup(func() { configs.Manager.Tenants = nil })
+	return NewAPI()
+}
+
+// serve sends the request to the api with the header pairs and decodes the response body into out
+func serve(t *testing.T, a *api, method string, path string, body string, out interface{}, headers ...string) int {
+	req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rr := httptest.NewRecorder()
+	a.Router.ServeHTTP(rr, req)
+	if out != nil {
+		assert.NoError(t, json.NewDecoder(rr.Body).Decode(out))
+	}
+	return rr.Code
+}
+
+func TestAPI_Tenants(t *testing.T) {
+	brandA := []string{"X-API-Key", "key-a"}
+	brandB := []string{"X-API-Key", "key-b"}
+	individual := `{"ownerName":"Ada Lovelace","currencyCode":"TRY","accountType":"individual"}`
+	corporate := `{"ownerName":"Apple","currencyCode":"TRY","accountType":"corporate"}`
+
+	t.Run("SeparateLedgers", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+
+		// Every tenant opens the house revenue and the escrow accounts of its currencies first
+		var account models.AccountDTO
+		assert.Equal(t, http.StatusCreated, serve(t, a, http.MethodPost, "/account/", individual, &account, brandA...))
+		assert.Equal(t, types.AccountNumber(3), account.AccountNumber)
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodPost, "/deposit", `{"accountNumber":3,"amount":100}`, nil, brandA...))
+
+		// The other tenant neither sees the account nor its history
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/account/3", "", &account, brandB...))
+		assert.Equal(t, "Tringle House Revenue", account.OwnerName)
+		assert.Equal(t, float64(0), account.Balance)
+		assert.Equal(t, http.StatusNotFound, serve(t, a, http.MethodGet, "/accounting/3", "", nil, brandB...))
+
+		// and numbers its own accounts after its own house and escrow accounts
+		assert.Equal(t, http.StatusCreated, serve(t, a, http.MethodPost, "/account/", corporate, &account, brandB...))
+		assert.Equal(t, types.AccountNumber(7), account.AccountNumber)
+		assert.Equal(t, "Apple", account.OwnerName)
+
+		var problem map[string]interface{}
+		assert.Equal(t, http.StatusNotFound, serve(t, a, http.MethodGet, "/account/7", "", &problem, brandA...))
+		assert.Equal(t, string(errs.AccountNotFound), problem["code"])
+
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/account/3", "", &account, brandA...))
+		assert.Equal(t, "Ada Lovelace", account.OwnerName)
+		assert.Equal(t, float64(100), account.Balance)
+	})
+
+	t.Run("NoCrossTenantPayments", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+
+		serve(t, a, http.MethodPost, "/account/", individual, nil, brandA...)
+		serve(t, a, http.MethodPost, "/deposit", `{"accountNumber":3,"amount":100}`, nil, brandA...)
+		serve(t, a, http.MethodPost, "/account/", corporate, nil, brandB...)
+		serve(t, a, http.MethodPost, "/account/", corporate, nil, brandB...)
+
+		// Account 8 only exists in the ledger of the other tenant
+		var problem map[string]interface{}
+		status := serve(t, a, http.MethodPost, "/payment", `{"senderAccount":3,"receiverAccount":8,"amount":10}`, &problem, brandA...)
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Equal(t, string(errs.AccountNotFound), problem["code"])
+
+		var account models.AccountDTO
+		serve(t, a, http.MethodGet, "/account/3", "", &account, brandA...)
+		assert.Equal(t, float64(100), account.Balance)
+		serve(t, a, http.MethodGet, "/account/8", "", &account, brandB...)
+		assert.Equal(t, float64(0), account.Balance)
+	})
+
+	t.Run("UnknownTenant", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+
+		for _, headers := range [][]string{
+			nil,
+			{"X-API-Key", "key-c"},
+			// Tenants are only picked by their keys, never by their IDs alone
+			{"X-Tenant-ID", "brand-a"},
+			{"X-Tenant-ID", "brand-c"},
+			{"X-API-Key", "key-a", "X-Tenant-ID", "brand-b"},
+		} {
+			var problem map[string]interface{}
+			assert.Equal(t, http.StatusUnauthorized, serve(t, a, http.MethodGet, "/accounts", "", &problem, headers...))
+			assert.Equal(t, string(errs.UnknownTenant), problem["code"])
+		}
+
+		// Only the configured tenants have ledgers, requests for others never create one
+		var ids []string
+		for _, ledger := range a.tenants.all() {
+			ids = append(ids, ledger.ID)
+		}
+		assert.Equal(t, []string{"brand-a", "brand-b"}, ids)
+
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/healthz", "", nil))
+	})
+
+	t.Run("AdminKey", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+
+		// Without a configured key the admin routes are closed
+		var problem map[string]interface{}
+		assert.Equal(t, http.StatusUnauthorized, serve(t, a, http.MethodGet, "/admin/events", "", &problem, brandA...))
+		assert.Equal(t, string(errs.AdminKeyInvalid), problem["code"])
+
+		configs.Manager.AdminAPIKey = "admin-key"
+		t.Cleanup(func() { configs.Manager.AdminAPIKey = "" })
+		a = NewAPI()
+		assert.Equal(t, http.StatusUnauthorized, serve(t, a, http.MethodPost, "/admin/reconciliation", "", nil, brandA...))
+		assert.Equal(t, http.StatusUnauthorized, serve(t, a, http.MethodPut, "/admin/account/2/limits", "{}", nil, brandA...))
+		assert.Equal(t, http.StatusNotFound, serve(t, a, http.MethodPut, "/account/2/limits", "{}", nil, brandA...))
+		assert.Equal(t, http.StatusUnauthorized, serve(t, a, http.MethodPut, "/admin/account/2/overdraft", "{}", nil, brandA...))
+		assert.Equal(t, http.StatusNotFound, serve(t, a, http.MethodPut, "/account/2/overdraft", "{}", nil, brandA...))
+		assert.Equal(t, http.StatusUnauthorized, serve(t, a, http.MethodGet, "/admin/events", "", nil, append(brandA, "X-Admin-Key", "key-a")...))
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/admin/events", "", nil, append(brandA, "X-Admin-Key", "admin-key")...))
+	})
+
+	t.Run("EventLog", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+		configs.Manager.EventsDir = t.TempDir()
+		t.Cleanup(func() { configs.Manager.EventsDir = "" })
+
+		a = NewAPI()
+		serve(t, a, http.MethodPost, "/account/", individual, nil, brandA...)
+		serve(t, a, http.MethodPost, "/account/", corporate, nil, brandA...)
+		serve(t, a, http.MethodPost, "/deposit", `{"accountNumber":3,"amount":100}`, nil, brandA...)
+		assert.Equal(t, http.StatusCreated, serve(t, a, http.MethodPost, "/holds/", `{"accountNumber":3,"receiverAccount":4,"amount":30}`, nil, brandA...))
+		assert.NoError(t, a.Shutdown())
+
+		// The accounts are replayed from the event log of the tenant, the house and escrow accounts are not opened again
+		a = NewAPI()
+		var account models.AccountDTO
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/account/3", "", &account, brandA...))
+		assert.Equal(t, "Ada Lovelace", account.OwnerName)
+		assert.Equal(t, float64(70), account.AvailableBalance)
+		assert.Equal(t, 1, len(account.Holds))
+
+		// The history and the hold reserving the held amount come back with them
+		var history []map[string]interface{}
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/accounting/3", "", &history, brandA...))
+		assert.Equal(t, 1, len(history))
+		var hold models.HoldDTO
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/holds/1", "", &hold, brandA...))
+		assert.Equal(t, types.HoldActive, hold.Status)
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodPost, "/holds/1/void", "", nil, brandA...))
+		assert.Equal(t, http.StatusCreated, serve(t, a, http.MethodPost, "/account/", individual, &account, brandA...))
+		assert.Equal(t, types.AccountNumber(5), account.AccountNumber)
+	})
+
+	t.Run("BalancesAfterRestart", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+		configs.Manager.EventsDir = t.TempDir()
+		t.Cleanup(func() { configs.Manager.EventsDir = "" })
+
+		a = NewAPI()
+		serve(t, a, http.MethodPost, "/account/", individual, nil, brandA...)
+		serve(t, a, http.MethodPost, "/deposit", `{"accountNumber":3,"amount":100}`, nil, brandA...)
+		assert.Equal(t, 1, a.tenants.all()[0].balanceService.Checkpoint())
+		serve(t, a, http.MethodPost, "/withdraw", `{"accountNumber":3,"amount":40}`, nil, brandA...)
+		assert.Equal(t, 1, a.tenants.all()[0].balanceService.Checkpoint())
+		assert.NoError(t, a.Shutdown())
+
+		// The accrual jobs read the balances from the history and the checkpoints replayed with the accounts
+		a = NewAPI()
+		var balance models.HistoricalBalanceDTO
+		assert.Equal(t, http.StatusOK, serve(t, a, http.MethodGet, "/account/3/balance", "", &balance, brandA...))
+		assert.Equal(t, float64(60), balance.Balance)
+		assert.Equal(t, 0, a.tenants.all()[0].balanceService.Checkpoint())
+	})
+
+	t.Run("Currencies", func(t *testing.T) {
+		a := newTenantTestAPI(t)
+
+		usd := `{"ownerName":"Ada Lovelace","currencyCode":"USD","accountType":"individual"}`
+		var problem map[string]interface{}
+		assert.Equal(t, http.StatusBadRequest, serve(t, a, http.MethodPost, "/account/", usd, &problem, brandA...))
+		assert.Equal(t, string(errs.InvalidCurrency), problem["code"])
+		assert.Equal(t, http.StatusCreated, serve(t, a, http.MethodPost, "/account/", usd, nil, brandB...))
+	})
+}
@@ -2,58 +2,493 @@ package cache
 
 import (
 	"errors"
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
+	"sort"
 	"sync"
+	"time"
 )
 
-var lastAccountNumber = 0
-
+// AccountCache is a projection of the event store, accounts are never changed in place.
+// Every change is appended to the store as an event and then applied to the projection,
+// so the projection can always be rebuilt by replaying the store.
 type AccountCache struct {
 	mu       sync.Mutex
+	store    *EventStore
 	accounts map[types.AccountNumber]*models.Account
+	index    *accountIndex
+	numbers  *AccountNumberAllocator
+	// changes keeps the profile versions of every account
+	changes map[types.AccountNumber][]*models.AccountChange
 }
 
 func NewAccountCache() *AccountCache {
-	return &AccountCache{
-		mu:       sync.Mutex{},
-		accounts: make(map[types.AccountNumber]*models.Account),
+	return NewAccountCacheFromEvents(NewEventStore(), nil)
+}
+
+// NewAccountCacheFromEvents returns the projection of the events already in the store,
+// later changes are appended to the same store. New accounts are numbered by the allocator,
+// without one every account type is numbered from 1.
+func NewAccountCacheFromEvents(store *EventStore, numbers *AccountNumberAllocator) *AccountCache {
+	if numbers == nil {
+		numbers, _ = NewAccountNumberAllocator(nil)
+	}
+	a := &AccountCache{
+		mu:      sync.Mutex{},
+		store:   store,
+		numbers: numbers,
+	}
+	a.replay()
+	return a
+}
+
+// replay empties the projection and applies every event in the store to it
+func (a *AccountCache) replay() {
+	a.accounts = make(map[types.AccountNumber]*models.Account)
+	a.index = newAccountIndex()
+	a.changes = make(map[types.AccountNumber][]*models.AccountChange)
+	a.numbers.reset()
+	for _, event := range a.store.GetAll(0) {
+		a.apply(event)
 	}
 }
 
 func (a *AccountCache) Get(accountNumber types.AccountNumber) (*models.Account, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.get(accountNumber)
+}
+
+func (a *AccountCache) get(accountNumber types.AccountNumber) (*models.Account, error) {
 	account, ok := a.accounts[accountNumber]
 	if !ok {
-		return nil, errors.New("invalid account number")
+		return nil, errs.New(errs.AccountNotFound)
 	}
 	return account, nil
 }
 
-func (a *AccountCache) Create(account *models.Account) *models.Account {
+// Create opens the account with its balance as the opening balance and its overdraft limit,
+// the account is given the next free number of the range of its type
+func (a *AccountCache) Create(account *models.Account) (*models.Account, error) {
 	// Locks with mutex to prevent errors from concurrent access
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	lastAccountNumber++
-	account.AccountNumber = types.AccountNumber(lastAccountNumber)
-	a.accounts[account.AccountNumber] = account
-	return account
+	accountNumber, err := a.numbers.next(account.AccountType)
+	if err != nil {
+		return nil, err
+	}
+	account.AccountNumber = accountNumber
+	events := []*models.Event{{
+		Type:          types.AccountOpened,
+		AccountNumber: account.AccountNumber,
+		Amount:        account.Balance,
+		CurrencyCode:  account.CurrencyCode,
+		OwnerName:     account.OwnerName,
+		AccountType:   account.AccountType,
+		CustomerID:    account.CustomerID,
+		Contact:       account.Contact,
+		Metadata:      account.Metadata,
+	}}
+	if !account.OverdraftLimit.IsZero() {
+		events = append(events, &models.Event{
+			Type:          types.OverdraftLimitSet,
+			AccountNumber: account.AccountNumber,
+			Amount:        account.OverdraftLimit,
+		})
+	}
+	a.record(events...)
+	return a.accounts[account.AccountNumber], nil
 }
 
 func (a *AccountCache) Delete(accountNumber types.AccountNumber) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	delete(a.accounts, accountNumber)
+	if _, err := a.get(accountNumber); err != nil {
+		return
+	}
+	a.record(&models.Event{Type: types.AccountClosed, AccountNumber: accountNumber})
 }
 
+// UpdateBalance records the difference to the balance as an adjustment
 func (a *AccountCache) UpdateBalance(accountNumber types.AccountNumber, balance decimal.Decimal) error {
 	// Locks with mutex to prevent errors from concurrent access
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	account, err := a.Get(accountNumber)
+	account, err := a.get(accountNumber)
+	if err != nil {
+		return err
+	}
+	a.record(&models.Event{
+		Type:          types.BalanceAdjusted,
+		AccountNumber: accountNumber,
+		Amount:        balance.Sub(account.Balance),
+	})
+	return nil
+}
+
+// SetOverdraftLimit changes how far below zero the balance of the account can go,
+// a balance already below the new limit is kept but cannot be spent further
+func (a *AccountCache) SetOverdraftLimit(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	account, err := a.get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	a.record(&models.Event{
+		Type:          types.OverdraftLimitSet,
+		AccountNumber: accountNumber,
+		Amount:        limit,
+	})
+	return account, nil
+}
+
+// UpdateProfile replaces the profile of the account if the account is still at the version,
+// a zero version updates whatever version the account is at. An unchanged profile keeps the version.
+func (a *AccountCache) UpdateProfile(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	account, err := a.get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && account.Version != version {
+		return nil, errs.Newf(errs.VersionMismatch, "account %d is at version %d", accountNumber, account.Version).
+			With("version", account.Version)
+	}
+	if len(models.DiffProfiles(account.Profile(), profile)) == 0 {
+		return account, nil
+	}
+	a.record(&models.Event{
+		Type:          types.AccountUpdated,
+		AccountNumber: accountNumber,
+		OwnerName:     profile.OwnerName,
+		Contact:       profile.Contact,
+		Metadata:      profile.Metadata,
+	})
+	return account, nil
+}
+
+// Changes returns the profile versions of the account, oldest first
+func (a *AccountCache) Changes(accountNumber types.AccountNumber) ([]*models.AccountChange, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.get(accountNumber); err != nil {
+		return nil, err
+	}
+	return append([]*models.AccountChange(nil), a.changes[accountNumber]...), nil
+}
+
+// GetAll returns every account, ordered by account number
+func (a *AccountCache) GetAll() []*models.Account {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	accounts := make([]*models.Account, 0, len(a.accounts))
+	for _, account := range a.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountNumber < accounts[j].AccountNumber
+	})
+	return accounts
+}
+
+// Search returns the page of the accounts matching the filter, ordered by account number.
+// The indexed fields narrow down the accounts first, only those are checked for the balance range.
+func (a *AccountCache) Search(filter *models.AccountFilter) *models.AccountPage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	page := &models.AccountPage{Offset: filter.Offset, Limit: filter.Limit, Accounts: []*models.Account{}}
+	for _, accountNumber := range a.index.candidates(filter) {
+		account := a.accounts[accountNumber]
+		if filter.MinBalance != nil && account.Balance.LessThan(*filter.MinBalance) {
+			continue
+		}
+		if filter.MaxBalance != nil && account.Balance.GreaterThan(*filter.MaxBalance) {
+			continue
+		}
+		if page.Total >= filter.Offset && len(page.Accounts) < filter.Limit {
+			page.Accounts = append(page.Accounts, account)
+		}
+		page.Total++
+	}
+	return page
+}
+
+// ApplyChanges applies every change or none of them. Changes to the same account are summed,
+// if any of them requires funds the final available balance of that account cannot be negative,
+// that is the balance cannot go below the overdraft limit of the account.
+// Every change is recorded as an event of its reason.
+func (a *AccountCache) ApplyChanges(changes []*models.BalanceChange) error {
+	// Locks with mutex so that no other update happens between checking and applying
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.applyChanges(changes)
+}
+
+// Commit applies the changes like ApplyChanges and, once they are applied, calls record before the lock is released.
+// record writes the history lines of the changes, so no other change and no Inspect sees the balances without them.
+// record cannot call the account cache.
+func (a *AccountCache) Commit(changes []*models.BalanceChange, record func()) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.applyChanges(changes); err != nil {
+		return err
+	}
+	record()
+	return nil
+}
+
+// Inspect calls read with a copy of the account while no change can be committed,
+// so that whatever read looks up next to the account, e.g. its history, matches its balance.
+// read cannot call the account cache.
+func (a *AccountCache) Inspect(accountNumber types.AccountNumber, read func(account *models.Account)) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	account, err := a.get(accountNumber)
 	if err != nil {
 		return err
 	}
-	account.Balance = balance
-	return err
+	inspected := *account
+	inspected.Metadata = models.CopyMetadata(account.Metadata)
+	read(&inspected)
+	return nil
+}
+
+// applyChanges applies every change or none of them, it has to be called with the lock held
+func (a *AccountCache) applyChanges(changes []*models.BalanceChange) error {
+	type pending struct {
+		balance      decimal.Decimal
+		held         decimal.Decimal
+		overdraft    decimal.Decimal
+		requireFunds bool
+	}
+	results := make(map[types.AccountNumber]*pending)
+	var order []types.AccountNumber
+	for _, change := range changes {
+		result, ok := results[change.AccountNumber]
+		if !ok {
+			account, err := a.get(change.AccountNumber)
+			if err != nil {
+				return err
+			}
+			result = &pending{balance: account.Balance, held: account.Held, overdraft: account.OverdraftLimit}
+			results[change.AccountNumber] = result
+			order = append(order, change.AccountNumber)
+		}
+		result.balance = result.balance.Add(change.Amount)
+		result.held = result.held.Add(change.Held)
+		result.requireFunds = result.requireFunds || change.RequireFunds
+	}
+
+	for _, accountNumber := range order {
+		result := results[accountNumber]
+		if result.requireFunds && result.balance.Sub(result.held).Add(result.overdraft).IsNegative() {
+			return errs.Newf(errs.InsufficientBalance, "account %d", accountNumber)
+		}
+	}
+
+	events := make([]*models.Event, 0, len(changes))
+	for _, change := range changes {
+		events = append(events, &models.Event{
+			Type:          reasonOf(change),
+			AccountNumber: change.AccountNumber,
+			Amount:        change.Amount,
+			Held:          change.Held,
+		})
+	}
+	a.record(events...)
+	return nil
+}
+
+// reasonOf returns the event type of a change, changes without a reason
+// are told apart by whether they only reserve funds
+func reasonOf(change *models.BalanceChange) types.EventType {
+	switch {
+	case change.Reason != "":
+		return change.Reason
+	case change.Amount.IsZero() && change.Held.IsPositive():
+		return types.FundsHeld
+	case change.Amount.IsZero() && change.Held.IsNegative():
+		return types.FundsReleased
+	}
+	return types.BalanceAdjusted
+}
+
+// record appends the events to the store and applies them to the projection,
+// it has to be called with the lock held
+func (a *AccountCache) record(events ...*models.Event) {
+	a.store.Append(events...)
+	for _, event := range events {
+		a.apply(event)
+	}
+}
+
+// apply changes the projection by a single event
+func (a *AccountCache) apply(event *models.Event) {
+	// Records of the other caches change no account
+	if event.Type == types.RecordSaved {
+		return
+	}
+	if event.Type == types.AccountOpened {
+		a.accounts[event.AccountNumber] = &models.Account{
+			AccountNumber: event.AccountNumber,
+			CurrencyCode:  event.CurrencyCode,
+			OwnerName:     event.OwnerName,
+			AccountType:   event.AccountType,
+			CustomerID:    event.CustomerID,
+			Balance:       event.Amount,
+			Contact:       event.Contact,
+			Metadata:      models.CopyMetadata(event.Metadata),
+			Version:       1,
+			CreatedAt:     event.At,
+		}
+		a.index.add(a.accounts[event.AccountNumber])
+		a.changes[event.AccountNumber] = []*models.AccountChange{{
+			Version: 1,
+			At:      event.At,
+			Fields:  models.DiffProfiles(&models.AccountProfile{}, a.accounts[event.AccountNumber].Profile()),
+		}}
+		trackOverdrawn(a.accounts[event.AccountNumber], event.At)
+		// Replayed accounts keep their numbers, new accounts are numbered after them
+		a.numbers.reserve(event.AccountType, event.AccountNumber)
+		return
+	}
+
+	account, ok := a.accounts[event.AccountNumber]
+	if !ok {
+		return
+	}
+	switch event.Type {
+	case types.AccountClosed:
+		a.index.remove(account)
+		delete(a.accounts, event.AccountNumber)
+		delete(a.changes, event.AccountNumber)
+	case types.AccountUpdated:
+		old := account.Profile()
+		// The owner name is indexed, so the account is indexed again under its new name
+		a.index.remove(account)
+		account.OwnerName = event.OwnerName
+		account.Contact = event.Contact
+		account.Metadata = models.CopyMetadata(event.Metadata)
+		account.Version++
+		a.index.add(account)
+		a.changes[event.AccountNumber] = append(a.changes[event.AccountNumber], &models.AccountChange{
+			Version: account.Version,
+			At:      event.At,
+			Fields:  models.DiffProfiles(old, account.Profile()),
+		})
+	case types.OverdraftLimitSet:
+		account.OverdraftLimit = event.Amount
+	default:
+		account.Balance = account.Balance.Add(event.Amount)
+		account.Held = account.Held.Add(event.Held)
+		trackOverdrawn(account, event.At)
+	}
+}
+
+// trackOverdrawn records when the balance of the account went below zero
+func trackOverdrawn(account *models.Account, at time.Time) {
+	if !account.Balance.IsNegative() {
+		account.OverdrawnSince = time.Time{}
+		return
+	}
+	if account.OverdrawnSince.IsZero() {
+		account.OverdrawnSince = at
+	}
+}
+
+// Store returns the event store the projection is built from
+func (a *AccountCache) Store() *EventStore {
+	return a.store
+}
+
+// Events returns the events recorded after the sequence, in order
+func (a *AccountCache) Events(after types.EventSequence) []*models.Event {
+	return a.store.GetAll(after)
+}
+
+// Rebuild replaces the projection with a replay of every event in the store
+func (a *AccountCache) Rebuild() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.replay()
+}
+
+// Diff compares the projection with a projection rebuilt from the store
+// and returns every field that differs
+func (a *AccountCache) Diff() []*models.ProjectionDiff {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rebuilt := &AccountCache{store: a.store, numbers: a.numbers.empty()}
+	rebuilt.replay()
+
+	var numbers []types.AccountNumber
+	for accountNumber := range a.accounts {
+		numbers = append(numbers, accountNumber)
+	}
+	for accountNumber := range rebuilt.accounts {
+		if _, ok := a.accounts[accountNumber]; !ok {
+			numbers = append(numbers, accountNumber)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	var diffs []*models.ProjectionDiff
+	for _, accountNumber := range numbers {
+		diffs = append(diffs, diffAccount(accountNumber, a.accounts[accountNumber], rebuilt.accounts[accountNumber])...)
+	}
+	return diffs
+}
+
+func diffAccount(accountNumber types.AccountNumber, live *models.Account, rebuilt *models.Account) []*models.ProjectionDiff {
+	if live == nil || rebuilt == nil {
+		return []*models.ProjectionDiff{{
+			AccountNumber: accountNumber,
+			Field:         "exists",
+			Live:          fmt.Sprint(live != nil),
+			Rebuilt:       fmt.Sprint(rebuilt != nil),
+		}}
+	}
+
+	fields := []struct {
+		name    string
+		live    string
+		rebuilt string
+	}{
+		{"currencyCode", string(live.CurrencyCode), string(rebuilt.CurrencyCode)},
+		{"ownerName", live.OwnerName, rebuilt.OwnerName},
+		{"version", fmt.Sprint(live.Version), fmt.Sprint(rebuilt.Version)},
+		{"accountType", string(live.AccountType), string(rebuilt.AccountType)},
+		{"balance", live.Balance.String(), rebuilt.Balance.String()},
+		{"held", live.Held.String(), rebuilt.Held.String()},
+		{"overdraftLimit", live.OverdraftLimit.String(), rebuilt.OverdraftLimit.String()},
+	}
+	var diffs []*models.ProjectionDiff
+	for _, f := range fields {
+		if f.live != f.rebuilt {
+			diffs = append(diffs, &models.ProjectionDiff{AccountNumber: accountNumber, Field: f.name, Live: f.live, Rebuilt: f.rebuilt})
+		}
+	}
+	return diffs
+}
+
+// Ready reports whether the cache can serve reads and writes
+func (a *AccountCache) Ready() error {
+	if a.accounts == nil || a.store == nil {
+		return errors.New("account cache is not initialized")
+	}
+	return a.store.Ready()
+}
+
+// Flush writes pending changes to the underlying storage,
+// the accounts are kept as the events of their store so the store is flushed.
+func (a *AccountCache) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.store.Flush()
 }
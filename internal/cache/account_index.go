@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sort"
+	"strings"
+	"time"
+)
+
+// accountSet is a set of account numbers
+type accountSet map[types.AccountNumber]struct{}
+
+// accountIndex keeps the secondary indexes of the account projection so that
+// searches only look at the accounts that can match instead of every account
+type accountIndex struct {
+	// numbers is every account number in order
+	numbers []types.AccountNumber
+	// opened is every account in the order it was opened in, accounts of types numbered from
+	// different ranges are not opened in the order of their numbers
+	opened     []openedAccount
+	byType     map[string]accountSet
+	byCurrency map[string]accountSet
+	// byWord maps every lowercased word of the owner names to the accounts having it,
+	// words keeps its keys sorted for prefix lookups
+	byWord map[string]accountSet
+	words  []string
+}
+
+// openedAccount is an account number with the time its account was opened
+type openedAccount struct {
+	accountNumber types.AccountNumber
+	at            time.Time
+}
+
+func newAccountIndex() *accountIndex {
+	return &accountIndex{
+		byType:     make(map[string]accountSet),
+		byCurrency: make(map[string]accountSet),
+		byWord:     make(map[string]accountSet),
+	}
+}
+
+func (ai *accountIndex) add(account *models.Account) {
+	i := sort.Search(len(ai.numbers), func(i int) bool { return ai.numbers[i] >= account.AccountNumber })
+	if i < len(ai.numbers) && ai.numbers[i] == account.AccountNumber {
+		return
+	}
+	ai.numbers = append(ai.numbers, 0)
+	copy(ai.numbers[i+1:], ai.numbers[i:])
+	ai.numbers[i] = account.AccountNumber
+
+	// Accounts opened at the same time are kept in the order they were added
+	j := sort.Search(len(ai.opened), func(j int) bool { return ai.opened[j].at.After(account.CreatedAt) })
+	ai.opened = append(ai.opened, openedAccount{})
+	copy(ai.opened[j+1:], ai.opened[j:])
+	ai.opened[j] = openedAccount{accountNumber: account.AccountNumber, at: account.CreatedAt}
+
+	addTo(ai.byType, string(account.AccountType), account.AccountNumber)
+	addTo(ai.byCurrency, string(account.CurrencyCode), account.AccountNumber)
+	for _, word := range nameWords(account.OwnerName) {
+		if _, ok := ai.byWord[word]; !ok {
+			j := sort.SearchStrings(ai.words, word)
+			ai.words = append(ai.words, "")
+			copy(ai.words[j+1:], ai.words[j:])
+			ai.words[j] = word
+		}
+		addTo(ai.byWord, word, account.AccountNumber)
+	}
+}
+
+func (ai *accountIndex) remove(account *models.Account) {
+	i := sort.Search(len(ai.numbers), func(i int) bool { return ai.numbers[i] >= account.AccountNumber })
+	if i == len(ai.numbers) || ai.numbers[i] != account.AccountNumber {
+		return
+	}
+	ai.numbers = append(ai.numbers[:i], ai.numbers[i+1:]...)
+
+	for j := sort.Search(len(ai.opened), func(j int) bool { return !ai.opened[j].at.Before(account.CreatedAt) }); j < len(ai.opened); j++ {
+		if ai.opened[j].accountNumber == account.AccountNumber {
+			ai.opened = append(ai.opened[:j], ai.opened[j+1:]...)
+			break
+		}
+	}
+
+	removeFrom(ai.byType, string(account.AccountType), account.AccountNumber)
+	removeFrom(ai.byCurrency, string(account.CurrencyCode), account.AccountNumber)
+	for _, word := range nameWords(account.OwnerName) {
+		removeFrom(ai.byWord, word, account.AccountNumber)
+		if _, ok := ai.byWord[word]; !ok {
+			j := sort.SearchStrings(ai.words, word)
+			ai.words = append(ai.words[:j], ai.words[j+1:]...)
+		}
+	}
+}
+
+// candidates returns the accounts that can match the filter on the indexed fields, in order
+func (ai *accountIndex) candidates(filter *models.AccountFilter) []types.AccountNumber {
+	var sets []accountSet
+	if !filter.CreatedFrom.IsZero() || !filter.CreatedTo.IsZero() {
+		sets = append(sets, ai.openedBetween(filter.CreatedFrom, filter.CreatedTo))
+	}
+	if filter.AccountType != "" {
+		sets = append(sets, ai.byType[string(filter.AccountType)])
+	}
+	if filter.CurrencyCode != "" {
+		sets = append(sets, ai.byCurrency[string(filter.CurrencyCode)])
+	}
+	for _, word := range nameWords(filter.OwnerName) {
+		sets = append(sets, ai.matchWord(word, filter.Fuzzy))
+	}
+	if len(sets) == 0 {
+		return append([]types.AccountNumber(nil), ai.numbers...)
+	}
+
+	// Walk the smallest set and check the others, then put the matches back in account order
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	var matches []types.AccountNumber
+	for accountNumber := range sets[0] {
+		inAll := true
+		for _, set := range sets[1:] {
+			if _, ok := set[accountNumber]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			matches = append(matches, accountNumber)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i] < matches[j] })
+	return matches
+}
+
+// openedBetween returns the accounts opened in the range, a zero time leaves that end of the range open
+func (ai *accountIndex) openedBetween(from time.Time, to time.Time) accountSet {
+	first := 0
+	if !from.IsZero() {
+		first = sort.Search(len(ai.opened), func(i int) bool { return !ai.opened[i].at.Before(from) })
+	}
+	last := len(ai.opened)
+	if !to.IsZero() {
+		last = sort.Search(len(ai.opened), func(i int) bool { return ai.opened[i].at.After(to) })
+	}
+	opened := make(accountSet)
+	for i := first; i < last; i++ {
+		opened[ai.opened[i].accountNumber] = struct{}{}
+	}
+	return opened
+}
+
+// matchWord returns the accounts having a word that starts with the word,
+// or with fuzzy set, a word close enough to it
+func (ai *accountIndex) matchWord(word string, fuzzy bool) accountSet {
+	matches := make(accountSet)
+	for i := sort.SearchStrings(ai.words, word); i < len(ai.words) && strings.HasPrefix(ai.words[i], word); i++ {
+		for accountNumber := range ai.byWord[ai.words[i]] {
+			matches[accountNumber] = struct{}{}
+		}
+	}
+	if !fuzzy {
+		return matches
+	}
+
+	// Fuzzy lookups compare against the distinct words of the owner names, not against every account
+	for _, candidate := range ai.words {
+		if editDistance(word, candidate) > maxTypos(word) {
+			continue
+		}
+		for accountNumber := range ai.byWord[candidate] {
+			matches[accountNumber] = struct{}{}
+		}
+	}
+	return matches
+}
+
+// maxTypos is how many letters of a word can be wrong for it to still match,
+// short words have to be spelled right
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	}
+	return 2
+}
+
+// editDistance returns the Levenshtein distance between the words
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// nameWords splits an owner name into its distinct lowercased words
+func nameWords(name string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func addTo(index map[string]accountSet, key string, accountNumber types.AccountNumber) {
+	set, ok := index[key]
+	if !ok {
+		set = make(accountSet)
+		index[key] = set
+	}
+	set[accountNumber] = struct{}{}
+}
+
+func removeFrom(index map[string]accountSet, key string, accountNumber types.AccountNumber) {
+	delete(index[key], accountNumber)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// AccountNumberAllocator gives out the numbers of new accounts from the range of their type.
+// Every number it gives out is persisted in the AccountOpened event of its account,
+// so the allocator is restored by replaying the store it belongs to and keeps no state of its own.
+// It is not safe for concurrent use, the account cache owning it calls it with its lock held.
+type AccountNumberAllocator struct {
+	ranges []*models.AccountNumberRange
+	// fallback numbers the account types without a range of their own
+	fallback *models.AccountNumberRange
+	// last is the highest number given out of every range, new numbers continue after it
+	last map[*models.AccountNumberRange]types.AccountNumber
+	// taken has every number ever given out, numbers of closed accounts are never given out again
+	taken map[types.AccountNumber]struct{}
+}
+
+// NewAccountNumberAllocator returns an allocator numbering the accounts of every type from its range,
+// types without a range are numbered from the range without an account type, or from 1 without one
+func NewAccountNumberAllocator(ranges []*models.AccountNumberRange) (*AccountNumberAllocator, error) {
+	if err := models.ValidateAccountNumberRanges(ranges); err != nil {
+		return nil, err
+	}
+	an := &AccountNumberAllocator{
+		ranges:   ranges,
+		fallback: &models.AccountNumberRange{First: 1, Last: types.MaxAccountNumber},
+	}
+	for _, r := range ranges {
+		if r.AccountType == "" {
+			an.fallback = r
+		}
+	}
+	an.reset()
+	return an, nil
+}
+
+// empty returns an allocator with the same ranges that has not given out any number
+func (an *AccountNumberAllocator) empty() *AccountNumberAllocator {
+	empty := &AccountNumberAllocator{ranges: an.ranges, fallback: an.fallback}
+	empty.reset()
+	return empty
+}
+
+func (an *AccountNumberAllocator) reset() {
+	an.last = make(map[*models.AccountNumberRange]types.AccountNumber)
+	an.taken = make(map[types.AccountNumber]struct{})
+}
+
+func (an *AccountNumberAllocator) rangeOf(accountType types.AccountType) *models.AccountNumberRange {
+	for _, r := range an.ranges {
+		if r.AccountType == accountType {
+			return r
+		}
+	}
+	return an.fallback
+}
+
+// reserve marks the number as given out, so that it is never given out again
+func (an *AccountNumberAllocator) reserve(accountType types.AccountType, accountNumber types.AccountNumber) {
+	an.taken[accountNumber] = struct{}{}
+	if r := an.rangeOf(accountType); r.Contains(accountNumber) && accountNumber > an.last[r] {
+		an.last[r] = accountNumber
+	}
+}
+
+// next returns the lowest number after the last one given out of the range of the type that nobody has,
+// numbers another type was given, e.g. before the ranges were configured, are skipped.
+// The fallback range of 1 to MaxAccountNumber skips the ranges of the other types as a whole.
+func (an *AccountNumberAllocator) next(accountType types.AccountType) (types.AccountNumber, error) {
+	r := an.rangeOf(accountType)
+	accountNumber := r.First
+	if last, ok := an.last[r]; ok {
+		accountNumber = last + 1
+	}
+	for accountNumber <= r.Last {
+		if other := an.otherRange(r, accountNumber); other != nil {
+			accountNumber = other.Last + 1
+			continue
+		}
+		if _, ok := an.taken[accountNumber]; !ok {
+			return accountNumber, nil
+		}
+		accountNumber++
+	}
+	return 0, errs.Newf(errs.AccountNumbersExhausted, "every number from %d to %d is taken", r.First, r.Last)
+}
+
+// otherRange returns the range other than r the number belongs to, or nil if it belongs to none
+func (an *AccountNumberAllocator) otherRange(r *models.AccountNumberRange, accountNumber types.AccountNumber) *models.AccountNumberRange {
+	for _, other := range an.ranges {
+		if other != r && other.Contains(accountNumber) {
+			return other
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAccountNumberAllocator(t *testing.T) {
+	individual := &models.Account{CurrencyCode: types.TRY, OwnerName: "Ken Thompson", AccountType: types.Individual}
+	corporate := &models.Account{CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate}
+	open := func(accountCache *AccountCache, account models.Account) types.AccountNumber {
+		created, err := accountCache.Create(&account)
+		assert.NoError(t, err)
+		return created.AccountNumber
+	}
+
+	t.Run("SeparateStores", func(t *testing.T) {
+		first := NewAccountCache()
+		second := NewAccountCache()
+		assert.Equal(t, types.AccountNumber(1), open(first, *individual))
+		assert.Equal(t, types.AccountNumber(1), open(second, *individual))
+		assert.Equal(t, types.AccountNumber(2), open(first, *corporate))
+	})
+
+	t.Run("Ranges", func(t *testing.T) {
+		numbers, err := NewAccountNumberAllocator([]*models.AccountNumberRange{
+			(&models.AccountNumberRangeDTO{AccountType: types.Individual, Prefix: "1", Digits: 8}).Normal(),
+			{AccountType: types.Corporate, First: 90000000, Last: 90000001},
+		})
+		assert.NoError(t, err)
+		accountCache := NewAccountCacheFromEvents(NewEventStore(), numbers)
+
+		assert.Equal(t, types.AccountNumber(10000000), open(accountCache, *individual))
+		assert.Equal(t, types.AccountNumber(90000000), open(accountCache, *corporate))
+		assert.Equal(t, types.AccountNumber(10000001), open(accountCache, *individual))
+		assert.Equal(t, types.AccountNumber(90000001), open(accountCache, *corporate))
+
+		_, err = accountCache.Create(&models.Account{CurrencyCode: types.TRY, OwnerName: "Google", AccountType: types.Corporate})
+		assert.True(t, errs.Is(err, errs.AccountNumbersExhausted))
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		open(accountCache, *individual)
+		closed := open(accountCache, *individual)
+		accountCache.Delete(closed)
+
+		restarted := NewAccountCacheFromEvents(accountCache.Store(), nil)
+		assert.Equal(t, types.AccountNumber(3), open(restarted, *individual))
+	})
+
+	t.Run("Collision", func(t *testing.T) {
+		// Accounts opened before the ranges were configured keep their numbers
+		accountCache := NewAccountCache()
+		open(accountCache, *individual)
+		open(accountCache, *corporate)
+
+		numbers, err := NewAccountNumberAllocator([]*models.AccountNumberRange{
+			{AccountType: types.Corporate, First: 2, Last: 10},
+			{AccountType: types.Individual, First: 11, Last: 20},
+		})
+		assert.NoError(t, err)
+		restarted := NewAccountCacheFromEvents(accountCache.Store(), numbers)
+		assert.Equal(t, types.AccountNumber(3), open(restarted, *corporate))
+		assert.Equal(t, types.AccountNumber(11), open(restarted, *individual))
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		// Types without a range of their own are numbered from 1 around the ranges of the others
+		numbers, err := NewAccountNumberAllocator([]*models.AccountNumberRange{
+			{AccountType: types.Corporate, First: 2, Last: 3},
+		})
+		assert.NoError(t, err)
+		accountCache := NewAccountCacheFromEvents(NewEventStore(), numbers)
+
+		assert.Equal(t, types.AccountNumber(1), open(accountCache, *individual))
+		assert.Equal(t, types.AccountNumber(4), open(accountCache, *individual))
+		assert.Equal(t, types.AccountNumber(2), open(accountCache, *corporate))
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		_, err := NewAccountNumberAllocator([]*models.AccountNumberRange{
+			{AccountType: types.Individual, First: 1, Last: 100},
+			{AccountType: types.Corporate, First: 100, Last: 200},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -1,21 +1,24 @@
 package cache
 
 import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestAccountCache_Create(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		accountCache := NewAccountCache()
-		account := accountCache.Create(&models.Account{
+		account, err := accountCache.Create(&models.Account{
 			CurrencyCode: types.TRY,
 			OwnerName:    "Ken Thompson",
 			AccountType:  types.Individual,
 		})
+		assert.NoError(t, err)
 		assert.Equal(t, types.AccountNumber(1), account.AccountNumber)
 	})
 }
@@ -23,7 +26,7 @@ func TestAccountCache_Create(t *testing.T) {
 func TestAccountCache_Get(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		accountCache := NewAccountCache()
-		account := accountCache.Create(&models.Account{
+		account, _ := accountCache.Create(&models.Account{
 			CurrencyCode: types.TRY,
 			OwnerName:    "Ken Thompson",
 			AccountType:  types.Individual,
@@ -42,7 +45,7 @@ func TestAccountCache_Get(t *testing.T) {
 func TestAccountCache_Delete(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		accountCache := NewAccountCache()
-		account := accountCache.Create(&models.Account{
+		account, _ := accountCache.Create(&models.Account{
 			CurrencyCode: types.TRY,
 			OwnerName:    "Ken Thompson",
 			AccountType:  types.Individual,
@@ -63,7 +66,7 @@ func TestAccountCache_UpdateBalance(t *testing.T) {
 			AccountType:  types.Individual,
 			Balance:      decimal.NewFromFloat(float64(123)),
 		}
-		eAccount = accountCache.Create(eAccount)
+		eAccount, _ = accountCache.Create(eAccount)
 		err := accountCache.UpdateBalance(eAccount.AccountNumber, decimal.NewFromFloat(200))
 		assert.NoError(t, err)
 
@@ -73,3 +76,336 @@ func TestAccountCache_UpdateBalance(t *testing.T) {
 		assert.Equal(t, float64(200), amountF)
 	})
 }
+
+func TestAccountCache_ApplyChanges(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		sender, _ := accountCache.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Ken Thompson",
+			AccountType:  types.Individual,
+			Balance:      decimal.NewFromFloat(100),
+		})
+		receiver, _ := accountCache.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Bell Labs",
+			AccountType:  types.Corporate,
+		})
+
+		err := accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: sender.AccountNumber, Amount: decimal.NewFromFloat(-60), RequireFunds: true},
+			{AccountNumber: receiver.AccountNumber, Amount: decimal.NewFromFloat(60)},
+			{AccountNumber: receiver.AccountNumber, Amount: decimal.NewFromFloat(-1), RequireFunds: true},
+		})
+		assert.NoError(t, err)
+		assert.True(t, sender.Balance.Equal(decimal.NewFromFloat(40)))
+		assert.True(t, receiver.Balance.Equal(decimal.NewFromFloat(59)))
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		sender, _ := accountCache.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Ken Thompson",
+			AccountType:  types.Individual,
+			Balance:      decimal.NewFromFloat(100),
+		})
+		receiver, _ := accountCache.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Bell Labs",
+			AccountType:  types.Corporate,
+		})
+
+		err := accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: receiver.AccountNumber, Amount: decimal.NewFromFloat(150)},
+			{AccountNumber: sender.AccountNumber, Amount: decimal.NewFromFloat(-150), RequireFunds: true},
+		})
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+		assert.True(t, sender.Balance.Equal(decimal.NewFromFloat(100)))
+		assert.True(t, receiver.Balance.IsZero())
+	})
+	t.Run("AccountNotFound", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		err := accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: 42, Amount: decimal.NewFromFloat(1)},
+		})
+		assert.True(t, errs.Is(err, errs.AccountNotFound))
+	})
+	t.Run("Overdraft", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		account, _ := accountCache.Create(&models.Account{
+			CurrencyCode:   types.TRY,
+			OwnerName:      "Ken Thompson",
+			AccountType:    types.Individual,
+			Balance:        decimal.NewFromFloat(100),
+			OverdraftLimit: decimal.NewFromFloat(50),
+		})
+
+		err := accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: account.AccountNumber, Amount: decimal.NewFromFloat(-151), RequireFunds: true},
+		})
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+		assert.True(t, account.OverdrawnSince.IsZero())
+
+		err = accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: account.AccountNumber, Amount: decimal.NewFromFloat(-150), RequireFunds: true},
+		})
+		assert.NoError(t, err)
+		assert.True(t, account.Balance.Equal(decimal.NewFromFloat(-50)))
+		assert.False(t, account.OverdrawnSince.IsZero())
+
+		err = accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: account.AccountNumber, Amount: decimal.NewFromFloat(50)},
+		})
+		assert.NoError(t, err)
+		assert.True(t, account.OverdrawnSince.IsZero())
+	})
+}
+
+func TestAccountCache_Commit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		account, _ := accountCache.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Ken Thompson",
+			AccountType:  types.Individual,
+		})
+
+		// An account inspected while a commit is recording its history waits for the history to be written
+		recording := make(chan struct{})
+		recorded := make(chan struct{})
+		var history []string
+		go func() {
+			_ = accountCache.Commit([]*models.BalanceChange{
+				{AccountNumber: account.AccountNumber, Amount: decimal.NewFromFloat(10)},
+			}, func() {
+				close(recording)
+				<-recorded
+				history = append(history, "deposit")
+			})
+		}()
+		<-recording
+
+		inspected := make(chan decimal.Decimal)
+		go func() {
+			_ = accountCache.Inspect(account.AccountNumber, func(account *models.Account) {
+				assert.Equal(t, []string{"deposit"}, history)
+				inspected <- account.Balance
+			})
+		}()
+		select {
+		case <-inspected:
+			t.Fatal("account was inspected before its history was written")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(recorded)
+		assert.True(t, (<-inspected).Equal(decimal.NewFromFloat(10)))
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		accountCache := NewAccountCache()
+		account, _ := accountCache.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Ken Thompson",
+			AccountType:  types.Individual,
+		})
+
+		err := accountCache.Commit([]*models.BalanceChange{
+			{AccountNumber: account.AccountNumber, Amount: decimal.NewFromFloat(-10), RequireFunds: true},
+		}, func() {
+			t.Fatal("history of a change that was not applied was written")
+		})
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+	})
+}
+
+func TestAccountCache_Rebuild(t *testing.T) {
+	accountCache := NewAccountCache()
+	sender, _ := accountCache.Create(&models.Account{
+		CurrencyCode: types.TRY,
+		OwnerName:    "Ken Thompson",
+		AccountType:  types.Individual,
+		Balance:      decimal.NewFromFloat(100),
+	})
+	receiver, _ := accountCache.Create(&models.Account{
+		CurrencyCode: types.TRY,
+		OwnerName:    "Bell Labs",
+		AccountType:  types.Corporate,
+	})
+	_, err := accountCache.SetOverdraftLimit(sender.AccountNumber, decimal.NewFromFloat(50))
+	assert.NoError(t, err)
+	err = accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: sender.AccountNumber, Amount: decimal.NewFromFloat(-120), RequireFunds: true, Reason: types.PaymentSent},
+		{AccountNumber: receiver.AccountNumber, Amount: decimal.NewFromFloat(120), Reason: types.PaymentReceived},
+	})
+	assert.NoError(t, err)
+	err = accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: receiver.AccountNumber, Held: decimal.NewFromFloat(20), RequireFunds: true},
+	})
+	assert.NoError(t, err)
+
+	events := accountCache.Events(0)
+	assert.Equal(t, 6, len(events))
+	assert.Equal(t, types.PaymentSent, events[3].Type)
+	assert.Equal(t, types.FundsHeld, events[5].Type)
+	assert.Empty(t, accountCache.Diff())
+
+	rebuilt := NewAccountCacheFromEvents(accountCache.Store(), nil)
+	rebuiltSender, err := rebuilt.Get(sender.AccountNumber)
+	assert.NoError(t, err)
+	assert.True(t, rebuiltSender.Balance.Equal(decimal.NewFromFloat(-20)))
+	assert.True(t, rebuiltSender.OverdraftLimit.Equal(decimal.NewFromFloat(50)))
+	assert.Equal(t, sender.OverdrawnSince, rebuiltSender.OverdrawnSince)
+
+	// A projection changed outside of the events is found by the diff and fixed by the rebuild
+	receiver.Balance = decimal.NewFromFloat(1000)
+	diffs := accountCache.Diff()
+	assert.Equal(t, 1, len(diffs))
+	assert.Equal(t, "balance", diffs[0].Field)
+	assert.Equal(t, "120", diffs[0].Rebuilt)
+
+	accountCache.Rebuild()
+	assert.Empty(t, accountCache.Diff())
+	rebuiltReceiver, err := accountCache.Get(receiver.AccountNumber)
+	assert.NoError(t, err)
+	assert.True(t, rebuiltReceiver.Balance.Equal(decimal.NewFromFloat(120)))
+	assert.True(t, rebuiltReceiver.Held.Equal(decimal.NewFromFloat(20)))
+}
+
+func TestAccountCache_Search(t *testing.T) {
+	accountCache := NewAccountCache()
+	ahmet, _ := accountCache.Create(&models.Account{CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual})
+	mehmet, _ := accountCache.Create(&models.Account{CurrencyCode: types.USD, OwnerName: "Mehmet Berker", AccountType: types.Individual})
+	apple, _ := accountCache.Create(&models.Account{CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate})
+	closed, _ := accountCache.Create(&models.Account{CurrencyCode: types.TRY, OwnerName: "Ahmet Closed", AccountType: types.Individual})
+	accountCache.Delete(closed.AccountNumber)
+	err := accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: ahmet.AccountNumber, Amount: decimal.NewFromFloat(100)},
+		{AccountNumber: apple.AccountNumber, Amount: decimal.NewFromFloat(1000)},
+	})
+	assert.NoError(t, err)
+
+	numbers := func(page *models.AccountPage) []types.AccountNumber {
+		var accountNumbers []types.AccountNumber
+		for _, account := range page.Accounts {
+			accountNumbers = append(accountNumbers, account.AccountNumber)
+		}
+		return accountNumbers
+	}
+
+	t.Run("All", func(t *testing.T) {
+		page := accountCache.Search(&models.AccountFilter{Limit: 10})
+		assert.Equal(t, 3, page.Total)
+		assert.Equal(t, []types.AccountNumber{ahmet.AccountNumber, mehmet.AccountNumber, apple.AccountNumber}, numbers(page))
+	})
+	t.Run("OwnerNamePrefix", func(t *testing.T) {
+		page := accountCache.Search(&models.AccountFilter{OwnerName: "ber", Limit: 10})
+		assert.Equal(t, []types.AccountNumber{ahmet.AccountNumber, mehmet.AccountNumber}, numbers(page))
+
+		page = accountCache.Search(&models.AccountFilter{OwnerName: "AHMET ber", Limit: 10})
+		assert.Equal(t, []types.AccountNumber{ahmet.AccountNumber}, numbers(page))
+	})
+	t.Run("OwnerNameFuzzy", func(t *testing.T) {
+		page := accountCache.Search(&models.AccountFilter{OwnerName: "ahmte", Limit: 10})
+		assert.Equal(t, 0, page.Total)
+
+		page = accountCache.Search(&models.AccountFilter{OwnerName: "ahmat", Fuzzy: true, Limit: 10})
+		assert.Equal(t, []types.AccountNumber{ahmet.AccountNumber}, numbers(page))
+	})
+	t.Run("TypeAndCurrency", func(t *testing.T) {
+		page := accountCache.Search(&models.AccountFilter{AccountType: types.Individual, CurrencyCode: types.TRY, Limit: 10})
+		assert.Equal(t, []types.AccountNumber{ahmet.AccountNumber}, numbers(page))
+	})
+	t.Run("BalanceRange", func(t *testing.T) {
+		minBalance := decimal.NewFromFloat(50)
+		maxBalance := decimal.NewFromFloat(500)
+		page := accountCache.Search(&models.AccountFilter{MinBalance: &minBalance, MaxBalance: &maxBalance, Limit: 10})
+		assert.Equal(t, []types.AccountNumber{ahmet.AccountNumber}, numbers(page))
+	})
+	t.Run("CreatedRange", func(t *testing.T) {
+		page := accountCache.Search(&models.AccountFilter{CreatedFrom: mehmet.CreatedAt, CreatedTo: apple.CreatedAt, Limit: 10})
+		assert.Contains(t, numbers(page), mehmet.AccountNumber)
+		assert.Contains(t, numbers(page), apple.AccountNumber)
+
+		page = accountCache.Search(&models.AccountFilter{CreatedFrom: time.Now().Add(time.Hour), Limit: 10})
+		assert.Equal(t, 0, page.Total)
+	})
+	t.Run("CreatedRangeAcrossNumberRanges", func(t *testing.T) {
+		// Individual and corporate accounts are numbered from separate ranges,
+		// so the accounts are not opened in the order of their numbers
+		ranges, err := NewAccountNumberAllocator([]*models.AccountNumberRange{
+			{AccountType: types.Individual, First: 1000, Last: 1999},
+			{AccountType: types.Corporate, First: 100, Last: 199},
+		})
+		assert.NoError(t, err)
+		accountCache := NewAccountCacheFromEvents(NewEventStore(), ranges)
+		var opened []*models.Account
+		for _, account := range []*models.Account{
+			{CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual},
+			{CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			{CurrencyCode: types.TRY, OwnerName: "Mehmet Berker", AccountType: types.Individual},
+			{CurrencyCode: types.TRY, OwnerName: "Google", AccountType: types.Corporate},
+		} {
+			account, err := accountCache.Create(account)
+			assert.NoError(t, err)
+			opened = append(opened, account)
+			time.Sleep(time.Millisecond)
+		}
+
+		page := accountCache.Search(&models.AccountFilter{CreatedFrom: opened[1].CreatedAt, CreatedTo: opened[2].CreatedAt, Limit: 10})
+		assert.Equal(t, []types.AccountNumber{opened[1].AccountNumber, opened[2].AccountNumber}, numbers(page))
+
+		page = accountCache.Search(&models.AccountFilter{CreatedFrom: opened[2].CreatedAt, Limit: 10})
+		assert.Equal(t, []types.AccountNumber{101, 1001}, numbers(page))
+
+		page = accountCache.Search(&models.AccountFilter{CreatedTo: opened[1].CreatedAt, AccountType: types.Individual, Limit: 10})
+		assert.Equal(t, []types.AccountNumber{1000}, numbers(page))
+	})
+	t.Run("Pagination", func(t *testing.T) {
+		page := accountCache.Search(&models.AccountFilter{Offset: 1, Limit: 1})
+		assert.Equal(t, 3, page.Total)
+		assert.Equal(t, []types.AccountNumber{mehmet.AccountNumber}, numbers(page))
+	})
+}
+
+func TestAccountCache_UpdateProfile(t *testing.T) {
+	accountCache := NewAccountCache()
+	account, _ := accountCache.Create(&models.Account{
+		CurrencyCode: types.TRY,
+		OwnerName:    "Ahmet Berk",
+		AccountType:  types.Individual,
+		Metadata:     map[string]string{"segment": "retail"},
+	})
+	assert.Equal(t, 1, account.Version)
+
+	t.Run("Success", func(t *testing.T) {
+		updated, err := accountCache.UpdateProfile(account.AccountNumber, 1, &models.AccountProfile{
+			OwnerName: "Ahmet Berke",
+			Contact:   models.ContactInfo{Email: "ahmet@example.com"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+		assert.Equal(t, "Ahmet Berke", updated.OwnerName)
+
+		changes, err := accountCache.Changes(account.AccountNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(changes))
+		assert.Equal(t, []*models.FieldChange{
+			{Field: "ownerName", Old: "Ahmet Berk", New: "Ahmet Berke"},
+			{Field: "contact.email", Old: "", New: "ahmet@example.com"},
+			{Field: "metadata.segment", Old: "retail", New: ""},
+		}, changes[1].Fields)
+
+		// The account is indexed under its new name
+		assert.Equal(t, 1, accountCache.Search(&models.AccountFilter{OwnerName: "berke", Limit: 10}).Total)
+		assert.Empty(t, accountCache.Diff())
+	})
+	t.Run("Unchanged", func(t *testing.T) {
+		updated, err := accountCache.UpdateProfile(account.AccountNumber, 0, account.Profile())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+	})
+	t.Run("VersionMismatch", func(t *testing.T) {
+		_, err := accountCache.UpdateProfile(account.AccountNumber, 1, &models.AccountProfile{OwnerName: "Someone Else"})
+		assert.True(t, errs.Is(err, errs.VersionMismatch))
+		assert.Equal(t, "Ahmet Berke", account.OwnerName)
+	})
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// AccrualCursor is the day a daily accrual job has accrued until, so that a day is never accrued twice
+// nor skipped, even across restarts when the cursor is kept in a file
+type AccrualCursor struct {
+	mu    sync.Mutex
+	until time.Time
+	// path is the file the cursor is written to, empty keeps it in memory only
+	path string
+}
+
+type accrualCursorState struct {
+	AccruedUntil time.Time `json:"accruedUntil"`
+}
+
+func NewAccrualCursor() *AccrualCursor {
+	return &AccrualCursor{
+		mu: sync.Mutex{},
+	}
+}
+
+// OpenAccrualCursor returns the cursor kept in the file at path, a missing file is a job that has not run yet
+func OpenAccrualCursor(path string) (*AccrualCursor, error) {
+	var state accrualCursorState
+	if err := readState(path, &state); err != nil {
+		return nil, err
+	}
+	return &AccrualCursor{mu: sync.Mutex{}, until: state.AccruedUntil, path: path}, nil
+}
+
+// Get returns the day the job accrued until, zero if it has not run yet
+func (ac *AccrualCursor) Get() time.Time {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.until
+}
+
+// Set moves the cursor to until, the cursor does not move if it cannot be written to its file
+func (ac *AccrualCursor) Set(until time.Time) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if err := writeState(ac.path, &accrualCursorState{AccruedUntil: until}); err != nil {
+		return err
+	}
+	ac.until = until
+	return nil
+}
+
+// readState reads the JSON state in the file at path into state, a missing file leaves state as it is
+func readState(path string, state interface{}) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, state); err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeState replaces the file at path with state as JSON, the file is either the old or the new state
+// even if writing fails halfway. An empty path writes nothing.
+func writeState(path string, state interface{}) error {
+	if path == "" {
+		return nil
+	}
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, content, 0o644); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("cannot write %s: %w", path, err)
+	}
+	return nil
+}
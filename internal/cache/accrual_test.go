@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAccrualCursor_Open(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "overdraft.json")
+	cursor, err := OpenAccrualCursor(path)
+	assert.NoError(t, err)
+	assert.True(t, cursor.Get().IsZero())
+
+	until := time.Date(2022, 3, 2, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, cursor.Set(until))
+	assert.Equal(t, until, cursor.Get())
+
+	reopened, err := OpenAccrualCursor(path)
+	assert.NoError(t, err)
+	assert.Equal(t, until, reopened.Get())
+
+	t.Run("Corrupt", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+		_, err := OpenAccrualCursor(path)
+		assert.Error(t, err)
+	})
+}
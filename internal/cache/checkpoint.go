@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+)
+
+type CheckpointCache struct {
+	mu          sync.Mutex
+	checkpoints map[types.AccountNumber][]*models.BalanceCheckpoint
+	// log is where the checkpoints are saved
+	log *recordLog
+}
+
+func NewCheckpointCache() *CheckpointCache {
+	cc, _ := NewCheckpointCacheFromEvents(nil)
+	return cc
+}
+
+// NewCheckpointCacheFromEvents returns the cache of the checkpoints saved to the store,
+// later checkpoints are saved to the same store
+func NewCheckpointCacheFromEvents(events *EventStore) (*CheckpointCache, error) {
+	cc := &CheckpointCache{
+		mu:          sync.Mutex{},
+		checkpoints: make(map[types.AccountNumber][]*models.BalanceCheckpoint),
+		log:         newRecordLog(events, types.CheckpointRecord),
+	}
+	err := replayRecords(cc.log, func(checkpoint *models.BalanceCheckpoint) {
+		cc.checkpoints[checkpoint.AccountNumber] = append(cc.checkpoints[checkpoint.AccountNumber], checkpoint)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// Add appends the checkpoint to the checkpoints of its account,
+// checkpoints have to be added in the order they were taken
+func (cc *CheckpointCache) Add(checkpoint *models.BalanceCheckpoint) {
+	// Locks with mutex to prevent errors from concurrent access
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.checkpoints[checkpoint.AccountNumber] = append(cc.checkpoints[checkpoint.AccountNumber], checkpoint)
+	cc.log.save(checkpoint)
+}
+
+// GetAll returns the checkpoints of the account in the order they were taken
+func (cc *CheckpointCache) GetAll(accountNumber types.AccountNumber) []*models.BalanceCheckpoint {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.checkpoints[accountNumber]
+}
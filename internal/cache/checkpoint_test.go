@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckpointCache_Add(t *testing.T) {
+	checkpointCache := NewCheckpointCache()
+	checkpointCache.Add(&models.BalanceCheckpoint{AccountNumber: 1, TransactionID: 1})
+	checkpointCache.Add(&models.BalanceCheckpoint{AccountNumber: 2, TransactionID: 2})
+	checkpointCache.Add(&models.BalanceCheckpoint{AccountNumber: 1, TransactionID: 3})
+
+	checkpoints := checkpointCache.GetAll(1)
+	assert.Equal(t, 2, len(checkpoints))
+	assert.Equal(t, types.TransactionID(3), checkpoints[1].TransactionID)
+	assert.Nil(t, checkpointCache.GetAll(3))
+}
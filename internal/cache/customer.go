@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+	"time"
+)
+
+type CustomerCache struct {
+	mu        sync.Mutex
+	lastID    types.CustomerID
+	customers map[types.CustomerID]*models.Customer
+	// log is where every created and changed customer is saved
+	log *recordLog
+}
+
+func NewCustomerCache() *CustomerCache {
+	cc, _ := NewCustomerCacheFromEvents(nil)
+	return cc
+}
+
+// NewCustomerCacheFromEvents returns the cache of the customers saved to the store, later changes are saved to the same store
+func NewCustomerCacheFromEvents(events *EventStore) (*CustomerCache, error) {
+	cc := &CustomerCache{
+		mu:        sync.Mutex{},
+		customers: make(map[types.CustomerID]*models.Customer),
+		log:       newRecordLog(events, types.CustomerRecord),
+	}
+	err := replayRecords(cc.log, func(customer *models.Customer) {
+		cc.customers[customer.ID] = customer
+		if customer.ID > cc.lastID {
+			cc.lastID = customer.ID
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+func (cc *CustomerCache) Create(customer *models.Customer) *models.Customer {
+	// Locks with mutex to prevent errors from concurrent access
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.lastID++
+	customer.ID = cc.lastID
+	customer.Wallets = make(map[types.Currency]types.AccountNumber)
+	customer.CreatedAt = time.Now()
+	cc.customers[customer.ID] = customer
+	cc.log.save(customer)
+	return copyCustomer(customer)
+}
+
+// Get returns a copy of the customer so that its wallets can be read while others are added
+func (cc *CustomerCache) Get(id types.CustomerID) (*models.Customer, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	customer, ok := cc.customers[id]
+	if !ok {
+		return nil, errs.New(errs.CustomerNotFound)
+	}
+	return copyCustomer(customer), nil
+}
+
+// AddWallet records the account as the wallet of the customer in its currency,
+// a customer has at most one wallet in each currency
+func (cc *CustomerCache) AddWallet(id types.CustomerID, currency types.Currency, accountNumber types.AccountNumber) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	customer, ok := cc.customers[id]
+	if !ok {
+		return errs.New(errs.CustomerNotFound)
+	}
+	if _, ok := customer.Wallets[currency]; ok {
+		return errs.Newf(errs.WalletAlreadyExists, "customer %d already has a %s wallet", id, currency)
+	}
+	customer.Wallets[currency] = accountNumber
+	cc.log.save(customer)
+	return nil
+}
+
+// SetKYC replaces the KYC data of the customer
+func (cc *CustomerCache) SetKYC(id types.CustomerID, kyc *models.KYC) (*models.Customer, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	customer, ok := cc.customers[id]
+	if !ok {
+		return nil, errs.New(errs.CustomerNotFound)
+	}
+	customer.KYC = *kyc
+	cc.log.save(customer)
+	return copyCustomer(customer), nil
+}
+
+func copyCustomer(customer *models.Customer) *models.Customer {
+	copied := *customer
+	copied.Wallets = make(map[types.Currency]types.AccountNumber, len(customer.Wallets))
+	for currency, accountNumber := range customer.Wallets {
+		copied.Wallets[currency] = accountNumber
+	}
+	return &copied
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCustomerCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		customerCache := NewCustomerCache()
+		customer := customerCache.Create(&models.Customer{Name: "Ada Lovelace", CustomerType: types.Individual})
+		assert.Equal(t, types.CustomerID(1), customer.ID)
+		assert.False(t, customer.CreatedAt.IsZero())
+
+		iCustomer, err := customerCache.Get(customer.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, customer, iCustomer)
+	})
+	t.Run("CustomerNotFound", func(t *testing.T) {
+		customerCache := NewCustomerCache()
+		_, err := customerCache.Get(1)
+		assert.True(t, errs.Is(err, errs.CustomerNotFound))
+	})
+}
+
+func TestCustomerCache_AddWallet(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		customerCache := NewCustomerCache()
+		customer := customerCache.Create(&models.Customer{Name: "Ada Lovelace", CustomerType: types.Individual})
+
+		assert.NoError(t, customerCache.AddWallet(customer.ID, types.TRY, 1))
+		assert.NoError(t, customerCache.AddWallet(customer.ID, types.USD, 2))
+
+		iCustomer, err := customerCache.Get(customer.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.AccountNumber{1, 2}, iCustomer.SortedWallets())
+		// the customer read before the wallets were added is a copy
+		assert.Equal(t, 0, len(customer.Wallets))
+	})
+	t.Run("WalletAlreadyExists", func(t *testing.T) {
+		customerCache := NewCustomerCache()
+		customer := customerCache.Create(&models.Customer{Name: "Ada Lovelace", CustomerType: types.Individual})
+
+		assert.NoError(t, customerCache.AddWallet(customer.ID, types.TRY, 1))
+		err := customerCache.AddWallet(customer.ID, types.TRY, 2)
+		assert.True(t, errs.Is(err, errs.WalletAlreadyExists))
+	})
+	t.Run("CustomerNotFound", func(t *testing.T) {
+		customerCache := NewCustomerCache()
+		err := customerCache.AddWallet(1, types.TRY, 1)
+		assert.True(t, errs.Is(err, errs.CustomerNotFound))
+	})
+}
+
+func TestCustomerCache_SetKYC(t *testing.T) {
+	customerCache := NewCustomerCache()
+	customer := customerCache.Create(&models.Customer{Name: "Ada Lovelace", CustomerType: types.Individual})
+
+	updated, err := customerCache.SetKYC(customer.ID, &models.KYC{Nationality: "GB", Status: types.KYCRejected})
+	assert.NoError(t, err)
+	assert.Equal(t, types.KYCRejected, updated.KYC.Status)
+	assert.Equal(t, "GB", updated.KYC.Nationality)
+
+	_, err = customerCache.SetKYC(2, &models.KYC{})
+	assert.True(t, errs.Is(err, errs.CustomerNotFound))
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// DisputeCache keeps the disputes indexed by their customer and their merchant
+type DisputeCache struct {
+	*store[types.DisputeID, *models.Dispute]
+}
+
+func NewDisputeCache() *DisputeCache {
+	dc, _ := NewDisputeCacheFromEvents(nil)
+	return dc
+}
+
+// NewDisputeCacheFromEvents returns the cache of the disputes saved to the store, later changes are saved to the same store
+func NewDisputeCacheFromEvents(events *EventStore) (*DisputeCache, error) {
+	dc := &DisputeCache{
+		store: newStore(errs.DisputeNotFound,
+			func(dispute *models.Dispute) *types.DisputeID { return &dispute.ID },
+			func(dispute *models.Dispute) []types.AccountNumber {
+				return []types.AccountNumber{dispute.CustomerAccount, dispute.MerchantAccount}
+			},
+		),
+	}
+	if err := dc.restore(newRecordLog(events, types.DisputeRecord)); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// GetOpen returns every dispute that is not resolved yet, ordered by id
+func (dc *DisputeCache) GetOpen() []*models.Dispute {
+	return dc.filter(func(dispute *models.Dispute) bool {
+		return dispute.Status == types.DisputeOpen
+	})
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDisputeCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		disputeCache := NewDisputeCache()
+		dispute := disputeCache.Create(&models.Dispute{
+			PaymentID:       1,
+			CustomerAccount: 1,
+			MerchantAccount: 2,
+			Amount:          decimal.NewFromFloat(10),
+			Status:          types.DisputeOpen,
+		})
+		assert.Equal(t, types.DisputeID(1), dispute.ID)
+
+		iDispute, err := disputeCache.Get(dispute.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, dispute, iDispute)
+	})
+	t.Run("DisputeNotFound", func(t *testing.T) {
+		disputeCache := NewDisputeCache()
+		_, err := disputeCache.Get(1)
+		assert.True(t, errs.Is(err, errs.DisputeNotFound))
+	})
+}
+
+func TestDisputeCache_GetAll(t *testing.T) {
+	disputeCache := NewDisputeCache()
+	disputeCache.Create(&models.Dispute{CustomerAccount: 1, MerchantAccount: 2, Status: types.DisputeOpen})
+	disputeCache.Create(&models.Dispute{CustomerAccount: 3, MerchantAccount: 1, Status: types.DisputeChargedBack})
+	disputeCache.Create(&models.Dispute{CustomerAccount: 3, MerchantAccount: 2, Status: types.DisputeReleased})
+
+	assert.Equal(t, 2, len(disputeCache.GetAll(1)))
+	assert.Equal(t, 1, len(disputeCache.GetOpen()))
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// EscrowCache keeps the escrows indexed by their sender and their receiver
+type EscrowCache struct {
+	*store[types.EscrowID, *models.Escrow]
+}
+
+func NewEscrowCache() *EscrowCache {
+	ec, _ := NewEscrowCacheFromEvents(nil)
+	return ec
+}
+
+// NewEscrowCacheFromEvents returns the cache of the escrows saved to the store, later changes are saved to the same store
+func NewEscrowCacheFromEvents(events *EventStore) (*EscrowCache, error) {
+	ec := &EscrowCache{
+		store: newStore(errs.EscrowNotFound,
+			func(escrow *models.Escrow) *types.EscrowID { return &escrow.ID },
+			func(escrow *models.Escrow) []types.AccountNumber {
+				return []types.AccountNumber{escrow.SenderAccount, escrow.ReceiverAccount}
+			},
+		),
+	}
+	if err := ec.restore(newRecordLog(events, types.EscrowRecord)); err != nil {
+		return nil, err
+	}
+	return ec, nil
+}
+
+// GetFunded returns every escrow that is not settled yet, ordered by id
+func (ec *EscrowCache) GetFunded() []*models.Escrow {
+	return ec.filter(func(escrow *models.Escrow) bool {
+		return escrow.Status == types.EscrowFunded
+	})
+}
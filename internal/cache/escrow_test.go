@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEscrowCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		escrowCache := NewEscrowCache()
+		escrow := escrowCache.Create(&models.Escrow{
+			SenderAccount:   1,
+			ReceiverAccount: 2,
+			Amount:          decimal.NewFromFloat(10),
+			Status:          types.EscrowFunded,
+		})
+		assert.Equal(t, types.EscrowID(1), escrow.ID)
+
+		iEscrow, err := escrowCache.Get(escrow.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, escrow, iEscrow)
+	})
+	t.Run("EscrowNotFound", func(t *testing.T) {
+		escrowCache := NewEscrowCache()
+		_, err := escrowCache.Get(1)
+		assert.True(t, errs.Is(err, errs.EscrowNotFound))
+	})
+}
+
+func TestEscrowCache_GetAll(t *testing.T) {
+	escrowCache := NewEscrowCache()
+	escrowCache.Create(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Status: types.EscrowFunded})
+	escrowCache.Create(&models.Escrow{SenderAccount: 3, ReceiverAccount: 1, Status: types.EscrowReleased})
+	escrowCache.Create(&models.Escrow{SenderAccount: 3, ReceiverAccount: 2, Status: types.EscrowRefunded})
+
+	assert.Equal(t, 2, len(escrowCache.GetAll(1)))
+	assert.Equal(t, 1, len(escrowCache.GetFunded()))
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventStore is the append-only stream of account events and of the records saved by the other caches,
+// events are never changed once appended
+type EventStore struct {
+	mu           sync.Mutex
+	lastSequence types.EventSequence
+	events       []*models.Event
+	// file is the event log every appended event is written to, nil keeps the events in memory only
+	file *os.File
+	// err is the first error writing to the file, the store stops writing and reports it from then on
+	err error
+}
+
+func NewEventStore() *EventStore {
+	return &EventStore{
+		mu: sync.Mutex{},
+	}
+}
+
+// OpenEventStore returns the store of the event log at path, one JSON event per line.
+// The events already in the log are read back and appended events are written to its end,
+// a missing log is created.
+func OpenEventStore(path string) (*EventStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	es := &EventStore{mu: sync.Mutex{}, file: file}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event models.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("cannot read %s: event after %d: %w", path, es.lastSequence, err)
+		}
+		if event.Sequence != es.lastSequence+1 {
+			file.Close()
+			return nil, fmt.Errorf("cannot read %s: event %d follows %d", path, event.Sequence, es.lastSequence)
+		}
+		es.lastSequence = event.Sequence
+		es.events = append(es.events, &event)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return es, nil
+}
+
+// Append numbers and timestamps the events and adds them to the end of the stream
+func (es *EventStore) Append(events ...*models.Event) {
+	now := time.Now()
+
+	// Locks with mutex to prevent errors from concurrent access
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	for _, event := range events {
+		es.lastSequence++
+		event.Sequence = es.lastSequence
+		event.At = now
+		es.events = append(es.events, event)
+	}
+	es.write(events)
+}
+
+// write adds the events to the end of the log, es.mu has to be held
+func (es *EventStore) write(events []*models.Event) {
+	if es.file == nil || es.err != nil {
+		return
+	}
+	var lines []byte
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			es.err = err
+			return
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if _, err := es.file.Write(lines); err != nil {
+		es.err = fmt.Errorf("cannot write the event log: %w", err)
+	}
+}
+
+// GetAll returns the events appended after the sequence in order, zero returns the whole stream
+func (es *EventStore) GetAll(after types.EventSequence) []*models.Event {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if int(after) >= len(es.events) {
+		return nil
+	}
+	events := make([]*models.Event, len(es.events)-int(after))
+	copy(events, es.events[after:])
+	return events
+}
+
+// fail stops writing to the log as if the error happened writing to it
+func (es *EventStore) fail(err error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if es.err == nil {
+		es.err = err
+	}
+}
+
+// Ready reports whether appended events are still written to the log
+func (es *EventStore) Ready() error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return es.err
+}
+
+// Flush commits the events written to the log to disk,
+// it fails if any event could not be written
+func (es *EventStore) Flush() error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if es.file == nil || es.err != nil {
+		return es.err
+	}
+	if err := es.file.Sync(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return err
+	}
+	return nil
+}
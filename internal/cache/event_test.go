@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEventStore_Append(t *testing.T) {
+	eventStore := NewEventStore()
+	eventStore.Append(&models.Event{Type: types.AccountOpened, AccountNumber: 1})
+	eventStore.Append(&models.Event{Type: types.Deposited, AccountNumber: 1}, &models.Event{Type: types.Withdrawn, AccountNumber: 1})
+
+	events := eventStore.GetAll(0)
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, types.EventSequence(3), events[2].Sequence)
+	assert.False(t, events[0].At.IsZero())
+
+	events = eventStore.GetAll(1)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, types.Deposited, events[0].Type)
+	assert.Nil(t, eventStore.GetAll(3))
+}
+
+func TestEventStore_Open(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	eventStore, err := OpenEventStore(path)
+	assert.NoError(t, err)
+	eventStore.Append(&models.Event{Type: types.AccountOpened, AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ada Lovelace"})
+	eventStore.Append(&models.Event{Type: types.Deposited, AccountNumber: 1, Amount: decimal.NewFromFloat(10.25)})
+	assert.NoError(t, eventStore.Flush())
+
+	// The events are read back from the log and new ones continue its sequence
+	reopened, err := OpenEventStore(path)
+	assert.NoError(t, err)
+	events := reopened.GetAll(0)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "Ada Lovelace", events[0].OwnerName)
+	assert.True(t, decimal.NewFromFloat(10.25).Equal(events[1].Amount))
+
+	reopened.Append(&models.Event{Type: types.Withdrawn, AccountNumber: 1})
+	assert.Equal(t, types.EventSequence(3), reopened.GetAll(2)[0].Sequence)
+
+	// A projection of the reopened store has the account as it was
+	accountCache := NewAccountCacheFromEvents(reopened, nil)
+	account, err := accountCache.Get(1)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(10.25).Equal(account.Balance))
+
+	t.Run("Corrupt", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		assert.NoError(t, os.WriteFile(path, []byte("{\"Sequence\":2}\n"), 0o644))
+		_, err := OpenEventStore(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+)
+
+// FraudCache keeps the alerts raised by the fraud rules
+type FraudCache struct {
+	mu     sync.Mutex
+	lastID types.FraudAlertID
+	alerts map[types.FraudAlertID]*models.FraudAlert
+	// log is where the alerts are saved
+	log *recordLog
+}
+
+func NewFraudCache() *FraudCache {
+	fc, _ := NewFraudCacheFromEvents(nil)
+	return fc
+}
+
+// NewFraudCacheFromEvents returns the cache of the alerts saved to the store, later changes are saved to the same store
+func NewFraudCacheFromEvents(events *EventStore) (*FraudCache, error) {
+	fc := &FraudCache{
+		mu:     sync.Mutex{},
+		alerts: make(map[types.FraudAlertID]*models.FraudAlert),
+		log:    newRecordLog(events, types.FraudAlertRecord),
+	}
+	err := replayRecords(fc.log, func(alert *models.FraudAlert) {
+		fc.alerts[alert.ID] = alert
+		if alert.ID > fc.lastID {
+			fc.lastID = alert.ID
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+func (fc *FraudCache) Create(alert *models.FraudAlert) *models.FraudAlert {
+	// Locks with mutex to prevent errors from concurrent access
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.lastID++
+	alert.ID = fc.lastID
+	fc.alerts[alert.ID] = alert
+	fc.log.save(alert)
+	return alert
+}
+
+// Save records the changes made to an alert handed out by the cache
+func (fc *FraudCache) Save(alert *models.FraudAlert) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.log.save(alert)
+}
+
+func (fc *FraudCache) Get(id types.FraudAlertID) (*models.FraudAlert, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	alert, ok := fc.alerts[id]
+	if !ok {
+		return nil, errs.New(errs.FraudAlertNotFound)
+	}
+	return alert, nil
+}
+
+// GetByStatus returns the alerts in the status, or every alert for an empty status, ordered by id
+func (fc *FraudCache) GetByStatus(status types.FraudAlertStatus) []*models.FraudAlert {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	var alerts []*models.FraudAlert
+	for id := types.FraudAlertID(1); id <= fc.lastID; id++ {
+		alert, ok := fc.alerts[id]
+		if ok && (status == "" || alert.Status == status) {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
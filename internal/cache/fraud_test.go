@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFraudCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		fraudCache := NewFraudCache()
+		alert := fraudCache.Create(&models.FraudAlert{
+			AccountNumber:   1,
+			TransactionType: types.Withdraw,
+			Amount:          decimal.NewFromInt(10),
+			Action:          types.FraudReview,
+			Status:          types.FraudAlertPending,
+		})
+		assert.Equal(t, types.FraudAlertID(1), alert.ID)
+
+		iAlert, err := fraudCache.Get(alert.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, alert, iAlert)
+	})
+	t.Run("FraudAlertNotFound", func(t *testing.T) {
+		fraudCache := NewFraudCache()
+		_, err := fraudCache.Get(1)
+		assert.True(t, errs.Is(err, errs.FraudAlertNotFound))
+	})
+}
+
+func TestFraudCache_GetByStatus(t *testing.T) {
+	fraudCache := NewFraudCache()
+	fraudCache.Create(&models.FraudAlert{AccountNumber: 1, Status: types.FraudAlertPending})
+	fraudCache.Create(&models.FraudAlert{AccountNumber: 1, Status: types.FraudAlertBlocked})
+	fraudCache.Create(&models.FraudAlert{AccountNumber: 2, Status: types.FraudAlertPending})
+
+	alerts := fraudCache.GetByStatus(types.FraudAlertPending)
+	assert.Equal(t, 2, len(alerts))
+	assert.Equal(t, types.FraudAlertID(3), alerts[1].ID)
+	assert.Equal(t, 3, len(fraudCache.GetByStatus("")))
+}
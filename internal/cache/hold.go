@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// HoldCache keeps the holds indexed by the account they are placed on
+type HoldCache struct {
+	*store[types.HoldID, *models.Hold]
+}
+
+func NewHoldCache() *HoldCache {
+	hc, _ := NewHoldCacheFromEvents(nil)
+	return hc
+}
+
+// NewHoldCacheFromEvents returns the cache of the holds saved to the store, later changes are saved to the same store
+func NewHoldCacheFromEvents(events *EventStore) (*HoldCache, error) {
+	hc := &HoldCache{
+		store: newStore(errs.HoldNotFound,
+			func(hold *models.Hold) *types.HoldID { return &hold.ID },
+			func(hold *models.Hold) []types.AccountNumber { return []types.AccountNumber{hold.AccountNumber} },
+		),
+	}
+	if err := hc.restore(newRecordLog(events, types.HoldRecord)); err != nil {
+		return nil, err
+	}
+	return hc, nil
+}
+
+// GetActive returns every active hold, ordered by id
+func (hc *HoldCache) GetActive() []*models.Hold {
+	return hc.filter(func(hold *models.Hold) bool {
+		return hold.Status == types.HoldActive
+	})
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHoldCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		holdCache := NewHoldCache()
+		hold := holdCache.Create(&models.Hold{
+			AccountNumber: 1,
+			Amount:        decimal.NewFromFloat(10),
+			Status:        types.HoldActive,
+		})
+		assert.Equal(t, types.HoldID(1), hold.ID)
+
+		iHold, err := holdCache.Get(hold.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, hold, iHold)
+	})
+	t.Run("HoldNotFound", func(t *testing.T) {
+		holdCache := NewHoldCache()
+		_, err := holdCache.Get(1)
+		assert.True(t, errs.Is(err, errs.HoldNotFound))
+	})
+}
+
+func TestHoldCache_GetAll(t *testing.T) {
+	holdCache := NewHoldCache()
+	holdCache.Create(&models.Hold{AccountNumber: 1, Status: types.HoldActive})
+	holdCache.Create(&models.Hold{AccountNumber: 2, Status: types.HoldActive})
+	holdCache.Create(&models.Hold{AccountNumber: 1, Status: types.HoldVoided})
+
+	assert.Equal(t, 2, len(holdCache.GetAll(1)))
+	assert.Equal(t, 2, len(holdCache.GetActive()))
+}
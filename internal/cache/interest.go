@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InterestCache keeps the interest the accounts accrued together with the day it was accrued until,
+// both are saved at once so that they never disagree, even across restarts when they are kept in a file
+type InterestCache struct {
+	mu           sync.Mutex
+	accruals     map[types.AccountNumber]*models.InterestAccrual
+	accruedUntil time.Time
+	// path is the file the accruals are written to, empty keeps them in memory only
+	path string
+}
+
+type interestState struct {
+	AccruedUntil time.Time                 `json:"accruedUntil"`
+	Accruals     []*models.InterestAccrual `json:"accruals"`
+}
+
+func NewInterestCache() *InterestCache {
+	return &InterestCache{
+		mu:       sync.Mutex{},
+		accruals: make(map[types.AccountNumber]*models.InterestAccrual),
+	}
+}
+
+// OpenInterestCache returns the accruals kept in the file at path, a missing file is a cache that has nothing accrued yet
+func OpenInterestCache(path string) (*InterestCache, error) {
+	var state interestState
+	if err := readState(path, &state); err != nil {
+		return nil, err
+	}
+	ic := NewInterestCache()
+	ic.path = path
+	ic.accruedUntil = state.AccruedUntil
+	for _, accrual := range state.Accruals {
+		ic.accruals[accrual.AccountNumber] = accrual
+	}
+	return ic, nil
+}
+
+// Get returns a copy of the accrual of the account, an account without one starts with nothing accrued
+func (ic *InterestCache) Get(accountNumber types.AccountNumber) *models.InterestAccrual {
+	// Locks with mutex to prevent errors from concurrent access
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	accrual, ok := ic.accruals[accountNumber]
+	if !ok {
+		return &models.InterestAccrual{
+			AccountNumber: accountNumber,
+			Accrued:       decimal.Zero,
+			Posted:        decimal.Zero,
+		}
+	}
+	copied := *accrual
+	return &copied
+}
+
+// AccruedUntil returns the day the accruals were accrued until, zero if nothing was accrued yet
+func (ic *InterestCache) AccruedUntil() time.Time {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	return ic.accruedUntil
+}
+
+// Save replaces the accruals of the accounts and the day they were accrued until,
+// nothing changes if they cannot be written to the file
+func (ic *InterestCache) Save(accruals []*models.InterestAccrual, accruedUntil time.Time) error {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	saved := make(map[types.AccountNumber]*models.InterestAccrual, len(ic.accruals))
+	for accountNumber, accrual := range ic.accruals {
+		saved[accountNumber] = accrual
+	}
+	for _, accrual := range accruals {
+		copied := *accrual
+		copied.Rate = nil
+		copied.AccruedUntil = time.Time{}
+		saved[accrual.AccountNumber] = &copied
+	}
+
+	state := &interestState{AccruedUntil: accruedUntil}
+	for _, accrual := range saved {
+		state.Accruals = append(state.Accruals, accrual)
+	}
+	sort.Slice(state.Accruals, func(i, j int) bool {
+		return state.Accruals[i].AccountNumber < state.Accruals[j].AccountNumber
+	})
+	if err := writeState(ic.path, state); err != nil {
+		return err
+	}
+	ic.accruals = saved
+	ic.accruedUntil = accruedUntil
+	return nil
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestInterestCache_Save(t *testing.T) {
+	interestCache := NewInterestCache()
+
+	accrual := interestCache.Get(1)
+	assert.Equal(t, types.AccountNumber(1), accrual.AccountNumber)
+	assert.True(t, accrual.Accrued.IsZero())
+	assert.True(t, interestCache.AccruedUntil().IsZero())
+
+	// The accrual handed out is a copy until it is saved
+	accrual.Accrued = decimal.NewFromFloat(0.5)
+	assert.True(t, interestCache.Get(1).Accrued.IsZero())
+
+	until := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, interestCache.Save([]*models.InterestAccrual{accrual}, until))
+	assert.True(t, interestCache.Get(1).Accrued.Equal(decimal.NewFromFloat(0.5)))
+	assert.Equal(t, until, interestCache.AccruedUntil())
+}
+
+func TestInterestCache_Open(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "interest.json")
+	interestCache, err := OpenInterestCache(path)
+	assert.NoError(t, err)
+	assert.True(t, interestCache.AccruedUntil().IsZero())
+
+	until := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	err = interestCache.Save([]*models.InterestAccrual{
+		{AccountNumber: 1, Accrued: decimal.RequireFromString("0.123456"), Posted: decimal.NewFromInt(20), LastPostedAt: until},
+	}, until)
+	assert.NoError(t, err)
+
+	reopened, err := OpenInterestCache(path)
+	assert.NoError(t, err)
+	assert.Equal(t, until, reopened.AccruedUntil())
+	accrual := reopened.Get(1)
+	assert.True(t, accrual.Accrued.Equal(decimal.RequireFromString("0.123456")))
+	assert.True(t, accrual.Posted.Equal(decimal.NewFromInt(20)))
+	assert.Equal(t, until, accrual.LastPostedAt)
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// InvoiceCache keeps the invoices indexed by their issuer and their payer
+type InvoiceCache struct {
+	*store[types.InvoiceID, *models.Invoice]
+}
+
+func NewInvoiceCache() *InvoiceCache {
+	ic, _ := NewInvoiceCacheFromEvents(nil)
+	return ic
+}
+
+// NewInvoiceCacheFromEvents returns the cache of the invoices saved to the store, later changes are saved to the same store
+func NewInvoiceCacheFromEvents(events *EventStore) (*InvoiceCache, error) {
+	ic := &InvoiceCache{
+		store: newStore(errs.InvoiceNotFound,
+			func(invoice *models.Invoice) *types.InvoiceID { return &invoice.ID },
+			func(invoice *models.Invoice) []types.AccountNumber {
+				return []types.AccountNumber{invoice.IssuerAccount, invoice.PayerAccount}
+			},
+		),
+	}
+	if err := ic.restore(newRecordLog(events, types.InvoiceRecord)); err != nil {
+		return nil, err
+	}
+	return ic, nil
+}
+
+// GetOpen returns every open invoice, ordered by id
+func (ic *InvoiceCache) GetOpen() []*models.Invoice {
+	return ic.filter(func(invoice *models.Invoice) bool {
+		return invoice.Status == types.InvoiceOpen
+	})
+}
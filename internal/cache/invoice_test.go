@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInvoiceCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		invoiceCache := NewInvoiceCache()
+		invoice := invoiceCache.Create(&models.Invoice{
+			IssuerAccount: 2,
+			PayerAccount:  1,
+			Amount:        decimal.NewFromFloat(10),
+			Status:        types.InvoiceOpen,
+		})
+		assert.Equal(t, types.InvoiceID(1), invoice.ID)
+
+		iInvoice, err := invoiceCache.Get(invoice.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, invoice, iInvoice)
+	})
+	t.Run("InvoiceNotFound", func(t *testing.T) {
+		invoiceCache := NewInvoiceCache()
+		_, err := invoiceCache.Get(1)
+		assert.True(t, errs.Is(err, errs.InvoiceNotFound))
+	})
+}
+
+func TestInvoiceCache_GetAll(t *testing.T) {
+	invoiceCache := NewInvoiceCache()
+	invoiceCache.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Status: types.InvoiceOpen})
+	invoiceCache.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 3, Status: types.InvoicePaid})
+	invoiceCache.Create(&models.Invoice{IssuerAccount: 4, PayerAccount: 1, Status: types.InvoiceCancelled})
+
+	assert.Equal(t, 2, len(invoiceCache.GetAll(1)))
+	assert.Equal(t, 2, len(invoiceCache.GetAll(2)))
+	assert.Equal(t, 1, len(invoiceCache.GetOpen()))
+}
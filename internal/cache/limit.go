@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+)
+
+type limitKey struct {
+	transactionType types.TransactionType
+	accountType     types.AccountType
+	currency        types.Currency
+}
+
+type overrideKey struct {
+	accountNumber   types.AccountNumber
+	transactionType types.TransactionType
+}
+
+type LimitCache struct {
+	mu        sync.RWMutex
+	rules     map[limitKey]*models.Limit
+	overrides map[overrideKey]*models.Limit
+	// log is where the limits set after the configuration was read are saved
+	log *recordLog
+}
+
+// NewLimitCache takes the limits read from the configuration,
+// limits with an account number are stored as overrides of that account
+func NewLimitCache(limits []*models.Limit) *LimitCache {
+	lc, _ := NewLimitCacheFromEvents(limits, nil)
+	return lc
+}
+
+// NewLimitCacheFromEvents takes the limits read from the configuration like NewLimitCache
+// and replaces them by the limits saved to the store, later limits are saved to the same store
+func NewLimitCacheFromEvents(limits []*models.Limit, events *EventStore) (*LimitCache, error) {
+	lc := &LimitCache{
+		mu:        sync.RWMutex{},
+		rules:     make(map[limitKey]*models.Limit),
+		overrides: make(map[overrideKey]*models.Limit),
+		log:       newRecordLog(events, types.LimitRecord),
+	}
+	for _, limit := range limits {
+		lc.set(limit)
+	}
+	if err := replayRecords(lc.log, lc.set); err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// Find returns the limit of the account, an override of the account has priority
+// over the rule of its account type and currency. It returns nil if nothing is configured.
+func (lc *LimitCache) Find(account *models.Account, transactionType types.TransactionType) *models.Limit {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	if limit, ok := lc.overrides[overrideKey{account.AccountNumber, transactionType}]; ok {
+		return limit
+	}
+	return lc.rules[limitKey{transactionType, account.AccountType, account.CurrencyCode}]
+}
+
+// Set stores the limit as an override if it has an account number
+// and as a rule of its account type and currency otherwise
+func (lc *LimitCache) Set(limit *models.Limit) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.set(limit)
+	lc.log.save(limit)
+}
+
+func (lc *LimitCache) set(limit *models.Limit) {
+	if limit.AccountNumber != 0 {
+		lc.overrides[overrideKey{limit.AccountNumber, limit.TransactionType}] = limit
+		return
+	}
+	lc.rules[limitKey{limit.TransactionType, limit.AccountType, limit.Currency}] = limit
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLimitCache_Find(t *testing.T) {
+	account := &models.Account{
+		AccountNumber: 1,
+		CurrencyCode:  types.TRY,
+		AccountType:   types.Individual,
+	}
+
+	t.Run("Rule", func(t *testing.T) {
+		limitCache := NewLimitCache([]*models.Limit{
+			{
+				TransactionType: types.Withdraw,
+				AccountType:     types.Individual,
+				Currency:        types.TRY,
+				PerTransaction:  decimal.NewFromFloat(100),
+			},
+		})
+		limit := limitCache.Find(account, types.Withdraw)
+		assert.NotNil(t, limit)
+		assert.True(t, limit.PerTransaction.Equal(decimal.NewFromFloat(100)))
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		limitCache := NewLimitCache([]*models.Limit{
+			{
+				TransactionType: types.Withdraw,
+				AccountType:     types.Individual,
+				Currency:        types.TRY,
+				PerTransaction:  decimal.NewFromFloat(100),
+			},
+		})
+		limitCache.Set(&models.Limit{
+			AccountNumber:   1,
+			TransactionType: types.Withdraw,
+			PerTransaction:  decimal.NewFromFloat(500),
+		})
+		limit := limitCache.Find(account, types.Withdraw)
+		assert.True(t, limit.PerTransaction.Equal(decimal.NewFromFloat(500)))
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		limitCache := NewLimitCache(nil)
+		assert.Nil(t, limitCache.Find(account, types.Deposit))
+	})
+}
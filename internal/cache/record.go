@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// recordLog saves the records of a cache to the event store as RecordSaved events,
+// so the cache can be filled again from the store after a restart.
+// A nil log keeps the records in memory only.
+type recordLog struct {
+	store *EventStore
+	kind  types.RecordKind
+}
+
+func newRecordLog(store *EventStore, kind types.RecordKind) *recordLog {
+	if store == nil {
+		return nil
+	}
+	return &recordLog{store: store, kind: kind}
+}
+
+// save appends the record as it is now to the store, the latest saved version of a record is the one replayed
+func (l *recordLog) save(record interface{}) {
+	if l == nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		l.store.fail(fmt.Errorf("cannot save a %s record: %w", l.kind, err))
+		return
+	}
+	l.store.Append(&models.Event{Type: types.RecordSaved, Record: l.kind, Data: data})
+}
+
+// replayRecords calls load with every record of the kind of the log in the order they were saved
+func replayRecords[R any](l *recordLog, load func(record R)) error {
+	if l == nil {
+		return nil
+	}
+	for _, event := range l.store.GetAll(0) {
+		if event.Type != types.RecordSaved || event.Record != l.kind {
+			continue
+		}
+		var record R
+		if err := json.Unmarshal(event.Data, &record); err != nil {
+			return fmt.Errorf("cannot read the %s record of event %d: %w", l.kind, event.Sequence, err)
+		}
+		load(record)
+	}
+	return nil
+}
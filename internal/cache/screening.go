@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+)
+
+// ScreeningCache keeps the screening cases and the audit trail of the decisions made on them
+type ScreeningCache struct {
+	mu        sync.Mutex
+	lastID    types.ScreeningCaseID
+	cases     map[types.ScreeningCaseID]*models.ScreeningCase
+	decisions []*models.ScreeningDecision
+	// caseLog and decisionLog are where the cases and the decisions are saved
+	caseLog     *recordLog
+	decisionLog *recordLog
+}
+
+func NewScreeningCache() *ScreeningCache {
+	sc, _ := NewScreeningCacheFromEvents(nil)
+	return sc
+}
+
+// NewScreeningCacheFromEvents returns the cache of the cases and the decisions saved to the store,
+// later changes are saved to the same store
+func NewScreeningCacheFromEvents(events *EventStore) (*ScreeningCache, error) {
+	sc := &ScreeningCache{
+		mu:          sync.Mutex{},
+		cases:       make(map[types.ScreeningCaseID]*models.ScreeningCase),
+		caseLog:     newRecordLog(events, types.ScreeningCaseRecord),
+		decisionLog: newRecordLog(events, types.ScreeningDecisionRecord),
+	}
+	err := replayRecords(sc.caseLog, func(screeningCase *models.ScreeningCase) {
+		sc.cases[screeningCase.ID] = screeningCase
+		if screeningCase.ID > sc.lastID {
+			sc.lastID = screeningCase.ID
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = replayRecords(sc.decisionLog, func(decision *models.ScreeningDecision) {
+		sc.decisions = append(sc.decisions, decision)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+func (sc *ScreeningCache) Create(screeningCase *models.ScreeningCase) *models.ScreeningCase {
+	// Locks with mutex to prevent errors from concurrent access
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.lastID++
+	screeningCase.ID = sc.lastID
+	sc.cases[screeningCase.ID] = screeningCase
+	sc.caseLog.save(screeningCase)
+	return screeningCase
+}
+
+// Save records the changes made to a case handed out by the cache
+func (sc *ScreeningCache) Save(screeningCase *models.ScreeningCase) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.caseLog.save(screeningCase)
+}
+
+func (sc *ScreeningCache) Get(id types.ScreeningCaseID) (*models.ScreeningCase, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	screeningCase, ok := sc.cases[id]
+	if !ok {
+		return nil, errs.New(errs.ScreeningCaseNotFound)
+	}
+	return screeningCase, nil
+}
+
+// GetByStatus returns the cases in the status, or every case for an empty status, ordered by id
+func (sc *ScreeningCache) GetByStatus(status types.ScreeningStatus) []*models.ScreeningCase {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	var cases []*models.ScreeningCase
+	for id := types.ScreeningCaseID(1); id <= sc.lastID; id++ {
+		screeningCase, ok := sc.cases[id]
+		if ok && (status == "" || screeningCase.Status == status) {
+			cases = append(cases, screeningCase)
+		}
+	}
+	return cases
+}
+
+// AddDecision appends the decision to the audit trail
+func (sc *ScreeningCache) AddDecision(decision *models.ScreeningDecision) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.decisions = append(sc.decisions, decision)
+	sc.decisionLog.save(decision)
+}
+
+// GetDecisions returns the audit trail of the decisions in the order they were made
+func (sc *ScreeningCache) GetDecisions() []*models.ScreeningDecision {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	decisions := make([]*models.ScreeningDecision, len(sc.decisions))
+	copy(decisions, sc.decisions)
+	return decisions
+}
+
+// LastDecision returns the latest decision made on the account and the entity, or nil if none was made
+func (sc *ScreeningCache) LastDecision(accountNumber types.AccountNumber, entityID string) *models.ScreeningDecision {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for i := len(sc.decisions) - 1; i >= 0; i-- {
+		decision := sc.decisions[i]
+		if decision.AccountNumber == accountNumber && decision.EntityID == entityID {
+			return decision
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScreeningCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		screeningCache := NewScreeningCache()
+		screeningCase := screeningCache.Create(&models.ScreeningCase{
+			AccountNumber: 1,
+			Name:          "Ahmet Berke",
+			Hit:           &models.ScreeningHit{EntityID: "101", Score: 1},
+			Status:        types.ScreeningPending,
+		})
+		assert.Equal(t, types.ScreeningCaseID(1), screeningCase.ID)
+
+		iScreeningCase, err := screeningCache.Get(screeningCase.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, screeningCase, iScreeningCase)
+	})
+	t.Run("ScreeningCaseNotFound", func(t *testing.T) {
+		screeningCache := NewScreeningCache()
+		_, err := screeningCache.Get(1)
+		assert.True(t, errs.Is(err, errs.ScreeningCaseNotFound))
+	})
+}
+
+func TestScreeningCache_GetByStatus(t *testing.T) {
+	screeningCache := NewScreeningCache()
+	screeningCache.Create(&models.ScreeningCase{AccountNumber: 1, Status: types.ScreeningPending})
+	screeningCache.Create(&models.ScreeningCase{AccountNumber: 2, Status: types.ScreeningCleared})
+	screeningCache.Create(&models.ScreeningCase{AccountNumber: 3, Status: types.ScreeningPending})
+
+	assert.Equal(t, 2, len(screeningCache.GetByStatus(types.ScreeningPending)))
+	assert.Equal(t, 3, len(screeningCache.GetByStatus("")))
+}
+
+func TestScreeningCache_LastDecision(t *testing.T) {
+	screeningCache := NewScreeningCache()
+	screeningCache.AddDecision(&models.ScreeningDecision{CaseID: 1, AccountNumber: 1, EntityID: "101", Status: types.ScreeningCleared})
+	screeningCache.AddDecision(&models.ScreeningDecision{CaseID: 2, AccountNumber: 2, EntityID: "101", Status: types.ScreeningConfirmed})
+	screeningCache.AddDecision(&models.ScreeningDecision{CaseID: 3, AccountNumber: 1, EntityID: "101", Status: types.ScreeningConfirmed})
+
+	decision := screeningCache.LastDecision(1, "101")
+	assert.Equal(t, types.ScreeningCaseID(3), decision.CaseID)
+	assert.Nil(t, screeningCache.LastDecision(1, "202"))
+	assert.Equal(t, 3, len(screeningCache.GetDecisions()))
+}
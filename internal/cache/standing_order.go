@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// StandingOrderCache keeps the standing orders indexed by the account they pay from
+type StandingOrderCache struct {
+	*store[types.StandingOrderID, *models.StandingOrder]
+}
+
+func NewStandingOrderCache() *StandingOrderCache {
+	sc, _ := NewStandingOrderCacheFromEvents(nil)
+	return sc
+}
+
+// NewStandingOrderCacheFromEvents returns the cache of the standing orders saved to the store, later changes are saved to the same store
+func NewStandingOrderCacheFromEvents(events *EventStore) (*StandingOrderCache, error) {
+	sc := &StandingOrderCache{
+		store: newStore(errs.StandingOrderNotFound,
+			func(order *models.StandingOrder) *types.StandingOrderID { return &order.ID },
+			func(order *models.StandingOrder) []types.AccountNumber {
+				return []types.AccountNumber{order.Payment.SenderAccount}
+			},
+		),
+	}
+	if err := sc.restore(newRecordLog(events, types.StandingOrderRecord)); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// GetActive returns every active standing order, ordered by id
+func (sc *StandingOrderCache) GetActive() []*models.StandingOrder {
+	return sc.filter(func(order *models.StandingOrder) bool {
+		return order.Status == types.StandingOrderActive
+	})
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStandingOrderCache_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		standingOrderCache := NewStandingOrderCache()
+		order := standingOrderCache.Create(&models.StandingOrder{
+			Payment: &models.Payment{SenderAccount: 1, ReceiverAccount: 2},
+			Status:  types.StandingOrderActive,
+		})
+		assert.Equal(t, types.StandingOrderID(1), order.ID)
+
+		iOrder, err := standingOrderCache.Get(order.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, order, iOrder)
+	})
+	t.Run("StandingOrderNotFound", func(t *testing.T) {
+		standingOrderCache := NewStandingOrderCache()
+		_, err := standingOrderCache.Get(1)
+		assert.True(t, errs.Is(err, errs.StandingOrderNotFound))
+	})
+}
+
+func TestStandingOrderCache_GetAll(t *testing.T) {
+	standingOrderCache := NewStandingOrderCache()
+	standingOrderCache.Create(&models.StandingOrder{Payment: &models.Payment{SenderAccount: 1}, Status: types.StandingOrderActive})
+	standingOrderCache.Create(&models.StandingOrder{Payment: &models.Payment{SenderAccount: 2}, Status: types.StandingOrderActive})
+	standingOrderCache.Create(&models.StandingOrder{Payment: &models.Payment{SenderAccount: 1}, Status: types.StandingOrderCancelled})
+
+	assert.Equal(t, 2, len(standingOrderCache.GetAll(1)))
+	assert.Equal(t, 2, len(standingOrderCache.GetActive()))
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sort"
+	"sync"
+)
+
+// record is a value kept in a store, Copy returns a copy of it that shares nothing with it
+type record[R any] interface {
+	Copy() R
+}
+
+// store keeps records by their id and indexes them by the accounts they belong to.
+// Records are copied on their way in and out, so a record handed out can be changed freely
+// and the kept one only changes with Update while the store is locked.
+type store[K ~int64, R record[R]] struct {
+	mu        sync.Mutex
+	lastID    K
+	ids       []K
+	records   map[K]R
+	byAccount map[types.AccountNumber][]K
+	notFound  errs.Code
+	id        func(R) *K
+	accounts  func(R) []types.AccountNumber
+	// log is where every created and updated record is saved, nil keeps them in memory only
+	log *recordLog
+}
+
+// newStore takes the error code of a missing record, the id field of a record and the accounts it belongs to
+func newStore[K ~int64, R record[R]](notFound errs.Code, id func(R) *K, accounts func(R) []types.AccountNumber) *store[K, R] {
+	return &store[K, R]{
+		mu:        sync.Mutex{},
+		records:   make(map[K]R),
+		byAccount: make(map[types.AccountNumber][]K),
+		notFound:  notFound,
+		id:        id,
+		accounts:  accounts,
+	}
+}
+
+// Create keeps the record with the next id and returns a copy of it
+func (s *store[K, R]) Create(r R) R {
+	// Locks with mutex to prevent errors from concurrent access
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	*s.id(r) = s.lastID
+	kept := r.Copy()
+	s.records[s.lastID] = kept
+	s.ids = append(s.ids, s.lastID)
+	s.index(s.lastID, kept)
+	s.log.save(kept)
+	return kept.Copy()
+}
+
+func (s *store[K, R]) Get(id K) (R, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[id]
+	if !ok {
+		var zero R
+		return zero, errs.New(s.notFound)
+	}
+	return r.Copy(), nil
+}
+
+// GetAll returns the records the account belongs to, ordered by id
+func (s *store[K, R]) GetAll(accountNumber types.AccountNumber) []R {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []R
+	for _, id := range s.byAccount[accountNumber] {
+		records = append(records, s.records[id].Copy())
+	}
+	return records
+}
+
+// Update replaces the kept record with the same id by a copy of the record
+func (s *store[K, R]) Update(r R) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := *s.id(r)
+	old, ok := s.records[id]
+	if !ok {
+		return errs.New(s.notFound)
+	}
+	s.unindex(id, old)
+	kept := r.Copy()
+	s.records[id] = kept
+	s.index(id, kept)
+	s.log.save(kept)
+	return nil
+}
+
+// restore fills the store with the records saved to the log, later changes are saved to it as well
+func (s *store[K, R]) restore(log *recordLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := replayRecords(log, func(r R) {
+		id := *s.id(r)
+		if old, ok := s.records[id]; ok {
+			s.unindex(id, old)
+		} else {
+			s.ids = append(s.ids, id)
+		}
+		s.records[id] = r
+		s.index(id, r)
+		if id > s.lastID {
+			s.lastID = id
+		}
+	})
+	if err != nil {
+		return err
+	}
+	s.log = log
+	return nil
+}
+
+// filter returns the records keep returns true for, ordered by id
+func (s *store[K, R]) filter(keep func(R) bool) []R {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var records []R
+	for _, id := range s.ids {
+		r := s.records[id]
+		if keep(r) {
+			records = append(records, r.Copy())
+		}
+	}
+	return records
+}
+
+// index adds the id to the ids of every account the record belongs to, keeping them ordered
+func (s *store[K, R]) index(id K, r R) {
+	for _, accountNumber := range s.accounts(r) {
+		ids := s.byAccount[accountNumber]
+		i := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+		if i < len(ids) && ids[i] == id {
+			continue
+		}
+		ids = append(ids, 0)
+		copy(ids[i+1:], ids[i:])
+		ids[i] = id
+		s.byAccount[accountNumber] = ids
+	}
+}
+
+func (s *store[K, R]) unindex(id K, r R) {
+	for _, accountNumber := range s.accounts(r) {
+		ids := s.byAccount[accountNumber]
+		i := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
+		if i < len(ids) && ids[i] == id {
+			s.byAccount[accountNumber] = append(ids[:i], ids[i+1:]...)
+		}
+	}
+}
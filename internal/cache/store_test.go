@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStore_Copies(t *testing.T) {
+	invoiceCache := NewInvoiceCache()
+	invoice := invoiceCache.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromInt(10), Status: types.InvoiceOpen})
+
+	// Changing a handed out record does not change the kept one
+	invoice.Status = types.InvoiceCancelled
+	iInvoice, err := invoiceCache.Get(invoice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.InvoiceOpen, iInvoice.Status)
+
+	iInvoice.PaymentIDs = append(iInvoice.PaymentIDs, 1)
+	assert.Empty(t, invoiceCache.GetAll(1)[0].PaymentIDs)
+	assert.Equal(t, 1, len(invoiceCache.GetOpen()))
+}
+
+func TestStore_Update(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		invoiceCache := NewInvoiceCache()
+		invoice := invoiceCache.Create(&models.Invoice{IssuerAccount: 3, PayerAccount: 1, Status: types.InvoiceOpen})
+		invoiceCache.Create(&models.Invoice{IssuerAccount: 3, PayerAccount: 2, Status: types.InvoiceOpen})
+
+		invoice.PayerAccount = 2
+		invoice.Status = types.InvoiceCancelled
+		err := invoiceCache.Update(invoice)
+		assert.NoError(t, err)
+
+		// The invoice moves to the index of its new account and stays ordered by id
+		assert.Equal(t, 0, len(invoiceCache.GetAll(1)))
+		invoices := invoiceCache.GetAll(2)
+		assert.Equal(t, 2, len(invoices))
+		assert.Equal(t, types.InvoiceID(1), invoices[0].ID)
+		assert.Equal(t, types.InvoiceCancelled, invoices[0].Status)
+		assert.Equal(t, 2, len(invoiceCache.GetAll(3)))
+		assert.Equal(t, 1, len(invoiceCache.GetOpen()))
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		invoiceCache := NewInvoiceCache()
+		err := invoiceCache.Update(&models.Invoice{ID: 1})
+		assert.True(t, errs.Is(err, errs.InvoiceNotFound))
+	})
+}
+
+func TestStore_Restore(t *testing.T) {
+	eventStore := NewEventStore()
+	invoiceCache, err := NewInvoiceCacheFromEvents(eventStore)
+	assert.NoError(t, err)
+	invoice := invoiceCache.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Status: types.InvoiceOpen})
+	invoiceCache.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 3, Status: types.InvoiceOpen})
+	invoice.Status = types.InvoicePaid
+	assert.NoError(t, invoiceCache.Update(invoice))
+
+	// The latest version of every record is restored and new records continue after them
+	restored, err := NewInvoiceCacheFromEvents(eventStore)
+	assert.NoError(t, err)
+	rInvoice, err := restored.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, types.InvoicePaid, rInvoice.Status)
+	assert.Equal(t, 2, len(restored.GetAll(2)))
+	assert.Equal(t, 1, len(restored.GetOpen()))
+	assert.Equal(t, types.InvoiceID(3), restored.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1}).ID)
+
+	// The records change no account of a projection of the same store
+	assert.Empty(t, NewAccountCacheFromEvents(eventStore, nil).GetAll())
+}
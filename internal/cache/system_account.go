@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+)
+
+type systemAccountKey struct {
+	role     types.SystemAccountRole
+	currency types.Currency
+}
+
+// SystemAccountCache keeps the numbers of the accounts the bank opens for itself by their role and currency,
+// so they are found again after a restart without looking at the accounts themselves
+type SystemAccountCache struct {
+	mu       sync.Mutex
+	accounts map[systemAccountKey]types.AccountNumber
+	// log is where the system accounts are saved
+	log *recordLog
+}
+
+func NewSystemAccountCache() *SystemAccountCache {
+	sc, _ := NewSystemAccountCacheFromEvents(nil)
+	return sc
+}
+
+// NewSystemAccountCacheFromEvents returns the cache of the system accounts saved to the store,
+// later system accounts are saved to the same store
+func NewSystemAccountCacheFromEvents(events *EventStore) (*SystemAccountCache, error) {
+	sc := &SystemAccountCache{
+		mu:       sync.Mutex{},
+		accounts: make(map[systemAccountKey]types.AccountNumber),
+		log:      newRecordLog(events, types.SystemAccountRecord),
+	}
+	err := replayRecords(sc.log, func(account *models.SystemAccount) {
+		sc.accounts[systemAccountKey{account.Role, account.CurrencyCode}] = account.AccountNumber
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// Get returns the account of the role in the currency and whether there is one
+func (sc *SystemAccountCache) Get(role types.SystemAccountRole, currency types.Currency) (types.AccountNumber, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	accountNumber, ok := sc.accounts[systemAccountKey{role, currency}]
+	return accountNumber, ok
+}
+
+// Set records the account as the account of its role in its currency
+func (sc *SystemAccountCache) Set(account *models.SystemAccount) {
+	// Locks with mutex to prevent errors from concurrent access
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.accounts[systemAccountKey{account.Role, account.CurrencyCode}] = account.AccountNumber
+	sc.log.save(account)
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSystemAccountCache_FromEvents(t *testing.T) {
+	eventStore := NewEventStore()
+	systemAccountCache, err := NewSystemAccountCacheFromEvents(eventStore)
+	assert.NoError(t, err)
+	systemAccountCache.Set(&models.SystemAccount{Role: types.HouseRevenue, CurrencyCode: types.TRY, AccountNumber: 1})
+	systemAccountCache.Set(&models.SystemAccount{Role: types.HouseRevenue, CurrencyCode: types.USD, AccountNumber: 2})
+
+	restored, err := NewSystemAccountCacheFromEvents(eventStore)
+	assert.NoError(t, err)
+	accountNumber, ok := restored.Get(types.HouseRevenue, types.USD)
+	assert.True(t, ok)
+	assert.Equal(t, types.AccountNumber(2), accountNumber)
+	_, ok = restored.Get(types.HouseRevenue, types.EUR)
+	assert.False(t, ok)
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"sync"
@@ -10,44 +11,118 @@ import (
 
 type TransactionCache struct {
 	mu           sync.Mutex
+	lastID       types.TransactionID
 	transactions map[types.AccountNumber][]*models.Transaction
+	// lines and histories are where the history lines and the opened histories are saved
+	lines     *recordLog
+	histories *recordLog
 }
 
 func NewTransactionCache() *TransactionCache {
-	return &TransactionCache{
+	tc, _ := NewTransactionCacheFromEvents(nil)
+	return tc
+}
+
+// NewTransactionCacheFromEvents returns the cache of the histories saved to the store,
+// later lines are saved to the same store
+func NewTransactionCacheFromEvents(events *EventStore) (*TransactionCache, error) {
+	tc := &TransactionCache{
 		mu:           sync.Mutex{},
 		transactions: make(map[types.AccountNumber][]*models.Transaction),
+		lines:        newRecordLog(events, types.TransactionRecord),
+		histories:    newRecordLog(events, types.HistoryRecord),
+	}
+	err := replayRecords(tc.histories, func(accountNumber types.AccountNumber) {
+		tc.transactions[accountNumber] = []*models.Transaction{}
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Lines are saved in the order of their ids, so appending them keeps every history in order
+	err = replayRecords(tc.lines, func(transaction *models.Transaction) {
+		tc.transactions[transaction.AccountNumber] = append(tc.transactions[transaction.AccountNumber], transaction)
+		if transaction.ID > tc.lastID {
+			tc.lastID = transaction.ID
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
+	return tc, nil
 }
 
 func (tc *TransactionCache) Create(transactionHistory *models.Transaction) *models.Transaction {
 	transactionHistory.CreatedAt = time.Now()
+	// The metadata is copied so that the caller cannot change a stored line through its map
+	transactionHistory.TransactionDetails = transactionHistory.TransactionDetails.Copy()
 
 	// Locks with mutex to prevent errors from concurrent access
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
+	tc.lastID++
+	transactionHistory.ID = tc.lastID
 	tc.transactions[transactionHistory.AccountNumber] = append(tc.transactions[transactionHistory.AccountNumber], transactionHistory)
+	tc.lines.save(transactionHistory)
 	return transactionHistory
 }
 
 func (tc *TransactionCache) AddAccount(accountNumber types.AccountNumber) error {
-	_, ok := tc.transactions[accountNumber]
-	if ok {
-		return errors.New("this account already has transaction history")
-	}
-
 	// Locks with mutex to prevent errors from concurrent access
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
+
+	_, ok := tc.transactions[accountNumber]
+	if ok {
+		return errs.New(errs.HistoryAlreadyExists)
+	}
 	tc.transactions[accountNumber] = []*models.Transaction{}
+	tc.histories.save(accountNumber)
 	return nil
 }
 
+// GetAll returns a copy of the history of the account, so that later lines do not race with the caller
 func (tc *TransactionCache) GetAll(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
-	accounts, ok := tc.transactions[accountNumber]
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	history, ok := tc.transactions[accountNumber]
+	if !ok {
+		return nil, errs.New(errs.HistoryNotFound)
+	}
+	return append([]*models.Transaction{}, history...), nil
+}
+
+// Search returns the lines in the history of the account that match the filter, in order
+func (tc *TransactionCache) Search(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 
+	history, ok := tc.transactions[accountNumber]
 	if !ok {
-		return nil, errors.New("this account has no transaction history")
+		return nil, errs.New(errs.HistoryNotFound)
+	}
+
+	matches := []*models.Transaction{}
+	for _, transaction := range history {
+		if filter.Matches(transaction) {
+			matches = append(matches, transaction)
+		}
+	}
+	return matches, nil
+}
+
+// Ready reports whether the cache can serve reads and writes
+func (tc *TransactionCache) Ready() error {
+	if tc.transactions == nil {
+		return errors.New("transaction cache is not initialized")
 	}
-	return accounts, nil
+	return nil
+}
+
+// Flush writes pending changes to the underlying storage.
+// Lines are saved to the event store as they are created, the store is flushed with the accounts.
+func (tc *TransactionCache) Flush() error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return nil
 }
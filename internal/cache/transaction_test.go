@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
@@ -54,6 +55,7 @@ func TestTransactionHistoryCache_Create(t *testing.T) {
 
 		transactionR := cache.Create(transaction)
 		assert.Equal(t, transactionR, transaction)
+		assert.Equal(t, types.TransactionID(1), transactionR.ID)
 
 		transactionHistory, err := cache.GetAll(accountNumber)
 		assert.NoError(t, err)
@@ -67,4 +69,103 @@ func TestTransactionHistoryCache_Create(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("CopiesTheHistory", func(t *testing.T) {
+		cache := NewTransactionCache()
+		assert.NoError(t, cache.AddAccount(1))
+		cache.Create(&models.Transaction{AccountNumber: 1, Amount: decimal.NewFromFloat(10), TransactionType: types.Deposit})
+
+		transactionHistory, err := cache.GetAll(1)
+		assert.NoError(t, err)
+		transactionHistory[0] = nil
+
+		transactionHistory, err = cache.GetAll(1)
+		assert.NoError(t, err)
+		assert.NotNil(t, transactionHistory[0])
+	})
+
+}
+
+func TestTransactionHistoryCache_FromEvents(t *testing.T) {
+	eventStore := NewEventStore()
+	cache, err := NewTransactionCacheFromEvents(eventStore)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.AddAccount(1))
+	assert.NoError(t, cache.AddAccount(2))
+	cache.Create(&models.Transaction{AccountNumber: 1, Amount: decimal.NewFromFloat(10), TransactionType: types.Deposit})
+	cache.Create(&models.Transaction{AccountNumber: 1, Amount: decimal.NewFromFloat(-4), TransactionType: types.Withdraw})
+
+	// Histories without lines come back as well, lines in their order
+	restored, err := NewTransactionCacheFromEvents(eventStore)
+	assert.NoError(t, err)
+	transactionHistory, err := restored.GetAll(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(transactionHistory))
+	assert.True(t, decimal.NewFromFloat(-4).Equal(transactionHistory[1].Amount))
+	transactionHistory, err = restored.GetAll(2)
+	assert.NoError(t, err)
+	assert.Empty(t, transactionHistory)
+	assert.Equal(t, types.TransactionID(3), restored.Create(&models.Transaction{AccountNumber: 2}).ID)
+}
+
+func TestTransactionHistoryCache_Search(t *testing.T) {
+	cache := NewTransactionCache()
+	metadata := map[string]string{"orderId": "1042"}
+	cache.Create(&models.Transaction{
+		AccountNumber:       1,
+		Amount:              decimal.NewFromFloat(50),
+		TransactionType:     types.Payment,
+		CounterpartyAccount: 2,
+		TransactionDetails: models.TransactionDetails{
+			Description: "Coffee beans",
+			Reference:   "E2E-1042",
+			Metadata:    metadata,
+		},
+	})
+	cache.Create(&models.Transaction{
+		AccountNumber:   1,
+		Amount:          decimal.NewFromFloat(100),
+		TransactionType: types.Deposit,
+		Counterparty:    "TR330006100519786457841326",
+	})
+	// Changing the map of the caller does not change the stored line
+	metadata["orderId"] = "9999"
+
+	t.Run("All", func(t *testing.T) {
+		history, err := cache.Search(1, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(history))
+	})
+	t.Run("Counterparty", func(t *testing.T) {
+		history, err := cache.Search(1, &models.TransactionFilter{Counterparty: "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, types.Payment, history[0].TransactionType)
+
+		history, err = cache.Search(1, &models.TransactionFilter{Counterparty: "TR330006100519786457841326"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, types.Deposit, history[0].TransactionType)
+	})
+	t.Run("Text", func(t *testing.T) {
+		history, err := cache.Search(1, &models.TransactionFilter{Text: "coffee"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(history))
+
+		history, err = cache.Search(1, &models.TransactionFilter{Text: "tea"})
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+	t.Run("Metadata", func(t *testing.T) {
+		history, err := cache.Search(1, &models.TransactionFilter{Metadata: map[string]string{"orderId": "1042"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(history))
+
+		history, err = cache.Search(1, &models.TransactionFilter{Metadata: map[string]string{"orderId": "9999"}})
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+	t.Run("NoHistory", func(t *testing.T) {
+		_, err := cache.Search(2, nil)
+		assert.True(t, errs.Is(err, errs.HistoryNotFound))
+	})
 }
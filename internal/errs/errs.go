@@ -0,0 +1,123 @@
+// Package errs contains the domain errors returned by the services and caches.
+// Every error carries a stable machine-readable Code that clients can rely on,
+// the human-readable message for a code is looked up from the message catalog.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Code string
+
+const (
+	InvalidArgument         Code = "invalid_argument"
+	InvalidRequestBody      Code = "invalid_request_body"
+	InvalidAmount           Code = "invalid_amount"
+	InvalidCurrency         Code = "invalid_currency"
+	InvalidAccountType      Code = "invalid_account_type"
+	InvalidOwnerName        Code = "invalid_owner_name"
+	NegativeAccount         Code = "negative_account_number"
+	AccountNotFound         Code = "account_not_found"
+	HistoryNotFound         Code = "history_not_found"
+	HistoryAlreadyExists    Code = "history_already_exists"
+	AccountTypeNotAllowed   Code = "account_type_not_allowed"
+	CurrencyMismatch        Code = "currency_mismatch"
+	InsufficientBalance     Code = "insufficient_balance"
+	LimitExceeded           Code = "limit_exceeded"
+	HoldNotFound            Code = "hold_not_found"
+	HoldNotActive           Code = "hold_not_active"
+	CaptureExceedsHold      Code = "capture_exceeds_hold"
+	InvalidSchedule         Code = "invalid_schedule"
+	StandingOrderNotFound   Code = "standing_order_not_found"
+	StandingOrderNotActive  Code = "standing_order_not_active"
+	OverdraftLimitTooHigh   Code = "overdraft_limit_too_high"
+	ReconciliationNotRun    Code = "reconciliation_not_run"
+	VersionMismatch         Code = "version_mismatch"
+	InvalidIBAN             Code = "invalid_iban"
+	AccountNumbersExhausted Code = "account_numbers_exhausted"
+	UnknownTenant           Code = "unknown_tenant"
+	CustomerNotFound        Code = "customer_not_found"
+	WalletAlreadyExists     Code = "wallet_already_exists"
+	WalletNotFound          Code = "wallet_not_found"
+	RateNotFound            Code = "rate_not_found"
+	EscrowNotFound          Code = "escrow_not_found"
+	EscrowNotFunded         Code = "escrow_not_funded"
+	EscrowPartyNotAllowed   Code = "escrow_party_not_allowed"
+	InvoiceNotFound         Code = "invoice_not_found"
+	InvoiceNotOpen          Code = "invoice_not_open"
+	PaymentExceedsInvoice   Code = "payment_exceeds_invoice"
+	DisputeNotFound         Code = "dispute_not_found"
+	DisputeNotOpen          Code = "dispute_not_open"
+	DisputeExceedsPayment   Code = "dispute_exceeds_payment"
+	ScreeningCaseNotFound   Code = "screening_case_not_found"
+	ScreeningCaseDecided    Code = "screening_case_decided"
+	PaymentUnderReview      Code = "payment_under_review"
+	SanctionsMatch          Code = "sanctions_match"
+	FraudAlertNotFound      Code = "fraud_alert_not_found"
+	FraudAlertDecided       Code = "fraud_alert_decided"
+	TransactionUnderReview  Code = "transaction_under_review"
+	TransactionBlocked      Code = "transaction_blocked"
+	AdminKeyInvalid         Code = "admin_key_invalid"
+	Internal                Code = "internal"
+)
+
+// Error is a domain error with a stable code.
+// Detail optionally describes this specific occurrence,
+// Extensions carries additional machine-readable members for the response
+type Error struct {
+	Code       Code
+	Detail     string
+	Extensions map[string]interface{}
+}
+
+// New returns a domain error for the code
+func New(code Code) *Error {
+	return &Error{Code: code}
+}
+
+// Newf returns a domain error for the code with a formatted detail
+func Newf(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Detail: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s", Message(e.Code, English), e.Detail)
+	}
+	return Message(e.Code, English)
+}
+
+// Is makes errors.Is match any domain error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// With returns a copy of the error with an additional extension member
+func (e *Error) With(key string, value interface{}) *Error {
+	extensions := make(map[string]interface{}, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		extensions[k] = v
+	}
+	extensions[key] = value
+	return &Error{Code: e.Code, Detail: e.Detail, Extensions: extensions}
+}
+
+// CodeOf returns the code of a domain error anywhere in the chain of err,
+// or an empty code if err is not a domain error
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// Is reports whether err is a domain error with the code
+func Is(err error, code Code) bool {
+	return err != nil && CodeOf(err) == code
+}
@@ -0,0 +1,36 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	t.Run("SameCode", func(t *testing.T) {
+		err := fmt.Errorf("paying: %w", Newf(InsufficientBalance, "balance is 10"))
+		assert.True(t, errors.Is(err, New(InsufficientBalance)))
+		assert.True(t, Is(err, InsufficientBalance))
+		assert.Equal(t, InsufficientBalance, CodeOf(err))
+	})
+	t.Run("DifferentCode", func(t *testing.T) {
+		err := New(AccountNotFound)
+		assert.False(t, errors.Is(err, New(InsufficientBalance)))
+		assert.Equal(t, Code(""), CodeOf(errors.New("insufficient balance")))
+	})
+}
+
+func TestError_With(t *testing.T) {
+	err := New(InsufficientBalance)
+	withBalance := err.With("balance", 10)
+	assert.Nil(t, err.Extensions)
+	assert.Equal(t, 10, withBalance.Extensions["balance"])
+}
+
+func TestParseLanguage(t *testing.T) {
+	assert.Equal(t, Turkish, ParseLanguage("tr-TR,tr;q=0.9"))
+	assert.Equal(t, Turkish, ParseLanguage("de-DE, TR;q=0.5"))
+	assert.Equal(t, English, ParseLanguage("de-DE"))
+	assert.Equal(t, English, ParseLanguage(""))
+}
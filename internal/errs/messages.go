@@ -0,0 +1,140 @@
+package errs
+
+import "strings"
+
+type Language string
+
+const (
+	English Language = "en"
+	Turkish Language = "tr"
+)
+
+var messages = map[Language]map[Code]string{
+	English: {
+		InvalidArgument:         "invalid argument",
+		InvalidRequestBody:      "cannot bind json",
+		InvalidAmount:           "amount must be greater than 0",
+		InvalidCurrency:         "invalid currency code",
+		InvalidAccountType:      "invalid account type",
+		InvalidOwnerName:        "invalid owner name",
+		NegativeAccount:         "account number cannot be negative",
+		AccountNotFound:         "invalid account number",
+		HistoryNotFound:         "this account has no transaction history",
+		HistoryAlreadyExists:    "this account already has transaction history",
+		AccountTypeNotAllowed:   "account type is not allowed for this operation",
+		CurrencyMismatch:        "the currency codes of the accounts are not the same",
+		InsufficientBalance:     "insufficient balance",
+		LimitExceeded:           "transaction limit exceeded",
+		HoldNotFound:            "hold not found",
+		HoldNotActive:           "hold is no longer active",
+		CaptureExceedsHold:      "capture amount exceeds the hold",
+		InvalidSchedule:         "schedule of the standing order is not valid",
+		StandingOrderNotFound:   "standing order not found",
+		StandingOrderNotActive:  "standing order is no longer active",
+		OverdraftLimitTooHigh:   "overdraft limit is higher than allowed",
+		ReconciliationNotRun:    "no reconciliation has run yet",
+		VersionMismatch:         "the resource was changed since it was read",
+		InvalidIBAN:             "invalid IBAN",
+		AccountNumbersExhausted: "no account numbers are left for the account type",
+		UnknownTenant:           "the API key or tenant is not known",
+		CustomerNotFound:        "customer not found",
+		WalletAlreadyExists:     "the customer already has a wallet in the currency",
+		WalletNotFound:          "the account is not a wallet of the customer",
+		RateNotFound:            "no exchange rate between the currencies",
+		EscrowNotFound:          "escrow not found",
+		EscrowNotFunded:         "escrow is already settled",
+		EscrowPartyNotAllowed:   "escrow cannot be settled by this account",
+		InvoiceNotFound:         "invoice not found",
+		InvoiceNotOpen:          "invoice is no longer open",
+		PaymentExceedsInvoice:   "payment amount exceeds the unpaid amount of the invoice",
+		DisputeNotFound:         "dispute not found",
+		DisputeNotOpen:          "dispute is already resolved",
+		DisputeExceedsPayment:   "dispute amount exceeds the undisputed amount of the payment",
+		ScreeningCaseNotFound:   "screening case not found",
+		ScreeningCaseDecided:    "screening case is already decided",
+		PaymentUnderReview:      "payment is held for a sanctions screening review",
+		SanctionsMatch:          "account matches the sanctions list",
+		FraudAlertNotFound:      "fraud alert not found",
+		FraudAlertDecided:       "fraud alert is already decided",
+		TransactionUnderReview:  "transaction is held for a fraud review",
+		TransactionBlocked:      "transaction is blocked by the fraud rules",
+		AdminKeyInvalid:         "the admin key is missing or not valid",
+		Internal:                "something is wrong",
+	},
+	Turkish: {
+		InvalidArgument:         "geçersiz parametre",
+		InvalidRequestBody:      "istek gövdesi okunamadı",
+		InvalidAmount:           "tutar 0'dan büyük olmalıdır",
+		InvalidCurrency:         "geçersiz para birimi",
+		InvalidAccountType:      "geçersiz hesap türü",
+		InvalidOwnerName:        "geçersiz hesap sahibi adı",
+		NegativeAccount:         "hesap numarası negatif olamaz",
+		AccountNotFound:         "geçersiz hesap numarası",
+		HistoryNotFound:         "bu hesabın işlem geçmişi yok",
+		HistoryAlreadyExists:    "bu hesabın zaten işlem geçmişi var",
+		AccountTypeNotAllowed:   "hesap türü bu işlem için uygun değil",
+		CurrencyMismatch:        "hesapların para birimleri aynı değil",
+		InsufficientBalance:     "yetersiz bakiye",
+		LimitExceeded:           "işlem limiti aşıldı",
+		HoldNotFound:            "provizyon bulunamadı",
+		HoldNotActive:           "provizyon artık geçerli değil",
+		CaptureExceedsHold:      "tahsilat tutarı provizyonu aşıyor",
+		InvalidSchedule:         "düzenli ödeme talimatının zamanlaması geçerli değil",
+		StandingOrderNotFound:   "düzenli ödeme talimatı bulunamadı",
+		StandingOrderNotActive:  "düzenli ödeme talimatı artık geçerli değil",
+		OverdraftLimitTooHigh:   "ek hesap limiti izin verilenden yüksek",
+		ReconciliationNotRun:    "henüz mutabakat yapılmadı",
+		VersionMismatch:         "kayıt okunduktan sonra değiştirildi",
+		InvalidIBAN:             "geçersiz IBAN",
+		AccountNumbersExhausted: "hesap türü için boş hesap numarası kalmadı",
+		UnknownTenant:           "API anahtarı veya kiracı tanınmıyor",
+		CustomerNotFound:        "müşteri bulunamadı",
+		WalletAlreadyExists:     "müşterinin bu para biriminde zaten bir cüzdanı var",
+		WalletNotFound:          "hesap müşterinin cüzdanı değil",
+		RateNotFound:            "para birimleri arasında döviz kuru yok",
+		EscrowNotFound:          "emanet ödeme bulunamadı",
+		EscrowNotFunded:         "emanet ödeme zaten sonuçlandı",
+		EscrowPartyNotAllowed:   "emanet ödeme bu hesap tarafından sonuçlandırılamaz",
+		InvoiceNotFound:         "fatura bulunamadı",
+		InvoiceNotOpen:          "fatura artık açık değil",
+		PaymentExceedsInvoice:   "ödeme tutarı faturanın ödenmemiş tutarını aşıyor",
+		DisputeNotFound:         "itiraz bulunamadı",
+		DisputeNotOpen:          "itiraz zaten sonuçlandı",
+		DisputeExceedsPayment:   "itiraz tutarı ödemenin itiraz edilmemiş tutarını aşıyor",
+		ScreeningCaseNotFound:   "tarama kaydı bulunamadı",
+		ScreeningCaseDecided:    "tarama kaydı zaten karara bağlandı",
+		PaymentUnderReview:      "ödeme yaptırım taraması incelemesi için bekletiliyor",
+		SanctionsMatch:          "hesap yaptırım listesiyle eşleşiyor",
+		FraudAlertNotFound:      "dolandırıcılık uyarısı bulunamadı",
+		FraudAlertDecided:       "dolandırıcılık uyarısı zaten karara bağlandı",
+		TransactionUnderReview:  "işlem dolandırıcılık incelemesi için bekletiliyor",
+		TransactionBlocked:      "işlem dolandırıcılık kuralları tarafından engellendi",
+		AdminKeyInvalid:         "yönetici anahtarı eksik veya geçersiz",
+		Internal:                "bir şeyler ters gitti",
+	},
+}
+
+// Message returns the human-readable message of the code in the language,
+// falling back to English and then to the code itself
+func Message(code Code, lang Language) string {
+	if m, ok := messages[lang][code]; ok {
+		return m
+	}
+	if m, ok := messages[English][code]; ok {
+		return m
+	}
+	return string(code)
+}
+
+// ParseLanguage picks the first supported language of an Accept-Language header,
+// it returns English if none of them is supported
+func ParseLanguage(acceptLanguage string) Language {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		tag = strings.TrimSpace(strings.Split(tag, ";")[0])
+		primary := Language(strings.ToLower(strings.Split(tag, "-")[0]))
+		if _, ok := messages[primary]; ok {
+			return primary
+		}
+	}
+	return English
+}
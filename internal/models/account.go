@@ -3,43 +3,189 @@ package models
 import (
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
+	"time"
 )
 
+// Account keeps the ledger balance in Balance, Held is the part of it
+// reserved by active holds and cannot be spent until the holds are released.
+// OverdraftLimit lets the balance go down to its negative, OverdrawnSince is
+// when the balance last went below zero and is zero while it is not negative.
+// Version counts the versions of the profile, it starts at 1 and grows with every update.
+// CustomerID is set for the wallets of customers.
 type Account struct {
-	AccountNumber types.AccountNumber
-	CurrencyCode  types.Currency
-	OwnerName     string
-	AccountType   types.AccountType
-	Balance       decimal.Decimal
+	AccountNumber  types.AccountNumber
+	CustomerID     types.CustomerID
+	CurrencyCode   types.Currency
+	OwnerName      string
+	AccountType    types.AccountType
+	Balance        decimal.Decimal
+	Held           decimal.Decimal
+	OverdraftLimit decimal.Decimal
+	OverdrawnSince time.Time
+	Contact        ContactInfo
+	Metadata       map[string]string
+	Version        int
+	CreatedAt      time.Time
 }
 
 type AccountDTO struct {
-	AccountNumber types.AccountNumber `json:"accountNumber"`
-	CurrencyCode  types.Currency      `json:"currencyCode"`
-	OwnerName     string              `json:"ownerName"`
-	AccountType   types.AccountType   `json:"accountType"`
-	Balance       float64             `json:"balance"`
+	AccountNumber    types.AccountNumber `json:"accountNumber"`
+	IBAN             string              `json:"iban,omitempty"`
+	CustomerID       types.CustomerID    `json:"customerId,omitempty"`
+	CurrencyCode     types.Currency      `json:"currencyCode"`
+	OwnerName        string              `json:"ownerName"`
+	AccountType      types.AccountType   `json:"accountType"`
+	Balance          float64             `json:"balance"`
+	AvailableBalance float64             `json:"availableBalance"`
+	OverdraftLimit   float64             `json:"overdraftLimit,omitempty"`
+	OverdrawnSince   *time.Time          `json:"overdrawnSince,omitempty"`
+	Holds            []*HoldDTO          `json:"holds,omitempty"`
+	Contact          *ContactInfo        `json:"contact,omitempty"`
+	Metadata         map[string]string   `json:"metadata,omitempty"`
+	Version          int                 `json:"version,omitempty"`
+	CreatedAt        *time.Time          `json:"createdAt,omitempty"`
+}
+
+// Profile returns the mutable part of the account
+func (account *Account) Profile() *AccountProfile {
+	return &AccountProfile{
+		OwnerName: account.OwnerName,
+		Contact:   account.Contact,
+		Metadata:  CopyMetadata(account.Metadata),
+	}
+}
+
+// Available returns the part of the balance that can be spent, including the unused overdraft
+func (account *Account) Available() decimal.Decimal {
+	return account.Balance.Sub(account.Held).Add(account.OverdraftLimit)
 }
 
 func (account *Account) DTO() *AccountDTO {
 
 	balanceF, _ := account.Balance.Truncate(2).Float64()
+	availableF, _ := account.Available().Truncate(2).Float64()
+	overdraftF, _ := account.OverdraftLimit.Truncate(2).Float64()
 
-	return &AccountDTO{
-		AccountNumber: account.AccountNumber,
-		CurrencyCode:  account.CurrencyCode,
-		OwnerName:     account.OwnerName,
-		AccountType:   account.AccountType,
-		Balance:       balanceF,
+	accountDTO := &AccountDTO{
+		AccountNumber:    account.AccountNumber,
+		IBAN:             account.AccountNumber.IBAN(),
+		CustomerID:       account.CustomerID,
+		CurrencyCode:     account.CurrencyCode,
+		OwnerName:        account.OwnerName,
+		AccountType:      account.AccountType,
+		Balance:          balanceF,
+		AvailableBalance: availableF,
+		OverdraftLimit:   overdraftF,
+		Metadata:         CopyMetadata(account.Metadata),
+		Version:          account.Version,
+	}
+	if account.Contact != (ContactInfo{}) {
+		contact := account.Contact
+		accountDTO.Contact = &contact
+	}
+	if !account.OverdrawnSince.IsZero() {
+		overdrawnSince := account.OverdrawnSince
+		accountDTO.OverdrawnSince = &overdrawnSince
 	}
+	if !account.CreatedAt.IsZero() {
+		createdAt := account.CreatedAt
+		accountDTO.CreatedAt = &createdAt
+	}
+	return accountDTO
 }
 
 func (accountDTO *AccountDTO) Normal() *Account {
-	return &Account{
+	account := &Account{
 		AccountNumber: accountDTO.AccountNumber,
 		CurrencyCode:  accountDTO.CurrencyCode,
 		OwnerName:     accountDTO.OwnerName,
 		AccountType:   accountDTO.AccountType,
 		Balance:       decimal.NewFromFloat(accountDTO.Balance),
 	}
+	if accountDTO.Contact != nil {
+		account.Contact = *accountDTO.Contact
+	}
+	account.Metadata = CopyMetadata(accountDTO.Metadata)
+	return account
+}
+
+// AccountFilter selects accounts, empty fields match every account.
+// OwnerName matches owners with a word starting with each of its words,
+// with Fuzzy set the words may also be misspelled by a letter or two.
+// The balance and creation bounds are inclusive.
+type AccountFilter struct {
+	OwnerName    string
+	Fuzzy        bool
+	AccountType  types.AccountType
+	CurrencyCode types.Currency
+	MinBalance   *decimal.Decimal
+	MaxBalance   *decimal.Decimal
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+	Offset       int
+	Limit        int
+}
+
+// AccountFilterDTO is read from the query parameters of an account search
+type AccountFilterDTO struct {
+	OwnerName    string            `form:"ownerName"`
+	Fuzzy        bool              `form:"fuzzy"`
+	AccountType  types.AccountType `form:"accountType"`
+	CurrencyCode types.Currency    `form:"currencyCode"`
+	MinBalance   *float64          `form:"minBalance"`
+	MaxBalance   *float64          `form:"maxBalance"`
+	CreatedFrom  time.Time         `form:"createdFrom" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo    time.Time         `form:"createdTo" time_format:"2006-01-02T15:04:05Z07:00"`
+	Offset       int               `form:"offset"`
+	Limit        int               `form:"limit"`
+}
+
+func (afd *AccountFilterDTO) Normal() *AccountFilter {
+	filter := &AccountFilter{
+		OwnerName:    afd.OwnerName,
+		Fuzzy:        afd.Fuzzy,
+		AccountType:  afd.AccountType,
+		CurrencyCode: afd.CurrencyCode,
+		CreatedFrom:  afd.CreatedFrom,
+		CreatedTo:    afd.CreatedTo,
+		Offset:       afd.Offset,
+		Limit:        afd.Limit,
+	}
+	if afd.MinBalance != nil {
+		minBalance := decimal.NewFromFloat(*afd.MinBalance)
+		filter.MinBalance = &minBalance
+	}
+	if afd.MaxBalance != nil {
+		maxBalance := decimal.NewFromFloat(*afd.MaxBalance)
+		filter.MaxBalance = &maxBalance
+	}
+	return filter
+}
+
+// AccountPage is a page of the accounts matching a filter, Total counts every match
+type AccountPage struct {
+	Accounts []*Account
+	Total    int
+	Offset   int
+	Limit    int
+}
+
+type AccountPageDTO struct {
+	Accounts []*AccountDTO `json:"accounts"`
+	Total    int           `json:"total"`
+	Offset   int           `json:"offset"`
+	Limit    int           `json:"limit"`
+}
+
+func (ap *AccountPage) DTO() *AccountPageDTO {
+	dto := &AccountPageDTO{
+		Accounts: []*AccountDTO{},
+		Total:    ap.Total,
+		Offset:   ap.Offset,
+		Limit:    ap.Limit,
+	}
+	for _, account := range ap.Accounts {
+		dto.Accounts = append(dto.Accounts, account.DTO())
+	}
+	return dto
 }
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sort"
+	"strconv"
+)
+
+// AccountNumberRange is the block of numbers the accounts of a type are numbered from,
+// an empty AccountType makes it the range of every type without its own
+type AccountNumberRange struct {
+	AccountType types.AccountType
+	First       types.AccountNumber
+	Last        types.AccountNumber
+}
+
+// AccountNumberRangeDTO describes a range either by its first and last numbers,
+// or as every number of Digits digits starting with Prefix
+type AccountNumberRangeDTO struct {
+	AccountType types.AccountType `json:"accountType,omitempty"`
+	Prefix      string            `json:"prefix,omitempty"`
+	Digits      int               `json:"digits,omitempty"`
+	First       int64             `json:"first,omitempty"`
+	Last        int64             `json:"last,omitempty"`
+}
+
+func (rd *AccountNumberRangeDTO) Normal() *AccountNumberRange {
+	r := &AccountNumberRange{
+		AccountType: rd.AccountType,
+		First:       types.AccountNumber(rd.First),
+		Last:        types.AccountNumber(rd.Last),
+	}
+	if rd.Prefix == "" {
+		return r
+	}
+	prefix, err := strconv.ParseInt(rd.Prefix, 10, 64)
+	free := rd.Digits - len(rd.Prefix)
+	if err != nil || prefix < 0 || free < 1 || rd.Digits > 16 {
+		// Left empty so that Validate rejects it
+		return &AccountNumberRange{AccountType: rd.AccountType}
+	}
+	r.First = types.AccountNumber(prefix)
+	r.Last = types.AccountNumber(prefix)
+	for i := 0; i < free; i++ {
+		r.First *= 10
+		r.Last = r.Last*10 + 9
+	}
+	if r.First == 0 {
+		r.First = 1
+	}
+	return r
+}
+
+func (r *AccountNumberRange) Contains(accountNumber types.AccountNumber) bool {
+	return accountNumber >= r.First && accountNumber <= r.Last
+}
+
+func (r *AccountNumberRange) Validate() error {
+	if r.First < 1 || r.Last < r.First || r.Last > types.MaxAccountNumber {
+		return fmt.Errorf("account number range of %q has to be between 1 and %d", r.AccountType, types.MaxAccountNumber)
+	}
+	return nil
+}
+
+// ValidateAccountNumberRanges checks every range and that no two of them overlap
+// or belong to the same account type, so that no number can be given out for two types
+func ValidateAccountNumberRanges(ranges []*AccountNumberRange) error {
+	seen := make(map[types.AccountType]bool)
+	for _, r := range ranges {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if seen[r.AccountType] {
+			return fmt.Errorf("account type %q has more than one account number range", r.AccountType)
+		}
+		seen[r.AccountType] = true
+	}
+
+	sorted := append([]*AccountNumberRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].First < sorted[j].First })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].First <= sorted[i-1].Last {
+			return fmt.Errorf("account number ranges of %q and %q overlap", sorted[i-1].AccountType, sorted[i].AccountType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContactInfo is how the owner of an account can be reached, every field is optional
+type ContactInfo struct {
+	Email   string `json:"email,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+const (
+	minPhoneDigits   = 7
+	maxPhoneDigits   = 15
+	maxAddressLength = 200
+)
+
+// Validate checks the format of the fields that are set
+func (ci ContactInfo) Validate() error {
+	if ci.Email != "" {
+		address, err := mail.ParseAddress(ci.Email)
+		if err != nil || address.Address != ci.Email {
+			return errs.Newf(errs.InvalidArgument, "email is not a valid address")
+		}
+	}
+	if ci.Phone != "" {
+		digits := 0
+		for i, r := range ci.Phone {
+			switch {
+			case r >= '0' && r <= '9':
+				digits++
+			case r == '+' && i == 0, r == ' ', r == '-':
+			default:
+				return errs.Newf(errs.InvalidArgument, "phone can only have digits, spaces, dashes and a leading +")
+			}
+		}
+		if digits < minPhoneDigits || digits > maxPhoneDigits {
+			return errs.Newf(errs.InvalidArgument, "phone must have %d to %d digits", minPhoneDigits, maxPhoneDigits)
+		}
+	}
+	if len([]rune(ci.Address)) > maxAddressLength {
+		return errs.Newf(errs.InvalidArgument, "address cannot be longer than %d characters", maxAddressLength)
+	}
+	return nil
+}
+
+// AccountProfile is the part of an account that can be changed after it is opened
+type AccountProfile struct {
+	OwnerName string
+	Contact   ContactInfo
+	Metadata  map[string]string
+}
+
+// Validate checks the contact info and the metadata, owner names are checked by the account service
+// since the rules depend on the account type
+func (ap *AccountProfile) Validate() error {
+	if err := ap.Contact.Validate(); err != nil {
+		return err
+	}
+	return validateMetadata(ap.Metadata)
+}
+
+// ContactInfoPatch changes the contact fields that are set, an empty string clears a field
+type ContactInfoPatch struct {
+	Email   *string `json:"email"`
+	Phone   *string `json:"phone"`
+	Address *string `json:"address"`
+}
+
+// AccountPatch is an update of the profile of an account, only the fields present are changed.
+// Metadata is merged into the existing pairs, a key set to null is removed.
+type AccountPatch struct {
+	OwnerName *string            `json:"ownerName"`
+	Contact   *ContactInfoPatch  `json:"contact"`
+	Metadata  map[string]*string `json:"metadata"`
+}
+
+// Apply returns the profile with the patch applied, the profile itself is not changed
+func (apd *AccountPatch) Apply(profile *AccountProfile) *AccountProfile {
+	patched := &AccountProfile{
+		OwnerName: profile.OwnerName,
+		Contact:   profile.Contact,
+		Metadata:  CopyMetadata(profile.Metadata),
+	}
+	if apd.OwnerName != nil {
+		patched.OwnerName = strings.TrimSpace(*apd.OwnerName)
+	}
+	if apd.Contact != nil {
+		if apd.Contact.Email != nil {
+			patched.Contact.Email = *apd.Contact.Email
+		}
+		if apd.Contact.Phone != nil {
+			patched.Contact.Phone = *apd.Contact.Phone
+		}
+		if apd.Contact.Address != nil {
+			patched.Contact.Address = *apd.Contact.Address
+		}
+	}
+	for key, value := range apd.Metadata {
+		if value == nil {
+			delete(patched.Metadata, key)
+			continue
+		}
+		if patched.Metadata == nil {
+			patched.Metadata = make(map[string]string)
+		}
+		patched.Metadata[key] = *value
+	}
+	if len(patched.Metadata) == 0 {
+		patched.Metadata = nil
+	}
+	return patched
+}
+
+// AccountChange is a version of the profile of an account and the fields it changed.
+// Version 1 is the profile the account was opened with.
+type AccountChange struct {
+	Version int
+	At      time.Time
+	Fields  []*FieldChange
+}
+
+// FieldChange is the old and the new value of a profile field,
+// metadata fields are named metadata.<key>
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type AccountChangeDTO struct {
+	Version int            `json:"version"`
+	At      time.Time      `json:"at"`
+	Fields  []*FieldChange `json:"fields"`
+}
+
+func (ac *AccountChange) DTO() *AccountChangeDTO {
+	return &AccountChangeDTO{
+		Version: ac.Version,
+		At:      ac.At,
+		Fields:  ac.Fields,
+	}
+}
+
+// DiffProfiles returns the fields that differ between the profiles, in a stable order
+func DiffProfiles(old *AccountProfile, new *AccountProfile) []*FieldChange {
+	var changes []*FieldChange
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, &FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	add("ownerName", old.OwnerName, new.OwnerName)
+	add("contact.email", old.Contact.Email, new.Contact.Email)
+	add("contact.phone", old.Contact.Phone, new.Contact.Phone)
+	add("contact.address", old.Contact.Address, new.Contact.Address)
+
+	keys := make(map[string]bool)
+	for key := range old.Metadata {
+		keys[key] = true
+	}
+	for key := range new.Metadata {
+		keys[key] = true
+	}
+	var sorted []string
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		add("metadata."+key, old.Metadata[key], new.Metadata[key])
+	}
+	return changes
+}
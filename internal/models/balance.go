@@ -0,0 +1,54 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// BalanceChange adds Amount to the balance of an account, a negative Amount is a debit.
+// Held is added to the held part of the balance the same way.
+// When RequireFunds is set the resulting available balance of the account cannot be negative.
+// Reason is the type of the event the change is recorded with.
+type BalanceChange struct {
+	AccountNumber types.AccountNumber
+	Amount        decimal.Decimal
+	Held          decimal.Decimal
+	RequireFunds  bool
+	Reason        types.EventType
+}
+
+// BalanceCheckpoint is the balance of an account after the history line TransactionID,
+// taken at At so that historical balances can be computed from it instead of the whole history
+type BalanceCheckpoint struct {
+	AccountNumber types.AccountNumber
+	At            time.Time
+	Balance       decimal.Decimal
+	TransactionID types.TransactionID
+}
+
+// HistoricalBalance is the balance an account had at a past moment
+type HistoricalBalance struct {
+	AccountNumber types.AccountNumber
+	CurrencyCode  types.Currency
+	Balance       decimal.Decimal
+	At            time.Time
+}
+
+type HistoricalBalanceDTO struct {
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+	CurrencyCode  types.Currency      `json:"currencyCode"`
+	Balance       float64             `json:"balance"`
+	At            time.Time           `json:"at"`
+}
+
+func (hb *HistoricalBalance) DTO() *HistoricalBalanceDTO {
+	balanceF, _ := hb.Balance.Truncate(2).Float64()
+
+	return &HistoricalBalanceDTO{
+		AccountNumber: hb.AccountNumber,
+		CurrencyCode:  hb.CurrencyCode,
+		Balance:       balanceF,
+		At:            hb.At,
+	}
+}
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sort"
+	"time"
+	"unicode"
+)
+
+// Customer owns a wallet account in each currency it holds money in.
+// The wallets are opened with the name and the type of the customer.
+type Customer struct {
+	ID           types.CustomerID
+	Name         string
+	CustomerType types.AccountType
+	KYC          KYC
+	Wallets      map[types.Currency]types.AccountNumber
+	CreatedAt    time.Time
+}
+
+// KYC is what is known about the identity of a customer,
+// VerifiedAt is when Status last became verified
+type KYC struct {
+	IdentityNumber string
+	DateOfBirth    time.Time
+	Nationality    string
+	Address        string
+	Status         types.KYCStatus
+	VerifiedAt     time.Time
+}
+
+const (
+	maxIdentityNumberLength = 20
+	maxKYCAddressLength     = 200
+)
+
+// Validate checks the fields the customer filled in, an empty status is pending
+func (kyc *KYC) Validate(now time.Time) error {
+	if len(kyc.IdentityNumber) > maxIdentityNumberLength {
+		return errs.Newf(errs.InvalidArgument, "identity number cannot be longer than %d characters", maxIdentityNumberLength)
+	}
+	for _, r := range kyc.IdentityNumber {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return errs.Newf(errs.InvalidArgument, "identity number can only have letters and digits")
+		}
+	}
+	if kyc.DateOfBirth.After(now) {
+		return errs.Newf(errs.InvalidArgument, "date of birth cannot be in the future")
+	}
+	if kyc.Nationality != "" && (len(kyc.Nationality) != 2 || !isUpper(kyc.Nationality)) {
+		return errs.Newf(errs.InvalidArgument, "nationality has to be a two letter ISO 3166 country code")
+	}
+	if len([]rune(kyc.Address)) > maxKYCAddressLength {
+		return errs.Newf(errs.InvalidArgument, "address cannot be longer than %d characters", maxKYCAddressLength)
+	}
+	switch kyc.Status {
+	case "", types.KYCPending, types.KYCVerified, types.KYCRejected:
+	default:
+		return errs.Newf(errs.InvalidArgument, "unknown kyc status %q", kyc.Status)
+	}
+	if kyc.Status == types.KYCVerified && kyc.IdentityNumber == "" {
+		return errs.Newf(errs.InvalidArgument, "a customer cannot be verified without an identity number")
+	}
+	return nil
+}
+
+func isUpper(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+type KYCDTO struct {
+	IdentityNumber string          `json:"identityNumber,omitempty"`
+	DateOfBirth    string          `json:"dateOfBirth,omitempty"`
+	Nationality    string          `json:"nationality,omitempty"`
+	Address        string          `json:"address,omitempty"`
+	Status         types.KYCStatus `json:"status,omitempty"`
+	VerifiedAt     *time.Time      `json:"verifiedAt,omitempty"`
+}
+
+// dateLayout is the layout of dates without a time of day
+const dateLayout = "2006-01-02"
+
+func (kyc *KYC) DTO() *KYCDTO {
+	dto := &KYCDTO{
+		IdentityNumber: kyc.IdentityNumber,
+		Nationality:    kyc.Nationality,
+		Address:        kyc.Address,
+		Status:         kyc.Status,
+	}
+	if !kyc.DateOfBirth.IsZero() {
+		dto.DateOfBirth = kyc.DateOfBirth.Format(dateLayout)
+	}
+	if !kyc.VerifiedAt.IsZero() {
+		verifiedAt := kyc.VerifiedAt
+		dto.VerifiedAt = &verifiedAt
+	}
+	return dto
+}
+
+// Normal returns the KYC data, the date of birth has to be written as YYYY-MM-DD
+func (kd *KYCDTO) Normal() (*KYC, error) {
+	kyc := &KYC{
+		IdentityNumber: kd.IdentityNumber,
+		Nationality:    kd.Nationality,
+		Address:        kd.Address,
+		Status:         kd.Status,
+	}
+	if kd.DateOfBirth != "" {
+		dateOfBirth, err := time.Parse(dateLayout, kd.DateOfBirth)
+		if err != nil {
+			return nil, errs.Newf(errs.InvalidArgument, "date of birth has to be written as YYYY-MM-DD")
+		}
+		kyc.DateOfBirth = dateOfBirth
+	}
+	return kyc, nil
+}
+
+type CustomerDTO struct {
+	ID           types.CustomerID                       `json:"id"`
+	Name         string                                 `json:"name"`
+	CustomerType types.AccountType                      `json:"customerType"`
+	KYC          *KYCDTO                                `json:"kyc,omitempty"`
+	Wallets      map[types.Currency]types.AccountNumber `json:"wallets,omitempty"`
+	CreatedAt    *time.Time                             `json:"createdAt,omitempty"`
+}
+
+func (c *Customer) DTO() *CustomerDTO {
+	dto := &CustomerDTO{
+		ID:           c.ID,
+		Name:         c.Name,
+		CustomerType: c.CustomerType,
+		KYC:          c.KYC.DTO(),
+		Wallets:      make(map[types.Currency]types.AccountNumber, len(c.Wallets)),
+	}
+	for currency, accountNumber := range c.Wallets {
+		dto.Wallets[currency] = accountNumber
+	}
+	if !c.CreatedAt.IsZero() {
+		createdAt := c.CreatedAt
+		dto.CreatedAt = &createdAt
+	}
+	return dto
+}
+
+func (cd *CustomerDTO) Normal() (*Customer, error) {
+	customer := &Customer{
+		Name:         cd.Name,
+		CustomerType: cd.CustomerType,
+	}
+	if cd.KYC != nil {
+		kyc, err := cd.KYC.Normal()
+		if err != nil {
+			return nil, err
+		}
+		customer.KYC = *kyc
+	}
+	return customer, nil
+}
+
+// WalletDTO is the body opening a wallet of a customer
+type WalletDTO struct {
+	CurrencyCode types.Currency `json:"currencyCode"`
+}
+
+// Transfer moves Amount from one wallet of the customer to another,
+// Amount is in the currency of the wallet it is taken from
+type Transfer struct {
+	CustomerID  types.CustomerID
+	FromAccount types.AccountNumber
+	ToAccount   types.AccountNumber
+	Amount      decimal.Decimal
+	TransactionDetails
+}
+
+type TransferDTO struct {
+	FromAccount types.AccountNumber `json:"fromAccount"`
+	ToAccount   types.AccountNumber `json:"toAccount"`
+	Amount      float64             `json:"amount"`
+	TransactionDetails
+}
+
+func (td *TransferDTO) Normal(customerID types.CustomerID) *Transfer {
+	return &Transfer{
+		CustomerID:         customerID,
+		FromAccount:        td.FromAccount,
+		ToAccount:          td.ToAccount,
+		Amount:             decimal.NewFromFloat(td.Amount),
+		TransactionDetails: td.TransactionDetails,
+	}
+}
+
+// TransferResult is the pair of history lines a transfer wrote,
+// the credit is the debit converted with Rate
+type TransferResult struct {
+	Debit  *Transaction
+	Credit *Transaction
+	Rate   decimal.Decimal
+}
+
+type TransferResultDTO struct {
+	Debit  *TransactionDTO `json:"debit"`
+	Credit *TransactionDTO `json:"credit"`
+	Rate   float64         `json:"rate"`
+}
+
+func (tr *TransferResult) DTO() *TransferResultDTO {
+	rateF, _ := tr.Rate.Float64()
+
+	return &TransferResultDTO{
+		Debit:  tr.Debit.DTO(),
+		Credit: tr.Credit.DTO(),
+		Rate:   rateF,
+	}
+}
+
+// WalletBalance is the balance of a wallet and its value in the currency of the customer balances
+type WalletBalance struct {
+	AccountNumber types.AccountNumber
+	CurrencyCode  types.Currency
+	Balance       decimal.Decimal
+	Available     decimal.Decimal
+	Rate          decimal.Decimal
+	Value         decimal.Decimal
+}
+
+// CustomerBalances adds up the wallets of a customer in a single currency
+type CustomerBalances struct {
+	CustomerID   types.CustomerID
+	CurrencyCode types.Currency
+	Wallets      []*WalletBalance
+	Total        decimal.Decimal
+	Available    decimal.Decimal
+}
+
+type WalletBalanceDTO struct {
+	AccountNumber    types.AccountNumber `json:"accountNumber"`
+	CurrencyCode     types.Currency      `json:"currencyCode"`
+	Balance          float64             `json:"balance"`
+	AvailableBalance float64             `json:"availableBalance"`
+	Rate             float64             `json:"rate"`
+	Value            float64             `json:"value"`
+}
+
+type CustomerBalancesDTO struct {
+	CustomerID       types.CustomerID    `json:"customerId"`
+	CurrencyCode     types.Currency      `json:"currencyCode"`
+	Wallets          []*WalletBalanceDTO `json:"wallets"`
+	Total            float64             `json:"total"`
+	AvailableBalance float64             `json:"availableBalance"`
+}
+
+func (cb *CustomerBalances) DTO() *CustomerBalancesDTO {
+	totalF, _ := cb.Total.Truncate(2).Float64()
+	availableF, _ := cb.Available.Truncate(2).Float64()
+
+	dto := &CustomerBalancesDTO{
+		CustomerID:       cb.CustomerID,
+		CurrencyCode:     cb.CurrencyCode,
+		Wallets:          []*WalletBalanceDTO{},
+		Total:            totalF,
+		AvailableBalance: availableF,
+	}
+	for _, wallet := range cb.Wallets {
+		balanceF, _ := wallet.Balance.Truncate(2).Float64()
+		walletAvailableF, _ := wallet.Available.Truncate(2).Float64()
+		rateF, _ := wallet.Rate.Float64()
+		valueF, _ := wallet.Value.Truncate(2).Float64()
+		dto.Wallets = append(dto.Wallets, &WalletBalanceDTO{
+			AccountNumber:    wallet.AccountNumber,
+			CurrencyCode:     wallet.CurrencyCode,
+			Balance:          balanceF,
+			AvailableBalance: walletAvailableF,
+			Rate:             rateF,
+			Value:            valueF,
+		})
+	}
+	return dto
+}
+
+// SortedWallets returns the wallets of the customer ordered by account number
+func (c *Customer) SortedWallets() []types.AccountNumber {
+	wallets := make([]types.AccountNumber, 0, len(c.Wallets))
+	for _, accountNumber := range c.Wallets {
+		wallets = append(wallets, accountNumber)
+	}
+	sort.Slice(wallets, func(i, j int) bool { return wallets[i] < wallets[j] })
+	return wallets
+}
@@ -8,25 +8,34 @@ import (
 type Deposit struct {
 	AccountNumber types.AccountNumber
 	Amount        decimal.Decimal
+	// Counterparty is the account outside of the bank the money came from or went to, if known
+	Counterparty string
+	TransactionDetails
 }
 
 type DepositDTO struct {
 	AccountNumber types.AccountNumber `json:"accountNumber"`
 	Amount        float64             `json:"amount"`
+	Counterparty  string              `json:"counterparty,omitempty"`
+	TransactionDetails
 }
 
 func (d *Deposit) DTO() *DepositDTO {
 	amountF, _ := d.Amount.Truncate(2).Float64()
 
 	return &DepositDTO{
-		AccountNumber: d.AccountNumber,
-		Amount:        amountF,
+		AccountNumber:      d.AccountNumber,
+		Amount:             amountF,
+		Counterparty:       d.Counterparty,
+		TransactionDetails: d.TransactionDetails,
 	}
 }
 
 func (dd *DepositDTO) Normal() *Deposit {
 	return &Deposit{
-		AccountNumber: dd.AccountNumber,
-		Amount:        decimal.NewFromFloat(dd.Amount),
+		AccountNumber:      dd.AccountNumber,
+		Amount:             decimal.NewFromFloat(dd.Amount),
+		Counterparty:       dd.Counterparty,
+		TransactionDetails: dd.TransactionDetails,
 	}
 }
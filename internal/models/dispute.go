@@ -0,0 +1,135 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Dispute is opened by the individual CustomerAccount on Amount of the payment PaymentID to MerchantAccount.
+// While it is open the amount is held on the merchant and credited to the customer provisionally from HouseAccount,
+// ProvisionalCreditID is the line that took the credit from the house account. Resolving the dispute either
+// charges the amount back from the merchant or releases it to the merchant and takes the provisional credit back,
+// ResolutionID is the line that took the funds from the merchant or the customer.
+// History is every state the dispute has been in, the first one is its opening.
+type Dispute struct {
+	ID                  types.DisputeID
+	PaymentID           types.TransactionID
+	CustomerAccount     types.AccountNumber
+	MerchantAccount     types.AccountNumber
+	HouseAccount        types.AccountNumber
+	Amount              decimal.Decimal
+	Reason              string
+	Status              types.DisputeStatus
+	ProvisionalCreditID types.TransactionID
+	ResolutionID        types.TransactionID
+	History             []*DisputeTransition
+	CreatedAt           time.Time
+	ResolvedAt          time.Time
+	TransactionDetails
+}
+
+// DisputeTransition is a state a dispute entered at At, TransactionID is the history line written with it
+type DisputeTransition struct {
+	Status        types.DisputeStatus
+	Note          string
+	TransactionID types.TransactionID
+	At            time.Time
+}
+
+// Copy returns a copy of the dispute that shares nothing with it
+func (d *Dispute) Copy() *Dispute {
+	dispute := *d
+	dispute.TransactionDetails = d.TransactionDetails.Copy()
+	dispute.History = nil
+	for _, transition := range d.History {
+		t := *transition
+		dispute.History = append(dispute.History, &t)
+	}
+	return &dispute
+}
+
+type DisputeDTO struct {
+	ID                  types.DisputeID         `json:"id"`
+	PaymentID           types.TransactionID     `json:"paymentId"`
+	CustomerAccount     types.AccountNumber     `json:"customerAccount"`
+	MerchantAccount     types.AccountNumber     `json:"merchantAccount"`
+	HouseAccount        types.AccountNumber     `json:"houseAccount"`
+	Amount              float64                 `json:"amount"`
+	Reason              string                  `json:"reason,omitempty"`
+	Status              types.DisputeStatus     `json:"status"`
+	ProvisionalCreditID types.TransactionID     `json:"provisionalCreditId"`
+	ResolutionID        types.TransactionID     `json:"resolutionId,omitempty"`
+	History             []*DisputeTransitionDTO `json:"history"`
+	CreatedAt           time.Time               `json:"createdAt"`
+	ResolvedAt          *time.Time              `json:"resolvedAt,omitempty"`
+	TransactionDetails
+}
+
+type DisputeTransitionDTO struct {
+	Status        types.DisputeStatus `json:"status"`
+	Note          string              `json:"note,omitempty"`
+	TransactionID types.TransactionID `json:"transactionId"`
+	At            time.Time           `json:"at"`
+}
+
+// DisputeOpenDTO is the body opening a dispute on either Amount or, with Full, what is left undisputed of the payment,
+// MerchantAccount picks the leg of a split payment
+type DisputeOpenDTO struct {
+	PaymentID       types.TransactionID `json:"paymentId"`
+	CustomerAccount types.AccountNumber `json:"customerAccount"`
+	MerchantAccount types.AccountNumber `json:"merchantAccount,omitempty"`
+	Amount          float64             `json:"amount,omitempty"`
+	Full            bool                `json:"full,omitempty"`
+	Reason          string              `json:"reason,omitempty"`
+}
+
+// DisputeResolutionDTO is the decision of an operator on an open dispute,
+// Status is either charged_back or released
+type DisputeResolutionDTO struct {
+	Status types.DisputeStatus `json:"status"`
+	Note   string              `json:"note"`
+}
+
+func (d *Dispute) DTO() *DisputeDTO {
+	amountF, _ := d.Amount.Truncate(2).Float64()
+
+	dto := &DisputeDTO{
+		ID:                  d.ID,
+		PaymentID:           d.PaymentID,
+		CustomerAccount:     d.CustomerAccount,
+		MerchantAccount:     d.MerchantAccount,
+		HouseAccount:        d.HouseAccount,
+		Amount:              amountF,
+		Reason:              d.Reason,
+		Status:              d.Status,
+		ProvisionalCreditID: d.ProvisionalCreditID,
+		ResolutionID:        d.ResolutionID,
+		History:             []*DisputeTransitionDTO{},
+		CreatedAt:           d.CreatedAt,
+		TransactionDetails:  d.TransactionDetails,
+	}
+	for _, t := range d.History {
+		dto.History = append(dto.History, &DisputeTransitionDTO{
+			Status:        t.Status,
+			Note:          t.Note,
+			TransactionID: t.TransactionID,
+			At:            t.At,
+		})
+	}
+	if !d.ResolvedAt.IsZero() {
+		resolvedAt := d.ResolvedAt
+		dto.ResolvedAt = &resolvedAt
+	}
+	return dto
+}
+
+func (do *DisputeOpenDTO) Normal() *Dispute {
+	return &Dispute{
+		PaymentID:       do.PaymentID,
+		CustomerAccount: do.CustomerAccount,
+		MerchantAccount: do.MerchantAccount,
+		Amount:          decimal.NewFromFloat(do.Amount),
+		Reason:          do.Reason,
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Escrow keeps Amount of SenderAccount in EscrowAccount until it is released to ReceiverAccount
+// or refunded to the sender. An escrow still funded at ExpiresAt is settled with OnTimeout.
+// FundingID is the history line that took the funds from the sender,
+// SettlementID the one that paid them out of the escrow account.
+type Escrow struct {
+	ID              types.EscrowID
+	SenderAccount   types.AccountNumber
+	ReceiverAccount types.AccountNumber
+	EscrowAccount   types.AccountNumber
+	Amount          decimal.Decimal
+	Status          types.EscrowStatus
+	OnTimeout       types.EscrowStatus
+	TimedOut        bool
+	FundingID       types.TransactionID
+	SettlementID    types.TransactionID
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+	SettledAt       time.Time
+	TransactionDetails
+}
+
+// Copy returns a copy of the escrow that shares nothing with it
+func (e *Escrow) Copy() *Escrow {
+	escrow := *e
+	escrow.TransactionDetails = e.TransactionDetails.Copy()
+	return &escrow
+}
+
+type EscrowDTO struct {
+	ID              types.EscrowID      `json:"id"`
+	SenderAccount   types.AccountNumber `json:"senderAccount"`
+	ReceiverAccount types.AccountNumber `json:"receiverAccount"`
+	EscrowAccount   types.AccountNumber `json:"escrowAccount"`
+	Amount          float64             `json:"amount"`
+	Status          types.EscrowStatus  `json:"status"`
+	OnTimeout       types.EscrowStatus  `json:"onTimeout"`
+	TimedOut        bool                `json:"timedOut"`
+	FundingID       types.TransactionID `json:"fundingId"`
+	SettlementID    types.TransactionID `json:"settlementId,omitempty"`
+	CreatedAt       time.Time           `json:"createdAt"`
+	ExpiresAt       time.Time           `json:"expiresAt"`
+	SettledAt       *time.Time          `json:"settledAt,omitempty"`
+	TransactionDetails
+}
+
+// EscrowFundDTO is the body funding an escrow, a zero TTLSeconds uses the default lifetime of escrows
+type EscrowFundDTO struct {
+	SenderAccount   types.AccountNumber `json:"senderAccount"`
+	ReceiverAccount types.AccountNumber `json:"receiverAccount"`
+	Amount          float64             `json:"amount"`
+	OnTimeout       types.EscrowStatus  `json:"onTimeout,omitempty"`
+	TTLSeconds      int64               `json:"ttlSeconds,omitempty"`
+	TransactionDetails
+}
+
+// EscrowSettleDTO is the body of a release or a refund, AccountNumber is the side giving the instruction
+type EscrowSettleDTO struct {
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+}
+
+func (e *Escrow) DTO() *EscrowDTO {
+	amountF, _ := e.Amount.Truncate(2).Float64()
+
+	dto := &EscrowDTO{
+		ID:                 e.ID,
+		SenderAccount:      e.SenderAccount,
+		ReceiverAccount:    e.ReceiverAccount,
+		EscrowAccount:      e.EscrowAccount,
+		Amount:             amountF,
+		Status:             e.Status,
+		OnTimeout:          e.OnTimeout,
+		TimedOut:           e.TimedOut,
+		FundingID:          e.FundingID,
+		SettlementID:       e.SettlementID,
+		CreatedAt:          e.CreatedAt,
+		ExpiresAt:          e.ExpiresAt,
+		TransactionDetails: e.TransactionDetails,
+	}
+	if !e.SettledAt.IsZero() {
+		settledAt := e.SettledAt
+		dto.SettledAt = &settledAt
+	}
+	return dto
+}
+
+func (ed *EscrowFundDTO) Normal() *Escrow {
+	return &Escrow{
+		SenderAccount:      ed.SenderAccount,
+		ReceiverAccount:    ed.ReceiverAccount,
+		Amount:             decimal.NewFromFloat(ed.Amount),
+		OnTimeout:          ed.OnTimeout,
+		TransactionDetails: ed.TransactionDetails,
+	}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Event is an immutable change of an account, accounts are the result of applying their events in order.
+// Amount is added to the balance and Held to the held part of it, except for AccountOpened
+// where Amount is the opening balance and OverdraftLimitSet where it is the new limit.
+// CurrencyCode, AccountType and CustomerID are only set for AccountOpened, OwnerName, Contact and Metadata
+// for AccountOpened and AccountUpdated where they are the whole new profile.
+// RecordSaved events change no account, they carry the latest version of a record of the Record kind as Data.
+type Event struct {
+	Sequence      types.EventSequence
+	Type          types.EventType
+	AccountNumber types.AccountNumber
+	Amount        decimal.Decimal
+	Held          decimal.Decimal
+	CurrencyCode  types.Currency
+	OwnerName     string
+	AccountType   types.AccountType
+	CustomerID    types.CustomerID
+	Contact       ContactInfo
+	Metadata      map[string]string
+	Record        types.RecordKind `json:",omitempty"`
+	Data          json.RawMessage  `json:",omitempty"`
+	At            time.Time
+}
+
+type EventDTO struct {
+	Sequence      types.EventSequence `json:"sequence"`
+	Type          types.EventType     `json:"type"`
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+	Amount        float64             `json:"amount,omitempty"`
+	Held          float64             `json:"held,omitempty"`
+	CurrencyCode  types.Currency      `json:"currencyCode,omitempty"`
+	OwnerName     string              `json:"ownerName,omitempty"`
+	AccountType   types.AccountType   `json:"accountType,omitempty"`
+	CustomerID    types.CustomerID    `json:"customerId,omitempty"`
+	Contact       *ContactInfo        `json:"contact,omitempty"`
+	Metadata      map[string]string   `json:"metadata,omitempty"`
+	Record        types.RecordKind    `json:"record,omitempty"`
+	Data          json.RawMessage     `json:"data,omitempty"`
+	At            time.Time           `json:"at"`
+}
+
+func (e *Event) DTO() *EventDTO {
+	amountF, _ := e.Amount.Float64()
+	heldF, _ := e.Held.Float64()
+
+	dto := &EventDTO{
+		Sequence:      e.Sequence,
+		Type:          e.Type,
+		AccountNumber: e.AccountNumber,
+		Amount:        amountF,
+		Held:          heldF,
+		CurrencyCode:  e.CurrencyCode,
+		OwnerName:     e.OwnerName,
+		AccountType:   e.AccountType,
+		CustomerID:    e.CustomerID,
+		Metadata:      CopyMetadata(e.Metadata),
+		Record:        e.Record,
+		Data:          e.Data,
+		At:            e.At,
+	}
+	if e.Contact != (ContactInfo{}) {
+		contact := e.Contact
+		dto.Contact = &contact
+	}
+	return dto
+}
+
+// ProjectionDiff is a field of an account whose value in the live projection
+// differs from its value in a projection rebuilt from the events
+type ProjectionDiff struct {
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+	Field         string              `json:"field"`
+	Live          string              `json:"live"`
+	Rebuilt       string              `json:"rebuilt"`
+}
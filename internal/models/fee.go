@@ -0,0 +1,114 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// FeeSchedule charges a fee on the transactions of one type to the account on the Payer side,
+// it applies when the payer has the AccountType and the transaction is in the Currency.
+// Percentage is in percent, Tiers are tried in order and the first tier whose UpTo
+// is not less than the amount replaces Flat and Percentage. Zero Min and Max are not applied.
+type FeeSchedule struct {
+	Name            string
+	TransactionType types.TransactionType
+	Payer           types.FeePayer
+	AccountType     types.AccountType
+	Currency        types.Currency
+	Flat            decimal.Decimal
+	Percentage      decimal.Decimal
+	Tiers           []*FeeTier
+	Min             decimal.Decimal
+	Max             decimal.Decimal
+}
+
+// FeeTier is a band of a tiered schedule, a zero UpTo is unbounded
+type FeeTier struct {
+	UpTo       decimal.Decimal
+	Flat       decimal.Decimal
+	Percentage decimal.Decimal
+}
+
+type FeeScheduleDTO struct {
+	Name            string                `json:"name"`
+	TransactionType types.TransactionType `json:"transactionType"`
+	Payer           types.FeePayer        `json:"payer"`
+	AccountType     types.AccountType     `json:"accountType,omitempty"`
+	Currency        types.Currency        `json:"currency,omitempty"`
+	Flat            float64               `json:"flat,omitempty"`
+	Percentage      float64               `json:"percentage,omitempty"`
+	Tiers           []*FeeTierDTO         `json:"tiers,omitempty"`
+	Min             float64               `json:"min,omitempty"`
+	Max             float64               `json:"max,omitempty"`
+}
+
+type FeeTierDTO struct {
+	UpTo       float64 `json:"upTo,omitempty"`
+	Flat       float64 `json:"flat,omitempty"`
+	Percentage float64 `json:"percentage,omitempty"`
+}
+
+func (fs *FeeSchedule) DTO() *FeeScheduleDTO {
+	var tiers []*FeeTierDTO
+	for _, t := range fs.Tiers {
+		upToF, _ := t.UpTo.Float64()
+		flatF, _ := t.Flat.Float64()
+		percentageF, _ := t.Percentage.Float64()
+		tiers = append(tiers, &FeeTierDTO{
+			UpTo:       upToF,
+			Flat:       flatF,
+			Percentage: percentageF,
+		})
+	}
+
+	flatF, _ := fs.Flat.Float64()
+	percentageF, _ := fs.Percentage.Float64()
+	minF, _ := fs.Min.Float64()
+	maxF, _ := fs.Max.Float64()
+
+	return &FeeScheduleDTO{
+		Name:            fs.Name,
+		TransactionType: fs.TransactionType,
+		Payer:           fs.Payer,
+		AccountType:     fs.AccountType,
+		Currency:        fs.Currency,
+		Flat:            flatF,
+		Percentage:      percentageF,
+		Tiers:           tiers,
+		Min:             minF,
+		Max:             maxF,
+	}
+}
+
+func (fsd *FeeScheduleDTO) Normal() *FeeSchedule {
+	var tiers []*FeeTier
+	for _, t := range fsd.Tiers {
+		tiers = append(tiers, &FeeTier{
+			UpTo:       decimal.NewFromFloat(t.UpTo),
+			Flat:       decimal.NewFromFloat(t.Flat),
+			Percentage: decimal.NewFromFloat(t.Percentage),
+		})
+	}
+
+	return &FeeSchedule{
+		Name:            fsd.Name,
+		TransactionType: fsd.TransactionType,
+		Payer:           fsd.Payer,
+		AccountType:     fsd.AccountType,
+		Currency:        fsd.Currency,
+		Flat:            decimal.NewFromFloat(fsd.Flat),
+		Percentage:      decimal.NewFromFloat(fsd.Percentage),
+		Tiers:           tiers,
+		Min:             decimal.NewFromFloat(fsd.Min),
+		Max:             decimal.NewFromFloat(fsd.Max),
+	}
+}
+
+// FeeCharge is a fee computed for a transaction, it moves Amount
+// from the Payer account to the HouseAccount of the currency
+type FeeCharge struct {
+	Schedule     string
+	Payer        types.AccountNumber
+	HouseAccount types.AccountNumber
+	Amount       decimal.Decimal
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// FraudRule fires for the outgoing transactions of one type, or of both payments and withdrawals when
+// TransactionType is empty, of accounts of the AccountType in the Currency. Empty filters match every account.
+//   - velocity fires when the account made more than MaxCount transactions or more than MaxVolume
+//     in the last Window, the scored transaction included.
+//   - unusual_amount fires when the amount is more than Multiplier times the average of the transactions
+//     the account made in the last Window, or ever for a zero Window, once there are at least MinHistory of them.
+//   - new_account fires when an account younger than AccountAge moves at least MinAmount.
+//
+// Zero values mean the check is not applied.
+type FraudRule struct {
+	Name            string
+	Kind            types.FraudRuleKind
+	TransactionType types.TransactionType
+	AccountType     types.AccountType
+	Currency        types.Currency
+	Action          types.FraudAction
+	Window          time.Duration
+	MaxCount        int
+	MaxVolume       decimal.Decimal
+	Multiplier      decimal.Decimal
+	MinHistory      int
+	AccountAge      time.Duration
+	MinAmount       decimal.Decimal
+}
+
+type FraudRuleDTO struct {
+	Name              string                `json:"name"`
+	Kind              types.FraudRuleKind   `json:"kind"`
+	TransactionType   types.TransactionType `json:"transactionType,omitempty"`
+	AccountType       types.AccountType     `json:"accountType,omitempty"`
+	Currency          types.Currency        `json:"currency,omitempty"`
+	Action            types.FraudAction     `json:"action"`
+	WindowSeconds     int64                 `json:"windowSeconds,omitempty"`
+	MaxCount          int                   `json:"maxCount,omitempty"`
+	MaxVolume         float64               `json:"maxVolume,omitempty"`
+	Multiplier        float64               `json:"multiplier,omitempty"`
+	MinHistory        int                   `json:"minHistory,omitempty"`
+	AccountAgeSeconds int64                 `json:"accountAgeSeconds,omitempty"`
+	MinAmount         float64               `json:"minAmount,omitempty"`
+}
+
+func (fd *FraudRuleDTO) Normal() *FraudRule {
+	return &FraudRule{
+		Name:            fd.Name,
+		Kind:            fd.Kind,
+		TransactionType: fd.TransactionType,
+		AccountType:     fd.AccountType,
+		Currency:        fd.Currency,
+		Action:          fd.Action,
+		Window:          time.Duration(fd.WindowSeconds) * time.Second,
+		MaxCount:        fd.MaxCount,
+		MaxVolume:       decimal.NewFromFloat(fd.MaxVolume),
+		Multiplier:      decimal.NewFromFloat(fd.Multiplier),
+		MinHistory:      fd.MinHistory,
+		AccountAge:      time.Duration(fd.AccountAgeSeconds) * time.Second,
+		MinAmount:       decimal.NewFromFloat(fd.MinAmount),
+	}
+}
+
+// FraudHit is a rule that fired for a transaction, Detail tells what it saw
+type FraudHit struct {
+	Rule   string
+	Kind   types.FraudRuleKind
+	Action types.FraudAction
+	Detail string
+}
+
+// FraudAlert is a transaction some fraud rules fired for and the Action taken on it, the transaction is kept in
+// Payment, SplitPayment, Withdraw or, with the lifetime it was asked for in TTL, Hold or Escrow. A pending alert
+// holds its amount on the account until the decision, TransactionID is the transaction made, HoldID the hold placed
+// or EscrowID the escrow funded once the alert is approved and TransactionError why it could not be made.
+type FraudAlert struct {
+	ID               types.FraudAlertID
+	AccountNumber    types.AccountNumber
+	TransactionType  types.TransactionType
+	Amount           decimal.Decimal
+	Hits             []*FraudHit
+	Action           types.FraudAction
+	Status           types.FraudAlertStatus
+	Payment          *Payment
+	SplitPayment     *SplitPayment
+	Withdraw         *Withdraw
+	Hold             *Hold
+	Escrow           *Escrow
+	TTL              time.Duration
+	TransactionID    types.TransactionID
+	HoldID           types.HoldID
+	EscrowID         types.EscrowID
+	TransactionError string
+	Reviewer         string
+	Note             string
+	CreatedAt        time.Time
+	DecidedAt        time.Time
+}
+
+// FraudDecision approves or rejects a pending alert
+type FraudDecision struct {
+	Status   types.FraudAlertStatus
+	Reviewer string
+	Note     string
+}
+
+type FraudHitDTO struct {
+	Rule   string              `json:"rule"`
+	Kind   types.FraudRuleKind `json:"kind"`
+	Action types.FraudAction   `json:"action"`
+	Detail string              `json:"detail"`
+}
+
+type FraudAlertDTO struct {
+	ID               types.FraudAlertID     `json:"id"`
+	AccountNumber    types.AccountNumber    `json:"accountNumber"`
+	TransactionType  types.TransactionType  `json:"transactionType"`
+	Amount           float64                `json:"amount"`
+	Hits             []*FraudHitDTO         `json:"hits"`
+	Action           types.FraudAction      `json:"action"`
+	Status           types.FraudAlertStatus `json:"status"`
+	Payment          *PaymentDTO            `json:"payment,omitempty"`
+	SplitPayment     *SplitPaymentDTO       `json:"splitPayment,omitempty"`
+	Withdraw         *WithdrawDTO           `json:"withdraw,omitempty"`
+	Hold             *HoldDTO               `json:"hold,omitempty"`
+	Escrow           *EscrowDTO             `json:"escrow,omitempty"`
+	TransactionID    types.TransactionID    `json:"transactionId,omitempty"`
+	HoldID           types.HoldID           `json:"holdId,omitempty"`
+	EscrowID         types.EscrowID         `json:"escrowId,omitempty"`
+	TransactionError string                 `json:"transactionError,omitempty"`
+	Reviewer         string                 `json:"reviewer,omitempty"`
+	Note             string                 `json:"note,omitempty"`
+	CreatedAt        time.Time              `json:"createdAt"`
+	DecidedAt        *time.Time             `json:"decidedAt,omitempty"`
+}
+
+type FraudDecisionDTO struct {
+	Status   types.FraudAlertStatus `json:"status"`
+	Reviewer string                 `json:"reviewer"`
+	Note     string                 `json:"note"`
+}
+
+func (fa *FraudAlert) DTO() *FraudAlertDTO {
+	amountF, _ := fa.Amount.Truncate(2).Float64()
+
+	dto := &FraudAlertDTO{
+		ID:               fa.ID,
+		AccountNumber:    fa.AccountNumber,
+		TransactionType:  fa.TransactionType,
+		Amount:           amountF,
+		Hits:             []*FraudHitDTO{},
+		Action:           fa.Action,
+		Status:           fa.Status,
+		TransactionID:    fa.TransactionID,
+		HoldID:           fa.HoldID,
+		EscrowID:         fa.EscrowID,
+		TransactionError: fa.TransactionError,
+		Reviewer:         fa.Reviewer,
+		Note:             fa.Note,
+		CreatedAt:        fa.CreatedAt,
+	}
+	for _, hit := range fa.Hits {
+		dto.Hits = append(dto.Hits, &FraudHitDTO{
+			Rule:   hit.Rule,
+			Kind:   hit.Kind,
+			Action: hit.Action,
+			Detail: hit.Detail,
+		})
+	}
+	if fa.Payment != nil {
+		dto.Payment = fa.Payment.DTO()
+	}
+	if fa.SplitPayment != nil {
+		dto.SplitPayment = fa.SplitPayment.DTO()
+	}
+	if fa.Withdraw != nil {
+		dto.Withdraw = fa.Withdraw.DTO()
+	}
+	if fa.Hold != nil {
+		dto.Hold = fa.Hold.DTO()
+	}
+	if fa.Escrow != nil {
+		dto.Escrow = fa.Escrow.DTO()
+	}
+	if !fa.DecidedAt.IsZero() {
+		decidedAt := fa.DecidedAt
+		dto.DecidedAt = &decidedAt
+	}
+	return dto
+}
+
+func (fd *FraudDecisionDTO) Normal() *FraudDecision {
+	return &FraudDecision{
+		Status:   fd.Status,
+		Reviewer: fd.Reviewer,
+		Note:     fd.Note,
+	}
+}
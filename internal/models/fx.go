@@ -0,0 +1,37 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// ExchangeRate is how many units of To one unit of From buys
+type ExchangeRate struct {
+	From types.Currency
+	To   types.Currency
+	Rate decimal.Decimal
+}
+
+type ExchangeRateDTO struct {
+	From types.Currency `json:"from"`
+	To   types.Currency `json:"to"`
+	Rate float64        `json:"rate"`
+}
+
+func (er *ExchangeRate) DTO() *ExchangeRateDTO {
+	rateF, _ := er.Rate.Float64()
+
+	return &ExchangeRateDTO{
+		From: er.From,
+		To:   er.To,
+		Rate: rateF,
+	}
+}
+
+func (erd *ExchangeRateDTO) Normal() *ExchangeRate {
+	return &ExchangeRate{
+		From: erd.From,
+		To:   erd.To,
+		Rate: decimal.NewFromFloat(erd.Rate),
+	}
+}
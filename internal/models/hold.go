@@ -0,0 +1,78 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Hold reserves Amount on an individual account for a later payment to ReceiverAccount.
+// Captured is the part of the hold that was paid, PaymentID the payment it was paid with.
+type Hold struct {
+	ID              types.HoldID
+	AccountNumber   types.AccountNumber
+	ReceiverAccount types.AccountNumber
+	Amount          decimal.Decimal
+	Captured        decimal.Decimal
+	Status          types.HoldStatus
+	PaymentID       types.TransactionID
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+// Copy returns a copy of the hold
+func (h *Hold) Copy() *Hold {
+	hold := *h
+	return &hold
+}
+
+type HoldDTO struct {
+	ID              types.HoldID        `json:"id"`
+	AccountNumber   types.AccountNumber `json:"accountNumber"`
+	ReceiverAccount types.AccountNumber `json:"receiverAccount"`
+	Amount          float64             `json:"amount"`
+	Captured        float64             `json:"captured"`
+	Status          types.HoldStatus    `json:"status"`
+	PaymentID       types.TransactionID `json:"paymentId,omitempty"`
+	CreatedAt       time.Time           `json:"createdAt"`
+	ExpiresAt       time.Time           `json:"expiresAt"`
+}
+
+// HoldPlaceDTO is the body placing a hold, a zero TTLSeconds uses the default lifetime of holds
+type HoldPlaceDTO struct {
+	AccountNumber   types.AccountNumber `json:"accountNumber"`
+	ReceiverAccount types.AccountNumber `json:"receiverAccount"`
+	Amount          float64             `json:"amount"`
+	TTLSeconds      int64               `json:"ttlSeconds,omitempty"`
+}
+
+// HoldCaptureDTO is the body of a capture, it captures either Amount or, with Full, the whole hold
+type HoldCaptureDTO struct {
+	Amount float64 `json:"amount,omitempty"`
+	Full   bool    `json:"full,omitempty"`
+}
+
+func (h *Hold) DTO() *HoldDTO {
+	amountF, _ := h.Amount.Truncate(2).Float64()
+	capturedF, _ := h.Captured.Truncate(2).Float64()
+
+	return &HoldDTO{
+		ID:              h.ID,
+		AccountNumber:   h.AccountNumber,
+		ReceiverAccount: h.ReceiverAccount,
+		Amount:          amountF,
+		Captured:        capturedF,
+		Status:          h.Status,
+		PaymentID:       h.PaymentID,
+		CreatedAt:       h.CreatedAt,
+		ExpiresAt:       h.ExpiresAt,
+	}
+}
+
+func (hp *HoldPlaceDTO) Normal() *Hold {
+	return &Hold{
+		AccountNumber:   hp.AccountNumber,
+		ReceiverAccount: hp.ReceiverAccount,
+		Amount:          decimal.NewFromFloat(hp.Amount),
+	}
+}
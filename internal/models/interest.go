@@ -0,0 +1,82 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// InterestRate is the annual rate in percent paid on the positive balances of individual accounts in Currency
+type InterestRate struct {
+	Currency   types.Currency
+	AnnualRate decimal.Decimal
+	DayCount   types.DayCount
+}
+
+type InterestRateDTO struct {
+	Currency   types.Currency `json:"currency"`
+	AnnualRate float64        `json:"annualRate"`
+	DayCount   types.DayCount `json:"dayCount"`
+}
+
+func (ir *InterestRate) DTO() *InterestRateDTO {
+	rateF, _ := ir.AnnualRate.Float64()
+
+	return &InterestRateDTO{
+		Currency:   ir.Currency,
+		AnnualRate: rateF,
+		DayCount:   ir.DayCount,
+	}
+}
+
+func (ird *InterestRateDTO) Normal() *InterestRate {
+	return &InterestRate{
+		Currency:   ird.Currency,
+		AnnualRate: decimal.NewFromFloat(ird.AnnualRate),
+		DayCount:   ird.DayCount,
+	}
+}
+
+// InterestAccrual is the interest an account earned since its last posting.
+// Accrued is kept unrounded, only whole cents are posted and the rest is carried over.
+// Rate and AccruedUntil are only filled in when the accrual is read.
+type InterestAccrual struct {
+	AccountNumber types.AccountNumber
+	Accrued       decimal.Decimal
+	Posted        decimal.Decimal
+	LastPostedAt  time.Time
+	Rate          *InterestRate
+	AccruedUntil  time.Time
+}
+
+type InterestAccrualDTO struct {
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+	Rate          *InterestRateDTO    `json:"rate,omitempty"`
+	Accrued       float64             `json:"accrued"`
+	Posted        float64             `json:"posted"`
+	AccruedUntil  *time.Time          `json:"accruedUntil,omitempty"`
+	LastPostedAt  *time.Time          `json:"lastPostedAt,omitempty"`
+}
+
+func (ia *InterestAccrual) DTO() *InterestAccrualDTO {
+	accruedF, _ := ia.Accrued.Truncate(2).Float64()
+	postedF, _ := ia.Posted.Truncate(2).Float64()
+
+	accrualDTO := &InterestAccrualDTO{
+		AccountNumber: ia.AccountNumber,
+		Accrued:       accruedF,
+		Posted:        postedF,
+	}
+	if ia.Rate != nil {
+		accrualDTO.Rate = ia.Rate.DTO()
+	}
+	if !ia.AccruedUntil.IsZero() {
+		accruedUntil := ia.AccruedUntil
+		accrualDTO.AccruedUntil = &accruedUntil
+	}
+	if !ia.LastPostedAt.IsZero() {
+		lastPostedAt := ia.LastPostedAt
+		accrualDTO.LastPostedAt = &lastPostedAt
+	}
+	return accrualDTO
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Invoice requests Amount from PayerAccount on behalf of the corporate IssuerAccount.
+// It can be paid in parts until DueDate, Paid is the part paid so far with the payments of PaymentIDs.
+// ClosedAt is when the invoice stopped being open.
+type Invoice struct {
+	ID            types.InvoiceID
+	IssuerAccount types.AccountNumber
+	PayerAccount  types.AccountNumber
+	CurrencyCode  types.Currency
+	Amount        decimal.Decimal
+	Paid          decimal.Decimal
+	DueDate       time.Time
+	Status        types.InvoiceStatus
+	PaymentIDs    []types.TransactionID
+	CreatedAt     time.Time
+	ClosedAt      time.Time
+	TransactionDetails
+}
+
+// Copy returns a copy of the invoice that shares nothing with it
+func (i *Invoice) Copy() *Invoice {
+	invoice := *i
+	invoice.TransactionDetails = i.TransactionDetails.Copy()
+	invoice.PaymentIDs = append([]types.TransactionID(nil), i.PaymentIDs...)
+	return &invoice
+}
+
+// Unpaid returns the part of the invoice that is still to be paid
+func (i *Invoice) Unpaid() decimal.Decimal {
+	return i.Amount.Sub(i.Paid)
+}
+
+type InvoiceDTO struct {
+	ID            types.InvoiceID       `json:"id"`
+	IssuerAccount types.AccountNumber   `json:"issuerAccount"`
+	PayerAccount  types.AccountNumber   `json:"payerAccount"`
+	CurrencyCode  types.Currency        `json:"currencyCode"`
+	Amount        float64               `json:"amount"`
+	Paid          float64               `json:"paid"`
+	Unpaid        float64               `json:"unpaid"`
+	DueDate       time.Time             `json:"dueDate"`
+	Status        types.InvoiceStatus   `json:"status"`
+	PaymentIDs    []types.TransactionID `json:"paymentIds,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	ClosedAt      *time.Time            `json:"closedAt,omitempty"`
+	TransactionDetails
+}
+
+// InvoicePaymentDTO is the body of a payment of an invoice, it pays either Amount or, with Full, the whole unpaid part
+type InvoicePaymentDTO struct {
+	Amount float64 `json:"amount,omitempty"`
+	Full   bool    `json:"full,omitempty"`
+}
+
+func (i *Invoice) DTO() *InvoiceDTO {
+	amountF, _ := i.Amount.Truncate(2).Float64()
+	paidF, _ := i.Paid.Truncate(2).Float64()
+	unpaidF, _ := i.Unpaid().Truncate(2).Float64()
+
+	dto := &InvoiceDTO{
+		ID:                 i.ID,
+		IssuerAccount:      i.IssuerAccount,
+		PayerAccount:       i.PayerAccount,
+		CurrencyCode:       i.CurrencyCode,
+		Amount:             amountF,
+		Paid:               paidF,
+		Unpaid:             unpaidF,
+		DueDate:            i.DueDate,
+		Status:             i.Status,
+		PaymentIDs:         i.PaymentIDs,
+		CreatedAt:          i.CreatedAt,
+		TransactionDetails: i.TransactionDetails,
+	}
+	if !i.ClosedAt.IsZero() {
+		closedAt := i.ClosedAt
+		dto.ClosedAt = &closedAt
+	}
+	return dto
+}
+
+func (id *InvoiceDTO) Normal() *Invoice {
+	return &Invoice{
+		ID:                 id.ID,
+		IssuerAccount:      id.IssuerAccount,
+		PayerAccount:       id.PayerAccount,
+		CurrencyCode:       id.CurrencyCode,
+		Amount:             decimal.NewFromFloat(id.Amount),
+		Paid:               decimal.NewFromFloat(id.Paid),
+		DueDate:            id.DueDate,
+		Status:             id.Status,
+		PaymentIDs:         id.PaymentIDs,
+		CreatedAt:          id.CreatedAt,
+		TransactionDetails: id.TransactionDetails,
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// Limit caps the transactions of one type for accounts of an account type and currency.
+// When AccountNumber is set the limit overrides the rule of that single account.
+// Zero values mean the cap is not applied.
+type Limit struct {
+	AccountNumber   types.AccountNumber
+	TransactionType types.TransactionType
+	AccountType     types.AccountType
+	Currency        types.Currency
+	PerTransaction  decimal.Decimal
+	DailyVolume     decimal.Decimal
+	DailyCount      int
+	MonthlyVolume   decimal.Decimal
+	MonthlyCount    int
+}
+
+type LimitDTO struct {
+	AccountNumber   types.AccountNumber   `json:"accountNumber,omitempty"`
+	TransactionType types.TransactionType `json:"transactionType"`
+	AccountType     types.AccountType     `json:"accountType,omitempty"`
+	Currency        types.Currency        `json:"currency,omitempty"`
+	PerTransaction  float64               `json:"perTransaction,omitempty"`
+	DailyVolume     float64               `json:"dailyVolume,omitempty"`
+	DailyCount      int                   `json:"dailyCount,omitempty"`
+	MonthlyVolume   float64               `json:"monthlyVolume,omitempty"`
+	MonthlyCount    int                   `json:"monthlyCount,omitempty"`
+}
+
+func (l *Limit) DTO() *LimitDTO {
+	perTransactionF, _ := l.PerTransaction.Truncate(2).Float64()
+	dailyVolumeF, _ := l.DailyVolume.Truncate(2).Float64()
+	monthlyVolumeF, _ := l.MonthlyVolume.Truncate(2).Float64()
+
+	return &LimitDTO{
+		AccountNumber:   l.AccountNumber,
+		TransactionType: l.TransactionType,
+		AccountType:     l.AccountType,
+		Currency:        l.Currency,
+		PerTransaction:  perTransactionF,
+		DailyVolume:     dailyVolumeF,
+		DailyCount:      l.DailyCount,
+		MonthlyVolume:   monthlyVolumeF,
+		MonthlyCount:    l.MonthlyCount,
+	}
+}
+
+func (ld *LimitDTO) Normal() *Limit {
+	return &Limit{
+		AccountNumber:   ld.AccountNumber,
+		TransactionType: ld.TransactionType,
+		AccountType:     ld.AccountType,
+		Currency:        ld.Currency,
+		PerTransaction:  decimal.NewFromFloat(ld.PerTransaction),
+		DailyVolume:     decimal.NewFromFloat(ld.DailyVolume),
+		DailyCount:      ld.DailyCount,
+		MonthlyVolume:   decimal.NewFromFloat(ld.MonthlyVolume),
+		MonthlyCount:    ld.MonthlyCount,
+	}
+}
+
+// Allowance is what is left of a limit for an account at a moment.
+// Nil values mean the cap is not applied.
+type Allowance struct {
+	TransactionType        types.TransactionType
+	PerTransaction         *decimal.Decimal
+	DailyVolumeRemaining   *decimal.Decimal
+	DailyCountRemaining    *int
+	MonthlyVolumeRemaining *decimal.Decimal
+	MonthlyCountRemaining  *int
+}
+
+type AllowanceDTO struct {
+	TransactionType        types.TransactionType `json:"transactionType"`
+	PerTransaction         *float64              `json:"perTransaction"`
+	DailyVolumeRemaining   *float64              `json:"dailyVolumeRemaining"`
+	DailyCountRemaining    *int                  `json:"dailyCountRemaining"`
+	MonthlyVolumeRemaining *float64              `json:"monthlyVolumeRemaining"`
+	MonthlyCountRemaining  *int                  `json:"monthlyCountRemaining"`
+}
+
+func (a *Allowance) DTO() *AllowanceDTO {
+	return &AllowanceDTO{
+		TransactionType:        a.TransactionType,
+		PerTransaction:         decimalPtrToFloatPtr(a.PerTransaction),
+		DailyVolumeRemaining:   decimalPtrToFloatPtr(a.DailyVolumeRemaining),
+		DailyCountRemaining:    a.DailyCountRemaining,
+		MonthlyVolumeRemaining: decimalPtrToFloatPtr(a.MonthlyVolumeRemaining),
+		MonthlyCountRemaining:  a.MonthlyCountRemaining,
+	}
+}
+
+func decimalPtrToFloatPtr(d *decimal.Decimal) *float64 {
+	if d == nil {
+		return nil
+	}
+	f, _ := d.Truncate(2).Float64()
+	return &f
+}
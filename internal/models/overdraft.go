@@ -0,0 +1,53 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Overdraft describes the overdraft facility of an account.
+// Used is how far the balance is below zero, AnnualRate the interest charged on it in percent.
+type Overdraft struct {
+	AccountNumber  types.AccountNumber
+	Limit          decimal.Decimal
+	Used           decimal.Decimal
+	AnnualRate     decimal.Decimal
+	OverdrawnSince time.Time
+	DaysOverdrawn  int
+}
+
+type OverdraftDTO struct {
+	AccountNumber  types.AccountNumber `json:"accountNumber"`
+	Limit          float64             `json:"limit"`
+	Used           float64             `json:"used"`
+	AnnualRate     float64             `json:"annualRate"`
+	OverdrawnSince *time.Time          `json:"overdrawnSince,omitempty"`
+	DaysOverdrawn  int                 `json:"daysOverdrawn"`
+}
+
+func (o *Overdraft) DTO() *OverdraftDTO {
+	limitF, _ := o.Limit.Truncate(2).Float64()
+	usedF, _ := o.Used.Truncate(2).Float64()
+	rateF, _ := o.AnnualRate.Float64()
+
+	overdraftDTO := &OverdraftDTO{
+		AccountNumber: o.AccountNumber,
+		Limit:         limitF,
+		Used:          usedF,
+		AnnualRate:    rateF,
+		DaysOverdrawn: o.DaysOverdrawn,
+	}
+	if !o.OverdrawnSince.IsZero() {
+		overdrawnSince := o.OverdrawnSince
+		overdraftDTO.OverdrawnSince = &overdrawnSince
+	}
+	return overdraftDTO
+}
+
+func (od *OverdraftDTO) Normal() *Overdraft {
+	return &Overdraft{
+		AccountNumber: od.AccountNumber,
+		Limit:         decimal.NewFromFloat(od.Limit),
+	}
+}
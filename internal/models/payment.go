@@ -9,28 +9,32 @@ type Payment struct {
 	SenderAccount   types.AccountNumber
 	ReceiverAccount types.AccountNumber
 	Amount          decimal.Decimal
+	TransactionDetails
 }
 
 type PaymentDTO struct {
 	SenderAccount   types.AccountNumber `json:"senderAccount"`
 	ReceiverAccount types.AccountNumber `json:"receiverAccount"`
 	Amount          float64             `json:"amount"`
+	TransactionDetails
 }
 
 func (p *Payment) DTO() *PaymentDTO {
 	amountF, _ := p.Amount.Truncate(2).Float64()
 
 	return &PaymentDTO{
-		SenderAccount:   p.SenderAccount,
-		ReceiverAccount: p.ReceiverAccount,
-		Amount:          amountF,
+		SenderAccount:      p.SenderAccount,
+		ReceiverAccount:    p.ReceiverAccount,
+		Amount:             amountF,
+		TransactionDetails: p.TransactionDetails,
 	}
 }
 
 func (pd *PaymentDTO) Normal() *Payment {
 	return &Payment{
-		SenderAccount:   pd.SenderAccount,
-		ReceiverAccount: pd.ReceiverAccount,
-		Amount:          decimal.NewFromFloat(pd.Amount),
+		SenderAccount:      pd.SenderAccount,
+		ReceiverAccount:    pd.ReceiverAccount,
+		Amount:             decimal.NewFromFloat(pd.Amount),
+		TransactionDetails: pd.TransactionDetails,
 	}
 }
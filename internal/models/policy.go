@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// PolicyRule allows one combination of transaction type, account types and currency.
+// For deposits and withdrawals the account is matched against SenderType
+// and ReceiverType must be empty. Empty fields match anything.
+type PolicyRule struct {
+	Name            string                `json:"name"`
+	TransactionType types.TransactionType `json:"transactionType"`
+	SenderType      types.AccountType     `json:"senderType,omitempty"`
+	ReceiverType    types.AccountType     `json:"receiverType,omitempty"`
+	Currency        types.Currency        `json:"currency,omitempty"`
+}
+
+// PolicyTrace records why a rule did or did not match a transaction
+type PolicyTrace struct {
+	Rule    string `json:"rule"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Discrepancy is an account whose balance is not the sum of its history.
+// Difference is Balance minus Computed, AdjustmentID is the corrective line written for it if any.
+type Discrepancy struct {
+	AccountNumber types.AccountNumber
+	CurrencyCode  types.Currency
+	Balance       decimal.Decimal
+	Computed      decimal.Decimal
+	Difference    decimal.Decimal
+	AdjustmentID  types.TransactionID
+}
+
+type DiscrepancyDTO struct {
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+	CurrencyCode  types.Currency      `json:"currencyCode"`
+	Balance       float64             `json:"balance"`
+	Computed      float64             `json:"computed"`
+	Difference    float64             `json:"difference"`
+	AdjustmentID  types.TransactionID `json:"adjustmentId,omitempty"`
+}
+
+func (d *Discrepancy) DTO() *DiscrepancyDTO {
+	balanceF, _ := d.Balance.Float64()
+	computedF, _ := d.Computed.Float64()
+	differenceF, _ := d.Difference.Float64()
+
+	return &DiscrepancyDTO{
+		AccountNumber: d.AccountNumber,
+		CurrencyCode:  d.CurrencyCode,
+		Balance:       balanceF,
+		Computed:      computedF,
+		Difference:    differenceF,
+		AdjustmentID:  d.AdjustmentID,
+	}
+}
+
+// CurrencyDiscrepancy sums the discrepancies of the accounts in a currency
+type CurrencyDiscrepancy struct {
+	CurrencyCode types.Currency
+	Accounts     int
+	Difference   decimal.Decimal
+}
+
+type CurrencyDiscrepancyDTO struct {
+	CurrencyCode types.Currency `json:"currencyCode"`
+	Accounts     int            `json:"accounts"`
+	Difference   float64        `json:"difference"`
+}
+
+func (cd *CurrencyDiscrepancy) DTO() *CurrencyDiscrepancyDTO {
+	differenceF, _ := cd.Difference.Float64()
+
+	return &CurrencyDiscrepancyDTO{
+		CurrencyCode: cd.CurrencyCode,
+		Accounts:     cd.Accounts,
+		Difference:   differenceF,
+	}
+}
+
+// ReconciliationReport is the result of comparing the balance of every account with its history at At
+type ReconciliationReport struct {
+	At            time.Time
+	Accounts      int
+	Corrected     bool
+	Discrepancies []*Discrepancy
+	Currencies    []*CurrencyDiscrepancy
+}
+
+type ReconciliationReportDTO struct {
+	At            time.Time                 `json:"at"`
+	Accounts      int                       `json:"accounts"`
+	Corrected     bool                      `json:"corrected"`
+	Discrepancies []*DiscrepancyDTO         `json:"discrepancies"`
+	Currencies    []*CurrencyDiscrepancyDTO `json:"currencies"`
+}
+
+func (rr *ReconciliationReport) DTO() *ReconciliationReportDTO {
+	dto := &ReconciliationReportDTO{
+		At:            rr.At,
+		Accounts:      rr.Accounts,
+		Corrected:     rr.Corrected,
+		Discrepancies: []*DiscrepancyDTO{},
+		Currencies:    []*CurrencyDiscrepancyDTO{},
+	}
+	for _, d := range rr.Discrepancies {
+		dto.Discrepancies = append(dto.Discrepancies, d.DTO())
+	}
+	for _, cd := range rr.Currencies {
+		dto.Currencies = append(dto.Currencies, cd.DTO())
+	}
+	return dto
+}
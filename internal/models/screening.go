@@ -0,0 +1,148 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"time"
+)
+
+// SanctionedEntity is an entry of the sanctions list. Names are its primary name followed by its aliases,
+// Type is empty for organisations as in the SDN list and Programs are the sanction programs it is listed under.
+type SanctionedEntity struct {
+	ID       string
+	Names    []string
+	Type     string
+	Programs []string
+}
+
+// ScreeningHit is the name of a sanctioned entity the screened name resembles, Score is between 0 and 1
+type ScreeningHit struct {
+	EntityID    string
+	MatchedName string
+	Programs    []string
+	Score       float64
+}
+
+// ScreeningCase is a hit of the owner Name of AccountNumber waiting for or given the decision of a reviewer.
+// Cases opened for a payment keep the Payment or SplitPayment with its amount held on the sender until the decision,
+// PaymentID is the payment made once the case is cleared and PaymentError why it could not be made.
+type ScreeningCase struct {
+	ID            types.ScreeningCaseID
+	Trigger       types.ScreeningTrigger
+	AccountNumber types.AccountNumber
+	Name          string
+	Hit           *ScreeningHit
+	Status        types.ScreeningStatus
+	Payment       *Payment
+	SplitPayment  *SplitPayment
+	PaymentID     types.TransactionID
+	PaymentError  string
+	Reviewer      string
+	Note          string
+	CreatedAt     time.Time
+	DecidedAt     time.Time
+}
+
+// ScreeningDecision is the audit record of a reviewer deciding a case, the latest decision on an account
+// and an entity is applied to every later screening of the account against the entity
+type ScreeningDecision struct {
+	CaseID        types.ScreeningCaseID
+	AccountNumber types.AccountNumber
+	EntityID      string
+	Status        types.ScreeningStatus
+	Reviewer      string
+	Note          string
+	At            time.Time
+}
+
+type ScreeningHitDTO struct {
+	EntityID    string   `json:"entityId"`
+	MatchedName string   `json:"matchedName"`
+	Programs    []string `json:"programs,omitempty"`
+	Score       float64  `json:"score"`
+}
+
+type ScreeningCaseDTO struct {
+	ID            types.ScreeningCaseID  `json:"id"`
+	Trigger       types.ScreeningTrigger `json:"trigger"`
+	AccountNumber types.AccountNumber    `json:"accountNumber"`
+	Name          string                 `json:"name"`
+	Hit           *ScreeningHitDTO       `json:"hit"`
+	Status        types.ScreeningStatus  `json:"status"`
+	Payment       *PaymentDTO            `json:"payment,omitempty"`
+	SplitPayment  *SplitPaymentDTO       `json:"splitPayment,omitempty"`
+	PaymentID     types.TransactionID    `json:"paymentId,omitempty"`
+	PaymentError  string                 `json:"paymentError,omitempty"`
+	Reviewer      string                 `json:"reviewer,omitempty"`
+	Note          string                 `json:"note,omitempty"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	DecidedAt     *time.Time             `json:"decidedAt,omitempty"`
+}
+
+// ScreeningDecisionDTO is both the decision a reviewer posts and its audit record
+type ScreeningDecisionDTO struct {
+	CaseID        types.ScreeningCaseID `json:"caseId,omitempty"`
+	AccountNumber types.AccountNumber   `json:"accountNumber,omitempty"`
+	EntityID      string                `json:"entityId,omitempty"`
+	Status        types.ScreeningStatus `json:"status"`
+	Reviewer      string                `json:"reviewer"`
+	Note          string                `json:"note"`
+	At            *time.Time            `json:"at,omitempty"`
+}
+
+func (sc *ScreeningCase) DTO() *ScreeningCaseDTO {
+	dto := &ScreeningCaseDTO{
+		ID:            sc.ID,
+		Trigger:       sc.Trigger,
+		AccountNumber: sc.AccountNumber,
+		Name:          sc.Name,
+		Status:        sc.Status,
+		PaymentID:     sc.PaymentID,
+		PaymentError:  sc.PaymentError,
+		Reviewer:      sc.Reviewer,
+		Note:          sc.Note,
+		CreatedAt:     sc.CreatedAt,
+	}
+	if sc.Hit != nil {
+		dto.Hit = &ScreeningHitDTO{
+			EntityID:    sc.Hit.EntityID,
+			MatchedName: sc.Hit.MatchedName,
+			Programs:    sc.Hit.Programs,
+			Score:       sc.Hit.Score,
+		}
+	}
+	if sc.Payment != nil {
+		dto.Payment = sc.Payment.DTO()
+	}
+	if sc.SplitPayment != nil {
+		dto.SplitPayment = sc.SplitPayment.DTO()
+	}
+	if !sc.DecidedAt.IsZero() {
+		decidedAt := sc.DecidedAt
+		dto.DecidedAt = &decidedAt
+	}
+	return dto
+}
+
+func (sd *ScreeningDecision) DTO() *ScreeningDecisionDTO {
+	at := sd.At
+	return &ScreeningDecisionDTO{
+		CaseID:        sd.CaseID,
+		AccountNumber: sd.AccountNumber,
+		EntityID:      sd.EntityID,
+		Status:        sd.Status,
+		Reviewer:      sd.Reviewer,
+		Note:          sd.Note,
+		At:            &at,
+	}
+}
+
+func (sd *ScreeningDecisionDTO) Normal() *ScreeningDecision {
+	return &ScreeningDecision{
+		CaseID:        sd.CaseID,
+		AccountNumber: sd.AccountNumber,
+		EntityID:      sd.EntityID,
+		Status:        sd.Status,
+		Reviewer:      sd.Reviewer,
+		Note:          sd.Note,
+	}
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// minSplitReceivers is the fewest receivers a split payment can have, a single receiver is a plain payment
+const minSplitReceivers = 2
+
+// SplitPayment pays several receivers from one sender at once. Every receiver is given either
+// an Amount or a Percentage of the Amount of the split, a split of amounts may leave its Amount zero.
+type SplitPayment struct {
+	SenderAccount types.AccountNumber
+	Amount        decimal.Decimal
+	Receivers     []*SplitReceiver
+	TransactionDetails
+}
+
+type SplitReceiver struct {
+	AccountNumber types.AccountNumber
+	Amount        decimal.Decimal
+	Percentage    decimal.Decimal
+}
+
+// LegAmounts returns the amount paid to each receiver, in the order of the receivers.
+// Percentages are rounded down to cents and the cents left over go to the first receiver,
+// so the legs always add up to the Amount of the split.
+func (sp *SplitPayment) LegAmounts() ([]decimal.Decimal, error) {
+	if len(sp.Receivers) < minSplitReceivers {
+		return nil, errs.Newf(errs.InvalidArgument, "a split payment needs at least %d receivers", minSplitReceivers)
+	}
+
+	byPercentage := !sp.Receivers[0].Percentage.IsZero()
+	for _, r := range sp.Receivers {
+		if r.Amount.IsZero() == r.Percentage.IsZero() {
+			return nil, errs.Newf(errs.InvalidArgument, "every receiver needs either an amount or a percentage, not both")
+		}
+		if r.Percentage.IsZero() == byPercentage {
+			return nil, errs.Newf(errs.InvalidArgument, "the receivers must all be given amounts or all percentages")
+		}
+	}
+
+	legs := make([]decimal.Decimal, 0, len(sp.Receivers))
+	if !byPercentage {
+		total := decimal.Zero
+		for _, r := range sp.Receivers {
+			if !r.Amount.IsPositive() {
+				return nil, errs.New(errs.InvalidAmount)
+			}
+			legs = append(legs, r.Amount)
+			total = total.Add(r.Amount)
+		}
+		if !sp.Amount.IsZero() && !sp.Amount.Equal(total) {
+			return nil, errs.Newf(errs.InvalidAmount, "the receivers add up to %s, not %s", total.StringFixed(2), sp.Amount.StringFixed(2))
+		}
+		return legs, nil
+	}
+
+	if !sp.Amount.IsPositive() {
+		return nil, errs.Newf(errs.InvalidAmount, "a split by percentages needs the amount to split")
+	}
+	hundred := decimal.NewFromInt(100)
+	percentages := decimal.Zero
+	total := decimal.Zero
+	for _, r := range sp.Receivers {
+		if !r.Percentage.IsPositive() {
+			return nil, errs.Newf(errs.InvalidArgument, "percentages must be greater than 0")
+		}
+		percentages = percentages.Add(r.Percentage)
+		leg := sp.Amount.Mul(r.Percentage).Div(hundred).Truncate(2)
+		legs = append(legs, leg)
+		total = total.Add(leg)
+	}
+	if !percentages.Equal(hundred) {
+		return nil, errs.Newf(errs.InvalidArgument, "percentages add up to %s, not 100", percentages.String())
+	}
+	legs[0] = legs[0].Add(sp.Amount.Sub(total))
+	for _, leg := range legs {
+		if !leg.IsPositive() {
+			return nil, errs.Newf(errs.InvalidAmount, "every receiver must be paid at least a cent")
+		}
+	}
+	return legs, nil
+}
+
+type SplitPaymentDTO struct {
+	SenderAccount types.AccountNumber `json:"senderAccount"`
+	Amount        float64             `json:"amount"`
+	Receivers     []*SplitReceiverDTO `json:"receivers"`
+	TransactionDetails
+}
+
+type SplitReceiverDTO struct {
+	AccountNumber types.AccountNumber `json:"accountNumber"`
+	Amount        float64             `json:"amount,omitempty"`
+	Percentage    float64             `json:"percentage,omitempty"`
+}
+
+func (sp *SplitPayment) DTO() *SplitPaymentDTO {
+	amountF, _ := sp.Amount.Truncate(2).Float64()
+
+	dto := &SplitPaymentDTO{
+		SenderAccount:      sp.SenderAccount,
+		Amount:             amountF,
+		Receivers:          []*SplitReceiverDTO{},
+		TransactionDetails: sp.TransactionDetails,
+	}
+	for _, r := range sp.Receivers {
+		receiverAmountF, _ := r.Amount.Truncate(2).Float64()
+		percentageF, _ := r.Percentage.Float64()
+		dto.Receivers = append(dto.Receivers, &SplitReceiverDTO{
+			AccountNumber: r.AccountNumber,
+			Amount:        receiverAmountF,
+			Percentage:    percentageF,
+		})
+	}
+	return dto
+}
+
+func (sd *SplitPaymentDTO) Normal() *SplitPayment {
+	split := &SplitPayment{
+		SenderAccount:      sd.SenderAccount,
+		Amount:             decimal.NewFromFloat(sd.Amount),
+		TransactionDetails: sd.TransactionDetails,
+	}
+	for _, r := range sd.Receivers {
+		if r == nil {
+			continue
+		}
+		split.Receivers = append(split.Receivers, &SplitReceiver{
+			AccountNumber: r.AccountNumber,
+			Amount:        decimal.NewFromFloat(r.Amount),
+			Percentage:    decimal.NewFromFloat(r.Percentage),
+		})
+	}
+	return split
+}
+
+// SplitPaymentResult is the line that took the whole amount from the sender
+// and the legs that credited each receiver, in the order of the receivers
+type SplitPaymentResult struct {
+	Payment *Transaction
+	Legs    []*Transaction
+}
+
+type SplitPaymentResultDTO struct {
+	Payment *TransactionDTO   `json:"payment"`
+	Legs    []*TransactionDTO `json:"legs"`
+}
+
+func (sr *SplitPaymentResult) DTO() *SplitPaymentResultDTO {
+	dto := &SplitPaymentResultDTO{
+		Payment: sr.Payment.DTO(),
+		Legs:    []*TransactionDTO{},
+	}
+	for _, leg := range sr.Legs {
+		dto.Legs = append(dto.Legs, leg.DTO())
+	}
+	return dto
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"time"
+)
+
+// StandingOrder is a payment instruction that runs at StartAt and then repeats
+// every Interval days, weeks or months depending on Frequency, or at the minutes matching Cron, until EndAt.
+// Occurrence counts the runs scheduled so far, ScheduledAt is the time of the current run and NextRunAt
+// the time of the next attempt, which is later than the scheduled time while a failed run is retried.
+type StandingOrder struct {
+	ID          types.StandingOrderID
+	Payment     *Payment
+	Frequency   types.Frequency
+	Interval    int
+	Cron        string
+	StartAt     time.Time
+	EndAt       time.Time
+	Status      types.StandingOrderStatus
+	Occurrence  int
+	ScheduledAt time.Time
+	NextRunAt   time.Time
+	Attempts    int
+	Executions  []*Execution
+	CreatedAt   time.Time
+}
+
+// Execution records a single attempt to run a standing order.
+// Error holds the error code the payment failed with, or of the review a pending payment waits for.
+type Execution struct {
+	ScheduledAt   time.Time
+	ExecutedAt    time.Time
+	Attempt       int
+	Outcome       types.ExecutionOutcome
+	TransactionID types.TransactionID
+	Error         string
+}
+
+// Copy returns a copy of the standing order that shares nothing with it
+func (so *StandingOrder) Copy() *StandingOrder {
+	order := *so
+	if so.Payment != nil {
+		payment := *so.Payment
+		payment.TransactionDetails = so.Payment.TransactionDetails.Copy()
+		order.Payment = &payment
+	}
+	order.Executions = nil
+	for _, execution := range so.Executions {
+		e := *execution
+		order.Executions = append(order.Executions, &e)
+	}
+	return &order
+}
+
+type StandingOrderDTO struct {
+	ID         types.StandingOrderID     `json:"id"`
+	Payment    *PaymentDTO               `json:"payment"`
+	Frequency  types.Frequency           `json:"frequency"`
+	Interval   int                       `json:"interval,omitempty"`
+	Cron       string                    `json:"cron,omitempty"`
+	StartAt    time.Time                 `json:"startAt"`
+	EndAt      *time.Time                `json:"endAt,omitempty"`
+	Status     types.StandingOrderStatus `json:"status"`
+	NextRunAt  *time.Time                `json:"nextRunAt,omitempty"`
+	Executions []*ExecutionDTO           `json:"executions"`
+	CreatedAt  time.Time                 `json:"createdAt"`
+}
+
+type ExecutionDTO struct {
+	ScheduledAt   time.Time              `json:"scheduledAt"`
+	ExecutedAt    time.Time              `json:"executedAt"`
+	Attempt       int                    `json:"attempt"`
+	Outcome       types.ExecutionOutcome `json:"outcome"`
+	TransactionID types.TransactionID    `json:"transactionId,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+func (so *StandingOrder) DTO() *StandingOrderDTO {
+	dto := &StandingOrderDTO{
+		ID:         so.ID,
+		Frequency:  so.Frequency,
+		Interval:   so.Interval,
+		Cron:       so.Cron,
+		StartAt:    so.StartAt,
+		Status:     so.Status,
+		Executions: []*ExecutionDTO{},
+		CreatedAt:  so.CreatedAt,
+	}
+	if so.Payment != nil {
+		dto.Payment = so.Payment.DTO()
+	}
+	if !so.EndAt.IsZero() {
+		endAt := so.EndAt
+		dto.EndAt = &endAt
+	}
+	if so.Status == types.StandingOrderActive {
+		nextRunAt := so.NextRunAt
+		dto.NextRunAt = &nextRunAt
+	}
+	for _, e := range so.Executions {
+		dto.Executions = append(dto.Executions, e.DTO())
+	}
+	return dto
+}
+
+func (sod *StandingOrderDTO) Normal() *StandingOrder {
+	order := &StandingOrder{
+		ID:        sod.ID,
+		Frequency: sod.Frequency,
+		Interval:  sod.Interval,
+		Cron:      sod.Cron,
+		StartAt:   sod.StartAt,
+		Status:    sod.Status,
+		CreatedAt: sod.CreatedAt,
+	}
+	if sod.Payment != nil {
+		order.Payment = sod.Payment.Normal()
+	}
+	if sod.EndAt != nil {
+		order.EndAt = *sod.EndAt
+	}
+	return order
+}
+
+func (e *Execution) DTO() *ExecutionDTO {
+	return &ExecutionDTO{
+		ScheduledAt:   e.ScheduledAt,
+		ExecutedAt:    e.ExecutedAt,
+		Attempt:       e.Attempt,
+		Outcome:       e.Outcome,
+		TransactionID: e.TransactionID,
+		Error:         e.Error,
+	}
+}
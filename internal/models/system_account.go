@@ -0,0 +1,10 @@
+package models
+
+import "github.com/ahmetberke/tringle-candidate-project/internal/types"
+
+// SystemAccount is an account the bank opens for itself, there is at most one for every role and currency
+type SystemAccount struct {
+	Role          types.SystemAccountRole
+	CurrencyCode  types.Currency
+	AccountNumber types.AccountNumber
+}
@@ -0,0 +1,37 @@
+package models
+
+import "github.com/ahmetberke/tringle-candidate-project/internal/types"
+
+// DefaultTenant is the tenant every request belongs to when no tenants are configured
+const DefaultTenant = "default"
+
+// Tenant is a partner brand served from its own ledger, its accounts and transactions
+// cannot be seen or reached from any other tenant
+type Tenant struct {
+	ID string
+	// APIKeys are the keys the callers of the tenant authenticate with, every tenant has at least one
+	APIKeys []string
+	// Currencies are the currencies accounts can be opened in, empty allows every currency
+	Currencies []types.Currency
+	// Limits replace the configured limits for the accounts of the tenant when set
+	Limits []*Limit
+}
+
+type TenantDTO struct {
+	ID         string           `json:"id"`
+	APIKeys    []string         `json:"apiKeys,omitempty"`
+	Currencies []types.Currency `json:"currencies,omitempty"`
+	Limits     []*LimitDTO      `json:"limits,omitempty"`
+}
+
+func (td *TenantDTO) Normal() *Tenant {
+	tenant := &Tenant{
+		ID:         td.ID,
+		APIKeys:    td.APIKeys,
+		Currencies: td.Currencies,
+	}
+	for _, l := range td.Limits {
+		tenant.Limits = append(tenant.Limits, l.Normal())
+	}
+	return tenant
+}
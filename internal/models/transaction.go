@@ -1,42 +1,177 @@
 package models
 
 import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Transaction is a line in the history of an account.
+// ParentID links lines such as fees to the transaction they were charged for.
+// CounterpartyAccount is the other account of a payment, Counterparty the account
+// outside of the bank a deposit came from or a withdrawal went to.
+// ExchangeRate is only set for transfers between wallets of different currencies.
 type Transaction struct {
-	AccountNumber   types.AccountNumber
-	Amount          decimal.Decimal
-	TransactionType types.TransactionType
-	CreatedAt       time.Time
+	ID                  types.TransactionID
+	ParentID            types.TransactionID
+	AccountNumber       types.AccountNumber
+	Amount              decimal.Decimal
+	TransactionType     types.TransactionType
+	Direction           types.Direction
+	CounterpartyAccount types.AccountNumber
+	Counterparty        string
+	ExchangeRate        decimal.Decimal
+	TransactionDetails
+	CreatedAt time.Time
+}
+
+// TransactionDetails describe a transaction to the owners of its accounts.
+// Reference is the end-to-end reference given by whoever started the transaction,
+// Metadata holds free key/value pairs.
+type TransactionDetails struct {
+	Description string            `json:"description,omitempty"`
+	Reference   string            `json:"reference,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+const (
+	maxDescriptionLength   = 140
+	maxReferenceLength     = 35
+	maxMetadataKeys        = 20
+	maxMetadataKeyLength   = 40
+	maxMetadataValueLength = 256
+)
+
+// Validate checks the details against the lengths every history line can store
+func (td TransactionDetails) Validate() error {
+	if len([]rune(td.Description)) > maxDescriptionLength {
+		return errs.Newf(errs.InvalidArgument, "description cannot be longer than %d characters", maxDescriptionLength)
+	}
+	if len([]rune(td.Reference)) > maxReferenceLength {
+		return errs.Newf(errs.InvalidArgument, "reference cannot be longer than %d characters", maxReferenceLength)
+	}
+	return validateMetadata(td.Metadata)
+}
+
+// validateMetadata checks the number and the lengths of free key/value pairs
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > maxMetadataKeys {
+		return errs.Newf(errs.InvalidArgument, "metadata cannot have more than %d keys", maxMetadataKeys)
+	}
+	for key, value := range metadata {
+		if key == "" || len([]rune(key)) > maxMetadataKeyLength {
+			return errs.Newf(errs.InvalidArgument, "metadata keys must be 1 to %d characters", maxMetadataKeyLength)
+		}
+		if len([]rune(value)) > maxMetadataValueLength {
+			return errs.Newf(errs.InvalidArgument, "metadata values cannot be longer than %d characters", maxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// CopyMetadata returns a copy of the pairs, nil stays nil
+func CopyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Copy returns the details with a metadata map of their own
+func (td TransactionDetails) Copy() TransactionDetails {
+	td.Metadata = CopyMetadata(td.Metadata)
+	return td
+}
+
+// TransactionFilter selects history lines, empty fields match every line.
+// Text is searched case-insensitively in the description and the reference.
+type TransactionFilter struct {
+	Counterparty string
+	Reference    string
+	Text         string
+	Metadata     map[string]string
+}
+
+// Matches reports whether the line satisfies every field of the filter
+func (tf *TransactionFilter) Matches(t *Transaction) bool {
+	if tf == nil {
+		return true
+	}
+	if tf.Counterparty != "" && tf.Counterparty != t.Counterparty &&
+		(t.CounterpartyAccount == 0 || tf.Counterparty != strconv.FormatInt(int64(t.CounterpartyAccount), 10)) {
+		return false
+	}
+	if tf.Reference != "" && tf.Reference != t.Reference {
+		return false
+	}
+	if tf.Text != "" {
+		text := strings.ToLower(tf.Text)
+		if !strings.Contains(strings.ToLower(t.Description), text) && !strings.Contains(strings.ToLower(t.Reference), text) {
+			return false
+		}
+	}
+	for key, value := range tf.Metadata {
+		if v, ok := t.Metadata[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
 }
 
 type TransactionDTO struct {
+	ID              types.TransactionID   `json:"id"`
+	ParentID        types.TransactionID   `json:"parentId,omitempty"`
 	AccountNumber   types.AccountNumber   `json:"accountNumber"`
 	Amount          float64               `json:"amount"`
 	TransactionType types.TransactionType `json:"transactionType"`
-	CreatedAt       time.Time             `json:"createdAt"`
+	Direction       types.Direction       `json:"direction"`
+	// CounterpartyAccount is only set for payments
+	CounterpartyAccount types.AccountNumber `json:"counterpartyAccount,omitempty"`
+	Counterparty        string              `json:"counterparty,omitempty"`
+	ExchangeRate        float64             `json:"exchangeRate,omitempty"`
+	TransactionDetails
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 func (t *Transaction) DTO() *TransactionDTO {
 
 	amountF, _ := t.Amount.Truncate(2).Float64()
+	rateF, _ := t.ExchangeRate.Float64()
 
 	return &TransactionDTO{
-		AccountNumber:   t.AccountNumber,
-		Amount:          amountF,
-		TransactionType: t.TransactionType,
-		CreatedAt:       t.CreatedAt,
+		ID:                  t.ID,
+		ParentID:            t.ParentID,
+		AccountNumber:       t.AccountNumber,
+		Amount:              amountF,
+		TransactionType:     t.TransactionType,
+		Direction:           t.Direction,
+		CounterpartyAccount: t.CounterpartyAccount,
+		Counterparty:        t.Counterparty,
+		ExchangeRate:        rateF,
+		TransactionDetails:  t.TransactionDetails,
+		CreatedAt:           t.CreatedAt,
 	}
 }
 
 func (td *TransactionDTO) Normal() *Transaction {
 	return &Transaction{
-		AccountNumber:   td.AccountNumber,
-		Amount:          decimal.NewFromFloat(td.Amount),
-		TransactionType: td.TransactionType,
-		CreatedAt:       td.CreatedAt,
+		ID:                  td.ID,
+		ParentID:            td.ParentID,
+		AccountNumber:       td.AccountNumber,
+		Amount:              decimal.NewFromFloat(td.Amount),
+		TransactionType:     td.TransactionType,
+		Direction:           td.Direction,
+		CounterpartyAccount: td.CounterpartyAccount,
+		Counterparty:        td.Counterparty,
+		ExchangeRate:        decimal.NewFromFloat(td.ExchangeRate),
+		TransactionDetails:  td.TransactionDetails,
+		CreatedAt:           td.CreatedAt,
 	}
 }
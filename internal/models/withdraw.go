@@ -8,25 +8,34 @@ import (
 type Withdraw struct {
 	AccountNumber types.AccountNumber
 	Amount        decimal.Decimal
+	// Counterparty is the account outside of the bank the money came from or went to, if known
+	Counterparty string
+	TransactionDetails
 }
 
 type WithdrawDTO struct {
 	AccountNumber types.AccountNumber `json:"accountNumber"`
 	Amount        float64             `json:"amount"`
+	Counterparty  string              `json:"counterparty,omitempty"`
+	TransactionDetails
 }
 
 func (w *Withdraw) DTO() *WithdrawDTO {
 	amountF, _ := w.Amount.Truncate(2).Float64()
 
 	return &WithdrawDTO{
-		AccountNumber: w.AccountNumber,
-		Amount:        amountF,
+		AccountNumber:      w.AccountNumber,
+		Amount:             amountF,
+		Counterparty:       w.Counterparty,
+		TransactionDetails: w.TransactionDetails,
 	}
 }
 
 func (wd *WithdrawDTO) Normal() *Withdraw {
 	return &Withdraw{
-		AccountNumber: wd.AccountNumber,
-		Amount:        decimal.NewFromFloat(wd.Amount),
+		AccountNumber:      wd.AccountNumber,
+		Amount:             decimal.NewFromFloat(wd.Amount),
+		Counterparty:       wd.Counterparty,
+		TransactionDetails: wd.TransactionDetails,
 	}
 }
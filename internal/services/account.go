@@ -1,7 +1,7 @@
 package services
 
 import (
-	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
@@ -9,23 +9,102 @@ import (
 )
 
 type AccountService struct {
-	Cache accountCache
+	Cache accountManager
+	holds holdLister
+	// currencies are the currencies accounts can be opened in, empty allows every supported currency
+	currencies []types.Currency
+	screening  accountScreener
+	// deposits make the opening balance of accounts their first deposit, without it accounts are only opened empty
+	deposits depositMaker
+}
+
+// AccountServiceOption configures the optional collaborators of the AccountService
+type AccountServiceOption func(as *AccountService)
+
+type holdLister interface {
+	FindByAccountNumber(accountNumber types.AccountNumber) []*models.Hold
+}
+
+// WithHolds makes the service report the active holds of accounts
+func WithHolds(h holdLister) AccountServiceOption {
+	return func(as *AccountService) {
+		as.holds = h
+	}
+}
+
+// WithCurrencies restricts the currencies accounts can be opened in
+func WithCurrencies(currencies []types.Currency) AccountServiceOption {
+	return func(as *AccountService) {
+		as.currencies = currencies
+	}
+}
+
+type accountScreener interface {
+	ScreenAccount(account *models.Account, trigger types.ScreeningTrigger) []*models.ScreeningCase
+}
+
+// WithAccountScreening makes the service screen the owner names of opened and renamed accounts against the sanctions list
+func WithAccountScreening(s accountScreener) AccountServiceOption {
+	return func(as *AccountService) {
+		as.screening = s
+	}
+}
+
+type depositMaker interface {
+	NewDeposit(deposit *models.Deposit) (*models.Transaction, error)
+}
+
+// WithOpeningDeposits lets accounts be opened with a balance, which is made as their first deposit
+// so that it passes the policy, limits and fees of deposits and their history accounts for the whole balance
+func WithOpeningDeposits(d depositMaker) AccountServiceOption {
+	return func(as *AccountService) {
+		as.deposits = d
+	}
 }
 
 type accountCache interface {
 	Get(accountNumber types.AccountNumber) (*models.Account, error)
-	Create(account *models.Account) *models.Account
+	Create(account *models.Account) (*models.Account, error)
 	Delete(accountNumber types.AccountNumber)
 	UpdateBalance(accountNumber types.AccountNumber, balance decimal.Decimal) error
+	ApplyChanges(changes []*models.BalanceChange) error
+	// Commit applies the changes and calls record to write their history before any other change is applied
+	Commit(changes []*models.BalanceChange, record func()) error
+}
+
+// accountLister is an accountCache that can also list every account
+type accountLister interface {
+	accountCache
+	GetAll() []*models.Account
+}
+
+// accountManager is an accountCache that can also search the accounts and update their profiles
+type accountManager interface {
+	accountCache
+	Search(filter *models.AccountFilter) *models.AccountPage
+	UpdateProfile(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error)
+	Changes(accountNumber types.AccountNumber) ([]*models.AccountChange, error)
 }
 
-func NewAccountService(cache accountCache) *AccountService {
-	return &AccountService{Cache: cache}
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
+	// maxUpdateAttempts is how many times an update without an expected version
+	// is tried again when another update gets in between reading and writing the account
+	maxUpdateAttempts = 3
+)
+
+func NewAccountService(cache accountManager, options ...AccountServiceOption) *AccountService {
+	as := &AccountService{Cache: cache}
+	for _, option := range options {
+		option(as)
+	}
+	return as
 }
 
 func (as *AccountService) FindByAccountNumber(accountNumber types.AccountNumber) (*models.Account, error) {
 	if accountNumber < 0 {
-		return nil, errors.New("account number cannot be negative")
+		return nil, errs.New(errs.NegativeAccount)
 	}
 	return as.Cache.Get(accountNumber)
 }
@@ -36,25 +115,186 @@ func (as *AccountService) Create(account *models.Account) (*models.Account, erro
 	switch account.CurrencyCode {
 	case types.TRY, types.EUR, types.USD:
 	default:
-		return nil, errors.New("invalid currency code")
+		return nil, errs.New(errs.InvalidCurrency)
+	}
+	if !as.offers(account.CurrencyCode) {
+		return nil, errs.Newf(errs.InvalidCurrency, "accounts cannot be opened in %s", account.CurrencyCode)
 	}
 
 	// Checking valid account type
 	switch account.AccountType {
-	case types.Individual:
-		// Checking valid owner name for individual accounts
-		res := strings.Split(account.OwnerName, " ")
+	case types.Individual, types.Corporate:
+	default:
+		return nil, errs.New(errs.InvalidAccountType)
+	}
+
+	err := validOwnerName(account.AccountType, account.OwnerName)
+	if err != nil {
+		return nil, err
+	}
+	err = account.Profile().Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	openingBalance := account.Balance
+	if openingBalance.IsNegative() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if openingBalance.IsPositive() && as.deposits == nil {
+		return nil, errs.Newf(errs.InvalidArgument, "accounts are opened empty, fund them with a deposit")
+	}
+
+	// Accounts are opened empty and the opening balance is deposited so that their history accounts for the whole balance
+	account.Balance = decimal.Zero
+	account.Held = decimal.Zero
+	account.OverdraftLimit = decimal.Zero
+	account, err = as.Cache.Create(account)
+	if err != nil {
+		return nil, err
+	}
+
+	if openingBalance.IsPositive() {
+		account, err = as.deposit(account, openingBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A hit does not reject the account, it is put into the review queue and its payments are screened
+	if as.screening != nil {
+		as.screening.ScreenAccount(account, types.AccountOpening)
+	}
+	return account, nil
+}
+
+// deposit makes the opening balance of a new account its first deposit,
+// the account is closed again if the deposit is rejected
+func (as *AccountService) deposit(account *models.Account, amount decimal.Decimal) (*models.Account, error) {
+	_, err := as.deposits.NewDeposit(&models.Deposit{
+		AccountNumber:      account.AccountNumber,
+		Amount:             amount,
+		TransactionDetails: models.TransactionDetails{Description: "opening balance"},
+	})
+	if err != nil {
+		as.Cache.Delete(account.AccountNumber)
+		return nil, err
+	}
+	return as.Cache.Get(account.AccountNumber)
+}
+
+// Update applies the patch to the profile of the account with the same rules as opening it.
+// A non-zero version is the version the patch was made against, the update fails if the account is
+// no longer at it. Without a version the update is made against the version the account is at.
+func (as *AccountService) Update(accountNumber types.AccountNumber, version int, patch *models.AccountPatch) (*models.Account, error) {
+	for attempt := 1; ; attempt++ {
+		account, err := as.FindByAccountNumber(accountNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		profile := patch.Apply(account.Profile())
+		err = validOwnerName(account.AccountType, profile.OwnerName)
+		if err != nil {
+			return nil, err
+		}
+		err = profile.Validate()
+		if err != nil {
+			return nil, err
+		}
+
+		expected := version
+		if expected == 0 {
+			expected = account.Version
+		}
+		// The account is changed in place by the update, so its name is kept to tell whether it was renamed
+		previousName := account.OwnerName
+		updated, err := as.Cache.UpdateProfile(accountNumber, expected, profile)
+		if version == 0 && errs.Is(err, errs.VersionMismatch) && attempt < maxUpdateAttempts {
+			continue
+		}
+		if err == nil && as.screening != nil && profile.OwnerName != previousName {
+			as.screening.ScreenAccount(updated, types.AccountRenamed)
+		}
+		return updated, err
+	}
+}
+
+// Changes returns the profile versions of the account, oldest first
+func (as *AccountService) Changes(accountNumber types.AccountNumber) ([]*models.AccountChange, error) {
+	if accountNumber < 0 {
+		return nil, errs.New(errs.NegativeAccount)
+	}
+	return as.Cache.Changes(accountNumber)
+}
+
+// validOwnerName checks the owner name against the rules of the account type,
+// individual accounts need at least a first and a last name
+func validOwnerName(accountType types.AccountType, ownerName string) error {
+	if accountType == types.Individual {
+		res := strings.Split(ownerName, " ")
 		if len(res) < 2 {
-			return nil, errors.New("invalid owner name")
+			return errs.New(errs.InvalidOwnerName)
 		}
+	}
+	return nil
+}
 
-	case types.Corporate:
+// Search returns the page of the accounts matching the filter, a page has 50 accounts unless the filter limits it
+func (as *AccountService) Search(filter *models.AccountFilter) (*models.AccountPage, error) {
+	if filter.AccountType != "" && filter.AccountType != types.Individual && filter.AccountType != types.Corporate {
+		return nil, errs.New(errs.InvalidAccountType)
+	}
+	switch filter.CurrencyCode {
+	case "", types.TRY, types.EUR, types.USD:
 	default:
-		return nil, errors.New("invalid account type")
+		return nil, errs.New(errs.InvalidCurrency)
+	}
+	if filter.MinBalance != nil && filter.MaxBalance != nil && filter.MinBalance.GreaterThan(*filter.MaxBalance) {
+		return nil, errs.Newf(errs.InvalidArgument, "minBalance cannot be greater than maxBalance")
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && filter.CreatedFrom.After(filter.CreatedTo) {
+		return nil, errs.Newf(errs.InvalidArgument, "createdFrom cannot be after createdTo")
+	}
+	if filter.Offset < 0 {
+		return nil, errs.Newf(errs.InvalidArgument, "offset cannot be negative")
+	}
+	if filter.Limit < 0 || filter.Limit > maxSearchLimit {
+		return nil, errs.Newf(errs.InvalidArgument, "limit must be between 1 and %d", maxSearchLimit)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchLimit
 	}
-	return as.Cache.Create(account), nil
+	return as.Cache.Search(filter), nil
+}
+
+// FindHolds returns the active holds of the account
+func (as *AccountService) FindHolds(accountNumber types.AccountNumber) []*models.Hold {
+	if as.holds == nil {
+		return nil
+	}
+	var active []*models.Hold
+	for _, hold := range as.holds.FindByAccountNumber(accountNumber) {
+		if hold.Status == types.HoldActive {
+			active = append(active, hold)
+		}
+	}
+	return active
 }
 
 func (as *AccountService) Delete(accountNumber types.AccountNumber) {
 	as.Cache.Delete(accountNumber)
 }
+
+// offers reports whether accounts can be opened in the currency
+func (as *AccountService) offers(currency types.Currency) bool {
+	if len(as.currencies) == 0 {
+		return true
+	}
+	for _, c := range as.currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
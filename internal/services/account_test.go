@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
@@ -11,16 +12,23 @@ import (
 
 type mockAccountCache struct {
 	GetMock           func(accountNumber types.AccountNumber) (*models.Account, error)
-	CreateMock        func(account *models.Account) *models.Account
+	CreateMock        func(account *models.Account) (*models.Account, error)
 	DeleteMock        func(accountNumber types.AccountNumber)
 	UpdateBalanceMock func(accountNumber types.AccountNumber, balance decimal.Decimal) error
+	ApplyChangesMock  func(changes []*models.BalanceChange) error
+
+	SearchMock            func(filter *models.AccountFilter) *models.AccountPage
+	UpdateProfileMock     func(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error)
+	ChangesMock           func(accountNumber types.AccountNumber) ([]*models.AccountChange, error)
+	GetAllMock            func() []*models.Account
+	SetOverdraftLimitMock func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error)
 }
 
 func (m *mockAccountCache) Get(accountNumber types.AccountNumber) (*models.Account, error) {
 	return m.GetMock(accountNumber)
 }
 
-func (m *mockAccountCache) Create(account *models.Account) *models.Account {
+func (m *mockAccountCache) Create(account *models.Account) (*models.Account, error) {
 	return m.CreateMock(account)
 }
 
@@ -32,17 +40,87 @@ func (m *mockAccountCache) UpdateBalance(accountNumber types.AccountNumber, bala
 	return m.UpdateBalanceMock(accountNumber, balance)
 }
 
+func (m *mockAccountCache) Search(filter *models.AccountFilter) *models.AccountPage {
+	return m.SearchMock(filter)
+}
+
+func (m *mockAccountCache) UpdateProfile(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error) {
+	return m.UpdateProfileMock(accountNumber, version, profile)
+}
+
+func (m *mockAccountCache) Changes(accountNumber types.AccountNumber) ([]*models.AccountChange, error) {
+	return m.ChangesMock(accountNumber)
+}
+
+func (m *mockAccountCache) GetAll() []*models.Account {
+	return m.GetAllMock()
+}
+
+func (m *mockAccountCache) SetOverdraftLimit(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error) {
+	return m.SetOverdraftLimitMock(accountNumber, limit)
+}
+
+// Inspect reads the account from GetMock
+func (m *mockAccountCache) Inspect(accountNumber types.AccountNumber, read func(account *models.Account)) error {
+	account, err := m.GetMock(accountNumber)
+	if err != nil {
+		return err
+	}
+	read(account)
+	return nil
+}
+
+// Commit records the history once the changes are applied
+func (m *mockAccountCache) Commit(changes []*models.BalanceChange, record func()) error {
+	if err := m.ApplyChanges(changes); err != nil {
+		return err
+	}
+	record()
+	return nil
+}
+
+// ApplyChanges falls back to GetMock and UpdateBalanceMock
+// so that tests written against single balance updates keep working
+func (m *mockAccountCache) ApplyChanges(changes []*models.BalanceChange) error {
+	if m.ApplyChangesMock != nil {
+		return m.ApplyChangesMock(changes)
+	}
+	for _, change := range changes {
+		account, err := m.GetMock(change.AccountNumber)
+		if err != nil {
+			return err
+		}
+		balance := account.Balance.Add(change.Amount)
+		if change.RequireFunds && balance.IsNegative() {
+			return errs.New(errs.InsufficientBalance)
+		}
+		err = m.UpdateBalanceMock(change.AccountNumber, balance)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mockAccountScreener struct {
+	ScreenAccountMock func(account *models.Account, trigger types.ScreeningTrigger) []*models.ScreeningCase
+}
+
+func (m *mockAccountScreener) ScreenAccount(account *models.Account, trigger types.ScreeningTrigger) []*models.ScreeningCase {
+	return m.ScreenAccountMock(account, trigger)
+}
+
 func TestAccountService_Create(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockAccountCach := mockAccountCache{
-			CreateMock: func(account *models.Account) *models.Account {
+			CreateMock: func(account *models.Account) (*models.Account, error) {
 				return &models.Account{
 					AccountNumber: 1,
 					CurrencyCode:  account.CurrencyCode,
 					OwnerName:     account.OwnerName,
 					AccountType:   account.AccountType,
 					Balance:       decimal.Decimal{},
-				}
+				}, nil
 			},
 		}
 		accounService := NewAccountService(&mockAccountCach)
@@ -63,6 +141,100 @@ func TestAccountService_Create(t *testing.T) {
 		assert.Equal(t, float64(0), balanceF)
 
 	})
+	t.Run("OpeningBalance", func(t *testing.T) {
+		opened := &models.Account{}
+		mockAccountCach := mockAccountCache{
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				assert.True(t, account.Balance.IsZero())
+				opened = account
+				opened.AccountNumber = 1
+				return opened, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				assert.Equal(t, types.Deposited, changes[0].Reason)
+				opened.Balance = opened.Balance.Add(changes[0].Amount)
+				return nil
+			},
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return opened, nil
+			},
+		}
+		var history []*models.Transaction
+		mockTransactionCach := mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errs.New(errs.HistoryNotFound)
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				history = append(history, transaction)
+				return transaction
+			},
+		}
+		accounService := NewAccountService(&mockAccountCach,
+			WithOpeningDeposits(NewTransactionService(&mockAccountCach, &mockTransactionCach)))
+
+		createdAccount, err := accounService.Create(&models.Account{
+			CurrencyCode: types.USD,
+			OwnerName:    "Robert Griesemer",
+			AccountType:  types.Individual,
+			Balance:      decimal.NewFromFloat(250),
+		})
+		assert.NoError(t, err)
+		assert.True(t, createdAccount.Balance.Equal(decimal.NewFromFloat(250)))
+		assert.Equal(t, 1, len(history))
+		assert.Equal(t, types.Deposit, history[0].TransactionType)
+		assert.True(t, history[0].Amount.Equal(decimal.NewFromFloat(250)))
+	})
+	t.Run("OpeningBalanceRejectedByPolicy", func(t *testing.T) {
+		opened := &models.Account{}
+		var closed types.AccountNumber
+		mockAccountCach := mockAccountCache{
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				opened = account
+				opened.AccountNumber = 2
+				return opened, nil
+			},
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return opened, nil
+			},
+			DeleteMock: func(accountNumber types.AccountNumber) {
+				closed = accountNumber
+			},
+		}
+		mockTransactionCach := mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errs.New(errs.HistoryNotFound)
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+		}
+		accounService := NewAccountService(&mockAccountCach,
+			WithOpeningDeposits(NewTransactionService(&mockAccountCach, &mockTransactionCach)))
+
+		// Corporate accounts cannot make deposits, so they cannot be opened funded either
+		_, err := accounService.Create(&models.Account{
+			CurrencyCode: types.TRY,
+			OwnerName:    "Apple",
+			AccountType:  types.Corporate,
+			Balance:      decimal.NewFromFloat(1000000),
+		})
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+		assert.Equal(t, types.AccountNumber(2), closed)
+	})
+	t.Run("OpeningBalanceWithoutHistory", func(t *testing.T) {
+		accounService := NewAccountService(&mockAccountCache{})
+
+		_, err := accounService.Create(&models.Account{
+			CurrencyCode: types.USD,
+			OwnerName:    "Robert Griesemer",
+			AccountType:  types.Individual,
+			Balance:      decimal.NewFromFloat(250),
+		})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
 	t.Run("InvalidAccountType", func(t *testing.T) {
 		mockAccountCach := mockAccountCache{}
 		accounService := NewAccountService(&mockAccountCach)
@@ -89,6 +261,19 @@ func TestAccountService_Create(t *testing.T) {
 		_, err := accounService.Create(&account)
 		assert.Error(t, err)
 	})
+	t.Run("CurrencyNotOffered", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{}
+		accounService := NewAccountService(&mockAccountCach, WithCurrencies([]types.Currency{types.TRY}))
+
+		account := models.Account{
+			CurrencyCode: types.USD,
+			OwnerName:    "Robert Griesemer",
+			AccountType:  types.Individual,
+		}
+
+		_, err := accounService.Create(&account)
+		assert.True(t, errs.Is(err, errs.InvalidCurrency))
+	})
 	t.Run("InvalidOwnerNameForIndividualAccount", func(t *testing.T) {
 		mockAccountCach := mockAccountCache{}
 		accounService := NewAccountService(&mockAccountCach)
@@ -142,3 +327,153 @@ func TestAccountService_Delete(t *testing.T) {
 		accounService.Delete(1)
 	})
 }
+
+func TestAccountService_Search(t *testing.T) {
+	t.Run("DefaultLimit", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{
+			SearchMock: func(filter *models.AccountFilter) *models.AccountPage {
+				assert.Equal(t, 50, filter.Limit)
+				return &models.AccountPage{Limit: filter.Limit}
+			},
+		}
+		accountService := NewAccountService(&mockAccountCach)
+
+		page, err := accountService.Search(&models.AccountFilter{OwnerName: "ahmet"})
+		assert.NoError(t, err)
+		assert.Equal(t, 50, page.Limit)
+	})
+	t.Run("InvalidFilter", func(t *testing.T) {
+		accountService := NewAccountService(&mockAccountCache{})
+		minBalance := decimal.NewFromFloat(100)
+		maxBalance := decimal.NewFromFloat(10)
+
+		_, err := accountService.Search(&models.AccountFilter{AccountType: "savings"})
+		assert.True(t, errs.Is(err, errs.InvalidAccountType))
+
+		_, err = accountService.Search(&models.AccountFilter{CurrencyCode: "GBP"})
+		assert.True(t, errs.Is(err, errs.InvalidCurrency))
+
+		_, err = accountService.Search(&models.AccountFilter{MinBalance: &minBalance, MaxBalance: &maxBalance})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+
+		_, err = accountService.Search(&models.AccountFilter{Limit: 1000})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
+
+func TestAccountService_Update(t *testing.T) {
+	newAccount := func() *models.Account {
+		return &models.Account{AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berk", AccountType: types.Individual, Version: 1}
+	}
+	ownerName := func(name string) *string { return &name }
+
+	t.Run("Success", func(t *testing.T) {
+		account := newAccount()
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return account, nil
+			},
+			UpdateProfileMock: func(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error) {
+				assert.Equal(t, 1, version)
+				account.OwnerName = profile.OwnerName
+				account.Version++
+				return account, nil
+			},
+		}
+		accountService := NewAccountService(&mockAccountCach)
+
+		updated, err := accountService.Update(1, 1, &models.AccountPatch{OwnerName: ownerName("Ahmet Berke")})
+		assert.NoError(t, err)
+		assert.Equal(t, "Ahmet Berke", updated.OwnerName)
+		assert.Equal(t, 2, updated.Version)
+	})
+	t.Run("ScreensRenamed", func(t *testing.T) {
+		account := newAccount()
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return account, nil
+			},
+			UpdateProfileMock: func(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error) {
+				account.OwnerName = profile.OwnerName
+				account.Contact = profile.Contact
+				return account, nil
+			},
+		}
+		var screened []types.ScreeningTrigger
+		accountService := NewAccountService(&mockAccountCach, WithAccountScreening(&mockAccountScreener{
+			ScreenAccountMock: func(account *models.Account, trigger types.ScreeningTrigger) []*models.ScreeningCase {
+				screened = append(screened, trigger)
+				return nil
+			},
+		}))
+
+		_, err := accountService.Update(1, 0, &models.AccountPatch{OwnerName: ownerName("Ahmet Berke")})
+		assert.NoError(t, err)
+		assert.Equal(t, []types.ScreeningTrigger{types.AccountRenamed}, screened)
+
+		// Only a new owner name is screened
+		_, err = accountService.Update(1, 0, &models.AccountPatch{OwnerName: ownerName("Ahmet Berke")})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(screened))
+	})
+	t.Run("InvalidOwnerName", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return newAccount(), nil
+			},
+		}
+		accountService := NewAccountService(&mockAccountCach)
+
+		_, err := accountService.Update(1, 0, &models.AccountPatch{OwnerName: ownerName("Ahmet")})
+		assert.True(t, errs.Is(err, errs.InvalidOwnerName))
+	})
+	t.Run("InvalidContact", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return newAccount(), nil
+			},
+		}
+		accountService := NewAccountService(&mockAccountCach)
+
+		email := "not an email"
+		_, err := accountService.Update(1, 0, &models.AccountPatch{Contact: &models.ContactInfoPatch{Email: &email}})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+	t.Run("RetriedWithoutVersion", func(t *testing.T) {
+		attempts := 0
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return newAccount(), nil
+			},
+			UpdateProfileMock: func(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error) {
+				attempts++
+				if attempts == 1 {
+					return nil, errs.New(errs.VersionMismatch)
+				}
+				return newAccount(), nil
+			},
+		}
+		accountService := NewAccountService(&mockAccountCach)
+
+		_, err := accountService.Update(1, 0, &models.AccountPatch{OwnerName: ownerName("Ahmet Berke")})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+	t.Run("VersionMismatch", func(t *testing.T) {
+		attempts := 0
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return newAccount(), nil
+			},
+			UpdateProfileMock: func(accountNumber types.AccountNumber, version int, profile *models.AccountProfile) (*models.Account, error) {
+				attempts++
+				return nil, errs.New(errs.VersionMismatch)
+			},
+		}
+		accountService := NewAccountService(&mockAccountCach)
+
+		_, err := accountService.Update(1, 3, &models.AccountPatch{OwnerName: ownerName("Ahmet Berke")})
+		assert.True(t, errs.Is(err, errs.VersionMismatch))
+		assert.Equal(t, 1, attempts)
+	})
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// BalanceService computes the balances of accounts from their history. Accounts are opened empty,
+// so the balance at a moment is the sum of the history lines created until then.
+type BalanceService struct {
+	accountCache     accountLister
+	transactionCache transactionCache
+	checkpointCache  checkpointCache
+	now              func() time.Time
+
+	// mu serializes the checkpoints so that an account never gets two checkpoints for the same line
+	mu sync.Mutex
+}
+
+type checkpointCache interface {
+	Add(checkpoint *models.BalanceCheckpoint)
+	GetAll(accountNumber types.AccountNumber) []*models.BalanceCheckpoint
+}
+
+// balanceReader reads the balance an account had at a moment
+type balanceReader interface {
+	BalanceAt(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error)
+}
+
+func NewBalanceService(ac accountLister, tc transactionCache, cc checkpointCache) *BalanceService {
+	return &BalanceService{
+		accountCache:     ac,
+		transactionCache: tc,
+		checkpointCache:  cc,
+		now:              time.Now,
+	}
+}
+
+// BalanceAt returns the balance the account had at the moment, starting from
+// the last checkpoint taken until then instead of the beginning of the history.
+// A zero moment is now.
+func (bs *BalanceService) BalanceAt(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error) {
+	account, err := bs.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if at.IsZero() {
+		at = bs.now()
+	}
+	if at.After(bs.now()) {
+		return nil, errs.Newf(errs.InvalidArgument, "at cannot be in the future")
+	}
+
+	balance := decimal.Zero
+	var after types.TransactionID
+	checkpoints := bs.checkpointCache.GetAll(accountNumber)
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if !checkpoints[i].At.After(at) {
+			balance = checkpoints[i].Balance
+			after = checkpoints[i].TransactionID
+			break
+		}
+	}
+
+	for _, transaction := range bs.history(accountNumber) {
+		if transaction.ID <= after || transaction.CreatedAt.After(at) {
+			continue
+		}
+		balance = balance.Add(signed(transaction))
+	}
+
+	return &models.HistoricalBalance{
+		AccountNumber: account.AccountNumber,
+		CurrencyCode:  account.CurrencyCode,
+		Balance:       balance,
+		At:            at,
+	}, nil
+}
+
+// Checkpoint takes a checkpoint of every account whose history grew since its last checkpoint
+// and returns how many were taken
+func (bs *BalanceService) Checkpoint() int {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	taken := 0
+	now := bs.now()
+	for _, account := range bs.accountCache.GetAll() {
+		balance := decimal.Zero
+		var after types.TransactionID
+		checkpoints := bs.checkpointCache.GetAll(account.AccountNumber)
+		if len(checkpoints) > 0 {
+			last := checkpoints[len(checkpoints)-1]
+			balance = last.Balance
+			after = last.TransactionID
+		}
+
+		lastID := after
+		for _, transaction := range bs.history(account.AccountNumber) {
+			if transaction.ID <= after {
+				continue
+			}
+			// Lines being written right now are left to the next checkpoint
+			if transaction.CreatedAt.After(now) {
+				break
+			}
+			balance = balance.Add(signed(transaction))
+			lastID = transaction.ID
+		}
+		if lastID == after {
+			continue
+		}
+
+		bs.checkpointCache.Add(&models.BalanceCheckpoint{
+			AccountNumber: account.AccountNumber,
+			At:            now,
+			Balance:       balance,
+			TransactionID: lastID,
+		})
+		taken++
+	}
+	return taken
+}
+
+// history returns the history of the account, an account without one has an empty history
+func (bs *BalanceService) history(accountNumber types.AccountNumber) []*models.Transaction {
+	history, err := bs.transactionCache.GetAll(accountNumber)
+	if err != nil {
+		return nil
+	}
+	return history
+}
+
+// signed returns the amount of the history line as it changed the balance
+func signed(transaction *models.Transaction) decimal.Decimal {
+	if transaction.Direction == types.Debit {
+		return transaction.Amount.Neg()
+	}
+	return transaction.Amount
+}
+
+// endOfDayBalances returns the balance the account had at the end of every day from the day from starts
+// until the day before until, both are midnights
+func endOfDayBalances(balances balanceReader, accountNumber types.AccountNumber, from time.Time, until time.Time) ([]decimal.Decimal, error) {
+	var endOfDay []decimal.Decimal
+	for day := from; day.Before(until); day = day.AddDate(0, 0, 1) {
+		balance, err := balances.BalanceAt(accountNumber, day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		endOfDay = append(endOfDay, balance.Balance)
+	}
+	return endOfDay, nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockCheckpointCache struct {
+	checkpoints map[types.AccountNumber][]*models.BalanceCheckpoint
+}
+
+func (m *mockCheckpointCache) Add(checkpoint *models.BalanceCheckpoint) {
+	if m.checkpoints == nil {
+		m.checkpoints = make(map[types.AccountNumber][]*models.BalanceCheckpoint)
+	}
+	m.checkpoints[checkpoint.AccountNumber] = append(m.checkpoints[checkpoint.AccountNumber], checkpoint)
+}
+
+func (m *mockCheckpointCache) GetAll(accountNumber types.AccountNumber) []*models.BalanceCheckpoint {
+	return m.checkpoints[accountNumber]
+}
+
+// mockBalanceReader reads the balances from BalanceAtMock
+type mockBalanceReader struct {
+	BalanceAtMock func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal
+}
+
+func (m mockBalanceReader) BalanceAt(accountNumber types.AccountNumber, at time.Time) (*models.HistoricalBalance, error) {
+	return &models.HistoricalBalance{AccountNumber: accountNumber, Balance: m.BalanceAtMock(accountNumber, at), At: at}, nil
+}
+
+func TestBalanceService_BalanceAt(t *testing.T) {
+	t.Run("FromHistory", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+		history := []*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Deposit, Direction: types.Credit, CreatedAt: start},
+			{ID: 2, AccountNumber: 1, Amount: decimal.NewFromInt(30), TransactionType: types.Payment, Direction: types.Debit, CreatedAt: start.Add(time.Hour)},
+			{ID: 4, AccountNumber: 1, Amount: decimal.NewFromInt(1), TransactionType: types.Fee, Direction: types.Debit, CreatedAt: start.Add(2 * time.Hour)},
+		}
+		mockTransactionCach := &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				if accountNumber != 1 {
+					return nil, errs.New(errs.HistoryNotFound)
+				}
+				return history, nil
+			},
+		}
+		mockCheckpointCach := &mockCheckpointCache{}
+		balanceService := NewBalanceService(mockAccountCach, mockTransactionCach, mockCheckpointCach)
+		balanceService.now = func() time.Time { return start.Add(24 * time.Hour) }
+
+		balance, err := balanceService.BalanceAt(1, time.Date(2022, 5, 1, 11, 30, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.True(t, balance.Balance.Equal(decimal.NewFromInt(70)))
+		assert.Equal(t, types.TRY, balance.CurrencyCode)
+
+		balance, err = balanceService.BalanceAt(1, time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.True(t, balance.Balance.IsZero())
+	})
+	t.Run("FromCheckpoint", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+		history := []*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Deposit, Direction: types.Credit, CreatedAt: start},
+			{ID: 2, AccountNumber: 1, Amount: decimal.NewFromInt(30), TransactionType: types.Payment, Direction: types.Debit, CreatedAt: start.Add(time.Hour)},
+			{ID: 4, AccountNumber: 1, Amount: decimal.NewFromInt(1), TransactionType: types.Fee, Direction: types.Debit, CreatedAt: start.Add(2 * time.Hour)},
+		}
+		mockTransactionCach := &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				if accountNumber != 1 {
+					return nil, errs.New(errs.HistoryNotFound)
+				}
+				return history, nil
+			},
+		}
+		mockCheckpointCach := &mockCheckpointCache{}
+		balanceService := NewBalanceService(mockAccountCach, mockTransactionCach, mockCheckpointCach)
+		balanceService.now = func() time.Time { return start.Add(24 * time.Hour) }
+		// A checkpoint that disagrees with the history shows that the lines it covers are not replayed
+		mockCheckpointCach.Add(&models.BalanceCheckpoint{
+			AccountNumber: 1,
+			At:            time.Date(2022, 5, 1, 11, 0, 0, 0, time.UTC),
+			Balance:       decimal.NewFromInt(500),
+			TransactionID: 2,
+		})
+
+		balance, err := balanceService.BalanceAt(1, time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.True(t, balance.Balance.Equal(decimal.NewFromInt(499)))
+
+		balance, err = balanceService.BalanceAt(1, time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		assert.True(t, balance.Balance.Equal(decimal.NewFromInt(100)))
+	})
+	t.Run("Future", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+		history := []*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Deposit, Direction: types.Credit, CreatedAt: start},
+			{ID: 2, AccountNumber: 1, Amount: decimal.NewFromInt(30), TransactionType: types.Payment, Direction: types.Debit, CreatedAt: start.Add(time.Hour)},
+			{ID: 4, AccountNumber: 1, Amount: decimal.NewFromInt(1), TransactionType: types.Fee, Direction: types.Debit, CreatedAt: start.Add(2 * time.Hour)},
+		}
+		mockTransactionCach := &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				if accountNumber != 1 {
+					return nil, errs.New(errs.HistoryNotFound)
+				}
+				return history, nil
+			},
+		}
+		mockCheckpointCach := &mockCheckpointCache{}
+		balanceService := NewBalanceService(mockAccountCach, mockTransactionCach, mockCheckpointCach)
+		balanceService.now = func() time.Time { return start.Add(24 * time.Hour) }
+
+		_, err := balanceService.BalanceAt(1, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
+
+func TestBalanceService_Checkpoint(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		GetAllMock: func() []*models.Account {
+			return []*models.Account{accounts[1], accounts[2], accounts[3]}
+		},
+	}
+	start := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	history := []*models.Transaction{
+		{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Deposit, Direction: types.Credit, CreatedAt: start},
+		{ID: 2, AccountNumber: 1, Amount: decimal.NewFromInt(30), TransactionType: types.Payment, Direction: types.Debit, CreatedAt: start.Add(time.Hour)},
+		{ID: 4, AccountNumber: 1, Amount: decimal.NewFromInt(1), TransactionType: types.Fee, Direction: types.Debit, CreatedAt: start.Add(2 * time.Hour)},
+	}
+	mockTransactionCach := &mockATransactionCache{
+		GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			if accountNumber != 1 {
+				return nil, errs.New(errs.HistoryNotFound)
+			}
+			return history, nil
+		},
+	}
+	mockCheckpointCach := &mockCheckpointCache{}
+	balanceService := NewBalanceService(mockAccountCach, mockTransactionCach, mockCheckpointCach)
+	balanceService.now = func() time.Time { return start.Add(24 * time.Hour) }
+
+	assert.Equal(t, 1, balanceService.Checkpoint())
+	checkpoint := mockCheckpointCach.GetAll(1)[0]
+	assert.True(t, checkpoint.Balance.Equal(decimal.NewFromInt(69)))
+	assert.Equal(t, types.TransactionID(4), checkpoint.TransactionID)
+
+	// Nothing happened since the last checkpoint
+	assert.Equal(t, 0, balanceService.Checkpoint())
+
+	history = append(history, &models.Transaction{ID: 5, AccountNumber: 1, Amount: decimal.NewFromInt(10),
+		TransactionType: types.Deposit, Direction: types.Credit, CreatedAt: time.Date(2022, 5, 1, 13, 0, 0, 0, time.UTC)})
+	assert.Equal(t, 1, balanceService.Checkpoint())
+	assert.True(t, mockCheckpointCach.GetAll(1)[1].Balance.Equal(decimal.NewFromInt(79)))
+}
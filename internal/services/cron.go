@@ -0,0 +1,145 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronHorizon is how far ahead the next run of a cron schedule is looked for,
+// a schedule without a run in it, e.g. the 30th of February, never runs
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// cronSchedule is a parsed five field cron expression: minute, hour, day of month, month and day of week.
+// Every field is a set of the values it matches, a bit per value.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday are set for the fields starting with *, when both days are restricted
+	// a day matching either of them runs, as in cron
+	anyDay, anyWeekday bool
+}
+
+// cronField is the range of the values of a field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// parseCron parses a five field cron expression. A field is *, a value, a range a-b or a list of them
+// separated by commas, each optionally stepped with /n. Sunday is both 0 and 7 in the day of week.
+func parseCron(expression string) (*cronSchedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, errs.Newf(errs.InvalidSchedule, "cron must have %d fields: minute hour day-of-month month day-of-week", len(cronFields))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &cronSchedule{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   sets[4],
+		anyDay:     strings.HasPrefix(parts[2], "*"),
+		anyWeekday: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+func parseCronField(part string, field cronField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, errs.Newf(errs.InvalidSchedule, "cron %s has an invalid step %q", field.name, item)
+			}
+			rangePart, step = item[:i], n
+		}
+
+		from, to := field.min, field.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, errs.Newf(errs.InvalidSchedule, "cron %s has an invalid value %q", field.name, item)
+			}
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, errs.Newf(errs.InvalidSchedule, "cron %s has an invalid value %q", field.name, item)
+				}
+			} else if step > 1 {
+				to = field.max
+			}
+		}
+		if from < field.min || to > field.max || from > to {
+			return 0, errs.Newf(errs.InvalidSchedule, "cron %s must be between %d and %d", field.name, field.min, field.max)
+		}
+
+		for v := from; v <= to; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// next returns the first minute at or after t the schedule runs at, in the location of t,
+// or the zero time if it does not run within the horizon
+func (cs *cronSchedule) next(t time.Time) time.Time {
+	if truncated := t.Truncate(time.Minute); truncated.Before(t) {
+		t = truncated.Add(time.Minute)
+	}
+
+	for end := t.Add(cronHorizon); t.Before(end); {
+		switch {
+		case cs.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !cs.runsOn(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case cs.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case cs.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// runsOn reports whether the schedule runs on the day of t
+func (cs *cronSchedule) runsOn(t time.Time) bool {
+	day := cs.days&(1<<uint(t.Day())) != 0
+	weekday := cs.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case cs.anyDay && cs.anyWeekday:
+		return true
+	case cs.anyDay:
+		return weekday
+	case cs.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Monday
+	from := time.Date(2022, 1, 31, 9, 0, 30, 0, time.UTC)
+	tests := []struct {
+		cron string
+		next time.Time
+	}{
+		{cron: "* * * * *", next: time.Date(2022, 1, 31, 9, 1, 0, 0, time.UTC)},
+		{cron: "*/15 * * * *", next: time.Date(2022, 1, 31, 9, 15, 0, 0, time.UTC)},
+		{cron: "0 9-17/4 * * *", next: time.Date(2022, 1, 31, 13, 0, 0, 0, time.UTC)},
+		{cron: "0 0 1 * *", next: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{cron: "0 12 * * 0", next: time.Date(2022, 2, 6, 12, 0, 0, 0, time.UTC)},
+		{cron: "0 12 * * 7", next: time.Date(2022, 2, 6, 12, 0, 0, 0, time.UTC)},
+		{cron: "0 0 29 2 *", next: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either the 13th or a Friday when both days are restricted
+		{cron: "0 0 13 * 5", next: time.Date(2022, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{cron: "0 0 30 2 *", next: time.Time{}},
+	}
+	for _, test := range tests {
+		schedule, err := parseCron(test.cron)
+		assert.NoError(t, err, test.cron)
+		assert.Equal(t, test.next, schedule.next(from), test.cron)
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	for _, cron := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := parseCron(cron)
+		assert.True(t, errs.Is(err, errs.InvalidSchedule), cron)
+	}
+}
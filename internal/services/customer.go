@@ -0,0 +1,274 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// defaultBalanceCurrency is the currency the balances of a customer are added up in when none is asked for
+const defaultBalanceCurrency = types.TRY
+
+type CustomerService struct {
+	customerCache    customerCache
+	accounts         accountCreator
+	accountCache     accountCache
+	transactionCache transactionCache
+	fx               currencyConverter
+	now              func() time.Time
+
+	// mu serializes opening wallets so that a customer cannot get two wallets in a currency at once
+	mu sync.Mutex
+}
+
+type customerCache interface {
+	Create(customer *models.Customer) *models.Customer
+	Get(id types.CustomerID) (*models.Customer, error)
+	AddWallet(id types.CustomerID, currency types.Currency, accountNumber types.AccountNumber) error
+	SetKYC(id types.CustomerID, kyc *models.KYC) (*models.Customer, error)
+}
+
+// accountCreator opens accounts with the same checks as opening them directly
+type accountCreator interface {
+	Create(account *models.Account) (*models.Account, error)
+}
+
+type currencyConverter interface {
+	Convert(amount decimal.Decimal, from types.Currency, to types.Currency) (decimal.Decimal, decimal.Decimal, error)
+}
+
+func NewCustomerService(cc customerCache, accounts accountCreator, ac accountCache, tc transactionCache, fx currencyConverter) *CustomerService {
+	return &CustomerService{
+		customerCache:    cc,
+		accounts:         accounts,
+		accountCache:     ac,
+		transactionCache: tc,
+		fx:               fx,
+		now:              time.Now,
+	}
+}
+
+func (cs *CustomerService) Create(customer *models.Customer) (*models.Customer, error) {
+	switch customer.CustomerType {
+	case types.Individual, types.Corporate:
+	default:
+		return nil, errs.New(errs.InvalidAccountType)
+	}
+
+	err := validOwnerName(customer.CustomerType, customer.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	kyc, err := cs.checkKYC(&customer.KYC, nil)
+	if err != nil {
+		return nil, err
+	}
+	customer.KYC = *kyc
+	return cs.customerCache.Create(customer), nil
+}
+
+func (cs *CustomerService) Get(id types.CustomerID) (*models.Customer, error) {
+	return cs.customerCache.Get(id)
+}
+
+// UpdateKYC replaces the KYC data of the customer
+func (cs *CustomerService) UpdateKYC(id types.CustomerID, kyc *models.KYC) (*models.Customer, error) {
+	customer, err := cs.customerCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	kyc, err = cs.checkKYC(kyc, &customer.KYC)
+	if err != nil {
+		return nil, err
+	}
+	return cs.customerCache.SetKYC(id, kyc)
+}
+
+// checkKYC validates the KYC data and fills in its status, a customer verified
+// before keeps the time it was first verified at
+func (cs *CustomerService) checkKYC(kyc *models.KYC, previous *models.KYC) (*models.KYC, error) {
+	now := cs.now()
+	if err := kyc.Validate(now); err != nil {
+		return nil, err
+	}
+
+	checked := *kyc
+	if checked.Status == "" {
+		checked.Status = types.KYCPending
+	}
+	checked.VerifiedAt = time.Time{}
+	if checked.Status == types.KYCVerified {
+		checked.VerifiedAt = now
+		if previous != nil && previous.Status == types.KYCVerified {
+			checked.VerifiedAt = previous.VerifiedAt
+		}
+	}
+	return &checked, nil
+}
+
+// OpenWallet opens an account of the customer in the currency
+func (cs *CustomerService) OpenWallet(id types.CustomerID, currency types.Currency) (*models.Account, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	customer, err := cs.customerCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := customer.Wallets[currency]; ok {
+		return nil, errs.Newf(errs.WalletAlreadyExists, "customer %d already has a %s wallet", id, currency)
+	}
+
+	account, err := cs.accounts.Create(&models.Account{
+		CustomerID:   customer.ID,
+		CurrencyCode: currency,
+		OwnerName:    customer.Name,
+		AccountType:  customer.CustomerType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.customerCache.AddWallet(id, currency, account.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Balances adds up the wallets of the customer in the currency,
+// every wallet is converted with the current rate
+func (cs *CustomerService) Balances(id types.CustomerID, currency types.Currency) (*models.CustomerBalances, error) {
+	if currency == "" {
+		currency = defaultBalanceCurrency
+	}
+	switch currency {
+	case types.TRY, types.EUR, types.USD:
+	default:
+		return nil, errs.New(errs.InvalidCurrency)
+	}
+
+	customer, err := cs.customerCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := &models.CustomerBalances{
+		CustomerID:   customer.ID,
+		CurrencyCode: currency,
+		Total:        decimal.Zero,
+		Available:    decimal.Zero,
+	}
+	for _, accountNumber := range customer.SortedWallets() {
+		account, err := cs.accountCache.Get(accountNumber)
+		if err != nil {
+			return nil, err
+		}
+		value, rate, err := cs.fx.Convert(account.Balance, account.CurrencyCode, currency)
+		if err != nil {
+			return nil, err
+		}
+		available, _, err := cs.fx.Convert(account.Available(), account.CurrencyCode, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		balances.Wallets = append(balances.Wallets, &models.WalletBalance{
+			AccountNumber: account.AccountNumber,
+			CurrencyCode:  account.CurrencyCode,
+			Balance:       account.Balance,
+			Available:     account.Available(),
+			Rate:          rate,
+			Value:         value,
+		})
+		balances.Total = balances.Total.Add(value)
+		balances.Available = balances.Available.Add(available)
+	}
+	return balances, nil
+}
+
+// Transfer moves the amount between two wallets of the customer. Wallets of different currencies
+// are credited the amount converted with the current rate, rounded to cents.
+// Transfers stay with the customer, so neither the transfer policy nor the limits apply to them.
+func (cs *CustomerService) Transfer(transfer *models.Transfer) (*models.TransferResult, error) {
+	if !transfer.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if err := transfer.TransactionDetails.Validate(); err != nil {
+		return nil, err
+	}
+	if transfer.FromAccount == transfer.ToAccount {
+		return nil, errs.Newf(errs.InvalidArgument, "a transfer needs two different wallets")
+	}
+
+	customer, err := cs.customerCache.Get(transfer.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := cs.wallet(customer, transfer.FromAccount)
+	if err != nil {
+		return nil, err
+	}
+	to, err := cs.wallet(customer, transfer.ToAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	converted, rate, err := cs.fx.Convert(transfer.Amount, from.CurrencyCode, to.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	if !converted.IsPositive() {
+		return nil, errs.Newf(errs.InvalidAmount, "the amount is less than a cent in %s", to.CurrencyCode)
+	}
+
+	exchangeRate := decimal.Zero
+	if from.CurrencyCode != to.CurrencyCode {
+		exchangeRate = rate
+	}
+	var debit, credit *models.Transaction
+	err = cs.accountCache.Commit([]*models.BalanceChange{
+		{AccountNumber: from.AccountNumber, Amount: transfer.Amount.Neg(), RequireFunds: true, Reason: types.TransferSent},
+		{AccountNumber: to.AccountNumber, Amount: converted, Reason: types.TransferReceived},
+	}, func() {
+		debit = cs.transactionCache.Create(&models.Transaction{
+			AccountNumber:       from.AccountNumber,
+			Amount:              transfer.Amount,
+			TransactionType:     types.Transfer,
+			Direction:           types.Debit,
+			CounterpartyAccount: to.AccountNumber,
+			ExchangeRate:        exchangeRate,
+			TransactionDetails:  transfer.TransactionDetails,
+		})
+		credit = cs.transactionCache.Create(&models.Transaction{
+			ParentID:            debit.ID,
+			AccountNumber:       to.AccountNumber,
+			Amount:              converted,
+			TransactionType:     types.Transfer,
+			Direction:           types.Credit,
+			CounterpartyAccount: from.AccountNumber,
+			ExchangeRate:        exchangeRate,
+			TransactionDetails:  transfer.TransactionDetails,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransferResult{Debit: debit, Credit: credit, Rate: rate}, nil
+}
+
+// wallet returns the account if it is a wallet of the customer
+func (cs *CustomerService) wallet(customer *models.Customer, accountNumber types.AccountNumber) (*models.Account, error) {
+	for _, wallet := range customer.Wallets {
+		if wallet == accountNumber {
+			return cs.accountCache.Get(accountNumber)
+		}
+	}
+	return nil, errs.Newf(errs.WalletNotFound, "account %d is not a wallet of customer %d", accountNumber, customer.ID)
+}
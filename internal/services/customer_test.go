@@ -0,0 +1,401 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockCustomerCache struct {
+	CreateMock    func(customer *models.Customer) *models.Customer
+	GetMock       func(id types.CustomerID) (*models.Customer, error)
+	AddWalletMock func(id types.CustomerID, currency types.Currency, accountNumber types.AccountNumber) error
+	SetKYCMock    func(id types.CustomerID, kyc *models.KYC) (*models.Customer, error)
+}
+
+func (m *mockCustomerCache) Create(customer *models.Customer) *models.Customer {
+	return m.CreateMock(customer)
+}
+
+func (m *mockCustomerCache) Get(id types.CustomerID) (*models.Customer, error) {
+	return m.GetMock(id)
+}
+
+func (m *mockCustomerCache) AddWallet(id types.CustomerID, currency types.Currency, accountNumber types.AccountNumber) error {
+	return m.AddWalletMock(id, currency, accountNumber)
+}
+
+func (m *mockCustomerCache) SetKYC(id types.CustomerID, kyc *models.KYC) (*models.Customer, error) {
+	return m.SetKYCMock(id, kyc)
+}
+
+var customerTestRates = NewFXService([]*models.ExchangeRate{
+	{From: types.USD, To: types.TRY, Rate: decimal.NewFromFloat(32.5)},
+})
+
+func TestCustomerService_Create(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			CreateMock: func(customer *models.Customer) *models.Customer {
+				customer.ID = 1
+				return customer
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, nil, nil, customerTestRates)
+		customerService.now = func() time.Time { return now }
+
+		customer, err := customerService.Create(&models.Customer{
+			Name:         "Ada Lovelace",
+			CustomerType: types.Individual,
+			KYC:          models.KYC{IdentityNumber: "12345678901", Nationality: "TR", Status: types.KYCVerified},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, types.CustomerID(1), customer.ID)
+		assert.Equal(t, types.KYCVerified, customer.KYC.Status)
+		assert.Equal(t, now, customer.KYC.VerifiedAt)
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			CreateMock: func(customer *models.Customer) *models.Customer {
+				return customer
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, nil, nil, customerTestRates)
+
+		customer, err := customerService.Create(&models.Customer{Name: "Apple", CustomerType: types.Corporate})
+		assert.NoError(t, err)
+		assert.Equal(t, types.KYCPending, customer.KYC.Status)
+		assert.True(t, customer.KYC.VerifiedAt.IsZero())
+	})
+
+	t.Run("InvalidKYC", func(t *testing.T) {
+		customerService := NewCustomerService(&mockCustomerCache{}, nil, nil, nil, customerTestRates)
+		customerService.now = func() time.Time { return now }
+
+		for _, kyc := range []models.KYC{
+			{Nationality: "Turkey"},
+			{DateOfBirth: now.AddDate(0, 0, 1)},
+			{IdentityNumber: "123-456"},
+			{Status: types.KYCVerified},
+			{Status: "approved"},
+		} {
+			_, err := customerService.Create(&models.Customer{Name: "Ada Lovelace", CustomerType: types.Individual, KYC: kyc})
+			assert.True(t, errs.Is(err, errs.InvalidArgument))
+		}
+	})
+
+	t.Run("InvalidCustomerType", func(t *testing.T) {
+		customerService := NewCustomerService(&mockCustomerCache{}, nil, nil, nil, customerTestRates)
+
+		_, err := customerService.Create(&models.Customer{Name: "Ada Lovelace", CustomerType: "x"})
+		assert.True(t, errs.Is(err, errs.InvalidAccountType))
+	})
+}
+
+func TestCustomerService_OpenWallet(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		wallets := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := wallets[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		var added types.AccountNumber
+		customerCache.AddWalletMock = func(id types.CustomerID, currency types.Currency, accountNumber types.AccountNumber) error {
+			assert.Equal(t, types.CustomerID(1), id)
+			assert.Equal(t, types.EUR, currency)
+			added = accountNumber
+			return nil
+		}
+		accounts := &mockAccountCache{
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				assert.Equal(t, types.CustomerID(1), account.CustomerID)
+				assert.Equal(t, "Ada Lovelace", account.OwnerName)
+				assert.Equal(t, types.Individual, account.AccountType)
+				account.AccountNumber = 4
+				return account, nil
+			},
+		}
+		customerService := NewCustomerService(customerCache, accounts, accountCache, nil, customerTestRates)
+
+		account, err := customerService.OpenWallet(1, types.EUR)
+		assert.NoError(t, err)
+		assert.Equal(t, types.EUR, account.CurrencyCode)
+		assert.Equal(t, types.AccountNumber(4), added)
+	})
+
+	t.Run("WalletAlreadyExists", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		customerService := NewCustomerService(customerCache, &mockAccountCache{}, accountCache, nil, customerTestRates)
+
+		_, err := customerService.OpenWallet(1, types.USD)
+		assert.True(t, errs.Is(err, errs.WalletAlreadyExists))
+	})
+}
+
+func TestCustomerService_Balances(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, accountCache, nil, customerTestRates)
+
+		balances, err := customerService.Balances(1, "")
+		assert.NoError(t, err)
+		assert.Equal(t, types.TRY, balances.CurrencyCode)
+		assert.Equal(t, 2, len(balances.Wallets))
+		assert.True(t, balances.Wallets[1].Value.Equal(decimal.NewFromFloat(325)))
+		assert.True(t, balances.Total.Equal(decimal.NewFromFloat(1325)))
+
+		balances, err = customerService.Balances(1, types.USD)
+		assert.NoError(t, err)
+		// 1000 TRY is 30.77 USD at the inverted rate
+		assert.True(t, balances.Total.Equal(decimal.NewFromFloat(40.77)))
+	})
+
+	t.Run("RateNotFound", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, accountCache, nil, customerTestRates)
+
+		_, err := customerService.Balances(1, types.EUR)
+		assert.True(t, errs.Is(err, errs.RateNotFound))
+	})
+}
+
+func TestCustomerService_Transfer(t *testing.T) {
+	t.Run("Exchange", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		var applied []*models.BalanceChange
+		accountCache.ApplyChangesMock = func(changes []*models.BalanceChange) error {
+			applied = changes
+			return nil
+		}
+		var history []*models.Transaction
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(history) + 1)
+				history = append(history, transaction)
+				return transaction
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, accountCache, transactionCache, customerTestRates)
+
+		result, err := customerService.Transfer(&models.Transfer{CustomerID: 1, FromAccount: 2, ToAccount: 1, Amount: decimal.NewFromFloat(4)})
+		assert.NoError(t, err)
+		assert.True(t, result.Rate.Equal(decimal.NewFromFloat(32.5)))
+
+		assert.Equal(t, 2, len(applied))
+		assert.True(t, applied[0].Amount.Equal(decimal.NewFromFloat(-4)))
+		assert.True(t, applied[0].RequireFunds)
+		assert.True(t, applied[1].Amount.Equal(decimal.NewFromFloat(130)))
+
+		assert.Equal(t, 2, len(history))
+		assert.Equal(t, types.Transfer, history[0].TransactionType)
+		assert.Equal(t, types.Debit, history[0].Direction)
+		assert.Equal(t, types.Credit, history[1].Direction)
+		assert.Equal(t, history[0].ID, history[1].ParentID)
+		assert.True(t, history[1].Amount.Equal(decimal.NewFromFloat(130)))
+		assert.True(t, history[1].ExchangeRate.Equal(decimal.NewFromFloat(32.5)))
+	})
+
+	t.Run("WalletNotFound", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, accountCache, nil, customerTestRates)
+
+		_, err := customerService.Transfer(&models.Transfer{CustomerID: 1, FromAccount: 1, ToAccount: 3, Amount: decimal.NewFromFloat(4)})
+		assert.True(t, errs.Is(err, errs.WalletNotFound))
+	})
+
+	t.Run("InvalidAmount", func(t *testing.T) {
+		customerCache := &mockCustomerCache{
+			GetMock: func(id types.CustomerID) (*models.Customer, error) {
+				if id != 1 {
+					return nil, errs.New(errs.CustomerNotFound)
+				}
+				return &models.Customer{
+					ID:           1,
+					Name:         "Ada Lovelace",
+					CustomerType: types.Individual,
+					Wallets:      map[types.Currency]types.AccountNumber{types.TRY: 1, types.USD: 2},
+				}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CustomerID: 1, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(1000)},
+			2: {AccountNumber: 2, CustomerID: 1, CurrencyCode: types.USD, Balance: decimal.NewFromFloat(10)},
+			3: {AccountNumber: 3, CustomerID: 2, CurrencyCode: types.TRY, Balance: decimal.NewFromFloat(50)},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		customerService := NewCustomerService(customerCache, nil, accountCache, nil, customerTestRates)
+
+		_, err := customerService.Transfer(&models.Transfer{CustomerID: 1, FromAccount: 1, ToAccount: 2, Amount: decimal.Zero})
+		assert.True(t, errs.Is(err, errs.InvalidAmount))
+		// 0.10 TRY is less than a cent in USD
+		_, err = customerService.Transfer(&models.Transfer{CustomerID: 1, FromAccount: 1, ToAccount: 2, Amount: decimal.NewFromFloat(0.1)})
+		assert.True(t, errs.Is(err, errs.InvalidAmount))
+	})
+}
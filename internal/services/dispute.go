@@ -0,0 +1,279 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// maxDisputeNoteLength is the longest reason of a dispute or note of its resolution
+const maxDisputeNoteLength = 140
+
+type DisputeService struct {
+	disputeCache     disputeCache
+	accountCache     accountCache
+	transactionCache transactionCache
+	houses           houseAccounter
+	now              func() time.Time
+
+	// mu serializes the state changes of disputes so that a payment cannot be disputed twice at once
+	// and a dispute cannot be resolved in favour of both sides
+	mu sync.Mutex
+}
+
+type disputeCache interface {
+	Create(dispute *models.Dispute) *models.Dispute
+	Get(id types.DisputeID) (*models.Dispute, error)
+	GetAll(accountNumber types.AccountNumber) []*models.Dispute
+	GetOpen() []*models.Dispute
+	Update(dispute *models.Dispute) error
+}
+
+// NewDisputeService takes the house accounts the provisional credits are paid from and the chargebacks are paid to
+func NewDisputeService(dc disputeCache, ac accountCache, tc transactionCache, houses houseAccounter) *DisputeService {
+	return &DisputeService{
+		disputeCache:     dc,
+		accountCache:     ac,
+		transactionCache: tc,
+		houses:           houses,
+		now:              time.Now,
+	}
+}
+
+// Open disputes Amount of the payment PaymentID the individual CustomerAccount made, full disputes
+// what is left undisputed of the payment instead. A split payment is disputed by its leg to MerchantAccount. The amount is held on the merchant, even beyond its available balance,
+// and credited to the customer provisionally from the house account of the currency.
+func (ds *DisputeService) Open(dispute *models.Dispute, full bool) (*models.Dispute, error) {
+	if full && !dispute.Amount.IsZero() {
+		return nil, errs.Newf(errs.InvalidArgument, "either amount or full can be given")
+	}
+	if !full && !dispute.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if len([]rune(dispute.Reason)) > maxDisputeNoteLength {
+		return nil, errs.Newf(errs.InvalidArgument, "reason cannot be longer than %d characters", maxDisputeNoteLength)
+	}
+
+	customer, err := ds.accountCache.Get(dispute.CustomerAccount)
+	if err != nil {
+		return nil, err
+	}
+	if customer.AccountType != types.Individual {
+		return nil, errs.Newf(errs.AccountTypeNotAllowed, "disputes can only be opened by individual accounts")
+	}
+
+	payment, err := ds.payment(customer.AccountNumber, dispute.PaymentID, dispute.MerchantAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := ds.accountCache.Get(payment.CounterpartyAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	undisputed := payment.Amount.Sub(ds.disputed(payment))
+	if full {
+		dispute.Amount = undisputed
+	}
+	if !dispute.Amount.IsPositive() || dispute.Amount.GreaterThan(undisputed) {
+		return nil, errs.Newf(errs.DisputeExceedsPayment, "undisputed amount is %s", undisputed.StringFixed(2))
+	}
+
+	houseAccount, err := ds.houses.HouseAccount(customer.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute.MerchantAccount = merchant.AccountNumber
+	dispute.HouseAccount = houseAccount
+	dispute.TransactionDetails = payment.TransactionDetails
+	var credit *models.Transaction
+	err = ds.accountCache.Commit([]*models.BalanceChange{
+		{AccountNumber: merchant.AccountNumber, Held: dispute.Amount, Reason: types.FundsHeld},
+		{AccountNumber: houseAccount, Amount: dispute.Amount.Neg(), Reason: types.ProvisionalCredited},
+		{AccountNumber: customer.AccountNumber, Amount: dispute.Amount, Reason: types.ProvisionalCredited},
+	}, func() {
+		credit = ds.record(dispute, payment.ID, types.ProvisionalCredit, houseAccount, customer.AccountNumber)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := ds.now()
+	dispute.Status = types.DisputeOpen
+	dispute.ProvisionalCreditID = credit.ID
+	dispute.ResolutionID = 0
+	dispute.History = []*models.DisputeTransition{
+		{Status: types.DisputeOpen, Note: dispute.Reason, TransactionID: credit.ID, At: now},
+	}
+	dispute.CreatedAt = now
+	dispute.ResolvedAt = time.Time{}
+	return ds.disputeCache.Create(dispute), nil
+}
+
+// Resolve closes an open dispute with the decision of an operator. A dispute charged back takes the held amount
+// from the merchant to the house account and the provisional credit of the customer becomes final.
+// A released dispute gives the held amount back to the merchant and takes the provisional credit back
+// from the customer, even beyond its available balance.
+func (ds *DisputeService) Resolve(id types.DisputeID, status types.DisputeStatus, note string) (*models.Dispute, error) {
+	if status != types.DisputeChargedBack && status != types.DisputeReleased {
+		return nil, errs.Newf(errs.InvalidArgument, "status must be %s or %s", types.DisputeChargedBack, types.DisputeReleased)
+	}
+	if len([]rune(note)) > maxDisputeNoteLength {
+		return nil, errs.Newf(errs.InvalidArgument, "note cannot be longer than %d characters", maxDisputeNoteLength)
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	dispute, err := ds.disputeCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != types.DisputeOpen {
+		return nil, errs.Newf(errs.DisputeNotOpen, "dispute is %s", dispute.Status)
+	}
+
+	var resolution *models.Transaction
+	if status == types.DisputeChargedBack {
+		err = ds.accountCache.Commit([]*models.BalanceChange{
+			{AccountNumber: dispute.MerchantAccount, Amount: dispute.Amount.Neg(), Held: dispute.Amount.Neg(), Reason: types.ChargedBack},
+			{AccountNumber: dispute.HouseAccount, Amount: dispute.Amount, Reason: types.ChargedBack},
+		}, func() {
+			resolution = ds.record(dispute, dispute.ProvisionalCreditID, types.Chargeback, dispute.MerchantAccount, dispute.HouseAccount)
+		})
+	} else {
+		err = ds.accountCache.Commit([]*models.BalanceChange{
+			{AccountNumber: dispute.MerchantAccount, Held: dispute.Amount.Neg(), Reason: types.FundsReleased},
+			{AccountNumber: dispute.CustomerAccount, Amount: dispute.Amount.Neg(), Reason: types.ProvisionalReversed},
+			{AccountNumber: dispute.HouseAccount, Amount: dispute.Amount, Reason: types.ProvisionalReversed},
+		}, func() {
+			resolution = ds.record(dispute, dispute.ProvisionalCreditID, types.ProvisionalCredit, dispute.CustomerAccount, dispute.HouseAccount)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := ds.now()
+	dispute.Status = status
+	dispute.ResolutionID = resolution.ID
+	dispute.ResolvedAt = now
+	dispute.History = append(dispute.History, &models.DisputeTransition{
+		Status: status, Note: note, TransactionID: resolution.ID, At: now,
+	})
+	err = ds.disputeCache.Update(dispute)
+	if err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+func (ds *DisputeService) Get(id types.DisputeID) (*models.Dispute, error) {
+	return ds.disputeCache.Get(id)
+}
+
+// FindByAccountNumber returns every dispute the account opened or is the merchant of
+func (ds *DisputeService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Dispute, error) {
+	_, err := ds.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	return ds.disputeCache.GetAll(accountNumber), nil
+}
+
+// FindOpen returns the disputes waiting for the decision of an operator
+func (ds *DisputeService) FindOpen() []*models.Dispute {
+	return ds.disputeCache.GetOpen()
+}
+
+// payment returns the line of the history of the customer that paid a merchant and can be disputed.
+// The line of a split payment is returned as its leg to merchant, with the amount the merchant received;
+// merchant is optional for a payment to a single merchant.
+func (ds *DisputeService) payment(accountNumber types.AccountNumber, id types.TransactionID, merchant types.AccountNumber) (*models.Transaction, error) {
+	history, err := ds.transactionCache.GetAll(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range history {
+		if transaction.ID != id {
+			continue
+		}
+		if transaction.TransactionType != types.Payment || transaction.Direction != types.Debit {
+			return nil, errs.Newf(errs.InvalidArgument, "only payments made by the account can be disputed")
+		}
+		if transaction.CounterpartyAccount == 0 {
+			return ds.leg(transaction, merchant)
+		}
+		if merchant != 0 && merchant != transaction.CounterpartyAccount {
+			return nil, errs.Newf(errs.InvalidArgument, "payment %d was not made to account %d", id, merchant)
+		}
+		return transaction, nil
+	}
+	return nil, errs.Newf(errs.InvalidArgument, "transaction %d is not in the history of account %d", id, accountNumber)
+}
+
+// leg returns the split payment as its leg to merchant, found by the payment line the merchant got for it
+func (ds *DisputeService) leg(split *models.Transaction, merchant types.AccountNumber) (*models.Transaction, error) {
+	if merchant == 0 {
+		return nil, errs.Newf(errs.InvalidArgument, "payment %d is split, merchantAccount is required to dispute one of its legs", split.ID)
+	}
+	history, err := ds.transactionCache.GetAll(merchant)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range history {
+		if transaction.ParentID != split.ID || transaction.TransactionType != types.Payment ||
+			transaction.Direction != types.Credit || transaction.CounterpartyAccount != split.AccountNumber {
+			continue
+		}
+		leg := *split
+		leg.Amount = transaction.Amount
+		leg.CounterpartyAccount = merchant
+		return &leg, nil
+	}
+	return nil, errs.Newf(errs.InvalidArgument, "payment %d has no leg to account %d", split.ID, merchant)
+}
+
+// disputed returns how much of the payment, or of the leg of a split payment, is disputed by open disputes or was charged back
+func (ds *DisputeService) disputed(payment *models.Transaction) decimal.Decimal {
+	disputed := decimal.Zero
+	for _, dispute := range ds.disputeCache.GetAll(payment.AccountNumber) {
+		if dispute.PaymentID == payment.ID && dispute.MerchantAccount == payment.CounterpartyAccount &&
+			dispute.Status != types.DisputeReleased {
+			disputed = disputed.Add(dispute.Amount)
+		}
+	}
+	return disputed
+}
+
+// record writes the dispute line of the account the funds left and of the one they went to,
+// the debit line is linked to parent and returned
+func (ds *DisputeService) record(dispute *models.Dispute, parent types.TransactionID, transactionType types.TransactionType, from types.AccountNumber, to types.AccountNumber) *models.Transaction {
+	debit := ds.transactionCache.Create(&models.Transaction{
+		ParentID:            parent,
+		AccountNumber:       from,
+		Amount:              dispute.Amount,
+		TransactionType:     transactionType,
+		Direction:           types.Debit,
+		CounterpartyAccount: to,
+		TransactionDetails:  dispute.TransactionDetails,
+	})
+	ds.transactionCache.Create(&models.Transaction{
+		ParentID:            debit.ID,
+		AccountNumber:       to,
+		Amount:              dispute.Amount,
+		TransactionType:     transactionType,
+		Direction:           types.Credit,
+		CounterpartyAccount: from,
+		TransactionDetails:  dispute.TransactionDetails,
+	})
+	return debit
+}
@@ -0,0 +1,601 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockDisputeCache struct {
+	disputes []*models.Dispute
+}
+
+func (m *mockDisputeCache) Create(dispute *models.Dispute) *models.Dispute {
+	dispute.ID = types.DisputeID(len(m.disputes) + 1)
+	m.disputes = append(m.disputes, dispute)
+	return dispute
+}
+
+func (m *mockDisputeCache) Get(id types.DisputeID) (*models.Dispute, error) {
+	if id < 1 || int(id) > len(m.disputes) {
+		return nil, errs.New(errs.DisputeNotFound)
+	}
+	return m.disputes[id-1], nil
+}
+
+func (m *mockDisputeCache) GetAll(accountNumber types.AccountNumber) []*models.Dispute {
+	var disputes []*models.Dispute
+	for _, d := range m.disputes {
+		if d.CustomerAccount == accountNumber || d.MerchantAccount == accountNumber {
+			disputes = append(disputes, d)
+		}
+	}
+	return disputes
+}
+
+func (m *mockDisputeCache) GetOpen() []*models.Dispute {
+	var disputes []*models.Dispute
+	for _, d := range m.disputes {
+		if d.Status == types.DisputeOpen {
+			disputes = append(disputes, d)
+		}
+	}
+	return disputes
+}
+
+func (m *mockDisputeCache) Update(dispute *models.Dispute) error {
+	if dispute.ID < 1 || int(dispute.ID) > len(m.disputes) {
+		return errs.New(errs.DisputeNotFound)
+	}
+	m.disputes[dispute.ID-1] = dispute
+	return nil
+}
+
+func TestDisputeService_Open(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+
+		dispute, err := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1, Reason: "not delivered"}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, types.DisputeOpen, dispute.Status)
+		assert.Equal(t, types.AccountNumber(2), dispute.MerchantAccount)
+		assert.True(t, dispute.Amount.Equal(decimal.NewFromInt(100)))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(600)))
+		assert.True(t, accounts[2].Held.Equal(decimal.NewFromInt(100)))
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromInt(-100)))
+
+		assert.Equal(t, 4, len(*history))
+		assert.Equal(t, types.ProvisionalCredit, (*history)[2].TransactionType)
+		assert.Equal(t, types.TransactionID(1), (*history)[2].ParentID)
+		assert.Equal(t, dispute.ProvisionalCreditID, (*history)[2].ID)
+		assert.Equal(t, 1, len(dispute.History))
+		assert.Equal(t, "not delivered", dispute.History[0].Note)
+	})
+	t.Run("Partial", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+
+		_, err := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1, Amount: decimal.NewFromInt(60)}, false)
+		assert.NoError(t, err)
+
+		_, err = disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1, Amount: decimal.NewFromInt(50)}, false)
+		assert.True(t, errs.Is(err, errs.DisputeExceedsPayment))
+
+		_, err = disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, false)
+		assert.True(t, errs.Is(err, errs.InvalidAmount))
+
+		dispute, err := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, true)
+		assert.NoError(t, err)
+		assert.True(t, dispute.Amount.Equal(decimal.NewFromInt(40)))
+	})
+	t.Run("SplitLeg", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+		*history = append(*history,
+			&models.Transaction{ID: 3, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit},
+			&models.Transaction{ID: 4, ParentID: 3, AccountNumber: 2, Amount: decimal.NewFromInt(70), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+			&models.Transaction{ID: 5, ParentID: 3, AccountNumber: 3, Amount: decimal.NewFromInt(30), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		)
+
+		_, err := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 3}, true)
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+
+		dispute, err := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 3, MerchantAccount: 2}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, types.AccountNumber(2), dispute.MerchantAccount)
+		assert.True(t, dispute.Amount.Equal(decimal.NewFromInt(70)))
+		assert.True(t, accounts[2].Held.Equal(decimal.NewFromInt(70)))
+		assert.Equal(t, types.TransactionID(3), (*history)[5].ParentID)
+
+		_, err = disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 3, MerchantAccount: 2, Amount: decimal.NewFromInt(1)}, false)
+		assert.True(t, errs.Is(err, errs.DisputeExceedsPayment))
+
+		_, err = disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1, MerchantAccount: 3}, true)
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+	t.Run("NotIndividual", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+
+		_, err := disputeService.Open(&models.Dispute{CustomerAccount: 2, PaymentID: 2}, true)
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+	})
+	t.Run("NotAPayment", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+
+		_, err := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 2}, true)
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
+
+func TestDisputeService_Resolve(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ChargedBack", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+		dispute, _ := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, true)
+
+		dispute, err := disputeService.Resolve(dispute.ID, types.DisputeChargedBack, "merchant did not answer")
+		assert.NoError(t, err)
+		assert.Equal(t, types.DisputeChargedBack, dispute.Status)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(600)))
+		assert.True(t, accounts[2].Balance.IsZero())
+		assert.True(t, accounts[2].Held.IsZero())
+		assert.True(t, accounts[3].Balance.IsZero())
+
+		assert.Equal(t, types.Chargeback, (*history)[4].TransactionType)
+		assert.Equal(t, types.AccountNumber(2), (*history)[4].AccountNumber)
+		assert.Equal(t, dispute.ResolutionID, (*history)[4].ID)
+		assert.Equal(t, 2, len(dispute.History))
+		assert.Equal(t, now, dispute.ResolvedAt)
+	})
+	t.Run("Released", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+		dispute, _ := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, true)
+
+		dispute, err := disputeService.Resolve(dispute.ID, types.DisputeReleased, "")
+		assert.NoError(t, err)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(500)))
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromInt(100)))
+		assert.True(t, accounts[2].Held.IsZero())
+		assert.True(t, accounts[3].Balance.IsZero())
+		assert.Equal(t, types.ProvisionalCredit, (*history)[4].TransactionType)
+		assert.Equal(t, types.AccountNumber(1), (*history)[4].AccountNumber)
+
+		// A released dispute leaves the payment open to a new dispute
+		_, err = disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, true)
+		assert.NoError(t, err)
+	})
+	t.Run("NotOpen", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+		dispute, _ := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, true)
+		_, _ = disputeService.Resolve(dispute.ID, types.DisputeReleased, "")
+
+		_, err := disputeService.Resolve(dispute.ID, types.DisputeChargedBack, "")
+		assert.True(t, errs.Is(err, errs.DisputeNotOpen))
+	})
+	t.Run("InvalidStatus", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(100)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{
+			{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Debit, CounterpartyAccount: 2},
+			{ID: 2, ParentID: 1, AccountNumber: 2, Amount: decimal.NewFromInt(100), TransactionType: types.Payment, Direction: types.Credit, CounterpartyAccount: 1},
+		}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				var lines []*models.Transaction
+				for _, transaction := range *history {
+					if transaction.AccountNumber == accountNumber {
+						lines = append(lines, transaction)
+					}
+				}
+				return lines, nil
+			},
+		}
+		disputeService := NewDisputeService(&mockDisputeCache{}, mockAccountCach, transactionCache, mockHouseAccounter{})
+		disputeService.now = func() time.Time { return now }
+		dispute, _ := disputeService.Open(&models.Dispute{CustomerAccount: 1, PaymentID: 1}, true)
+
+		_, err := disputeService.Resolve(dispute.ID, types.DisputeOpen, "")
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
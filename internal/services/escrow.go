@@ -0,0 +1,373 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// escrowOwnerName is the owner of the accounts that keep the escrowed funds
+const escrowOwnerName = "Tringle Escrow"
+
+type EscrowService struct {
+	escrowCache        escrowCache
+	accountCache       accountCache
+	transactionCache   transactionCache
+	systemAccountCache systemAccountCache
+	policy             policyEvaluator
+	fraud              escrowScorer
+	ttl                time.Duration
+	now                func() time.Time
+	escrowAccounts     map[types.Currency]types.AccountNumber
+	paymentControls
+
+	// mu serializes the state changes of escrows so that an escrow cannot be released and refunded at once
+	mu sync.Mutex
+}
+
+type escrowCache interface {
+	Create(escrow *models.Escrow) *models.Escrow
+	Get(id types.EscrowID) (*models.Escrow, error)
+	GetAll(accountNumber types.AccountNumber) []*models.Escrow
+	GetFunded() []*models.Escrow
+	Update(escrow *models.Escrow) error
+}
+
+type escrowScorer interface {
+	ScoreEscrow(escrow *models.Escrow, ttl time.Duration, sender *models.Account) error
+}
+
+// EscrowServiceOption configures the optional collaborators of the EscrowService
+type EscrowServiceOption func(es *EscrowService)
+
+// WithEscrowControls makes the escrows go through the limits, the sanctions screening and the fees of payments
+func WithEscrowControls(l limitChecker, s paymentScreener, f feeCalculator) EscrowServiceOption {
+	return func(es *EscrowService) {
+		es.paymentControls = paymentControls{limits: l, screening: s, fees: f}
+	}
+}
+
+// NewEscrowService takes the default lifetime of escrows that are funded without a ttl
+func NewEscrowService(ec escrowCache, ac accountCache, tc transactionCache, sc systemAccountCache, policy policyEvaluator,
+	ttl time.Duration, options ...EscrowServiceOption) *EscrowService {
+	es := &EscrowService{
+		escrowCache:        ec,
+		accountCache:       ac,
+		transactionCache:   tc,
+		systemAccountCache: sc,
+		policy:             policy,
+		ttl:                ttl,
+		now:                time.Now,
+		escrowAccounts:     make(map[types.Currency]types.AccountNumber),
+	}
+	for _, option := range options {
+		option(es)
+	}
+	return es
+}
+
+// UseFraudScoring makes the service evaluate the fraud rules before every escrow is funded.
+// It is set after the service is created since the fraud service funds the escrows it approves with this one.
+func (es *EscrowService) UseFraudScoring(fraud escrowScorer) {
+	es.fraud = fraud
+}
+
+// Fund moves the amount of the escrow from the sender to the escrow account of its currency.
+// A zero ttl uses the default lifetime of the service, an escrow without OnTimeout is refunded when it times out.
+// The funding is a payment to the receiver kept in escrow, so it goes through the limits, the screening,
+// the fraud rules and the fees of payments.
+func (es *EscrowService) Fund(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error) {
+	if ttl < 0 {
+		return nil, errs.Newf(errs.InvalidArgument, "ttl cannot be negative")
+	}
+	if ttl == 0 {
+		ttl = es.ttl
+	}
+	return es.fund(escrow, ttl, decimal.Zero)
+}
+
+// FundHeld funds an escrow approved by a fraud review from the amount the review reserved
+func (es *EscrowService) FundHeld(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error) {
+	return es.fund(escrow, ttl, escrow.Amount)
+}
+
+// fund funds the escrow, held is released from the sender in the same update that funds it
+func (es *EscrowService) fund(escrow *models.Escrow, ttl time.Duration, held decimal.Decimal) (*models.Escrow, error) {
+	if !escrow.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if err := escrow.TransactionDetails.Validate(); err != nil {
+		return nil, err
+	}
+	switch escrow.OnTimeout {
+	case "":
+		escrow.OnTimeout = types.EscrowRefunded
+	case types.EscrowReleased, types.EscrowRefunded:
+	default:
+		return nil, errs.Newf(errs.InvalidArgument, "onTimeout must be %s or %s", types.EscrowReleased, types.EscrowRefunded)
+	}
+	if escrow.SenderAccount == escrow.ReceiverAccount {
+		return nil, errs.Newf(errs.InvalidArgument, "the sender and the receiver must be different accounts")
+	}
+
+	sender, err := es.accountCache.Get(escrow.SenderAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err := es.accountCache.Get(escrow.ReceiverAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	// The escrow ends up as a payment to the receiver, so it has to be allowed as one when it is funded
+	err = es.policy.Evaluate(types.Payment, sender, receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	if sender.CurrencyCode != receiver.CurrencyCode {
+		return nil, errs.New(errs.CurrencyMismatch)
+	}
+
+	// The limits of the sender stay locked until the funding is in its history, where they are counted from
+	unlock := es.lockLimits(sender.AccountNumber)
+	defer unlock()
+
+	err = es.checkLimit(sender, types.Payment, escrow.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// An escrow cannot wait for a screening case like a payment, it has to be funded again once the case is cleared
+	err = es.screenParties(sender, receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Escrows funded from a fraud review were already scored
+	if es.fraud != nil && !held.IsPositive() {
+		err = es.fraud.ScoreEscrow(escrow, ttl, sender)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	charges, feeChanges, err := es.feeCharges(types.Payment, sender, receiver, escrow.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	escrowAccount, err := es.escrowAccount(sender.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := append([]*models.BalanceChange{
+		{AccountNumber: sender.AccountNumber, Amount: escrow.Amount.Neg(), Held: held.Neg(), RequireFunds: true, Reason: types.FundsEscrowed},
+		{AccountNumber: escrowAccount, Amount: escrow.Amount, Reason: types.FundsEscrowed},
+	}, feeChanges...)
+	var funding *models.Transaction
+	err = es.accountCache.Commit(changes, func() {
+		funding = es.record(escrow, 0, sender.AccountNumber, escrowAccount)
+		es.recordFees(es.transactionCache, funding, charges)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := es.now()
+	escrow.EscrowAccount = escrowAccount
+	escrow.Status = types.EscrowFunded
+	escrow.TimedOut = false
+	escrow.FundingID = funding.ID
+	escrow.SettlementID = 0
+	escrow.CreatedAt = now
+	escrow.ExpiresAt = now.Add(ttl)
+	escrow.SettledAt = time.Time{}
+	return es.escrowCache.Create(escrow), nil
+}
+
+// Release pays the escrowed funds to the receiver, only the sender can release them
+func (es *EscrowService) Release(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+	return es.settleFunded(id, accountNumber, types.EscrowReleased)
+}
+
+// Refund pays the escrowed funds back to the sender, only the receiver can refund them
+func (es *EscrowService) Refund(id types.EscrowID, accountNumber types.AccountNumber) (*models.Escrow, error) {
+	return es.settleFunded(id, accountNumber, types.EscrowRefunded)
+}
+
+func (es *EscrowService) Get(id types.EscrowID) (*models.Escrow, error) {
+	return es.escrowCache.Get(id)
+}
+
+// FindByAccountNumber returns every escrow the account is the sender or the receiver of
+func (es *EscrowService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Escrow, error) {
+	_, err := es.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	return es.escrowCache.GetAll(accountNumber), nil
+}
+
+// SettleDue settles every funded escrow whose lifetime is over with its OnTimeout
+// and returns how many were settled
+func (es *EscrowService) SettleDue() int {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	settled := 0
+	now := es.now()
+	for _, escrow := range es.escrowCache.GetFunded() {
+		if now.Before(escrow.ExpiresAt) {
+			continue
+		}
+		if es.settle(escrow, escrow.OnTimeout, true) == nil {
+			settled++
+		}
+	}
+	return settled
+}
+
+// settleFunded settles the escrow on the instruction of the account, an escrow found past its lifetime
+// is settled with its OnTimeout on the spot and can no longer be settled otherwise
+func (es *EscrowService) settleFunded(id types.EscrowID, accountNumber types.AccountNumber, status types.EscrowStatus) (*models.Escrow, error) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	escrow, err := es.escrowCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each side can only give up its own claim on the funds
+	instructing, role := escrow.SenderAccount, "sender"
+	if status == types.EscrowRefunded {
+		instructing, role = escrow.ReceiverAccount, "receiver"
+	}
+	if accountNumber != instructing {
+		return nil, errs.Newf(errs.EscrowPartyNotAllowed, "escrow can only be %s by its %s", status, role)
+	}
+
+	if escrow.Status == types.EscrowFunded && !es.now().Before(escrow.ExpiresAt) {
+		err = es.settle(escrow, escrow.OnTimeout, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if escrow.Status != types.EscrowFunded {
+		return nil, errs.Newf(errs.EscrowNotFunded, "escrow is %s", escrow.Status)
+	}
+
+	err = es.settle(escrow, status, false)
+	if err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// settle pays the escrowed funds to the receiver when status is released, or to the sender when it is refunded
+func (es *EscrowService) settle(escrow *models.Escrow, status types.EscrowStatus, timedOut bool) error {
+	payee := escrow.SenderAccount
+	if status == types.EscrowReleased {
+		payee = escrow.ReceiverAccount
+	}
+
+	// The funds are paid out like a payment, so a payee confirmed as a sanctions match cannot get them
+	account, err := es.accountCache.Get(payee)
+	if err != nil {
+		return err
+	}
+	err = es.screenParties(account)
+	if err != nil {
+		return err
+	}
+
+	var settlement *models.Transaction
+	err = es.accountCache.Commit([]*models.BalanceChange{
+		{AccountNumber: escrow.EscrowAccount, Amount: escrow.Amount.Neg(), RequireFunds: true, Reason: types.EscrowSettled},
+		{AccountNumber: payee, Amount: escrow.Amount, Reason: types.EscrowSettled},
+	}, func() {
+		settlement = es.record(escrow, escrow.FundingID, escrow.EscrowAccount, payee)
+	})
+	if err != nil {
+		return err
+	}
+
+	escrow.Status = status
+	escrow.TimedOut = timedOut
+	escrow.SettlementID = settlement.ID
+	escrow.SettledAt = es.now()
+	return es.escrowCache.Update(escrow)
+}
+
+// record writes the escrow line of the account the funds left and of the one they went to,
+// the debit line is linked to parent and returned
+func (es *EscrowService) record(escrow *models.Escrow, parent types.TransactionID, from types.AccountNumber, to types.AccountNumber) *models.Transaction {
+	debit := es.transactionCache.Create(&models.Transaction{
+		ParentID:            parent,
+		AccountNumber:       from,
+		Amount:              escrow.Amount,
+		TransactionType:     types.Escrow,
+		Direction:           types.Debit,
+		CounterpartyAccount: to,
+		TransactionDetails:  escrow.TransactionDetails,
+	})
+	es.transactionCache.Create(&models.Transaction{
+		ParentID:            debit.ID,
+		AccountNumber:       to,
+		Amount:              escrow.Amount,
+		TransactionType:     types.Escrow,
+		Direction:           types.Credit,
+		CounterpartyAccount: from,
+		TransactionDetails:  escrow.TransactionDetails,
+	})
+	return debit
+}
+
+// OpenEscrowAccounts opens the account keeping the escrowed funds of every currency, it is called before any escrow
+// is funded like the house accounts are opened. Every escrow account is recorded as a system account,
+// the ones already recorded, e.g. replayed from the event store, are kept.
+func (es *EscrowService) OpenEscrowAccounts(currencies []types.Currency) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	for _, currency := range currencies {
+		if accountNumber, ok := es.systemAccountCache.Get(types.EscrowHolding, currency); ok {
+			es.escrowAccounts[currency] = accountNumber
+			continue
+		}
+		account, err := es.accountCache.Create(&models.Account{
+			CurrencyCode: currency,
+			OwnerName:    escrowOwnerName,
+			AccountType:  types.Corporate,
+		})
+		if err != nil {
+			return err
+		}
+		es.systemAccountCache.Set(&models.SystemAccount{
+			Role:          types.EscrowHolding,
+			CurrencyCode:  currency,
+			AccountNumber: account.AccountNumber,
+		})
+		es.escrowAccounts[currency] = account.AccountNumber
+	}
+	return nil
+}
+
+// escrowAccount returns the account keeping the escrowed funds of the currency
+func (es *EscrowService) escrowAccount(currency types.Currency) (types.AccountNumber, error) {
+	accountNumber, ok := es.escrowAccounts[currency]
+	if !ok {
+		return 0, errs.Newf(errs.Internal, "no escrow account is open in %s", currency)
+	}
+	return accountNumber, nil
+}
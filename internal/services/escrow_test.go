@@ -0,0 +1,917 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockEscrowCache struct {
+	escrows []*models.Escrow
+}
+
+func (m *mockEscrowCache) Create(escrow *models.Escrow) *models.Escrow {
+	escrow.ID = types.EscrowID(len(m.escrows) + 1)
+	m.escrows = append(m.escrows, escrow)
+	return escrow
+}
+
+func (m *mockEscrowCache) Get(id types.EscrowID) (*models.Escrow, error) {
+	if id < 1 || int(id) > len(m.escrows) {
+		return nil, errs.New(errs.EscrowNotFound)
+	}
+	return m.escrows[id-1], nil
+}
+
+func (m *mockEscrowCache) GetAll(accountNumber types.AccountNumber) []*models.Escrow {
+	var escrows []*models.Escrow
+	for _, e := range m.escrows {
+		if e.SenderAccount == accountNumber || e.ReceiverAccount == accountNumber {
+			escrows = append(escrows, e)
+		}
+	}
+	return escrows
+}
+
+func (m *mockEscrowCache) GetFunded() []*models.Escrow {
+	var escrows []*models.Escrow
+	for _, e := range m.escrows {
+		if e.Status == types.EscrowFunded {
+			escrows = append(escrows, e)
+		}
+	}
+	return escrows
+}
+
+func (m *mockEscrowCache) Update(escrow *models.Escrow) error {
+	if escrow.ID < 1 || int(escrow.ID) > len(m.escrows) {
+		return errs.New(errs.EscrowNotFound)
+	}
+	m.escrows[escrow.ID-1] = escrow
+	return nil
+}
+
+type mockPaymentControls struct {
+	CheckMock         func(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) error
+	ScreenPartiesMock func(accounts ...*models.Account) error
+	ChargesMock       func(transactionType types.TransactionType, sender *models.Account, receiver *models.Account, amount decimal.Decimal) ([]*models.FeeCharge, error)
+}
+
+func (m *mockPaymentControls) Check(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) error {
+	if m.CheckMock == nil {
+		return nil
+	}
+	return m.CheckMock(account, transactionType, amount)
+}
+
+func (m *mockPaymentControls) Lock(accountNumber types.AccountNumber) func() {
+	return func() {}
+}
+
+func (m *mockPaymentControls) ScreenPayment(payment *models.Payment, sender *models.Account, receiver *models.Account) error {
+	return m.ScreenParties(sender, receiver)
+}
+
+func (m *mockPaymentControls) ScreenSplitPayment(split *models.SplitPayment, total decimal.Decimal, sender *models.Account, receivers ...*models.Account) error {
+	return m.ScreenParties(append([]*models.Account{sender}, receivers...)...)
+}
+
+func (m *mockPaymentControls) ScreenParties(accounts ...*models.Account) error {
+	if m.ScreenPartiesMock == nil {
+		return nil
+	}
+	return m.ScreenPartiesMock(accounts...)
+}
+
+func (m *mockPaymentControls) RejectConfirmed(accounts ...*models.Account) error {
+	return m.ScreenParties(accounts...)
+}
+
+func (m *mockPaymentControls) Charges(transactionType types.TransactionType, sender *models.Account, receiver *models.Account, amount decimal.Decimal) ([]*models.FeeCharge, error) {
+	if m.ChargesMock == nil {
+		return nil, nil
+	}
+	return m.ChargesMock(transactionType, sender, receiver, amount)
+}
+
+func TestEscrowService_Fund(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		escrow, err := escrowService.Fund(&models.Escrow{
+			SenderAccount:   1,
+			ReceiverAccount: 2,
+			Amount:          decimal.NewFromFloat(200),
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, types.EscrowFunded, escrow.Status)
+		assert.Equal(t, types.EscrowRefunded, escrow.OnTimeout)
+		assert.Equal(t, now.Add(time.Hour), escrow.ExpiresAt)
+		assert.Equal(t, types.AccountNumber(3), escrow.EscrowAccount)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(300)))
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromFloat(200)))
+
+		assert.Equal(t, 2, len(*history))
+		assert.Equal(t, escrow.FundingID, (*history)[0].ID)
+		assert.Equal(t, types.Escrow, (*history)[0].TransactionType)
+		assert.Equal(t, types.Debit, (*history)[0].Direction)
+		assert.Equal(t, types.AccountNumber(3), (*history)[1].AccountNumber)
+		assert.Equal(t, (*history)[0].ID, (*history)[1].ParentID)
+
+		// The escrow account of the currency is reused
+		escrow, err = escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(50)}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, types.AccountNumber(3), escrow.EscrowAccount)
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		_, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(600)}, 0)
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+		assert.Equal(t, 0, len(*history))
+	})
+	t.Run("AccountTypeNotAllowed", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		_, err := escrowService.Fund(&models.Escrow{SenderAccount: 2, ReceiverAccount: 1, Amount: decimal.NewFromFloat(10)}, 0)
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+	})
+	t.Run("InvalidOnTimeout", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		_, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(10), OnTimeout: types.EscrowFunded}, 0)
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
+
+func TestEscrowService_FundControls(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("LimitExceeded", func(t *testing.T) {
+		controls := &mockPaymentControls{
+			CheckMock: func(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) error {
+				assert.Equal(t, types.Payment, transactionType)
+				return errs.New(errs.LimitExceeded)
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		_, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.True(t, errs.Is(err, errs.LimitExceeded))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(500)))
+		assert.Equal(t, 0, len(*history))
+	})
+	t.Run("SanctionsMatch", func(t *testing.T) {
+		controls := &mockPaymentControls{
+			ScreenPartiesMock: func(accounts ...*models.Account) error {
+				return errs.New(errs.SanctionsMatch)
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		_, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.True(t, errs.Is(err, errs.SanctionsMatch))
+		assert.Equal(t, 0, len(*history))
+	})
+	t.Run("Fees", func(t *testing.T) {
+		controls := &mockPaymentControls{
+			ChargesMock: func(transactionType types.TransactionType, sender *models.Account, receiver *models.Account, amount decimal.Decimal) ([]*models.FeeCharge, error) {
+				return []*models.FeeCharge{{Payer: sender.AccountNumber, HouseAccount: receiver.AccountNumber, Amount: decimal.NewFromFloat(5)}}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+
+		escrow, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(295)))
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(5)))
+
+		assert.Equal(t, 4, len(*history))
+		assert.Equal(t, types.Fee, (*history)[2].TransactionType)
+		assert.Equal(t, escrow.FundingID, (*history)[2].ParentID)
+	})
+	t.Run("FraudReview", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+		fraudService := NewFraudService(&mockFraudCache{}, escrowService.accountCache, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return *history, nil
+			},
+		}, []*models.FraudRule{
+			{Name: "large payment", Kind: types.VelocityRule, TransactionType: types.Payment, Action: types.FraudReview, Window: time.Hour, MaxVolume: decimal.NewFromInt(100)},
+		})
+		escrowService.UseFraudScoring(fraudService)
+		fraudService.UseEscrows(escrowService)
+
+		// The escrow is not funded until the review, its amount is reserved by the alert meanwhile
+		_, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 2*time.Hour)
+		assert.True(t, errs.Is(err, errs.TransactionUnderReview))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromFloat(200)))
+		assert.Equal(t, 0, len(*history))
+
+		alert, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+		assert.Empty(t, alert.TransactionError)
+		assert.Equal(t, types.EscrowID(1), alert.EscrowID)
+
+		escrow, err := escrowService.Get(alert.EscrowID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.EscrowFunded, escrow.Status)
+		assert.Equal(t, escrow.CreatedAt.Add(2*time.Hour), escrow.ExpiresAt)
+		assert.True(t, accounts[1].Held.IsZero())
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(300)))
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromFloat(200)))
+	})
+}
+
+func TestEscrowService_Release(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+		escrow, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		escrow, err = escrowService.Release(escrow.ID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, types.EscrowReleased, escrow.Status)
+		assert.False(t, escrow.TimedOut)
+		assert.Equal(t, now, escrow.SettledAt)
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(200)))
+		assert.True(t, accounts[3].Balance.IsZero())
+
+		assert.Equal(t, 4, len(*history))
+		settlement := (*history)[2]
+		assert.Equal(t, escrow.SettlementID, settlement.ID)
+		assert.Equal(t, escrow.FundingID, settlement.ParentID)
+		assert.Equal(t, types.AccountNumber(2), (*history)[3].AccountNumber)
+		assert.Equal(t, types.Credit, (*history)[3].Direction)
+
+		_, err = escrowService.Refund(escrow.ID, 2)
+		assert.True(t, errs.Is(err, errs.EscrowNotFunded))
+	})
+	t.Run("EscrowPartyNotAllowed", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+		escrow, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		// The receiver cannot release the funds to itself, nor the sender refund them to itself
+		_, err = escrowService.Release(escrow.ID, 2)
+		assert.True(t, errs.Is(err, errs.EscrowPartyNotAllowed))
+		_, err = escrowService.Refund(escrow.ID, 1)
+		assert.True(t, errs.Is(err, errs.EscrowPartyNotAllowed))
+		assert.Equal(t, types.EscrowFunded, escrow.Status)
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromFloat(200)))
+	})
+	t.Run("SanctionsMatch", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+		escrow, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		// A receiver confirmed as a match after the funding cannot be paid
+		controls.ScreenPartiesMock = func(accounts ...*models.Account) error {
+			return errs.New(errs.SanctionsMatch)
+		}
+		_, err = escrowService.Release(escrow.ID, 1)
+		assert.True(t, errs.Is(err, errs.SanctionsMatch))
+		assert.Equal(t, types.EscrowFunded, escrow.Status)
+		assert.True(t, accounts[2].Balance.IsZero())
+	})
+	t.Run("TimedOut", func(t *testing.T) {
+		controls := &mockPaymentControls{}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+				account.Balance = decimal.Zero
+				accounts[account.AccountNumber] = account
+				return account, nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+			WithEscrowControls(controls, controls, controls))
+		assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+		escrowService.now = func() time.Time { return now }
+		escrow, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		escrowService.now = func() time.Time { return now.Add(time.Hour) }
+		_, err = escrowService.Release(escrow.ID, 1)
+		assert.True(t, errs.Is(err, errs.EscrowNotFunded))
+		assert.Equal(t, types.EscrowRefunded, escrow.Status)
+		assert.True(t, escrow.TimedOut)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(500)))
+	})
+}
+
+func TestEscrowService_Refund(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	controls := &mockPaymentControls{}
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+		CreateMock: func(account *models.Account) (*models.Account, error) {
+			account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+			account.Balance = decimal.Zero
+			accounts[account.AccountNumber] = account
+			return account, nil
+		},
+	}
+	history := &[]*models.Transaction{}
+	transactionCache := &mockATransactionCache{
+		CreateMock: func(transaction *models.Transaction) *models.Transaction {
+			transaction.ID = types.TransactionID(len(*history) + 1)
+			*history = append(*history, transaction)
+			return transaction
+		},
+	}
+	escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+		WithEscrowControls(controls, controls, controls))
+	assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+	escrowService.now = func() time.Time { return now }
+	escrow, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+	assert.NoError(t, err)
+
+	escrow, err = escrowService.Refund(escrow.ID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, types.EscrowRefunded, escrow.Status)
+	assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(500)))
+	assert.True(t, accounts[2].Balance.IsZero())
+	assert.Equal(t, types.AccountNumber(1), (*history)[3].AccountNumber)
+	assert.Equal(t, types.Credit, (*history)[3].Direction)
+
+	_, err = escrowService.Release(escrow.ID, 1)
+	assert.True(t, errs.Is(err, errs.EscrowNotFunded))
+}
+
+func TestEscrowService_SettleDue(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	controls := &mockPaymentControls{}
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+		CreateMock: func(account *models.Account) (*models.Account, error) {
+			account.AccountNumber = types.AccountNumber(len(accounts) + 1)
+			account.Balance = decimal.Zero
+			accounts[account.AccountNumber] = account
+			return account, nil
+		},
+	}
+	history := &[]*models.Transaction{}
+	transactionCache := &mockATransactionCache{
+		CreateMock: func(transaction *models.Transaction) *models.Transaction {
+			transaction.ID = types.TransactionID(len(*history) + 1)
+			*history = append(*history, transaction)
+			return transaction
+		},
+	}
+	escrowService := NewEscrowService(&mockEscrowCache{}, mockAccountCach, transactionCache, &mockSystemAccountCache{}, NewPolicyService(DefaultPolicyRules()), time.Hour,
+		WithEscrowControls(controls, controls, controls))
+	assert.NoError(t, escrowService.OpenEscrowAccounts([]types.Currency{types.TRY}))
+	escrowService.now = func() time.Time { return now }
+	released, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(100), OnTimeout: types.EscrowReleased}, 0)
+	assert.NoError(t, err)
+	pending, err := escrowService.Fund(&models.Escrow{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(50)}, 2*time.Hour)
+	assert.NoError(t, err)
+
+	escrowService.now = func() time.Time { return now.Add(time.Hour) }
+	assert.Equal(t, 1, escrowService.SettleDue())
+	assert.Equal(t, types.EscrowReleased, released.Status)
+	assert.True(t, released.TimedOut)
+	assert.Equal(t, types.EscrowFunded, pending.Status)
+	assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(100)))
+	assert.True(t, accounts[3].Balance.Equal(decimal.NewFromFloat(50)))
+}
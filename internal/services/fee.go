@@ -0,0 +1,162 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+)
+
+// houseOwnerName is the owner of the accounts that collect the fees
+const houseOwnerName = "Tringle House Revenue"
+
+type FeeService struct {
+	schedules          []*models.FeeSchedule
+	accountCache       accountCache
+	systemAccountCache systemAccountCache
+
+	mu            sync.Mutex
+	houseAccounts map[types.Currency]types.AccountNumber
+}
+
+// systemAccountCache keeps the numbers of the accounts the bank opened for itself
+type systemAccountCache interface {
+	Get(role types.SystemAccountRole, currency types.Currency) (types.AccountNumber, bool)
+	Set(account *models.SystemAccount)
+}
+
+func NewFeeService(schedules []*models.FeeSchedule, ac accountCache, sc systemAccountCache) *FeeService {
+	return &FeeService{
+		schedules:          schedules,
+		accountCache:       ac,
+		systemAccountCache: sc,
+		houseAccounts:      make(map[types.Currency]types.AccountNumber),
+	}
+}
+
+// Charges returns the fees of a transaction. The first matching schedule of each payer side applies,
+// so specific schedules have to be listed before the generic ones.
+func (fs *FeeService) Charges(transactionType types.TransactionType, sender *models.Account, receiver *models.Account, amount decimal.Decimal) ([]*models.FeeCharge, error) {
+	var charges []*models.FeeCharge
+	charged := make(map[types.FeePayer]bool)
+
+	for _, schedule := range fs.schedules {
+		if schedule.TransactionType != transactionType || charged[schedule.Payer] {
+			continue
+		}
+
+		payer := sender
+		if schedule.Payer == types.ReceiverPays {
+			payer = receiver
+		}
+		if payer == nil {
+			continue
+		}
+		if schedule.AccountType != "" && schedule.AccountType != payer.AccountType {
+			continue
+		}
+		if schedule.Currency != "" && schedule.Currency != payer.CurrencyCode {
+			continue
+		}
+		charged[schedule.Payer] = true
+
+		fee := Fee(schedule, amount)
+		if !fee.IsPositive() {
+			continue
+		}
+
+		houseAccount, err := fs.HouseAccount(payer.CurrencyCode)
+		if err != nil {
+			return nil, err
+		}
+		charges = append(charges, &models.FeeCharge{
+			Schedule:     schedule.Name,
+			Payer:        payer.AccountNumber,
+			HouseAccount: houseAccount,
+			Amount:       fee,
+		})
+	}
+	return charges, nil
+}
+
+// Fee computes the fee of the schedule for the amount rounded to cents
+func Fee(schedule *models.FeeSchedule, amount decimal.Decimal) decimal.Decimal {
+	flat := schedule.Flat
+	percentage := schedule.Percentage
+	for _, tier := range schedule.Tiers {
+		if tier.UpTo.IsZero() || amount.LessThanOrEqual(tier.UpTo) {
+			flat = tier.Flat
+			percentage = tier.Percentage
+			break
+		}
+	}
+
+	fee := flat.Add(amount.Mul(percentage).Div(decimal.NewFromInt(100)))
+	if schedule.Min.IsPositive() && fee.LessThan(schedule.Min) {
+		fee = schedule.Min
+	}
+	if schedule.Max.IsPositive() && fee.GreaterThan(schedule.Max) {
+		fee = schedule.Max
+	}
+	return fee.Round(2)
+}
+
+// OpenHouseAccounts opens the account collecting the revenue of every currency, it is called before any transaction
+// is made so that quoting or charging a fee never opens an account. Every house account is recorded as a system account,
+// the ones already recorded, e.g. replayed from the event store, are kept.
+func (fs *FeeService) OpenHouseAccounts(currencies []types.Currency) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for _, currency := range currencies {
+		if accountNumber, ok := fs.systemAccountCache.Get(types.HouseRevenue, currency); ok {
+			fs.houseAccounts[currency] = accountNumber
+			continue
+		}
+		account, err := fs.accountCache.Create(&models.Account{
+			CurrencyCode: currency,
+			OwnerName:    houseOwnerName,
+			AccountType:  types.Corporate,
+		})
+		if err != nil {
+			return err
+		}
+		fs.systemAccountCache.Set(&models.SystemAccount{
+			Role:          types.HouseRevenue,
+			CurrencyCode:  currency,
+			AccountNumber: account.AccountNumber,
+		})
+		fs.houseAccounts[currency] = account.AccountNumber
+	}
+	return nil
+}
+
+// HouseAccount returns the account collecting the revenue of the currency
+func (fs *FeeService) HouseAccount(currency types.Currency) (types.AccountNumber, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	accountNumber, ok := fs.houseAccounts[currency]
+	if !ok {
+		return 0, errs.Newf(errs.Internal, "no house account is open in %s", currency)
+	}
+	return accountNumber, nil
+}
+
+// Schedules returns the configured fee schedules in the order they are tried
+func (fs *FeeService) Schedules() []*models.FeeSchedule {
+	return fs.schedules
+}
+
+// HouseAccounts returns the accounts collecting the fees of each currency
+func (fs *FeeService) HouseAccounts() map[types.Currency]types.AccountNumber {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	houseAccounts := make(map[types.Currency]types.AccountNumber, len(fs.houseAccounts))
+	for currency, accountNumber := range fs.houseAccounts {
+		houseAccounts[currency] = accountNumber
+	}
+	return houseAccounts
+}
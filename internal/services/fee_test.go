@@ -0,0 +1,131 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type mockSystemAccountCache struct {
+	accounts []*models.SystemAccount
+}
+
+func (m *mockSystemAccountCache) Get(role types.SystemAccountRole, currency types.Currency) (types.AccountNumber, bool) {
+	for _, account := range m.accounts {
+		if account.Role == role && account.CurrencyCode == currency {
+			return account.AccountNumber, true
+		}
+	}
+	return 0, false
+}
+
+func (m *mockSystemAccountCache) Set(account *models.SystemAccount) {
+	m.accounts = append(m.accounts, account)
+}
+
+func TestFee(t *testing.T) {
+	t.Run("Flat", func(t *testing.T) {
+		fee := Fee(&models.FeeSchedule{Flat: decimal.NewFromFloat(2)}, decimal.NewFromFloat(500))
+		assert.True(t, fee.Equal(decimal.NewFromFloat(2)))
+	})
+	t.Run("Percentage", func(t *testing.T) {
+		fee := Fee(&models.FeeSchedule{Percentage: decimal.NewFromFloat(1.5)}, decimal.NewFromFloat(200))
+		assert.True(t, fee.Equal(decimal.NewFromFloat(3)))
+	})
+	t.Run("MinAndMax", func(t *testing.T) {
+		schedule := &models.FeeSchedule{
+			Percentage: decimal.NewFromFloat(1),
+			Min:        decimal.NewFromFloat(0.5),
+			Max:        decimal.NewFromFloat(10),
+		}
+		assert.True(t, Fee(schedule, decimal.NewFromFloat(10)).Equal(decimal.NewFromFloat(0.5)))
+		assert.True(t, Fee(schedule, decimal.NewFromFloat(5000)).Equal(decimal.NewFromFloat(10)))
+	})
+	t.Run("Tiered", func(t *testing.T) {
+		schedule := &models.FeeSchedule{
+			Tiers: []*models.FeeTier{
+				{UpTo: decimal.NewFromFloat(100), Flat: decimal.NewFromFloat(1)},
+				{UpTo: decimal.NewFromFloat(1000), Percentage: decimal.NewFromFloat(1)},
+				{Percentage: decimal.NewFromFloat(0.5)},
+			},
+		}
+		assert.True(t, Fee(schedule, decimal.NewFromFloat(100)).Equal(decimal.NewFromFloat(1)))
+		assert.True(t, Fee(schedule, decimal.NewFromFloat(500)).Equal(decimal.NewFromFloat(5)))
+		assert.True(t, Fee(schedule, decimal.NewFromFloat(2000)).Equal(decimal.NewFromFloat(10)))
+	})
+}
+
+func TestFeeService_Charges(t *testing.T) {
+	individual := &models.Account{AccountNumber: 1, AccountType: types.Individual, CurrencyCode: types.TRY}
+	corporate := &models.Account{AccountNumber: 2, AccountType: types.Corporate, CurrencyCode: types.TRY}
+	schedules := []*models.FeeSchedule{
+		{Name: "merchant", TransactionType: types.Payment, Payer: types.ReceiverPays, AccountType: types.Corporate, Percentage: decimal.NewFromFloat(2)},
+		{Name: "withdraw-usd", TransactionType: types.Withdraw, Payer: types.SenderPays, Currency: types.USD, Flat: decimal.NewFromFloat(1)},
+		{Name: "withdraw", TransactionType: types.Withdraw, Payer: types.SenderPays, Flat: decimal.NewFromFloat(2)},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		created := 0
+		mockAccountCach := mockAccountCache{
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				created++
+				account.AccountNumber = 99
+				return account, nil
+			},
+		}
+		systemAccounts := &mockSystemAccountCache{}
+		feeService := NewFeeService(schedules, &mockAccountCach, systemAccounts)
+		err := feeService.OpenHouseAccounts([]types.Currency{types.TRY})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, created)
+		assert.Equal(t, []*models.SystemAccount{{Role: types.HouseRevenue, CurrencyCode: types.TRY, AccountNumber: 99}}, systemAccounts.accounts)
+
+		// Charging opens no account
+		charges, err := feeService.Charges(types.Payment, individual, corporate, decimal.NewFromFloat(100))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(charges))
+		assert.Equal(t, "merchant", charges[0].Schedule)
+		assert.Equal(t, corporate.AccountNumber, charges[0].Payer)
+		assert.Equal(t, types.AccountNumber(99), charges[0].HouseAccount)
+		assert.True(t, charges[0].Amount.Equal(decimal.NewFromFloat(2)))
+
+		charges, err = feeService.Charges(types.Withdraw, individual, nil, decimal.NewFromFloat(100))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(charges))
+		assert.Equal(t, "withdraw", charges[0].Schedule)
+
+		assert.Equal(t, 1, created)
+		assert.Equal(t, types.AccountNumber(99), feeService.HouseAccounts()[types.TRY])
+	})
+
+	t.Run("RecordedHouseAccount", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				t.Fatal("a recorded house account is not opened again")
+				return nil, nil
+			},
+		}
+		systemAccounts := &mockSystemAccountCache{accounts: []*models.SystemAccount{
+			{Role: types.HouseRevenue, CurrencyCode: types.TRY, AccountNumber: 7},
+		}}
+		feeService := NewFeeService(schedules, &mockAccountCach, systemAccounts)
+		assert.NoError(t, feeService.OpenHouseAccounts([]types.Currency{types.TRY}))
+		assert.Equal(t, types.AccountNumber(7), feeService.HouseAccounts()[types.TRY])
+	})
+
+	t.Run("NoHouseAccount", func(t *testing.T) {
+		feeService := NewFeeService(schedules, &mockAccountCache{}, &mockSystemAccountCache{})
+		_, err := feeService.Charges(types.Payment, individual, corporate, decimal.NewFromFloat(100))
+		assert.True(t, errs.Is(err, errs.Internal))
+	})
+
+	t.Run("NoSchedule", func(t *testing.T) {
+		feeService := NewFeeService(schedules, &mockAccountCache{}, &mockSystemAccountCache{})
+		charges, err := feeService.Charges(types.Deposit, individual, nil, decimal.NewFromFloat(100))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(charges))
+	})
+}
@@ -0,0 +1,399 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fraudActionSeverity orders the actions so that the strictest action of the fired rules is taken
+var fraudActionSeverity = map[types.FraudAction]int{
+	types.FraudAllow:  0,
+	types.FraudReview: 1,
+	types.FraudBlock:  2,
+}
+
+type FraudService struct {
+	fraudCache       fraudCache
+	accountCache     accountCache
+	transactionCache transactionCache
+	transactions     heldTransactionMaker
+	holds            heldHoldPlacer
+	escrows          heldEscrowFunder
+	rules            []*models.FraudRule
+	now              func() time.Time
+
+	// mu serializes the decisions so that an alert cannot be approved and rejected at once
+	mu sync.Mutex
+}
+
+type fraudCache interface {
+	Create(alert *models.FraudAlert) *models.FraudAlert
+	Get(id types.FraudAlertID) (*models.FraudAlert, error)
+	GetByStatus(status types.FraudAlertStatus) []*models.FraudAlert
+	Save(alert *models.FraudAlert)
+}
+
+type heldTransactionMaker interface {
+	NewHeldPayment(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error)
+	NewHeldSplitPayment(split *models.SplitPayment, held decimal.Decimal) (*models.SplitPaymentResult, error)
+	NewHeldWithdraw(withdraw *models.Withdraw, held decimal.Decimal) (*models.Transaction, error)
+}
+
+type heldHoldPlacer interface {
+	PlaceHeld(hold *models.Hold, ttl time.Duration) (*models.Hold, error)
+}
+
+type heldEscrowFunder interface {
+	FundHeld(escrow *models.Escrow, ttl time.Duration) (*models.Escrow, error)
+}
+
+func NewFraudService(fc fraudCache, ac accountCache, tc transactionCache, rules []*models.FraudRule) *FraudService {
+	return &FraudService{
+		fraudCache:       fc,
+		accountCache:     ac,
+		transactionCache: tc,
+		rules:            rules,
+		now:              time.Now,
+	}
+}
+
+// UseTransactions sets the service the transactions held for review are made with once they are approved.
+// It is set after the service is created since that service scores its transactions with this one.
+func (fs *FraudService) UseTransactions(transactions heldTransactionMaker) {
+	fs.transactions = transactions
+}
+
+// UseHolds sets the service the holds held for review are placed with once they are approved,
+// it is set after the service is created for the same reason as UseTransactions
+func (fs *FraudService) UseHolds(holds heldHoldPlacer) {
+	fs.holds = holds
+}
+
+// UseEscrows sets the service the escrows held for review are funded with once they are approved,
+// it is set after the service is created for the same reason as UseTransactions
+func (fs *FraudService) UseEscrows(escrows heldEscrowFunder) {
+	fs.escrows = escrows
+}
+
+// Evaluate returns the rules that fire for the account moving amount out with a transaction of the type
+func (fs *FraudService) Evaluate(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) []*models.FraudHit {
+	var hits []*models.FraudHit
+	for _, rule := range fs.rules {
+		if rule.TransactionType != "" && rule.TransactionType != transactionType ||
+			rule.AccountType != "" && rule.AccountType != account.AccountType ||
+			rule.Currency != "" && rule.Currency != account.CurrencyCode {
+			continue
+		}
+
+		var detail string
+		switch rule.Kind {
+		case types.VelocityRule:
+			detail = fs.velocity(rule, account, transactionType, amount)
+		case types.UnusualAmountRule:
+			detail = fs.unusualAmount(rule, account, transactionType, amount)
+		case types.NewAccountRule:
+			detail = fs.newAccount(rule, account, amount)
+		}
+		if detail != "" {
+			hits = append(hits, &models.FraudHit{
+				Rule:   rule.Name,
+				Kind:   rule.Kind,
+				Action: rule.Action,
+				Detail: detail,
+			})
+		}
+	}
+	return hits
+}
+
+// ScorePayment evaluates the rules for a payment before it is made, see score
+func (fs *FraudService) ScorePayment(payment *models.Payment, sender *models.Account) error {
+	held := *payment
+	held.TransactionDetails = payment.TransactionDetails.Copy()
+	return fs.score(sender, types.Payment, payment.Amount, &models.FraudAlert{Payment: &held})
+}
+
+// ScoreSplitPayment evaluates the rules for a split payment of total before it is made, see score
+func (fs *FraudService) ScoreSplitPayment(split *models.SplitPayment, total decimal.Decimal, sender *models.Account) error {
+	held := *split
+	held.TransactionDetails = split.TransactionDetails.Copy()
+	held.Receivers = make([]*models.SplitReceiver, 0, len(split.Receivers))
+	for _, r := range split.Receivers {
+		receiver := *r
+		held.Receivers = append(held.Receivers, &receiver)
+	}
+	return fs.score(sender, types.Payment, total, &models.FraudAlert{SplitPayment: &held})
+}
+
+// ScoreWithdraw evaluates the rules for a withdrawal before it is made, see score
+func (fs *FraudService) ScoreWithdraw(withdraw *models.Withdraw, account *models.Account) error {
+	held := *withdraw
+	held.TransactionDetails = withdraw.TransactionDetails.Copy()
+	return fs.score(account, types.Withdraw, withdraw.Amount, &models.FraudAlert{Withdraw: &held})
+}
+
+// ScoreHold evaluates the rules for a hold before it is placed as the payment it is captured into, see score
+func (fs *FraudService) ScoreHold(hold *models.Hold, ttl time.Duration, account *models.Account) error {
+	return fs.score(account, types.Payment, hold.Amount, &models.FraudAlert{Hold: hold.Copy(), TTL: ttl})
+}
+
+// ScoreEscrow evaluates the rules for an escrow before it is funded as the payment it is released into, see score
+func (fs *FraudService) ScoreEscrow(escrow *models.Escrow, ttl time.Duration, sender *models.Account) error {
+	return fs.score(sender, types.Payment, escrow.Amount, &models.FraudAlert{Escrow: escrow.Copy(), TTL: ttl})
+}
+
+// Decide approves or rejects a pending alert. The amount held by the alert is released,
+// an approved transaction is made in the same update that releases it.
+func (fs *FraudService) Decide(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error) {
+	if decision.Status != types.FraudAlertApproved && decision.Status != types.FraudAlertRejected {
+		return nil, errs.Newf(errs.InvalidArgument, "status must be %s or %s", types.FraudAlertApproved, types.FraudAlertRejected)
+	}
+	if strings.TrimSpace(decision.Reviewer) == "" {
+		return nil, errs.Newf(errs.InvalidArgument, "reviewer is required")
+	}
+
+	alert, err := fs.decide(id, decision)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transaction is made outside of the lock since a held transaction does not come back to this service
+	if alert.Status == types.FraudAlertApproved {
+		made, err := fs.execute(alert)
+
+		fs.mu.Lock()
+		if err != nil {
+			alert.TransactionError = err.Error()
+		} else {
+			alert.TransactionID = made.TransactionID
+			alert.HoldID = made.HoldID
+			alert.EscrowID = made.EscrowID
+		}
+		fs.fraudCache.Save(alert)
+		fs.mu.Unlock()
+	}
+	return alert, nil
+}
+
+func (fs *FraudService) GetAlert(id types.FraudAlertID) (*models.FraudAlert, error) {
+	return fs.fraudCache.Get(id)
+}
+
+// FindAlerts returns the alerts in the status, or every alert for an empty status
+func (fs *FraudService) FindAlerts(status types.FraudAlertStatus) ([]*models.FraudAlert, error) {
+	switch status {
+	case "", types.FraudAlertAllowed, types.FraudAlertPending, types.FraudAlertBlocked, types.FraudAlertApproved, types.FraudAlertRejected:
+	default:
+		return nil, errs.Newf(errs.InvalidArgument, "unknown status %q", status)
+	}
+	return fs.fraudCache.GetByStatus(status), nil
+}
+
+// score raises an alert when any rule fires and takes the strictest action of the fired rules. Allowed transactions
+// go on, blocked ones are answered with transaction_blocked and the ones to review are answered with
+// transaction_under_review, their amount held on the account until a reviewer decides the alert.
+func (fs *FraudService) score(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal, alert *models.FraudAlert) error {
+	hits := fs.Evaluate(account, transactionType, amount)
+	if len(hits) == 0 {
+		return nil
+	}
+
+	alert.AccountNumber = account.AccountNumber
+	alert.TransactionType = transactionType
+	alert.Amount = amount
+	alert.Hits = hits
+	alert.Action = types.FraudAllow
+	alert.CreatedAt = fs.now()
+	for _, hit := range hits {
+		if fraudActionSeverity[hit.Action] > fraudActionSeverity[alert.Action] {
+			alert.Action = hit.Action
+		}
+	}
+
+	switch alert.Action {
+	case types.FraudBlock:
+		alert.Status = types.FraudAlertBlocked
+		fs.fraudCache.Create(alert)
+		return errs.Newf(errs.TransactionBlocked, "transaction is blocked by fraud alert %d", alert.ID).
+			With("alertId", alert.ID)
+	case types.FraudReview:
+		err := fs.accountCache.ApplyChanges([]*models.BalanceChange{
+			{AccountNumber: account.AccountNumber, Held: amount, RequireFunds: true, Reason: types.FundsHeld},
+		})
+		if err != nil {
+			return err
+		}
+		alert.Status = types.FraudAlertPending
+		fs.fraudCache.Create(alert)
+		return errs.Newf(errs.TransactionUnderReview, "transaction is held in fraud alert %d", alert.ID).
+			With("alertId", alert.ID)
+	default:
+		alert.Status = types.FraudAlertAllowed
+		fs.fraudCache.Create(alert)
+		return nil
+	}
+}
+
+func (fs *FraudService) decide(id types.FraudAlertID, decision *models.FraudDecision) (*models.FraudAlert, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	alert, err := fs.fraudCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Status != types.FraudAlertPending {
+		return nil, errs.Newf(errs.FraudAlertDecided, "alert is %s", alert.Status)
+	}
+
+	if decision.Status == types.FraudAlertRejected {
+		err = fs.release(alert)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	alert.Status = decision.Status
+	alert.Reviewer = decision.Reviewer
+	alert.Note = decision.Note
+	alert.DecidedAt = fs.now()
+	fs.fraudCache.Save(alert)
+	return alert, nil
+}
+
+// execute makes the transaction, places the hold or funds the escrow held by an approved alert and returns
+// the alert with what was made, the reserved amount is released on its own if it cannot be made
+func (fs *FraudService) execute(alert *models.FraudAlert) (*models.FraudAlert, error) {
+	made := &models.FraudAlert{}
+	var err error
+	switch {
+	case alert.Payment != nil && fs.transactions != nil:
+		var transaction *models.Transaction
+		transaction, err = fs.transactions.NewHeldPayment(alert.Payment, alert.Amount)
+		if err == nil {
+			made.TransactionID = transaction.ID
+		}
+	case alert.SplitPayment != nil && fs.transactions != nil:
+		var result *models.SplitPaymentResult
+		result, err = fs.transactions.NewHeldSplitPayment(alert.SplitPayment, alert.Amount)
+		if err == nil {
+			made.TransactionID = result.Payment.ID
+		}
+	case alert.Withdraw != nil && fs.transactions != nil:
+		var transaction *models.Transaction
+		transaction, err = fs.transactions.NewHeldWithdraw(alert.Withdraw, alert.Amount)
+		if err == nil {
+			made.TransactionID = transaction.ID
+		}
+	case alert.Hold != nil && fs.holds != nil:
+		var hold *models.Hold
+		hold, err = fs.holds.PlaceHeld(alert.Hold.Copy(), alert.TTL)
+		if err == nil {
+			made.HoldID = hold.ID
+		}
+	case alert.Escrow != nil && fs.escrows != nil:
+		var escrow *models.Escrow
+		escrow, err = fs.escrows.FundHeld(alert.Escrow.Copy(), alert.TTL)
+		if err == nil {
+			made.EscrowID = escrow.ID
+		}
+	default:
+		err = errs.Newf(errs.Internal, "the transaction held by alert %d cannot be made", alert.ID)
+	}
+	if err != nil {
+		_ = fs.release(alert)
+		return nil, err
+	}
+	return made, nil
+}
+
+// release frees the amount held by the alert
+func (fs *FraudService) release(alert *models.FraudAlert) error {
+	return fs.accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: alert.AccountNumber, Held: alert.Amount.Neg(), Reason: types.FundsReleased},
+	})
+}
+
+// outgoing sums the amount and the count of the transactions of the type
+// that the account made after since, or ever for a zero since
+func (fs *FraudService) outgoing(accountNumber types.AccountNumber, transactionType types.TransactionType, since time.Time) (decimal.Decimal, int) {
+	volume := decimal.Zero
+	count := 0
+
+	// An account without history has not made any transaction
+	transactions, err := fs.transactionCache.GetAll(accountNumber)
+	if err != nil {
+		return volume, count
+	}
+
+	for _, t := range transactions {
+		if spentAs(t) != transactionType || t.Direction != types.Debit || t.CreatedAt.Before(since) {
+			continue
+		}
+		volume = volume.Add(t.Amount)
+		count++
+	}
+	return volume, count
+}
+
+// velocity describes why the rule fires for the transaction, it returns an empty string if the rule does not fire
+func (fs *FraudService) velocity(rule *models.FraudRule, account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) string {
+	if rule.Window <= 0 {
+		return ""
+	}
+
+	volume, count := fs.outgoing(account.AccountNumber, transactionType, fs.now().Add(-rule.Window))
+	volume = volume.Add(amount)
+	count++
+
+	if rule.MaxCount > 0 && count > rule.MaxCount {
+		return fmt.Sprintf("%d %s transactions in %s, more than %d", count, transactionType, rule.Window, rule.MaxCount)
+	}
+	if rule.MaxVolume.IsPositive() && volume.GreaterThan(rule.MaxVolume) {
+		return fmt.Sprintf("%s of %s transactions in %s, more than %s", volume.StringFixed(2), transactionType, rule.Window, rule.MaxVolume.StringFixed(2))
+	}
+	return ""
+}
+
+// unusualAmount describes why the rule fires for the transaction, it returns an empty string if the rule does not fire
+func (fs *FraudService) unusualAmount(rule *models.FraudRule, account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) string {
+	if !rule.Multiplier.IsPositive() {
+		return ""
+	}
+
+	var since time.Time
+	if rule.Window > 0 {
+		since = fs.now().Add(-rule.Window)
+	}
+	volume, count := fs.outgoing(account.AccountNumber, transactionType, since)
+	// An account needs some history before any of its amounts can be unusual
+	if count == 0 || count < rule.MinHistory {
+		return ""
+	}
+
+	average := volume.Div(decimal.NewFromInt(int64(count)))
+	if amount.LessThanOrEqual(average.Mul(rule.Multiplier)) {
+		return ""
+	}
+	return fmt.Sprintf("%s is more than %s times the average %s of the last %d %s transactions",
+		amount.StringFixed(2), rule.Multiplier.String(), average.StringFixed(2), count, transactionType)
+}
+
+// newAccount describes why the rule fires for the transaction, it returns an empty string if the rule does not fire
+func (fs *FraudService) newAccount(rule *models.FraudRule, account *models.Account, amount decimal.Decimal) string {
+	if rule.AccountAge <= 0 || account.CreatedAt.IsZero() || amount.LessThan(rule.MinAmount) {
+		return ""
+	}
+
+	age := fs.now().Sub(account.CreatedAt)
+	if age >= rule.AccountAge {
+		return ""
+	}
+	return fmt.Sprintf("%s from an account opened %s ago", amount.StringFixed(2), age.Truncate(time.Second))
+}
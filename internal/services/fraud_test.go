@@ -0,0 +1,572 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockFraudCache struct {
+	alerts []*models.FraudAlert
+}
+
+func (m *mockFraudCache) Create(alert *models.FraudAlert) *models.FraudAlert {
+	alert.ID = types.FraudAlertID(len(m.alerts) + 1)
+	m.alerts = append(m.alerts, alert)
+	return alert
+}
+
+func (m *mockFraudCache) Get(id types.FraudAlertID) (*models.FraudAlert, error) {
+	if id < 1 || int(id) > len(m.alerts) {
+		return nil, errs.New(errs.FraudAlertNotFound)
+	}
+	return m.alerts[id-1], nil
+}
+
+func (m *mockFraudCache) Save(alert *models.FraudAlert) {}
+
+func (m *mockFraudCache) GetByStatus(status types.FraudAlertStatus) []*models.FraudAlert {
+	var alerts []*models.FraudAlert
+	for _, a := range m.alerts {
+		if status == "" || a.Status == status {
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+type mockHeldTransactionMaker struct {
+	NewHeldPaymentMock      func(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error)
+	NewHeldSplitPaymentMock func(split *models.SplitPayment, held decimal.Decimal) (*models.SplitPaymentResult, error)
+	NewHeldWithdrawMock     func(withdraw *models.Withdraw, held decimal.Decimal) (*models.Transaction, error)
+}
+
+func (m *mockHeldTransactionMaker) NewHeldPayment(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error) {
+	return m.NewHeldPaymentMock(payment, held)
+}
+
+func (m *mockHeldTransactionMaker) NewHeldSplitPayment(split *models.SplitPayment, held decimal.Decimal) (*models.SplitPaymentResult, error) {
+	return m.NewHeldSplitPaymentMock(split, held)
+}
+
+func (m *mockHeldTransactionMaker) NewHeldWithdraw(withdraw *models.Withdraw, held decimal.Decimal) (*models.Transaction, error) {
+	return m.NewHeldWithdrawMock(withdraw, held)
+}
+
+var fraudTestNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// withdrawals returns count withdrawals of amount made ago
+func withdrawals(count int, amount int64, ago time.Duration) []*models.Transaction {
+	var transactions []*models.Transaction
+	for i := 0; i < count; i++ {
+		transactions = append(transactions, &models.Transaction{
+			AccountNumber:   1,
+			Amount:          decimal.NewFromInt(amount),
+			TransactionType: types.Withdraw,
+			Direction:       types.Debit,
+			CreatedAt:       fraudTestNow.Add(-ago),
+		})
+	}
+	return transactions
+}
+
+func TestFraudService_Evaluate(t *testing.T) {
+	t.Run("Velocity", func(t *testing.T) {
+		history := append(withdrawals(4, 10, 30*time.Second), withdrawals(10, 10, time.Hour)...)
+		rules := []*models.FraudRule{
+			{Name: "withdrawal burst", Kind: types.VelocityRule, TransactionType: types.Withdraw, Action: types.FraudReview, Window: time.Minute, MaxCount: 5},
+			{Name: "payment burst", Kind: types.VelocityRule, TransactionType: types.Payment, Action: types.FraudReview, Window: time.Minute, MaxCount: 1},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+
+		assert.Equal(t, 0, len(fraudService.Evaluate(accounts[1], types.Withdraw, decimal.NewFromInt(10))))
+
+		history = append(history, withdrawals(1, 10, time.Second)...)
+		hits := fraudService.Evaluate(accounts[1], types.Withdraw, decimal.NewFromInt(10))
+		assert.Equal(t, 1, len(hits))
+		assert.Equal(t, "withdrawal burst", hits[0].Rule)
+		assert.Equal(t, types.VelocityRule, hits[0].Kind)
+	})
+	t.Run("UnusualAmount", func(t *testing.T) {
+		history := withdrawals(4, 10, 24*time.Hour)
+		rules := []*models.FraudRule{
+			{Name: "unusual amount", Kind: types.UnusualAmountRule, Action: types.FraudReview, Multiplier: decimal.NewFromInt(10), MinHistory: 5},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+
+		// Too little history to tell what is usual
+		assert.Equal(t, 0, len(fraudService.Evaluate(accounts[1], types.Withdraw, decimal.NewFromInt(150))))
+
+		history = append(history, withdrawals(1, 10, 24*time.Hour)...)
+		assert.Equal(t, 0, len(fraudService.Evaluate(accounts[1], types.Withdraw, decimal.NewFromInt(100))))
+		assert.Equal(t, 1, len(fraudService.Evaluate(accounts[1], types.Withdraw, decimal.NewFromInt(150))))
+		assert.Equal(t, 0, len(fraudService.Evaluate(accounts[1], types.Payment, decimal.NewFromInt(150))))
+	})
+	t.Run("NewAccount", func(t *testing.T) {
+		history := []*models.Transaction{}
+		rules := []*models.FraudRule{
+			{Name: "new account large transfer", Kind: types.NewAccountRule, AccountType: types.Individual, Action: types.FraudBlock,
+				AccountAge: 7 * 24 * time.Hour, MinAmount: decimal.NewFromInt(1000)},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		accounts[1].CreatedAt = fraudTestNow.Add(-24 * time.Hour)
+
+		assert.Equal(t, 0, len(fraudService.Evaluate(accounts[1], types.Payment, decimal.NewFromInt(999))))
+		assert.Equal(t, 1, len(fraudService.Evaluate(accounts[1], types.Payment, decimal.NewFromInt(1000))))
+
+		accounts[1].CreatedAt = fraudTestNow.Add(-8 * 24 * time.Hour)
+		assert.Equal(t, 0, len(fraudService.Evaluate(accounts[1], types.Payment, decimal.NewFromInt(1000))))
+	})
+}
+
+func TestFraudService_ScoreWithdraw(t *testing.T) {
+	history := withdrawals(5, 10, 30*time.Second)
+	withdraw := &models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(100)}
+
+	t.Run("Review", func(t *testing.T) {
+		rules := []*models.FraudRule{
+			{Name: "withdrawal burst", Kind: types.VelocityRule, Action: types.FraudReview, Window: time.Minute, MaxCount: 5},
+			{Name: "log", Kind: types.VelocityRule, Action: types.FraudAllow, Window: time.Minute, MaxCount: 1},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+
+		err := fraudService.ScoreWithdraw(withdraw, accounts[1])
+		assert.True(t, errs.Is(err, errs.TransactionUnderReview))
+		assert.Equal(t, types.FraudAlertID(1), err.(*errs.Error).Extensions["alertId"])
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(100)))
+
+		alert, err := fraudService.GetAlert(1)
+		assert.NoError(t, err)
+		assert.Equal(t, types.FraudReview, alert.Action)
+		assert.Equal(t, types.FraudAlertPending, alert.Status)
+		assert.Equal(t, 2, len(alert.Hits))
+		assert.True(t, alert.Withdraw.Amount.Equal(decimal.NewFromInt(100)))
+	})
+	t.Run("Block", func(t *testing.T) {
+		rules := []*models.FraudRule{
+			{Name: "withdrawal burst", Kind: types.VelocityRule, Action: types.FraudBlock, Window: time.Minute, MaxCount: 5},
+			{Name: "frequent withdrawals", Kind: types.VelocityRule, Action: types.FraudReview, Window: time.Minute, MaxCount: 3},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+
+		err := fraudService.ScoreWithdraw(withdraw, accounts[1])
+		assert.True(t, errs.Is(err, errs.TransactionBlocked))
+		assert.True(t, accounts[1].Held.IsZero())
+
+		alerts, err := fraudService.FindAlerts(types.FraudAlertBlocked)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(alerts))
+	})
+	t.Run("Allow", func(t *testing.T) {
+		rules := []*models.FraudRule{
+			{Name: "log", Kind: types.VelocityRule, Action: types.FraudAllow, Window: time.Minute, MaxCount: 5},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+
+		err := fraudService.ScoreWithdraw(withdraw, accounts[1])
+		assert.NoError(t, err)
+
+		alert, err := fraudService.GetAlert(1)
+		assert.NoError(t, err)
+		assert.Equal(t, types.FraudAlertAllowed, alert.Status)
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		rules := []*models.FraudRule{
+			{Name: "withdrawal burst", Kind: types.VelocityRule, Action: types.FraudReview, Window: time.Minute, MaxCount: 5},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+
+		err := fraudService.ScoreWithdraw(&models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(600)}, accounts[1])
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+		alerts, _ := fraudService.FindAlerts("")
+		assert.Equal(t, 0, len(alerts))
+	})
+}
+
+func TestFraudService_Decide(t *testing.T) {
+	history := withdrawals(5, 10, 30*time.Second)
+	rules := []*models.FraudRule{
+		{Name: "withdrawal burst", Kind: types.VelocityRule, Action: types.FraudReview, Window: time.Minute, MaxCount: 5},
+	}
+	withdraw := &models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(100)}
+
+	t.Run("Approved", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		fraudService.UseTransactions(&mockHeldTransactionMaker{
+			NewHeldWithdrawMock: func(w *models.Withdraw, held decimal.Decimal) (*models.Transaction, error) {
+				assert.True(t, held.Equal(decimal.NewFromInt(100)))
+				accounts[1].Balance = accounts[1].Balance.Sub(w.Amount)
+				accounts[1].Held = accounts[1].Held.Sub(held)
+				return &models.Transaction{ID: 9}, nil
+			},
+		})
+		_ = fraudService.ScoreWithdraw(withdraw, accounts[1])
+
+		alert, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk", Note: "known payee"})
+		assert.NoError(t, err)
+		assert.Equal(t, types.FraudAlertApproved, alert.Status)
+		assert.Equal(t, types.TransactionID(9), alert.TransactionID)
+		assert.Equal(t, fraudTestNow, alert.DecidedAt)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(400)))
+		assert.True(t, accounts[1].Held.IsZero())
+	})
+	t.Run("ApprovedTransactionFails", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		fraudService.UseTransactions(&mockHeldTransactionMaker{
+			NewHeldWithdrawMock: func(w *models.Withdraw, held decimal.Decimal) (*models.Transaction, error) {
+				return nil, errs.New(errs.LimitExceeded)
+			},
+		})
+		_ = fraudService.ScoreWithdraw(withdraw, accounts[1])
+
+		alert, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, alert.TransactionError)
+		assert.True(t, accounts[1].Held.IsZero())
+	})
+	t.Run("Rejected", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		_ = fraudService.ScoreWithdraw(withdraw, accounts[1])
+
+		alert, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertRejected, Reviewer: "risk"})
+		assert.NoError(t, err)
+		assert.Equal(t, types.FraudAlertRejected, alert.Status)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(500)))
+		assert.True(t, accounts[1].Held.IsZero())
+
+		_, err = fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.True(t, errs.Is(err, errs.FraudAlertDecided))
+	})
+	t.Run("InvalidDecision", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		_ = fraudService.ScoreWithdraw(withdraw, accounts[1])
+
+		_, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertBlocked, Reviewer: "risk"})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+		_, err = fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+)
+
+// rateDecimals is the precision rates configured only the other way round are inverted to
+const rateDecimals = 8
+
+type currencyPair struct {
+	from types.Currency
+	to   types.Currency
+}
+
+// FXService converts amounts between currencies with the configured rates
+type FXService struct {
+	rates map[currencyPair]decimal.Decimal
+}
+
+func NewFXService(rates []*models.ExchangeRate) *FXService {
+	fx := &FXService{rates: make(map[currencyPair]decimal.Decimal)}
+	for _, r := range rates {
+		if r.Rate.IsPositive() {
+			fx.rates[currencyPair{r.From, r.To}] = r.Rate
+		}
+	}
+	return fx
+}
+
+// Rate returns how many units of to one unit of from buys,
+// a rate that is only configured the other way round is inverted
+func (fx *FXService) Rate(from types.Currency, to types.Currency) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if rate, ok := fx.rates[currencyPair{from, to}]; ok {
+		return rate, nil
+	}
+	if rate, ok := fx.rates[currencyPair{to, from}]; ok {
+		return decimal.NewFromInt(1).DivRound(rate, rateDecimals), nil
+	}
+	return decimal.Zero, errs.Newf(errs.RateNotFound, "%s to %s", from, to)
+}
+
+// Convert returns the amount in the currency to rounded to cents and the rate it was converted with
+func (fx *FXService) Convert(amount decimal.Decimal, from types.Currency, to types.Currency) (decimal.Decimal, decimal.Decimal, error) {
+	rate, err := fx.Rate(from, to)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	return amount.Mul(rate).Round(2), rate, nil
+}
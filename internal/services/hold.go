@@ -0,0 +1,284 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+type HoldService struct {
+	holdCache    holdCache
+	accountCache accountCache
+	payments     heldPaymentMaker
+	policy       policyEvaluator
+	fraud        holdScorer
+	ttl          time.Duration
+	now          func() time.Time
+
+	// mu serializes the state changes of holds so that a hold cannot be captured and voided at once
+	mu sync.Mutex
+}
+
+type holdCache interface {
+	Create(hold *models.Hold) *models.Hold
+	Get(id types.HoldID) (*models.Hold, error)
+	GetAll(accountNumber types.AccountNumber) []*models.Hold
+	GetActive() []*models.Hold
+	Update(hold *models.Hold) error
+}
+
+type heldPaymentMaker interface {
+	NewHeldPayment(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error)
+}
+
+type holdScorer interface {
+	ScoreHold(hold *models.Hold, ttl time.Duration, account *models.Account) error
+}
+
+// NewHoldService takes the default lifetime of holds that are placed without a ttl
+func NewHoldService(hc holdCache, ac accountCache, payments heldPaymentMaker, policy policyEvaluator, ttl time.Duration) *HoldService {
+	return &HoldService{
+		holdCache:    hc,
+		accountCache: ac,
+		payments:     payments,
+		policy:       policy,
+		ttl:          ttl,
+		now:          time.Now,
+	}
+}
+
+// UseFraudScoring makes the service evaluate the fraud rules before every hold is placed,
+// the payments captured from holds are not scored again. It is set after the service is created
+// since the fraud service places the holds it approves with this one.
+func (hs *HoldService) UseFraudScoring(fraud holdScorer) {
+	hs.fraud = fraud
+}
+
+// Place reserves the amount of the hold on its account,
+// a zero ttl uses the default lifetime of the service
+func (hs *HoldService) Place(hold *models.Hold, ttl time.Duration) (*models.Hold, error) {
+	if !hold.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if ttl < 0 {
+		return nil, errs.Newf(errs.InvalidArgument, "ttl cannot be negative")
+	}
+	if ttl == 0 {
+		ttl = hs.ttl
+	}
+
+	account, err := hs.validate(hold)
+	if err != nil {
+		return nil, err
+	}
+
+	// A hold is scored as the payment it is captured into, a hold to review is placed once it is approved
+	if hs.fraud != nil {
+		err = hs.fraud.ScoreHold(hold, ttl, account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = hs.accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: account.AccountNumber, Held: hold.Amount, RequireFunds: true, Reason: types.FundsHeld},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hs.create(hold, ttl), nil
+}
+
+// PlaceHeld places a hold approved by a fraud review, the amount the review reserved becomes the hold
+func (hs *HoldService) PlaceHeld(hold *models.Hold, ttl time.Duration) (*models.Hold, error) {
+	_, err := hs.validate(hold)
+	if err != nil {
+		return nil, err
+	}
+	return hs.create(hold, ttl), nil
+}
+
+// validate returns the account of the hold if a payment from it to the receiver can be made
+func (hs *HoldService) validate(hold *models.Hold) (*models.Account, error) {
+	account, err := hs.accountCache.Get(hold.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err := hs.accountCache.Get(hold.ReceiverAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.AccountType != types.Individual {
+		return nil, errs.Newf(errs.AccountTypeNotAllowed, "holds can only be placed on individual accounts")
+	}
+
+	// The hold has to be capturable into a payment when it is placed
+	err = hs.policy.Evaluate(types.Payment, account, receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CurrencyCode != receiver.CurrencyCode {
+		return nil, errs.New(errs.CurrencyMismatch)
+	}
+	return account, nil
+}
+
+func (hs *HoldService) create(hold *models.Hold, ttl time.Duration) *models.Hold {
+	now := hs.now()
+	hold.Captured = decimal.Zero
+	hold.Status = types.HoldActive
+	hold.CreatedAt = now
+	hold.ExpiresAt = now.Add(ttl)
+	return hs.holdCache.Create(hold)
+}
+
+// Capture pays amount of the hold to its receiver and releases the rest of it,
+// full captures the whole hold instead
+func (hs *HoldService) Capture(id types.HoldID, amount decimal.Decimal, full bool) (*models.Hold, error) {
+	if full && !amount.IsZero() {
+		return nil, errs.Newf(errs.InvalidArgument, "either amount or full can be given")
+	}
+	if !full && !amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hold, err := hs.activeHold(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if full {
+		amount = hold.Amount
+	}
+	if amount.GreaterThan(hold.Amount) {
+		return nil, errs.Newf(errs.CaptureExceedsHold, "hold is %s", hold.Amount.StringFixed(2))
+	}
+
+	// The uncaptured part is released first so that the payment only sees the captured part as held
+	remainder := hold.Amount.Sub(amount)
+	if remainder.IsPositive() {
+		err = hs.release(hold, remainder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transaction, err := hs.payments.NewHeldPayment(&models.Payment{
+		SenderAccount:   hold.AccountNumber,
+		ReceiverAccount: hold.ReceiverAccount,
+		Amount:          amount,
+	}, amount)
+	if err != nil {
+		// Put the released part back so that a failed capture leaves the hold as it was
+		if remainder.IsPositive() {
+			_ = hs.accountCache.ApplyChanges([]*models.BalanceChange{
+				{AccountNumber: hold.AccountNumber, Held: remainder, Reason: types.FundsHeld},
+			})
+		}
+		return nil, err
+	}
+
+	hold.Captured = amount
+	hold.PaymentID = transaction.ID
+	hold.Status = types.HoldCaptured
+	err = hs.holdCache.Update(hold)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// Void releases the whole hold without paying anything
+func (hs *HoldService) Void(id types.HoldID) (*models.Hold, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	hold, err := hs.activeHold(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hs.release(hold, hold.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.Status = types.HoldVoided
+	err = hs.holdCache.Update(hold)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (hs *HoldService) Get(id types.HoldID) (*models.Hold, error) {
+	return hs.holdCache.Get(id)
+}
+
+// FindByAccountNumber returns every hold placed on the account
+func (hs *HoldService) FindByAccountNumber(accountNumber types.AccountNumber) []*models.Hold {
+	return hs.holdCache.GetAll(accountNumber)
+}
+
+// ExpireDue releases every active hold whose lifetime is over and returns how many were expired
+func (hs *HoldService) ExpireDue() int {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	expired := 0
+	now := hs.now()
+	for _, hold := range hs.holdCache.GetActive() {
+		if now.Before(hold.ExpiresAt) {
+			continue
+		}
+		if hs.expire(hold) == nil {
+			expired++
+		}
+	}
+	return expired
+}
+
+// activeHold returns the hold if it can still be captured or voided,
+// a hold found past its lifetime is expired on the spot
+func (hs *HoldService) activeHold(id types.HoldID) (*models.Hold, error) {
+	hold, err := hs.holdCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.Status == types.HoldActive && !hs.now().Before(hold.ExpiresAt) {
+		err = hs.expire(hold)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hold.Status != types.HoldActive {
+		return nil, errs.Newf(errs.HoldNotActive, "hold is %s", hold.Status)
+	}
+	return hold, nil
+}
+
+func (hs *HoldService) expire(hold *models.Hold) error {
+	err := hs.release(hold, hold.Amount)
+	if err != nil {
+		return err
+	}
+	hold.Status = types.HoldExpired
+	return hs.holdCache.Update(hold)
+}
+
+func (hs *HoldService) release(hold *models.Hold, amount decimal.Decimal) error {
+	return hs.accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: hold.AccountNumber, Held: amount.Neg(), Reason: types.FundsReleased},
+	})
+}
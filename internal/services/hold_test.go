@@ -0,0 +1,492 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockHoldCache struct {
+	holds []*models.Hold
+}
+
+func (m *mockHoldCache) Create(hold *models.Hold) *models.Hold {
+	hold.ID = types.HoldID(len(m.holds) + 1)
+	m.holds = append(m.holds, hold)
+	return hold
+}
+
+func (m *mockHoldCache) Get(id types.HoldID) (*models.Hold, error) {
+	if id < 1 || int(id) > len(m.holds) {
+		return nil, errs.New(errs.HoldNotFound)
+	}
+	return m.holds[id-1], nil
+}
+
+func (m *mockHoldCache) GetAll(accountNumber types.AccountNumber) []*models.Hold {
+	var holds []*models.Hold
+	for _, h := range m.holds {
+		if h.AccountNumber == accountNumber {
+			holds = append(holds, h)
+		}
+	}
+	return holds
+}
+
+func (m *mockHoldCache) GetActive() []*models.Hold {
+	var holds []*models.Hold
+	for _, h := range m.holds {
+		if h.Status == types.HoldActive {
+			holds = append(holds, h)
+		}
+	}
+	return holds
+}
+
+func (m *mockHoldCache) Update(hold *models.Hold) error {
+	if hold.ID < 1 || int(hold.ID) > len(m.holds) {
+		return errs.New(errs.HoldNotFound)
+	}
+	m.holds[hold.ID-1] = hold
+	return nil
+}
+
+type mockHeldPaymentMaker struct {
+	NewHeldPaymentMock func(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error)
+}
+
+func (m *mockHeldPaymentMaker) NewHeldPayment(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error) {
+	return m.NewHeldPaymentMock(payment, held)
+}
+
+func TestHoldService_Place(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+
+		hold, err := holdService.Place(&models.Hold{
+			AccountNumber:   1,
+			ReceiverAccount: 2,
+			Amount:          decimal.NewFromFloat(200),
+		}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, types.HoldActive, hold.Status)
+		assert.Equal(t, hold.CreatedAt.Add(time.Hour), hold.ExpiresAt)
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromFloat(200)))
+		assert.True(t, accounts[1].Available().Equal(decimal.NewFromFloat(300)))
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+
+		_, err := holdService.Place(&models.Hold{
+			AccountNumber:   1,
+			ReceiverAccount: 2,
+			Amount:          decimal.NewFromFloat(600),
+		}, 0)
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+	})
+	t.Run("CorporateAccount", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+
+		_, err := holdService.Place(&models.Hold{
+			AccountNumber:   2,
+			ReceiverAccount: 1,
+			Amount:          decimal.NewFromFloat(10),
+		}, 0)
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+	})
+}
+
+func TestHoldService_Capture(t *testing.T) {
+	t.Run("Partial", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		var paidHeld decimal.Decimal
+		payments := &mockHeldPaymentMaker{
+			NewHeldPaymentMock: func(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error) {
+				paidHeld = held
+				return &models.Transaction{ID: 7}, mockAccountCach.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), Held: held.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, payments, NewPolicyService(DefaultPolicyRules()), time.Hour)
+
+		hold, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		hold, err = holdService.Capture(hold.ID, decimal.NewFromFloat(150), false)
+		assert.NoError(t, err)
+		assert.Equal(t, types.HoldCaptured, hold.Status)
+		assert.Equal(t, types.TransactionID(7), hold.PaymentID)
+		assert.True(t, paidHeld.Equal(decimal.NewFromFloat(150)))
+		assert.True(t, accounts[1].Held.IsZero())
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(350)))
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(150)))
+
+		_, err = holdService.Void(hold.ID)
+		assert.True(t, errs.Is(err, errs.HoldNotActive))
+	})
+	t.Run("ExceedsHold", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+
+		hold, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		_, err = holdService.Capture(hold.ID, decimal.NewFromFloat(201), false)
+		assert.True(t, errs.Is(err, errs.CaptureExceedsHold))
+		_, err = holdService.Capture(hold.ID, decimal.Zero, false)
+		assert.True(t, errs.Is(err, errs.InvalidAmount))
+	})
+	t.Run("Expired", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+		now := time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+		holdService.now = func() time.Time { return now }
+
+		hold, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.NoError(t, err)
+
+		now = now.Add(time.Hour)
+		_, err = holdService.Capture(hold.ID, decimal.Zero, true)
+		assert.True(t, errs.Is(err, errs.HoldNotActive))
+		assert.Equal(t, types.HoldExpired, hold.Status)
+		assert.True(t, accounts[1].Held.IsZero())
+	})
+}
+
+func TestHoldService_FraudScoring(t *testing.T) {
+	// Any payment over 100 is reviewed, holds are scored as the payments they are captured into
+	rules := []*models.FraudRule{
+		{Name: "large payment", Kind: types.VelocityRule, TransactionType: types.Payment, Action: types.FraudReview, Window: time.Hour, MaxVolume: decimal.NewFromInt(100)},
+	}
+
+	t.Run("ReviewedAndCaptured", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &mockHeldPaymentMaker{
+			NewHeldPaymentMock: func(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error) {
+				return &models.Transaction{ID: 7}, mockAccountCach.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), Held: held.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+			},
+		}
+		holdCach := &mockHoldCache{}
+		holdService := NewHoldService(holdCach, mockAccountCach, payments, NewPolicyService(DefaultPolicyRules()), time.Hour)
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, nil
+			},
+		}, rules)
+		holdService.UseFraudScoring(fraudService)
+		fraudService.UseHolds(holdService)
+
+		// The hold is not placed until the review, its amount is reserved by the alert meanwhile
+		_, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 2*time.Hour)
+		assert.True(t, errs.Is(err, errs.TransactionUnderReview))
+		assert.Equal(t, 0, len(holdService.FindByAccountNumber(1)))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromFloat(200)))
+
+		alert, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+		assert.Empty(t, alert.TransactionError)
+		assert.Equal(t, types.HoldID(1), alert.HoldID)
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromFloat(200)))
+
+		hold, err := holdService.Get(alert.HoldID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.HoldActive, hold.Status)
+		assert.Equal(t, hold.CreatedAt.Add(2*time.Hour), hold.ExpiresAt)
+
+		hold, err = holdService.Capture(hold.ID, decimal.Zero, true)
+		assert.NoError(t, err)
+		assert.Equal(t, types.TransactionID(7), hold.PaymentID)
+		assert.True(t, accounts[1].Held.IsZero())
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(300)))
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(200)))
+	})
+	t.Run("Blocked", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+		holdService.UseFraudScoring(NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, nil
+			},
+		}, []*models.FraudRule{
+			{Name: "large payment", Kind: types.VelocityRule, Action: types.FraudBlock, Window: time.Hour, MaxVolume: decimal.NewFromInt(100)},
+		}))
+
+		_, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+		assert.True(t, errs.Is(err, errs.TransactionBlocked))
+		assert.Equal(t, 0, len(holdService.FindByAccountNumber(1)))
+		assert.True(t, accounts[1].Held.IsZero())
+	})
+}
+
+func TestHoldService_Void(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+
+	hold, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(200)}, 0)
+	assert.NoError(t, err)
+
+	hold, err = holdService.Void(hold.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.HoldVoided, hold.Status)
+	assert.True(t, accounts[1].Held.IsZero())
+	assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(500)))
+}
+
+func TestHoldService_ExpireDue(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	holdService := NewHoldService(&mockHoldCache{}, mockAccountCach, &mockHeldPaymentMaker{}, NewPolicyService(DefaultPolicyRules()), time.Hour)
+	now := time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+	holdService.now = func() time.Time { return now }
+
+	_, err := holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(100)}, 30*time.Minute)
+	assert.NoError(t, err)
+	_, err = holdService.Place(&models.Hold{AccountNumber: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(100)}, 2*time.Hour)
+	assert.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, 1, holdService.ExpireDue())
+	assert.True(t, accounts[1].Held.Equal(decimal.NewFromFloat(100)))
+	assert.Equal(t, 1, len(NewAccountService(mockAccountCach, WithHolds(holdService)).FindHolds(1)))
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+type InterestService struct {
+	accountCache     accountInspector
+	interestCache    interestCache
+	transactionCache transactionCache
+	balances         balanceReader
+	houses           houseAccounter
+	rates            map[types.Currency]*models.InterestRate
+	now              func() time.Time
+
+	// mu serializes the accruals so that a day is never accrued twice
+	mu sync.Mutex
+}
+
+type interestCache interface {
+	Get(accountNumber types.AccountNumber) *models.InterestAccrual
+	AccruedUntil() time.Time
+	Save(accruals []*models.InterestAccrual, accruedUntil time.Time) error
+}
+
+// NewInterestService pays the interest of the rates on the end-of-day balances read from balances,
+// currencies without a rate earn no interest
+func NewInterestService(ac accountInspector, ic interestCache, tc transactionCache, balances balanceReader, houses houseAccounter, rates []*models.InterestRate) *InterestService {
+	is := &InterestService{
+		accountCache:     ac,
+		interestCache:    ic,
+		transactionCache: tc,
+		balances:         balances,
+		houses:           houses,
+		rates:            make(map[types.Currency]*models.InterestRate),
+		now:              time.Now,
+	}
+	for _, rate := range rates {
+		is.rates[rate.Currency] = rate
+	}
+	return is
+}
+
+// Rates returns the configured interest rates
+func (is *InterestService) Rates() []*models.InterestRate {
+	var rates []*models.InterestRate
+	for _, currency := range []types.Currency{types.TRY, types.EUR, types.USD} {
+		if rate, ok := is.rates[currency]; ok {
+			rates = append(rates, rate)
+		}
+	}
+	return rates
+}
+
+// Get returns a copy of the interest the account earned since its last posting with the rate it earns
+func (is *InterestService) Get(accountNumber types.AccountNumber) (*models.InterestAccrual, error) {
+	account, err := is.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	accrual := is.interestCache.Get(account.AccountNumber)
+	accrual.Rate = is.rateOf(account)
+	accrual.AccruedUntil = is.interestCache.AccruedUntil()
+	return accrual, nil
+}
+
+// interestPosting is the interest posted to an account at the end of a month
+type interestPosting struct {
+	account      *models.Account
+	accrual      *models.InterestAccrual
+	interest     decimal.Decimal
+	lastPostedAt time.Time
+}
+
+// Accrue adds the interest of every day that ended since the last accrual to the accounts
+// and posts the accrued interest when a month has ended. It returns how many accounts accrued
+// and how many were posted. Every day earns interest on the balance the account had at its end.
+// The first call only starts counting the days.
+func (is *InterestService) Accrue() (int, int) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	today := startOfDay(is.now())
+	accruedUntil := is.interestCache.AccruedUntil()
+	if accruedUntil.IsZero() {
+		_ = is.interestCache.Save(nil, today)
+		return 0, 0
+	}
+	if !today.After(accruedUntil) {
+		return 0, 0
+	}
+	monthEnded := today.Year() != accruedUntil.Year() || today.Month() != accruedUntil.Month()
+
+	accrued := 0
+	var accruals []*models.InterestAccrual
+	var postings []*interestPosting
+	for _, account := range inspectAll(is.accountCache) {
+		rate := is.rateOf(account)
+		if rate == nil {
+			continue
+		}
+		endOfDay, err := endOfDayBalances(is.balances, account.AccountNumber, accruedUntil, today)
+		if err != nil {
+			continue
+		}
+
+		accrual := is.interestCache.Get(account.AccountNumber)
+		earning := decimal.Zero
+		for _, balance := range endOfDay {
+			if balance.IsPositive() {
+				earning = earning.Add(balance)
+			}
+		}
+		if earning.IsPositive() {
+			accrual.Accrued = accrual.Accrued.Add(earning.Mul(rate.AnnualRate).
+				Div(decimal.NewFromInt(int64(100 * yearBasis(rate.DayCount)))))
+			accrued++
+		}
+
+		// Only whole cents are posted, the rest is carried over to the next month
+		if interest := accrual.Accrued.Truncate(2); monthEnded && interest.IsPositive() {
+			postings = append(postings, &interestPosting{
+				account: account, accrual: accrual, interest: interest, lastPostedAt: accrual.LastPostedAt,
+			})
+			accrual.Accrued = accrual.Accrued.Sub(interest)
+			accrual.Posted = accrual.Posted.Add(interest)
+			accrual.LastPostedAt = today
+		}
+		accruals = append(accruals, accrual)
+	}
+
+	// The accruals are saved before the interest is posted so that no day is accrued or posted twice
+	if err := is.interestCache.Save(accruals, today); err != nil {
+		return 0, 0
+	}
+
+	var unposted []*models.InterestAccrual
+	for _, posting := range postings {
+		if err := is.post(posting.account, posting.interest); err != nil {
+			posting.accrual.Accrued = posting.accrual.Accrued.Add(posting.interest)
+			posting.accrual.Posted = posting.accrual.Posted.Sub(posting.interest)
+			posting.accrual.LastPostedAt = posting.lastPostedAt
+			unposted = append(unposted, posting.accrual)
+		}
+	}
+	if len(unposted) > 0 {
+		_ = is.interestCache.Save(unposted, today)
+	}
+	return accrued, len(postings) - len(unposted)
+}
+
+// post pays the interest from the house account of the currency
+func (is *InterestService) post(account *models.Account, interest decimal.Decimal) error {
+	houseAccount, err := is.houses.HouseAccount(account.CurrencyCode)
+	if err != nil {
+		return err
+	}
+	return is.accountCache.Commit([]*models.BalanceChange{
+		{AccountNumber: houseAccount, Amount: interest.Neg(), Reason: types.InterestDebited},
+		{AccountNumber: account.AccountNumber, Amount: interest, Reason: types.InterestCredited},
+	}, func() {
+		transaction := is.transactionCache.Create(&models.Transaction{
+			AccountNumber:   account.AccountNumber,
+			Amount:          interest,
+			TransactionType: types.Interest,
+			Direction:       types.Credit,
+		})
+		is.transactionCache.Create(&models.Transaction{
+			ParentID:        transaction.ID,
+			AccountNumber:   houseAccount,
+			Amount:          interest,
+			TransactionType: types.Interest,
+			Direction:       types.Debit,
+		})
+	})
+}
+
+// rateOf returns the rate the account earns, or nil if it earns no interest
+func (is *InterestService) rateOf(account *models.Account) *models.InterestRate {
+	if account.AccountType != types.Individual {
+		return nil
+	}
+	return is.rates[account.CurrencyCode]
+}
+
+// yearBasis returns the number of days the annual rate is divided by
+func yearBasis(dayCount types.DayCount) int {
+	if dayCount == types.Actual360 {
+		return 360
+	}
+	return 365
+}
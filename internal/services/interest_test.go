@@ -0,0 +1,318 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockInterestCache struct {
+	accruals     map[types.AccountNumber]models.InterestAccrual
+	accruedUntil time.Time
+}
+
+func (m *mockInterestCache) Get(accountNumber types.AccountNumber) *models.InterestAccrual {
+	accrual, ok := m.accruals[accountNumber]
+	if !ok {
+		accrual = models.InterestAccrual{AccountNumber: accountNumber}
+	}
+	return &accrual
+}
+
+func (m *mockInterestCache) AccruedUntil() time.Time {
+	return m.accruedUntil
+}
+
+func (m *mockInterestCache) Save(accruals []*models.InterestAccrual, accruedUntil time.Time) error {
+	if m.accruals == nil {
+		m.accruals = make(map[types.AccountNumber]models.InterestAccrual)
+	}
+	for _, accrual := range accruals {
+		m.accruals[accrual.AccountNumber] = *accrual
+	}
+	m.accruedUntil = accruedUntil
+	return nil
+}
+
+func TestInterestService_Accrue(t *testing.T) {
+	t.Run("DailyAccrualAndMonthlyPosting", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromInt(36500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		var transactions []*models.Transaction
+		mockTransactionCach := &mockATransactionCache{
+			CreateMock: func(transactionHistory *models.Transaction) *models.Transaction {
+				transactionHistory.ID = types.TransactionID(len(transactions) + 1)
+				transactions = append(transactions, transactionHistory)
+				return transactionHistory
+			},
+		}
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		interestService := NewInterestService(mockAccountCach, &mockInterestCache{}, mockTransactionCach, mockBalanceReadr, mockHouseAccounter{},
+			[]*models.InterestRate{{Currency: types.TRY, AnnualRate: decimal.NewFromInt(10), DayCount: types.Actual365}})
+		now := time.Date(2022, 1, 30, 0, 5, 0, 0, time.UTC)
+		interestService.now = func() time.Time { return now }
+
+		// The first accrual only starts counting the days
+		accrued, posted := interestService.Accrue()
+		assert.Equal(t, 0, accrued)
+		assert.Equal(t, 0, posted)
+
+		// 36500 at 10% under ACT/365 earns 10 a day
+		now = now.AddDate(0, 0, 1)
+		accrued, posted = interestService.Accrue()
+		assert.Equal(t, 1, accrued)
+		assert.Equal(t, 0, posted)
+		accrual, err := interestService.Get(1)
+		assert.NoError(t, err)
+		assert.True(t, accrual.Accrued.Equal(decimal.NewFromInt(10)))
+		assert.Equal(t, types.Actual365, accrual.Rate.DayCount)
+
+		// January 31st ends the month
+		now = now.AddDate(0, 0, 1)
+		accrued, posted = interestService.Accrue()
+		assert.Equal(t, 1, accrued)
+		assert.Equal(t, 1, posted)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(36520)))
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromInt(-20)))
+		assert.Equal(t, 2, len(transactions))
+		assert.Equal(t, types.Interest, transactions[0].TransactionType)
+		assert.Equal(t, types.Credit, transactions[0].Direction)
+		assert.Equal(t, types.AccountNumber(3), transactions[1].AccountNumber)
+		assert.Equal(t, types.Debit, transactions[1].Direction)
+
+		accrual, err = interestService.Get(1)
+		assert.NoError(t, err)
+		assert.True(t, accrual.Accrued.IsZero())
+		assert.True(t, accrual.Posted.Equal(decimal.NewFromInt(20)))
+	})
+	t.Run("Actual360", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromInt(1000)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		interestService := NewInterestService(mockAccountCach, &mockInterestCache{}, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{},
+			[]*models.InterestRate{{Currency: types.TRY, AnnualRate: decimal.NewFromInt(36), DayCount: types.Actual360}})
+		now := time.Date(2022, 1, 10, 0, 5, 0, 0, time.UTC)
+		interestService.now = func() time.Time { return now }
+
+		interestService.Accrue()
+		now = now.AddDate(0, 0, 2)
+		interestService.Accrue()
+
+		accrual, err := interestService.Get(1)
+		assert.NoError(t, err)
+		assert.True(t, accrual.Accrued.Equal(decimal.NewFromInt(2)))
+	})
+	t.Run("EndOfDayBalances", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		// 36500 until the payment of the 11th, nothing from then on
+		paidAt := time.Date(2022, 1, 11, 15, 0, 0, 0, time.UTC)
+		balances := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			if accountNumber == 1 && at.Before(paidAt) {
+				return decimal.NewFromInt(36500)
+			}
+			return decimal.Zero
+		}}
+		interestService := NewInterestService(mockAccountCach, &mockInterestCache{}, &mockATransactionCache{}, balances, mockHouseAccounter{},
+			[]*models.InterestRate{{Currency: types.TRY, AnnualRate: decimal.NewFromInt(10), DayCount: types.Actual365}})
+		now := time.Date(2022, 1, 10, 0, 5, 0, 0, time.UTC)
+		interestService.now = func() time.Time { return now }
+
+		interestService.Accrue()
+		now = now.AddDate(0, 0, 3)
+		accrued, _ := interestService.Accrue()
+		assert.Equal(t, 1, accrued)
+
+		// Only the 10th ended with 36500
+		accrual, err := interestService.Get(1)
+		assert.NoError(t, err)
+		assert.True(t, accrual.Accrued.Equal(decimal.NewFromInt(10)))
+	})
+	t.Run("Restart", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromInt(36500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		interestCache := &mockInterestCache{}
+		rates := []*models.InterestRate{{Currency: types.TRY, AnnualRate: decimal.NewFromInt(10), DayCount: types.Actual365}}
+		now := time.Date(2022, 1, 10, 0, 5, 0, 0, time.UTC)
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		interestService := NewInterestService(mockAccountCach, interestCache, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{}, rates)
+		interestService.now = func() time.Time { return now }
+		interestService.Accrue()
+		now = now.AddDate(0, 0, 1)
+		interestService.Accrue()
+
+		// A new service over the same cache goes on from the day the last one accrued until
+		restarted := NewInterestService(mockAccountCach, interestCache, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{}, rates)
+		restarted.now = func() time.Time { return now }
+		accrued, _ := restarted.Accrue()
+		assert.Equal(t, 0, accrued)
+
+		now = now.AddDate(0, 0, 1)
+		accrued, _ = restarted.Accrue()
+		assert.Equal(t, 1, accrued)
+		accrual, err := restarted.Get(1)
+		assert.NoError(t, err)
+		assert.True(t, accrual.Accrued.Equal(decimal.NewFromInt(20)))
+		assert.Equal(t, time.Date(2022, 1, 12, 0, 0, 0, 0, time.UTC), accrual.AccruedUntil)
+	})
+	t.Run("OnlyIndividualAccountsWithARate", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate, Balance: decimal.NewFromInt(1000)},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		interestService := NewInterestService(mockAccountCach, &mockInterestCache{}, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{},
+			[]*models.InterestRate{{Currency: types.USD, AnnualRate: decimal.NewFromInt(5), DayCount: types.Actual360}})
+		now := time.Date(2022, 1, 10, 0, 5, 0, 0, time.UTC)
+		interestService.now = func() time.Time { return now }
+
+		interestService.Accrue()
+		now = now.AddDate(0, 0, 1)
+		accrued, _ := interestService.Accrue()
+		assert.Equal(t, 0, accrued)
+
+		accrual, err := interestService.Get(1)
+		assert.NoError(t, err)
+		assert.Nil(t, accrual.Rate)
+	})
+}
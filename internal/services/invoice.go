@@ -0,0 +1,214 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+type InvoiceService struct {
+	invoiceCache invoiceCache
+	accountCache accountCache
+	payments     paymentMaker
+	policy       policyEvaluator
+	now          func() time.Time
+
+	// mu serializes the state changes of invoices so that an invoice cannot be overpaid by payments at once
+	mu sync.Mutex
+}
+
+type invoiceCache interface {
+	Create(invoice *models.Invoice) *models.Invoice
+	Get(id types.InvoiceID) (*models.Invoice, error)
+	GetAll(accountNumber types.AccountNumber) []*models.Invoice
+	GetOpen() []*models.Invoice
+	Update(invoice *models.Invoice) error
+}
+
+func NewInvoiceService(ic invoiceCache, ac accountCache, payments paymentMaker, policy policyEvaluator) *InvoiceService {
+	return &InvoiceService{
+		invoiceCache: ic,
+		accountCache: ac,
+		payments:     payments,
+		policy:       policy,
+		now:          time.Now,
+	}
+}
+
+// Create issues the invoice from its corporate issuer to its payer,
+// an invoice without a currency is issued in the currency of the issuer
+func (is *InvoiceService) Create(invoice *models.Invoice) (*models.Invoice, error) {
+	if !invoice.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if err := invoice.TransactionDetails.Validate(); err != nil {
+		return nil, err
+	}
+	now := is.now()
+	if !invoice.DueDate.After(now) {
+		return nil, errs.Newf(errs.InvalidArgument, "dueDate must be in the future")
+	}
+
+	issuer, err := is.accountCache.Get(invoice.IssuerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	payer, err := is.accountCache.Get(invoice.PayerAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	if issuer.AccountType != types.Corporate {
+		return nil, errs.Newf(errs.AccountTypeNotAllowed, "invoices can only be issued by corporate accounts")
+	}
+
+	// The invoice is paid with a payment from the payer, so it has to be allowed when it is issued
+	err = is.policy.Evaluate(types.Payment, payer, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.CurrencyCode == "" {
+		invoice.CurrencyCode = issuer.CurrencyCode
+	}
+	if invoice.CurrencyCode != issuer.CurrencyCode || payer.CurrencyCode != issuer.CurrencyCode {
+		return nil, errs.New(errs.CurrencyMismatch)
+	}
+
+	invoice.Paid = decimal.Zero
+	invoice.Status = types.InvoiceOpen
+	invoice.PaymentIDs = nil
+	invoice.CreatedAt = now
+	invoice.ClosedAt = time.Time{}
+	return is.invoiceCache.Create(invoice), nil
+}
+
+// Pay pays amount of the invoice from its payer to its issuer with a payment,
+// full pays the whole unpaid part instead. The invoice is paid once nothing is left unpaid.
+func (is *InvoiceService) Pay(id types.InvoiceID, amount decimal.Decimal, full bool) (*models.Invoice, error) {
+	if full && !amount.IsZero() {
+		return nil, errs.Newf(errs.InvalidArgument, "either amount or full can be given")
+	}
+	if !full && !amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	invoice, err := is.openInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if full {
+		amount = invoice.Unpaid()
+	}
+	if amount.GreaterThan(invoice.Unpaid()) {
+		return nil, errs.Newf(errs.PaymentExceedsInvoice, "unpaid amount is %s", invoice.Unpaid().StringFixed(2))
+	}
+
+	transaction, err := is.payments.NewPayment(&models.Payment{
+		SenderAccount:      invoice.PayerAccount,
+		ReceiverAccount:    invoice.IssuerAccount,
+		Amount:             amount,
+		TransactionDetails: invoice.TransactionDetails,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Paid = invoice.Paid.Add(amount)
+	invoice.PaymentIDs = append(invoice.PaymentIDs, transaction.ID)
+	if !invoice.Unpaid().IsPositive() {
+		invoice.Status = types.InvoicePaid
+		invoice.ClosedAt = is.now()
+	}
+	err = is.invoiceCache.Update(invoice)
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Cancel withdraws an open invoice, the parts paid before are kept by the issuer
+func (is *InvoiceService) Cancel(id types.InvoiceID) (*models.Invoice, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	invoice, err := is.openInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Status = types.InvoiceCancelled
+	invoice.ClosedAt = is.now()
+	err = is.invoiceCache.Update(invoice)
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (is *InvoiceService) Get(id types.InvoiceID) (*models.Invoice, error) {
+	return is.invoiceCache.Get(id)
+}
+
+// FindByAccountNumber returns every invoice the account issued or is asked to pay
+func (is *InvoiceService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.Invoice, error) {
+	_, err := is.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	return is.invoiceCache.GetAll(accountNumber), nil
+}
+
+// ExpireDue expires every open invoice past its due date and returns how many were expired
+func (is *InvoiceService) ExpireDue() int {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	expired := 0
+	now := is.now()
+	for _, invoice := range is.invoiceCache.GetOpen() {
+		if invoice.DueDate.After(now) {
+			continue
+		}
+		if is.expire(invoice, now) == nil {
+			expired++
+		}
+	}
+	return expired
+}
+
+// openInvoice returns the invoice if it can still be paid or cancelled,
+// an invoice found past its due date is expired on the spot
+func (is *InvoiceService) openInvoice(id types.InvoiceID) (*models.Invoice, error) {
+	invoice, err := is.invoiceCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := is.now()
+	if invoice.Status == types.InvoiceOpen && !invoice.DueDate.After(now) {
+		err = is.expire(invoice, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if invoice.Status != types.InvoiceOpen {
+		return nil, errs.Newf(errs.InvoiceNotOpen, "invoice is %s", invoice.Status)
+	}
+	return invoice, nil
+}
+
+func (is *InvoiceService) expire(invoice *models.Invoice, now time.Time) error {
+	invoice.Status = types.InvoiceExpired
+	invoice.ClosedAt = now
+	return is.invoiceCache.Update(invoice)
+}
@@ -0,0 +1,536 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockInvoiceCache struct {
+	invoices []*models.Invoice
+}
+
+func (m *mockInvoiceCache) Create(invoice *models.Invoice) *models.Invoice {
+	invoice.ID = types.InvoiceID(len(m.invoices) + 1)
+	m.invoices = append(m.invoices, invoice)
+	return invoice
+}
+
+func (m *mockInvoiceCache) Get(id types.InvoiceID) (*models.Invoice, error) {
+	if id < 1 || int(id) > len(m.invoices) {
+		return nil, errs.New(errs.InvoiceNotFound)
+	}
+	return m.invoices[id-1], nil
+}
+
+func (m *mockInvoiceCache) GetAll(accountNumber types.AccountNumber) []*models.Invoice {
+	var invoices []*models.Invoice
+	for _, i := range m.invoices {
+		if i.IssuerAccount == accountNumber || i.PayerAccount == accountNumber {
+			invoices = append(invoices, i)
+		}
+	}
+	return invoices
+}
+
+func (m *mockInvoiceCache) GetOpen() []*models.Invoice {
+	var invoices []*models.Invoice
+	for _, i := range m.invoices {
+		if i.Status == types.InvoiceOpen {
+			invoices = append(invoices, i)
+		}
+	}
+	return invoices
+}
+
+func (m *mockInvoiceCache) Update(invoice *models.Invoice) error {
+	if invoice.ID < 1 || int(invoice.ID) > len(m.invoices) {
+		return errs.New(errs.InvoiceNotFound)
+	}
+	m.invoices[invoice.ID-1] = invoice
+	return nil
+}
+
+func TestInvoiceService_Create(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+
+		invoice, err := invoiceService.Create(&models.Invoice{
+			IssuerAccount:      2,
+			PayerAccount:       1,
+			Amount:             decimal.NewFromFloat(300),
+			DueDate:            now.AddDate(0, 0, 30),
+			TransactionDetails: models.TransactionDetails{Reference: "INV-2024-001"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, types.InvoiceOpen, invoice.Status)
+		assert.Equal(t, types.TRY, invoice.CurrencyCode)
+		assert.True(t, invoice.Unpaid().Equal(decimal.NewFromFloat(300)))
+	})
+	t.Run("IndividualIssuer", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+
+		_, err := invoiceService.Create(&models.Invoice{IssuerAccount: 1, PayerAccount: 2, Amount: decimal.NewFromFloat(10), DueDate: now.Add(time.Hour)})
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+	})
+	t.Run("CurrencyMismatch", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+
+		_, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, CurrencyCode: types.USD, Amount: decimal.NewFromFloat(10), DueDate: now.Add(time.Hour)})
+		assert.True(t, errs.Is(err, errs.CurrencyMismatch))
+	})
+	t.Run("DueDateInThePast", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+
+		_, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromFloat(10), DueDate: now})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
+
+func TestInvoiceService_Pay(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Partial", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+		invoice, err := invoiceService.Create(&models.Invoice{
+			IssuerAccount:      2,
+			PayerAccount:       1,
+			Amount:             decimal.NewFromFloat(300),
+			DueDate:            now.Add(time.Hour),
+			TransactionDetails: models.TransactionDetails{Reference: "INV-2024-001"},
+		})
+		assert.NoError(t, err)
+
+		invoice, err = invoiceService.Pay(invoice.ID, decimal.NewFromFloat(100), false)
+		assert.NoError(t, err)
+		assert.Equal(t, types.InvoiceOpen, invoice.Status)
+		assert.True(t, invoice.Unpaid().Equal(decimal.NewFromFloat(200)))
+		assert.Equal(t, "INV-2024-001", (*payments)[0].Reference)
+
+		_, err = invoiceService.Pay(invoice.ID, decimal.NewFromFloat(250), false)
+		assert.True(t, errs.Is(err, errs.PaymentExceedsInvoice))
+		_, err = invoiceService.Pay(invoice.ID, decimal.Zero, false)
+		assert.True(t, errs.Is(err, errs.InvalidAmount))
+		_, err = invoiceService.Pay(invoice.ID, decimal.NewFromFloat(100), true)
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+
+		invoice, err = invoiceService.Pay(invoice.ID, decimal.Zero, true)
+		assert.NoError(t, err)
+		assert.Equal(t, types.InvoicePaid, invoice.Status)
+		assert.Equal(t, now, invoice.ClosedAt)
+		assert.Equal(t, []types.TransactionID{1, 2}, invoice.PaymentIDs)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(200)))
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(300)))
+
+		_, err = invoiceService.Pay(invoice.ID, decimal.Zero, true)
+		assert.True(t, errs.Is(err, errs.InvoiceNotOpen))
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+		invoice, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromFloat(600), DueDate: now.Add(time.Hour)})
+		assert.NoError(t, err)
+
+		_, err = invoiceService.Pay(invoice.ID, decimal.Zero, true)
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+		assert.True(t, invoice.Paid.IsZero())
+		assert.Equal(t, types.InvoiceOpen, invoice.Status)
+	})
+	t.Run("Expired", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		payments := &[]*models.Payment{}
+		paymentMaker := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				err := accountCache.ApplyChanges([]*models.BalanceChange{
+					{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+					{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+				})
+				if err != nil {
+					return nil, err
+				}
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		}
+		invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+		invoiceService.now = func() time.Time { return now }
+		invoice, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromFloat(100), DueDate: now.Add(time.Hour)})
+		assert.NoError(t, err)
+
+		invoiceService.now = func() time.Time { return now.Add(time.Hour) }
+		_, err = invoiceService.Pay(invoice.ID, decimal.Zero, true)
+		assert.True(t, errs.Is(err, errs.InvoiceNotOpen))
+		assert.Equal(t, types.InvoiceExpired, invoice.Status)
+		assert.Equal(t, 0, len(*payments))
+	})
+}
+
+func TestInvoiceService_Cancel(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	accountCache := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	payments := &[]*models.Payment{}
+	paymentMaker := &mockPaymentMaker{
+		NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+			err := accountCache.ApplyChanges([]*models.BalanceChange{
+				{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+				{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+			})
+			if err != nil {
+				return nil, err
+			}
+			*payments = append(*payments, payment)
+			return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+		},
+	}
+	invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+	invoiceService.now = func() time.Time { return now }
+	invoice, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromFloat(100), DueDate: now.Add(time.Hour)})
+	assert.NoError(t, err)
+
+	invoice, err = invoiceService.Cancel(invoice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.InvoiceCancelled, invoice.Status)
+
+	_, err = invoiceService.Pay(invoice.ID, decimal.Zero, true)
+	assert.True(t, errs.Is(err, errs.InvoiceNotOpen))
+	_, err = invoiceService.Cancel(invoice.ID)
+	assert.True(t, errs.Is(err, errs.InvoiceNotOpen))
+}
+
+func TestInvoiceService_ExpireDue(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	accountCache := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	payments := &[]*models.Payment{}
+	paymentMaker := &mockPaymentMaker{
+		NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+			err := accountCache.ApplyChanges([]*models.BalanceChange{
+				{AccountNumber: payment.SenderAccount, Amount: payment.Amount.Neg(), RequireFunds: true},
+				{AccountNumber: payment.ReceiverAccount, Amount: payment.Amount},
+			})
+			if err != nil {
+				return nil, err
+			}
+			*payments = append(*payments, payment)
+			return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+		},
+	}
+	invoiceService := NewInvoiceService(&mockInvoiceCache{}, accountCache, paymentMaker, NewPolicyService(DefaultPolicyRules()))
+	invoiceService.now = func() time.Time { return now }
+	due, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromFloat(100), DueDate: now.Add(time.Hour)})
+	assert.NoError(t, err)
+	later, err := invoiceService.Create(&models.Invoice{IssuerAccount: 2, PayerAccount: 1, Amount: decimal.NewFromFloat(100), DueDate: now.Add(2 * time.Hour)})
+	assert.NoError(t, err)
+
+	invoiceService.now = func() time.Time { return now.Add(time.Hour) }
+	assert.Equal(t, 1, invoiceService.ExpireDue())
+	assert.Equal(t, types.InvoiceExpired, due.Status)
+	assert.Equal(t, types.InvoiceOpen, later.Status)
+}
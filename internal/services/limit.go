@@ -0,0 +1,205 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// limitedTransactionTypes are the transaction types an allowance is reported for
+var limitedTransactionTypes = []types.TransactionType{types.Payment, types.Deposit, types.Withdraw}
+
+type LimitService struct {
+	limitCache       limitCache
+	accountCache     accountCache
+	transactionCache transactionCache
+	now              func() time.Time
+
+	// mu guards accountLocks, which has a lock for every account whose limits were checked
+	mu           sync.Mutex
+	accountLocks map[types.AccountNumber]*sync.Mutex
+}
+
+type limitCache interface {
+	Find(account *models.Account, transactionType types.TransactionType) *models.Limit
+	Set(limit *models.Limit)
+}
+
+func NewLimitService(lc limitCache, ac accountCache, tc transactionCache) *LimitService {
+	return &LimitService{
+		limitCache:       lc,
+		accountCache:     ac,
+		transactionCache: tc,
+		now:              time.Now,
+		accountLocks:     make(map[types.AccountNumber]*sync.Mutex),
+	}
+}
+
+// Lock locks the limits of the account until the returned function is called. A transaction holds it from its Check
+// until its history is written, so that two transactions cannot both pass a check that only one of them fits in.
+func (ls *LimitService) Lock(accountNumber types.AccountNumber) func() {
+	ls.mu.Lock()
+	lock, ok := ls.accountLocks[accountNumber]
+	if !ok {
+		lock = &sync.Mutex{}
+		ls.accountLocks[accountNumber] = lock
+	}
+	ls.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// usage sums the amount and the count of the transactions of the type that the account made after since.
+// Deposits count the money the account took in, payments and withdrawals the money it sent out,
+// so the payments an account received do not use its limits.
+func (ls *LimitService) usage(accountNumber types.AccountNumber, transactionType types.TransactionType, since time.Time) (decimal.Decimal, int) {
+	volume := decimal.Zero
+	count := 0
+
+	// An account without history has not used any of its limits
+	transactions, err := ls.transactionCache.GetAll(accountNumber)
+	if err != nil {
+		return volume, count
+	}
+
+	direction := types.Debit
+	if transactionType == types.Deposit {
+		direction = types.Credit
+	}
+
+	for _, t := range transactions {
+		if spentAs(t) != transactionType || t.Direction != direction || t.CreatedAt.Before(since) {
+			continue
+		}
+		volume = volume.Add(t.Amount)
+		count++
+	}
+	return volume, count
+}
+
+// Check returns a limit_exceeded error if the transaction
+// would exceed any limit configured for the account
+func (ls *LimitService) Check(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) error {
+	limit := ls.limitCache.Find(account, transactionType)
+	if limit == nil {
+		return nil
+	}
+
+	if limit.PerTransaction.IsPositive() && amount.GreaterThan(limit.PerTransaction) {
+		return errs.Newf(errs.LimitExceeded, "amount exceeds the per transaction limit of %s", limit.PerTransaction.StringFixed(2)).
+			With("limit", "perTransaction").
+			With("remaining", limit.PerTransaction.InexactFloat64())
+	}
+
+	now := ls.now()
+	windows := []struct {
+		name   string
+		since  time.Time
+		volume decimal.Decimal
+		count  int
+	}{
+		{"daily", now.AddDate(0, 0, -1), limit.DailyVolume, limit.DailyCount},
+		{"monthly", now.AddDate(0, -1, 0), limit.MonthlyVolume, limit.MonthlyCount},
+	}
+
+	for _, w := range windows {
+		usedVolume, usedCount := ls.usage(account.AccountNumber, transactionType, w.since)
+
+		if w.volume.IsPositive() && usedVolume.Add(amount).GreaterThan(w.volume) {
+			remaining := decimal.Max(w.volume.Sub(usedVolume), decimal.Zero)
+			return errs.Newf(errs.LimitExceeded, "amount exceeds the %s volume limit of %s", w.name, w.volume.StringFixed(2)).
+				With("limit", w.name+"Volume").
+				With("remaining", remaining.InexactFloat64())
+		}
+
+		if w.count > 0 && usedCount+1 > w.count {
+			return errs.Newf(errs.LimitExceeded, "the %s limit of %d transactions is reached", w.name, w.count).
+				With("limit", w.name+"Count").
+				With("remaining", 0)
+		}
+	}
+
+	return nil
+}
+
+// Allowances returns what is left of every limit of the account
+func (ls *LimitService) Allowances(accountNumber types.AccountNumber) ([]*models.Allowance, error) {
+	account, err := ls.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	now := ls.now()
+	var allowances []*models.Allowance
+	for _, transactionType := range limitedTransactionTypes {
+		allowance := &models.Allowance{TransactionType: transactionType}
+		limit := ls.limitCache.Find(account, transactionType)
+		if limit == nil {
+			allowances = append(allowances, allowance)
+			continue
+		}
+
+		if limit.PerTransaction.IsPositive() {
+			perTransaction := limit.PerTransaction
+			allowance.PerTransaction = &perTransaction
+		}
+
+		dailyVolume, dailyCount := ls.usage(account.AccountNumber, transactionType, now.AddDate(0, 0, -1))
+		allowance.DailyVolumeRemaining = remainingVolume(limit.DailyVolume, dailyVolume)
+		allowance.DailyCountRemaining = remainingCount(limit.DailyCount, dailyCount)
+
+		monthlyVolume, monthlyCount := ls.usage(account.AccountNumber, transactionType, now.AddDate(0, -1, 0))
+		allowance.MonthlyVolumeRemaining = remainingVolume(limit.MonthlyVolume, monthlyVolume)
+		allowance.MonthlyCountRemaining = remainingCount(limit.MonthlyCount, monthlyCount)
+
+		allowances = append(allowances, allowance)
+	}
+	return allowances, nil
+}
+
+// SetOverride replaces the limit of a single account for the transaction type of the limit
+func (ls *LimitService) SetOverride(limit *models.Limit) (*models.Limit, error) {
+	account, err := ls.accountCache.Get(limit.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	switch limit.TransactionType {
+	case types.Payment, types.Deposit, types.Withdraw:
+	default:
+		return nil, errs.Newf(errs.InvalidArgument, "invalid transaction type")
+	}
+
+	if limit.PerTransaction.IsNegative() || limit.DailyVolume.IsNegative() || limit.MonthlyVolume.IsNegative() ||
+		limit.DailyCount < 0 || limit.MonthlyCount < 0 {
+		return nil, errs.Newf(errs.InvalidArgument, "limits cannot be negative")
+	}
+
+	limit.AccountType = account.AccountType
+	limit.Currency = account.CurrencyCode
+	ls.limitCache.Set(limit)
+	return limit, nil
+}
+
+func remainingVolume(limit decimal.Decimal, used decimal.Decimal) *decimal.Decimal {
+	if !limit.IsPositive() {
+		return nil
+	}
+	remaining := decimal.Max(limit.Sub(used), decimal.Zero)
+	return &remaining
+}
+
+func remainingCount(limit int, used int) *int {
+	if limit <= 0 {
+		return nil
+	}
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
+}
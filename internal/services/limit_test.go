@@ -0,0 +1,200 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockLimitCache struct {
+	FindMock func(account *models.Account, transactionType types.TransactionType) *models.Limit
+	SetMock  func(limit *models.Limit)
+}
+
+func (m *mockLimitCache) Find(account *models.Account, transactionType types.TransactionType) *models.Limit {
+	return m.FindMock(account, transactionType)
+}
+
+func (m *mockLimitCache) Set(limit *models.Limit) {
+	m.SetMock(limit)
+}
+
+func TestLimitService_Check(t *testing.T) {
+	now := time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+	account := &models.Account{
+		AccountNumber: 1,
+		CurrencyCode:  types.TRY,
+		AccountType:   types.Individual,
+	}
+	mockLimitCach := mockLimitCache{
+		FindMock: func(account *models.Account, transactionType types.TransactionType) *models.Limit {
+			if transactionType != types.Withdraw {
+				return nil
+			}
+			return &models.Limit{
+				TransactionType: types.Withdraw,
+				PerTransaction:  decimal.NewFromFloat(100),
+				DailyVolume:     decimal.NewFromFloat(150),
+				DailyCount:      3,
+				MonthlyCount:    4,
+			}
+		},
+	}
+	mockTransactionCach := mockATransactionCache{
+		GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			return []*models.Transaction{
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(50), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.Add(-time.Hour)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(50), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.Add(-2 * time.Hour)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(50), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.AddDate(0, 0, -3)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(500), TransactionType: types.Deposit, Direction: types.Credit, CreatedAt: now.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	limitService := NewLimitService(&mockLimitCach, &mockAccountCache{}, &mockTransactionCach)
+	limitService.now = func() time.Time { return now }
+
+	t.Run("Success", func(t *testing.T) {
+		err := limitService.Check(account, types.Withdraw, decimal.NewFromFloat(50))
+		assert.NoError(t, err)
+	})
+	t.Run("NotConfigured", func(t *testing.T) {
+		err := limitService.Check(account, types.Deposit, decimal.NewFromFloat(5000))
+		assert.NoError(t, err)
+	})
+	t.Run("PerTransaction", func(t *testing.T) {
+		err := limitService.Check(account, types.Withdraw, decimal.NewFromFloat(101))
+		assert.True(t, errs.Is(err, errs.LimitExceeded))
+	})
+	t.Run("DailyVolume", func(t *testing.T) {
+		err := limitService.Check(account, types.Withdraw, decimal.NewFromFloat(51))
+		assert.True(t, errs.Is(err, errs.LimitExceeded))
+		assert.Equal(t, "dailyVolume", err.(*errs.Error).Extensions["limit"])
+	})
+	t.Run("MonthlyCount", func(t *testing.T) {
+		limitService.now = func() time.Time { return now }
+		mockTransactionCach.GetAllMock = func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			return []*models.Transaction{
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(1), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.AddDate(0, 0, -2)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(1), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.AddDate(0, 0, -3)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(1), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.AddDate(0, 0, -4)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(1), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.AddDate(0, 0, -5)},
+			}, nil
+		}
+		err := limitService.Check(account, types.Withdraw, decimal.NewFromFloat(1))
+		assert.True(t, errs.Is(err, errs.LimitExceeded))
+		assert.Equal(t, "monthlyCount", err.(*errs.Error).Extensions["limit"])
+	})
+	t.Run("ReceivedPayments", func(t *testing.T) {
+		mockLimitCach.FindMock = func(account *models.Account, transactionType types.TransactionType) *models.Limit {
+			return &models.Limit{TransactionType: types.Payment, DailyVolume: decimal.NewFromFloat(100), DailyCount: 1}
+		}
+		mockTransactionCach.GetAllMock = func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			return []*models.Transaction{
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(500), TransactionType: types.Payment, Direction: types.Credit, CreatedAt: now.Add(-time.Hour)},
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(500), TransactionType: types.Payment, Direction: types.Credit, CreatedAt: now.Add(-2 * time.Hour)},
+			}, nil
+		}
+		// The receiver still has its whole payment limit after receiving more than it
+		err := limitService.Check(account, types.Payment, decimal.NewFromFloat(100))
+		assert.NoError(t, err)
+
+		mockTransactionCach.GetAllMock = func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			return []*models.Transaction{
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(10), TransactionType: types.Payment, Direction: types.Debit, CreatedAt: now.Add(-time.Hour)},
+			}, nil
+		}
+		err = limitService.Check(account, types.Payment, decimal.NewFromFloat(10))
+		assert.True(t, errs.Is(err, errs.LimitExceeded))
+	})
+}
+
+func TestLimitService_Allowances(t *testing.T) {
+	now := time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC)
+	mockAccountCach := mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			return &models.Account{
+				AccountNumber: accountNumber,
+				CurrencyCode:  types.TRY,
+				AccountType:   types.Individual,
+			}, nil
+		},
+	}
+	mockLimitCach := mockLimitCache{
+		FindMock: func(account *models.Account, transactionType types.TransactionType) *models.Limit {
+			if transactionType != types.Withdraw {
+				return nil
+			}
+			return &models.Limit{
+				TransactionType: types.Withdraw,
+				DailyVolume:     decimal.NewFromFloat(150),
+				DailyCount:      3,
+			}
+		},
+	}
+	mockTransactionCach := mockATransactionCache{
+		GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			return []*models.Transaction{
+				{AccountNumber: 1, Amount: decimal.NewFromFloat(50), TransactionType: types.Withdraw, Direction: types.Debit, CreatedAt: now.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	limitService := NewLimitService(&mockLimitCach, &mockAccountCach, &mockTransactionCach)
+	limitService.now = func() time.Time { return now }
+
+	allowances, err := limitService.Allowances(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(allowances))
+
+	withdraw := allowances[2]
+	assert.Equal(t, types.Withdraw, withdraw.TransactionType)
+	assert.True(t, withdraw.DailyVolumeRemaining.Equal(decimal.NewFromFloat(100)))
+	assert.Equal(t, 2, *withdraw.DailyCountRemaining)
+	assert.Nil(t, withdraw.PerTransaction)
+	assert.Nil(t, allowances[0].DailyVolumeRemaining)
+}
+
+func TestLimitService_SetOverride(t *testing.T) {
+	mockAccountCach := mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			if accountNumber != 1 {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return &models.Account{
+				AccountNumber: accountNumber,
+				CurrencyCode:  types.TRY,
+				AccountType:   types.Individual,
+			}, nil
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		var stored *models.Limit
+		mockLimitCach := mockLimitCache{
+			SetMock: func(limit *models.Limit) {
+				stored = limit
+			},
+		}
+		limitService := NewLimitService(&mockLimitCach, &mockAccountCach, &mockATransactionCache{})
+		_, err := limitService.SetOverride(&models.Limit{
+			AccountNumber:   1,
+			TransactionType: types.Withdraw,
+			DailyCount:      1,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, types.TRY, stored.Currency)
+	})
+	t.Run("AccountNotFound", func(t *testing.T) {
+		limitService := NewLimitService(&mockLimitCache{}, &mockAccountCach, &mockATransactionCache{})
+		_, err := limitService.SetOverride(&models.Limit{AccountNumber: 2, TransactionType: types.Withdraw})
+		assert.True(t, errs.Is(err, errs.AccountNotFound))
+	})
+	t.Run("InvalidTransactionType", func(t *testing.T) {
+		limitService := NewLimitService(&mockLimitCache{}, &mockAccountCach, &mockATransactionCache{})
+		_, err := limitService.SetOverride(&models.Limit{AccountNumber: 1, TransactionType: "refund"})
+		assert.Error(t, err)
+	})
+}
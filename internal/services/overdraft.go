@@ -0,0 +1,188 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sync"
+	"time"
+)
+
+// daysInYear is the day count the annual overdraft rate is divided by
+const daysInYear = 365
+
+type OverdraftService struct {
+	accountCache     overdraftAccountCache
+	cursor           accrualCursor
+	transactionCache transactionCache
+	balances         balanceReader
+	houses           houseAccounter
+	annualRate       decimal.Decimal
+	maxLimit         decimal.Decimal
+	now              func() time.Time
+
+	// mu serializes the accruals so that a day is never charged twice
+	mu sync.Mutex
+}
+
+type overdraftAccountCache interface {
+	accountInspector
+	SetOverdraftLimit(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error)
+}
+
+// accrualCursor keeps the day a daily accrual job accrued until
+type accrualCursor interface {
+	Get() time.Time
+	Set(until time.Time) error
+}
+
+type houseAccounter interface {
+	HouseAccount(currency types.Currency) (types.AccountNumber, error)
+}
+
+// NewOverdraftService takes the annual interest rate charged on the negative end-of-day balances read from balances
+// in percent and the highest overdraft limit an account can have, a zero maxLimit does not cap the limits
+func NewOverdraftService(ac overdraftAccountCache, cursor accrualCursor, tc transactionCache, balances balanceReader, houses houseAccounter,
+	annualRate decimal.Decimal, maxLimit decimal.Decimal) *OverdraftService {
+	return &OverdraftService{
+		accountCache:     ac,
+		cursor:           cursor,
+		transactionCache: tc,
+		balances:         balances,
+		houses:           houses,
+		annualRate:       annualRate,
+		maxLimit:         maxLimit,
+		now:              time.Now,
+	}
+}
+
+func (od *OverdraftService) Get(accountNumber types.AccountNumber) (*models.Overdraft, error) {
+	account, err := od.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	return od.overdraft(account), nil
+}
+
+// SetLimit lets the balance of an individual account go down to the negative of limit,
+// a zero limit removes the overdraft facility
+func (od *OverdraftService) SetLimit(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Overdraft, error) {
+	if limit.IsNegative() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if od.maxLimit.IsPositive() && limit.GreaterThan(od.maxLimit) {
+		return nil, errs.Newf(errs.OverdraftLimitTooHigh, "limit can be at most %s", od.maxLimit.StringFixed(2))
+	}
+
+	account, err := od.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if account.AccountType != types.Individual {
+		return nil, errs.Newf(errs.AccountTypeNotAllowed, "overdraft facilities are only available to individual accounts")
+	}
+
+	account, err = od.accountCache.SetOverdraftLimit(accountNumber, limit)
+	if err != nil {
+		return nil, err
+	}
+	return od.overdraft(account), nil
+}
+
+// AccrueInterest charges the individual accounts the interest of every day that ended since the last accrual
+// on the balance they had at its end, if it was negative, and returns how many accounts were charged.
+// The first call only starts counting the days.
+func (od *OverdraftService) AccrueInterest() int {
+	od.mu.Lock()
+	defer od.mu.Unlock()
+
+	today := startOfDay(od.now())
+	accruedUntil := od.cursor.Get()
+	if accruedUntil.IsZero() {
+		_ = od.cursor.Set(today)
+		return 0
+	}
+	if !today.After(accruedUntil) {
+		return 0
+	}
+	// The cursor is moved before the charges so that no day is charged twice
+	if err := od.cursor.Set(today); err != nil {
+		return 0
+	}
+
+	charged := 0
+	for _, account := range inspectAll(od.accountCache) {
+		if account.AccountType != types.Individual {
+			continue
+		}
+		endOfDay, err := endOfDayBalances(od.balances, account.AccountNumber, accruedUntil, today)
+		if err != nil {
+			continue
+		}
+		used := decimal.Zero
+		for _, balance := range endOfDay {
+			if balance.IsNegative() {
+				used = used.Sub(balance)
+			}
+		}
+		interest := used.Mul(od.annualRate).Div(decimal.NewFromInt(100 * daysInYear)).Round(2)
+		if !interest.IsPositive() {
+			continue
+		}
+		if od.charge(account, interest) == nil {
+			charged++
+		}
+	}
+	return charged
+}
+
+// charge moves the interest from the account to the house account of its currency,
+// the charge is taken even if it puts the balance below the overdraft limit
+func (od *OverdraftService) charge(account *models.Account, interest decimal.Decimal) error {
+	houseAccount, err := od.houses.HouseAccount(account.CurrencyCode)
+	if err != nil {
+		return err
+	}
+	return od.accountCache.Commit([]*models.BalanceChange{
+		{AccountNumber: account.AccountNumber, Amount: interest.Neg(), Reason: types.InterestDebited},
+		{AccountNumber: houseAccount, Amount: interest, Reason: types.InterestCredited},
+	}, func() {
+		transaction := od.transactionCache.Create(&models.Transaction{
+			AccountNumber:   account.AccountNumber,
+			Amount:          interest,
+			TransactionType: types.OverdraftInterest,
+			Direction:       types.Debit,
+		})
+		od.transactionCache.Create(&models.Transaction{
+			ParentID:        transaction.ID,
+			AccountNumber:   houseAccount,
+			Amount:          interest,
+			TransactionType: types.OverdraftInterest,
+			Direction:       types.Credit,
+		})
+	})
+}
+
+func (od *OverdraftService) overdraft(account *models.Account) *models.Overdraft {
+	overdraft := &models.Overdraft{
+		AccountNumber:  account.AccountNumber,
+		Limit:          account.OverdraftLimit,
+		Used:           decimal.Zero,
+		AnnualRate:     od.annualRate,
+		OverdrawnSince: account.OverdrawnSince,
+	}
+	if account.Balance.IsNegative() {
+		overdraft.Used = account.Balance.Neg()
+	}
+	if !account.OverdrawnSince.IsZero() {
+		overdraft.DaysOverdrawn = int(startOfDay(od.now()).Sub(startOfDay(account.OverdrawnSince)).Hours()/24) + 1
+	}
+	return overdraft
+}
+
+// startOfDay truncates t to the midnight of its day in UTC
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockHouseAccounter struct{}
+
+func (m mockHouseAccounter) HouseAccount(currency types.Currency) (types.AccountNumber, error) {
+	return 3, nil
+}
+
+type mockAccrualCursor struct {
+	until time.Time
+}
+
+func (m *mockAccrualCursor) Get() time.Time {
+	return m.until
+}
+
+func (m *mockAccrualCursor) Set(until time.Time) error {
+	m.until = until
+	return nil
+}
+
+func TestOverdraftService_SetLimit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			SetOverdraftLimitMock: func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error) {
+				accounts[accountNumber].OverdraftLimit = limit
+				return accounts[accountNumber], nil
+			},
+		}
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		overdraftService := NewOverdraftService(mockAccountCach, &mockAccrualCursor{}, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{},
+			decimal.NewFromInt(24), decimal.NewFromInt(1000))
+
+		overdraft, err := overdraftService.SetLimit(1, decimal.NewFromInt(500))
+		assert.NoError(t, err)
+		assert.True(t, overdraft.Limit.Equal(decimal.NewFromInt(500)))
+		assert.True(t, accounts[1].Available().Equal(decimal.NewFromInt(1000)))
+	})
+	t.Run("TooHigh", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			SetOverdraftLimitMock: func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error) {
+				accounts[accountNumber].OverdraftLimit = limit
+				return accounts[accountNumber], nil
+			},
+		}
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		overdraftService := NewOverdraftService(mockAccountCach, &mockAccrualCursor{}, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{},
+			decimal.NewFromInt(24), decimal.NewFromInt(1000))
+
+		_, err := overdraftService.SetLimit(1, decimal.NewFromInt(1001))
+		assert.True(t, errs.Is(err, errs.OverdraftLimitTooHigh))
+	})
+	t.Run("CorporateAccount", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			SetOverdraftLimitMock: func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error) {
+				accounts[accountNumber].OverdraftLimit = limit
+				return accounts[accountNumber], nil
+			},
+		}
+		mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+			return accounts[accountNumber].Balance
+		}}
+		overdraftService := NewOverdraftService(mockAccountCach, &mockAccrualCursor{}, &mockATransactionCache{}, mockBalanceReadr, mockHouseAccounter{},
+			decimal.NewFromInt(24), decimal.Zero)
+
+		_, err := overdraftService.SetLimit(2, decimal.NewFromInt(100))
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+	})
+}
+
+func TestOverdraftService_AccrueInterest(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+		GetAllMock: func() []*models.Account {
+			return []*models.Account{accounts[1], accounts[2], accounts[3]}
+		},
+		SetOverdraftLimitMock: func(accountNumber types.AccountNumber, limit decimal.Decimal) (*models.Account, error) {
+			accounts[accountNumber].OverdraftLimit = limit
+			return accounts[accountNumber], nil
+		},
+	}
+	cursor := &mockAccrualCursor{}
+	var transactions []*models.Transaction
+	mockTransactionCach := &mockATransactionCache{
+		CreateMock: func(transactionHistory *models.Transaction) *models.Transaction {
+			transactionHistory.ID = types.TransactionID(len(transactions) + 1)
+			transactions = append(transactions, transactionHistory)
+			return transactionHistory
+		},
+	}
+	mockBalanceReadr := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+		return accounts[accountNumber].Balance
+	}}
+	overdraftService := NewOverdraftService(mockAccountCach, cursor, mockTransactionCach, mockBalanceReadr, mockHouseAccounter{},
+		decimal.NewFromFloat(36.5), decimal.Zero)
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	overdraftService.now = func() time.Time { return now }
+
+	accounts[1].Balance = decimal.NewFromInt(-1000)
+	accounts[1].OverdrawnSince = now.Add(-48 * time.Hour)
+
+	// The first accrual only starts counting the days
+	assert.Equal(t, 0, overdraftService.AccrueInterest())
+
+	now = now.Add(12 * time.Hour)
+	assert.Equal(t, 0, overdraftService.AccrueInterest())
+
+	now = now.Add(12 * time.Hour)
+	assert.Equal(t, 1, overdraftService.AccrueInterest())
+	// 1000 at 36.5% for a single day
+	assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(-1001)))
+	assert.True(t, accounts[3].Balance.Equal(decimal.NewFromInt(1)))
+	assert.Equal(t, 2, len(transactions))
+	assert.Equal(t, types.OverdraftInterest, transactions[0].TransactionType)
+	assert.Equal(t, types.Debit, transactions[0].Direction)
+	assert.Equal(t, types.Credit, transactions[1].Direction)
+
+	overdraft, err := overdraftService.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, overdraft.DaysOverdrawn)
+	assert.True(t, overdraft.Used.Equal(decimal.NewFromInt(1001)))
+
+	// A new service over the same cursor does not charge the day again
+	restarted := NewOverdraftService(mockAccountCach, cursor, mockTransactionCach, mockBalanceReadr, mockHouseAccounter{},
+		decimal.NewFromFloat(36.5), decimal.Zero)
+	restarted.now = func() time.Time { return now }
+	assert.Equal(t, 0, restarted.AccrueInterest())
+
+	// Only the days that ended with a negative balance are charged
+	accounts[1].Balance = decimal.NewFromInt(-1000)
+	balances := mockBalanceReader{BalanceAtMock: func(accountNumber types.AccountNumber, at time.Time) decimal.Decimal {
+		if accountNumber == 1 && at.Equal(startOfDay(now).AddDate(0, 0, 1)) {
+			return decimal.NewFromInt(-1000)
+		}
+		return decimal.Zero
+	}}
+	restarted = NewOverdraftService(mockAccountCach, cursor, mockTransactionCach, balances, mockHouseAccounter{},
+		decimal.NewFromFloat(36.5), decimal.Zero)
+	restarted.now = func() time.Time { return now.AddDate(0, 0, 3) }
+	assert.Equal(t, 1, restarted.AccrueInterest())
+	assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(-1001)))
+}
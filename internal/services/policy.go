@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// DefaultPolicyRules are the rules the service has always enforced:
+// individuals pay corporates, and only individuals deposit and withdraw
+func DefaultPolicyRules() []*models.PolicyRule {
+	return []*models.PolicyRule{
+		{Name: "individual-pays-corporate", TransactionType: types.Payment, SenderType: types.Individual, ReceiverType: types.Corporate},
+		{Name: "individual-deposits", TransactionType: types.Deposit, SenderType: types.Individual},
+		{Name: "individual-withdraws", TransactionType: types.Withdraw, SenderType: types.Individual},
+	}
+}
+
+type PolicyService struct {
+	rules []*models.PolicyRule
+}
+
+func NewPolicyService(rules []*models.PolicyRule) *PolicyService {
+	return &PolicyService{rules: rules}
+}
+
+// Evaluate allows the transaction if any rule matches it,
+// otherwise it returns an account_type_not_allowed error carrying the trace of every rule
+func (ps *PolicyService) Evaluate(transactionType types.TransactionType, sender *models.Account, receiver *models.Account) error {
+	trace := make([]*models.PolicyTrace, 0, len(ps.rules))
+	for _, rule := range ps.rules {
+		reason := mismatch(rule, transactionType, sender, receiver)
+		if reason == "" {
+			return nil
+		}
+		trace = append(trace, &models.PolicyTrace{
+			Rule:    rule.Name,
+			Matched: false,
+			Reason:  reason,
+		})
+	}
+
+	return errs.Newf(errs.AccountTypeNotAllowed, "no rule allows %s", describe(transactionType, sender, receiver)).
+		With("trace", trace)
+}
+
+// mismatch returns why the rule does not match the transaction, or an empty string if it does
+func mismatch(rule *models.PolicyRule, transactionType types.TransactionType, sender *models.Account, receiver *models.Account) string {
+	if rule.TransactionType != transactionType {
+		return fmt.Sprintf("rule is for %s", rule.TransactionType)
+	}
+	if rule.SenderType != "" && rule.SenderType != sender.AccountType {
+		return fmt.Sprintf("sender must be %s", rule.SenderType)
+	}
+	if receiver == nil && rule.ReceiverType != "" {
+		return "rule requires a receiver"
+	}
+	if receiver != nil && rule.ReceiverType != "" && rule.ReceiverType != receiver.AccountType {
+		return fmt.Sprintf("receiver must be %s", rule.ReceiverType)
+	}
+	if rule.Currency != "" && rule.Currency != sender.CurrencyCode {
+		return fmt.Sprintf("currency must be %s", rule.Currency)
+	}
+	return ""
+}
+
+func describe(transactionType types.TransactionType, sender *models.Account, receiver *models.Account) string {
+	if receiver == nil {
+		return fmt.Sprintf("%s by %s account in %s", transactionType, sender.AccountType, sender.CurrencyCode)
+	}
+	return fmt.Sprintf("%s from %s to %s account in %s", transactionType, sender.AccountType, receiver.AccountType, sender.CurrencyCode)
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPolicyService_Evaluate(t *testing.T) {
+	individual := &models.Account{AccountNumber: 1, AccountType: types.Individual, CurrencyCode: types.TRY}
+	otherIndividual := &models.Account{AccountNumber: 2, AccountType: types.Individual, CurrencyCode: types.TRY}
+	corporate := &models.Account{AccountNumber: 3, AccountType: types.Corporate, CurrencyCode: types.TRY}
+	usdCorporate := &models.Account{AccountNumber: 4, AccountType: types.Corporate, CurrencyCode: types.USD}
+
+	t.Run("DefaultRules", func(t *testing.T) {
+		policyService := NewPolicyService(DefaultPolicyRules())
+		assert.NoError(t, policyService.Evaluate(types.Payment, individual, corporate))
+		assert.NoError(t, policyService.Evaluate(types.Deposit, individual, nil))
+		assert.NoError(t, policyService.Evaluate(types.Withdraw, individual, nil))
+		assert.Error(t, policyService.Evaluate(types.Payment, individual, otherIndividual))
+		assert.Error(t, policyService.Evaluate(types.Payment, corporate, individual))
+		assert.Error(t, policyService.Evaluate(types.Withdraw, corporate, nil))
+	})
+
+	t.Run("PeerToPeer", func(t *testing.T) {
+		policyService := NewPolicyService(append(DefaultPolicyRules(), &models.PolicyRule{
+			Name:            "p2p",
+			TransactionType: types.Payment,
+			SenderType:      types.Individual,
+			ReceiverType:    types.Individual,
+		}))
+		assert.NoError(t, policyService.Evaluate(types.Payment, individual, otherIndividual))
+	})
+
+	t.Run("CurrencyRestricted", func(t *testing.T) {
+		policyService := NewPolicyService([]*models.PolicyRule{
+			{
+				Name:            "try-payouts",
+				TransactionType: types.Payment,
+				SenderType:      types.Corporate,
+				ReceiverType:    types.Individual,
+				Currency:        types.TRY,
+			},
+		})
+		assert.NoError(t, policyService.Evaluate(types.Payment, corporate, individual))
+		assert.Error(t, policyService.Evaluate(types.Payment, usdCorporate, individual))
+	})
+
+	t.Run("Trace", func(t *testing.T) {
+		policyService := NewPolicyService(DefaultPolicyRules())
+		err := policyService.Evaluate(types.Payment, corporate, individual)
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+
+		trace, ok := err.(*errs.Error).Extensions["trace"].([]*models.PolicyTrace)
+		assert.True(t, ok)
+		assert.Equal(t, 3, len(trace))
+		assert.Equal(t, "individual-pays-corporate", trace[0].Rule)
+		assert.Equal(t, "sender must be individual", trace[0].Reason)
+		assert.Equal(t, "rule is for deposit", trace[1].Reason)
+	})
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+)
+
+// ProjectionService exposes the event store behind the accounts
+// and the commands to verify and rebuild the account projection from it.
+type ProjectionService struct {
+	projection accountProjection
+}
+
+type accountProjection interface {
+	Events(after types.EventSequence) []*models.Event
+	Rebuild()
+	Diff() []*models.ProjectionDiff
+}
+
+func NewProjectionService(p accountProjection) *ProjectionService {
+	return &ProjectionService{projection: p}
+}
+
+// Events returns the events recorded after the sequence, in order
+func (ps *ProjectionService) Events(after types.EventSequence) []*models.Event {
+	return ps.projection.Events(after)
+}
+
+// Diff compares the live account projection with one rebuilt from the events
+func (ps *ProjectionService) Diff() []*models.ProjectionDiff {
+	return ps.projection.Diff()
+}
+
+// Rebuild replaces the live account projection with one rebuilt from the events
+// and returns the differences it fixed
+func (ps *ProjectionService) Rebuild() []*models.ProjectionDiff {
+	diffs := ps.projection.Diff()
+	ps.projection.Rebuild()
+	return diffs
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type mockAccountProjection struct {
+	EventsMock  func(after types.EventSequence) []*models.Event
+	RebuildMock func()
+	DiffMock    func() []*models.ProjectionDiff
+}
+
+func (m mockAccountProjection) Events(after types.EventSequence) []*models.Event {
+	return m.EventsMock(after)
+}
+
+func (m mockAccountProjection) Rebuild() {
+	m.RebuildMock()
+}
+
+func (m mockAccountProjection) Diff() []*models.ProjectionDiff {
+	return m.DiffMock()
+}
+
+func TestProjectionService_Rebuild(t *testing.T) {
+	rebuilt := false
+	mockProjection := mockAccountProjection{
+		RebuildMock: func() {
+			rebuilt = true
+		},
+		DiffMock: func() []*models.ProjectionDiff {
+			if rebuilt {
+				return nil
+			}
+			return []*models.ProjectionDiff{
+				{AccountNumber: 1, Field: "balance", Live: "1000", Rebuilt: "120"},
+			}
+		},
+	}
+	projectionService := NewProjectionService(mockProjection)
+
+	diffs := projectionService.Rebuild()
+	assert.True(t, rebuilt)
+	assert.Equal(t, 1, len(diffs))
+	assert.Empty(t, projectionService.Diff())
+}
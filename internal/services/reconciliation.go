@@ -0,0 +1,146 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReconciliationService proves that the balance of every account equals the sum of its history.
+// Balances and history lines are written to separate caches, so they can drift apart.
+type ReconciliationService struct {
+	accountCache     accountInspector
+	transactionCache transactionCache
+	now              func() time.Time
+
+	// mu serializes the runs so that a discrepancy is never corrected twice
+	mu   sync.Mutex
+	last *models.ReconciliationReport
+}
+
+// accountInspector is an accountLister that can read an account while no change is committed
+type accountInspector interface {
+	accountLister
+	Inspect(accountNumber types.AccountNumber, read func(account *models.Account)) error
+}
+
+// inspectAll returns a copy of every account, each read while no change of it is committed
+func inspectAll(ac accountInspector) []*models.Account {
+	var accounts []*models.Account
+	for _, account := range ac.GetAll() {
+		_ = ac.Inspect(account.AccountNumber, func(account *models.Account) {
+			accounts = append(accounts, account)
+		})
+	}
+	return accounts
+}
+
+func NewReconciliationService(ac accountInspector, tc transactionCache) *ReconciliationService {
+	return &ReconciliationService{
+		accountCache:     ac,
+		transactionCache: tc,
+		now:              time.Now,
+	}
+}
+
+// Reconcile compares the balance of every account with the sum of its history and reports the accounts where they differ.
+// With correct set an adjustment line of the difference is added to the history of each of them.
+func (rs *ReconciliationService) Reconcile(correct bool) *models.ReconciliationReport {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	report := &models.ReconciliationReport{
+		At:        rs.now(),
+		Corrected: correct,
+	}
+	currencies := make(map[types.Currency]*models.CurrencyDiscrepancy)
+	for _, account := range rs.accountCache.GetAll() {
+		report.Accounts++
+
+		// Balances are committed together with their history lines, so while the account is inspected
+		// a payment being written is either in both the balance and the history or in neither of them
+		var discrepancy *models.Discrepancy
+		err := rs.accountCache.Inspect(account.AccountNumber, func(account *models.Account) {
+			discrepancy = rs.discrepancy(account)
+			if discrepancy != nil && correct {
+				discrepancy.AdjustmentID = rs.adjust(discrepancy)
+			}
+		})
+		if err != nil || discrepancy == nil {
+			continue
+		}
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+
+		total, ok := currencies[discrepancy.CurrencyCode]
+		if !ok {
+			total = &models.CurrencyDiscrepancy{CurrencyCode: discrepancy.CurrencyCode}
+			currencies[discrepancy.CurrencyCode] = total
+			report.Currencies = append(report.Currencies, total)
+		}
+		total.Accounts++
+		total.Difference = total.Difference.Add(discrepancy.Difference)
+	}
+
+	sort.Slice(report.Discrepancies, func(i, j int) bool {
+		return report.Discrepancies[i].AccountNumber < report.Discrepancies[j].AccountNumber
+	})
+	sort.Slice(report.Currencies, func(i, j int) bool {
+		return report.Currencies[i].CurrencyCode < report.Currencies[j].CurrencyCode
+	})
+
+	rs.last = report
+	return report
+}
+
+// Last returns the report of the last reconciliation
+func (rs *ReconciliationService) Last() (*models.ReconciliationReport, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.last == nil {
+		return nil, errs.New(errs.ReconciliationNotRun)
+	}
+	return rs.last, nil
+}
+
+// discrepancy compares the balance of the account with the sum of its history, it returns nil if they are equal
+func (rs *ReconciliationService) discrepancy(account *models.Account) *models.Discrepancy {
+	balance := account.Balance
+
+	computed := decimal.Zero
+	history, _ := rs.transactionCache.GetAll(account.AccountNumber)
+	for _, transaction := range history {
+		computed = computed.Add(signed(transaction))
+	}
+	if balance.Equal(computed) {
+		return nil
+	}
+
+	return &models.Discrepancy{
+		AccountNumber: account.AccountNumber,
+		CurrencyCode:  account.CurrencyCode,
+		Balance:       balance,
+		Computed:      computed,
+		Difference:    balance.Sub(computed),
+	}
+}
+
+// adjust adds the line to the history of the account that makes it add up to the balance
+func (rs *ReconciliationService) adjust(discrepancy *models.Discrepancy) types.TransactionID {
+	direction := types.Credit
+	if discrepancy.Difference.IsNegative() {
+		direction = types.Debit
+	}
+
+	transaction := rs.transactionCache.Create(&models.Transaction{
+		AccountNumber:   discrepancy.AccountNumber,
+		Amount:          discrepancy.Difference.Abs(),
+		TransactionType: types.Adjustment,
+		Direction:       direction,
+	})
+	return transaction.ID
+}
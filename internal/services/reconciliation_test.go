@@ -0,0 +1,186 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	t.Run("ReportOnly", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		history := map[types.AccountNumber][]*models.Transaction{
+			1: {{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(500), TransactionType: types.Deposit, Direction: types.Credit}},
+			2: {{ID: 2, AccountNumber: 2, Amount: decimal.NewFromInt(20), TransactionType: types.Payment, Direction: types.Credit}},
+		}
+		mockTransactionCach := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(history) + 10)
+				history[transaction.AccountNumber] = append(history[transaction.AccountNumber], transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				if _, ok := history[accountNumber]; !ok {
+					return nil, errs.New(errs.HistoryNotFound)
+				}
+				return history[accountNumber], nil
+			},
+		}
+		reconciliationService := NewReconciliationService(mockAccountCach, mockTransactionCach)
+
+		report := reconciliationService.Reconcile(false)
+		assert.Equal(t, 3, report.Accounts)
+		assert.Equal(t, 1, len(report.Discrepancies))
+		assert.Equal(t, types.AccountNumber(2), report.Discrepancies[0].AccountNumber)
+		assert.True(t, report.Discrepancies[0].Difference.Equal(decimal.NewFromInt(-20)))
+		assert.Equal(t, types.TransactionID(0), report.Discrepancies[0].AdjustmentID)
+		assert.Equal(t, 1, len(report.Currencies))
+		assert.Equal(t, types.TRY, report.Currencies[0].CurrencyCode)
+		assert.True(t, report.Currencies[0].Difference.Equal(decimal.NewFromInt(-20)))
+		assert.Equal(t, 1, len(history[2]))
+
+		last, err := reconciliationService.Last()
+		assert.NoError(t, err)
+		assert.Equal(t, report, last)
+	})
+	t.Run("Correct", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		history := map[types.AccountNumber][]*models.Transaction{
+			1: {{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(500), TransactionType: types.Deposit, Direction: types.Credit}},
+			2: {{ID: 2, AccountNumber: 2, Amount: decimal.NewFromInt(20), TransactionType: types.Payment, Direction: types.Credit}},
+		}
+		mockTransactionCach := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(history) + 10)
+				history[transaction.AccountNumber] = append(history[transaction.AccountNumber], transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				if _, ok := history[accountNumber]; !ok {
+					return nil, errs.New(errs.HistoryNotFound)
+				}
+				return history[accountNumber], nil
+			},
+		}
+		reconciliationService := NewReconciliationService(mockAccountCach, mockTransactionCach)
+
+		report := reconciliationService.Reconcile(true)
+		assert.Equal(t, 1, len(report.Discrepancies))
+		assert.NotZero(t, report.Discrepancies[0].AdjustmentID)
+		assert.Equal(t, 2, len(history[2]))
+		assert.Equal(t, types.Adjustment, history[2][1].TransactionType)
+		assert.Equal(t, types.Debit, history[2][1].Direction)
+		assert.True(t, history[2][1].Amount.Equal(decimal.NewFromInt(20)))
+
+		report = reconciliationService.Reconcile(false)
+		assert.Empty(t, report.Discrepancies)
+	})
+	t.Run("NotRun", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: houseOwnerName, AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+			GetAllMock: func() []*models.Account {
+				return []*models.Account{accounts[1], accounts[2], accounts[3]}
+			},
+		}
+		history := map[types.AccountNumber][]*models.Transaction{
+			1: {{ID: 1, AccountNumber: 1, Amount: decimal.NewFromInt(500), TransactionType: types.Deposit, Direction: types.Credit}},
+			2: {{ID: 2, AccountNumber: 2, Amount: decimal.NewFromInt(20), TransactionType: types.Payment, Direction: types.Credit}},
+		}
+		mockTransactionCach := &mockATransactionCache{
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(history) + 10)
+				history[transaction.AccountNumber] = append(history[transaction.AccountNumber], transaction)
+				return transaction
+			},
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				if _, ok := history[accountNumber]; !ok {
+					return nil, errs.New(errs.HistoryNotFound)
+				}
+				return history[accountNumber], nil
+			},
+		}
+		reconciliationService := NewReconciliationService(mockAccountCach, mockTransactionCach)
+
+		_, err := reconciliationService.Last()
+		assert.True(t, errs.Is(err, errs.ReconciliationNotRun))
+	})
+}
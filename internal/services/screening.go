@@ -0,0 +1,460 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+type ScreeningService struct {
+	screeningCache screeningCache
+	accountCache   accountCache
+	payments       screenedPaymentMaker
+	entities       []*screenedEntity
+	// accountThreshold and paymentThreshold are the lowest scores between 0 and 1
+	// a name is held for review with when an account is opened or renamed and before a payment
+	accountThreshold float64
+	paymentThreshold float64
+	now              func() time.Time
+
+	// mu serializes the decisions so that a case cannot be cleared and confirmed at once
+	mu sync.Mutex
+}
+
+type screeningCache interface {
+	Create(screeningCase *models.ScreeningCase) *models.ScreeningCase
+	Get(id types.ScreeningCaseID) (*models.ScreeningCase, error)
+	GetByStatus(status types.ScreeningStatus) []*models.ScreeningCase
+	Save(screeningCase *models.ScreeningCase)
+	AddDecision(decision *models.ScreeningDecision)
+	GetDecisions() []*models.ScreeningDecision
+	LastDecision(accountNumber types.AccountNumber, entityID string) *models.ScreeningDecision
+}
+
+// screenedPaymentMaker makes the payments and split payments held for review once they are cleared
+type screenedPaymentMaker interface {
+	paymentMaker
+	NewSplitPayment(split *models.SplitPayment) (*models.SplitPaymentResult, error)
+}
+
+// screenedEntity is a sanctioned entity with its names normalized once for matching
+type screenedEntity struct {
+	entity *models.SanctionedEntity
+	names  []*normalizedName
+}
+
+func NewScreeningService(sc screeningCache, ac accountCache, entities []*models.SanctionedEntity, accountThreshold float64, paymentThreshold float64) *ScreeningService {
+	ss := &ScreeningService{
+		screeningCache:   sc,
+		accountCache:     ac,
+		accountThreshold: accountThreshold,
+		paymentThreshold: paymentThreshold,
+		now:              time.Now,
+	}
+	for _, entity := range entities {
+		screened := &screenedEntity{entity: entity}
+		for _, name := range entity.Names {
+			if n := normalizeName(name); n.joined != "" {
+				screened.names = append(screened.names, n)
+			}
+		}
+		ss.entities = append(ss.entities, screened)
+	}
+	return ss
+}
+
+// UsePayments sets the service the payments held for review are made with once they are cleared.
+// It is set after the service is created since that service screens its payments with this one.
+func (ss *ScreeningService) UsePayments(payments screenedPaymentMaker) {
+	ss.payments = payments
+}
+
+// Match returns the sanctioned entities the name scores at least threshold against, the best hit first
+func (ss *ScreeningService) Match(name string, threshold float64) []*models.ScreeningHit {
+	screened := normalizeName(name)
+	if screened.joined == "" {
+		return nil
+	}
+
+	var hits []*models.ScreeningHit
+	for _, e := range ss.entities {
+		var best *models.ScreeningHit
+		for i, n := range e.names {
+			score := nameSimilarity(screened, n)
+			if score >= threshold && (best == nil || score > best.Score) {
+				best = &models.ScreeningHit{
+					EntityID:    e.entity.ID,
+					MatchedName: e.entity.Names[i],
+					Programs:    e.entity.Programs,
+					Score:       math.Round(score*1000) / 1000,
+				}
+			}
+		}
+		if best != nil {
+			hits = append(hits, best)
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits
+}
+
+// ScreenAccount screens the owner name of an account that is opened or renamed. The account is not rejected,
+// every hit that was never decided for the account and has no pending case yet is put into the review queue.
+func (ss *ScreeningService) ScreenAccount(account *models.Account, trigger types.ScreeningTrigger) []*models.ScreeningCase {
+	var cases []*models.ScreeningCase
+	for _, hit := range ss.Match(account.OwnerName, ss.accountThreshold) {
+		if ss.screeningCache.LastDecision(account.AccountNumber, hit.EntityID) != nil || ss.pending(account.AccountNumber, hit.EntityID) != nil {
+			continue
+		}
+		cases = append(cases, ss.screeningCache.Create(&models.ScreeningCase{
+			Trigger:       trigger,
+			AccountNumber: account.AccountNumber,
+			Name:          account.OwnerName,
+			Hit:           hit,
+			Status:        types.ScreeningPending,
+			CreatedAt:     ss.now(),
+		}))
+	}
+	return cases
+}
+
+// ScreenPayment screens the owner names of both sides of a payment. A side confirmed as a match rejects the payment,
+// otherwise a hit not decided for the account puts the payment into the review queue with its amount held on the sender
+// and the payment is answered with payment_under_review. The payment is made once the case is cleared.
+// While the hit waits for a decision in a case, the payment is answered with that case and nothing is held again.
+func (ss *ScreeningService) ScreenPayment(payment *models.Payment, sender *models.Account, receiver *models.Account) error {
+	held := *payment
+	held.TransactionDetails = payment.TransactionDetails.Copy()
+	return ss.screenHeld(&models.ScreeningCase{Payment: &held}, payment.Amount, sender, receiver)
+}
+
+// ScreenSplitPayment screens the sender and every receiver of a split payment of total, see ScreenPayment.
+// The whole split is held and, once the case is cleared, paid to every receiver at once.
+func (ss *ScreeningService) ScreenSplitPayment(split *models.SplitPayment, total decimal.Decimal, sender *models.Account, receivers ...*models.Account) error {
+	held := *split
+	// The amount may be left out when the receivers are given amounts, the case keeps the total it holds
+	held.Amount = total
+	held.TransactionDetails = split.TransactionDetails.Copy()
+	held.Receivers = make([]*models.SplitReceiver, 0, len(split.Receivers))
+	for _, r := range split.Receivers {
+		receiver := *r
+		held.Receivers = append(held.Receivers, &receiver)
+	}
+	return ss.screenHeld(&models.ScreeningCase{SplitPayment: &held}, total, sender, receivers...)
+}
+
+// screenHeld screens the sender and the receivers of a payment of amount, and when a hit waits for a decision
+// holds the amount on the sender and opens screeningCase for the hit
+func (ss *ScreeningService) screenHeld(screeningCase *models.ScreeningCase, amount decimal.Decimal, sender *models.Account, receivers ...*models.Account) error {
+	account, hit, err := ss.screenParties(append([]*models.Account{sender}, receivers...)...)
+	if err != nil || hit == nil {
+		return err
+	}
+	if pending := ss.pending(account.AccountNumber, hit.EntityID); pending != nil {
+		return underReview(pending)
+	}
+
+	err = ss.accountCache.ApplyChanges([]*models.BalanceChange{
+		{AccountNumber: sender.AccountNumber, Held: amount, RequireFunds: true, Reason: types.FundsHeld},
+	})
+	if err != nil {
+		return err
+	}
+
+	screeningCase.Trigger = types.PaymentScreen
+	screeningCase.AccountNumber = account.AccountNumber
+	screeningCase.Name = account.OwnerName
+	screeningCase.Hit = hit
+	screeningCase.Status = types.ScreeningPending
+	screeningCase.CreatedAt = ss.now()
+	return underReview(ss.screeningCache.Create(screeningCase))
+}
+
+// ScreenParties screens the owner names of the accounts of a payment that cannot be held for review,
+// such as the capture of a hold or an escrow. A hit not decided for the account is put into the review
+// queue, unless a case already waits for it, and answered with payment_under_review, the payment has to be made again
+// once the case is cleared.
+func (ss *ScreeningService) ScreenParties(accounts ...*models.Account) error {
+	account, hit, err := ss.screenParties(accounts...)
+	if err != nil || hit == nil {
+		return err
+	}
+
+	screeningCase := ss.pending(account.AccountNumber, hit.EntityID)
+	if screeningCase == nil {
+		screeningCase = ss.screeningCache.Create(&models.ScreeningCase{
+			Trigger:       types.PaymentScreen,
+			AccountNumber: account.AccountNumber,
+			Name:          account.OwnerName,
+			Hit:           hit,
+			Status:        types.ScreeningPending,
+			CreatedAt:     ss.now(),
+		})
+	}
+	return underReview(screeningCase)
+}
+
+// RejectConfirmed returns sanctions_match if a hit of any of the accounts was confirmed, it opens no case.
+// It screens the transactions that only move funds out of the bank, such as withdrawals.
+func (ss *ScreeningService) RejectConfirmed(accounts ...*models.Account) error {
+	_, _, err := ss.screenParties(accounts...)
+	return err
+}
+
+// Decide records the decision of a reviewer on a pending case. Cleared marks the hit as a false positive for the account
+// so it is not held again, confirmed rejects every later payment of the account. A payment held by the case is released
+// and, when the case is cleared, made.
+func (ss *ScreeningService) Decide(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error) {
+	if decision.Status != types.ScreeningCleared && decision.Status != types.ScreeningConfirmed {
+		return nil, errs.Newf(errs.InvalidArgument, "status must be %s or %s", types.ScreeningCleared, types.ScreeningConfirmed)
+	}
+	if strings.TrimSpace(decision.Reviewer) == "" {
+		return nil, errs.Newf(errs.InvalidArgument, "reviewer is required for the audit trail")
+	}
+
+	screeningCase, err := ss.decide(id, decision)
+	if err != nil {
+		return nil, err
+	}
+
+	// The payment is made outside of the lock since it is screened again by this service
+	if screeningCase.Status == types.ScreeningCleared && ss.payments != nil {
+		var id types.TransactionID
+		switch {
+		case screeningCase.Payment != nil:
+			var transaction *models.Transaction
+			transaction, err = ss.payments.NewPayment(screeningCase.Payment)
+			if err == nil {
+				id = transaction.ID
+			}
+		case screeningCase.SplitPayment != nil:
+			var result *models.SplitPaymentResult
+			result, err = ss.payments.NewSplitPayment(screeningCase.SplitPayment)
+			if err == nil {
+				id = result.Payment.ID
+			}
+		}
+
+		ss.mu.Lock()
+		if err != nil {
+			screeningCase.PaymentError = err.Error()
+		} else {
+			screeningCase.PaymentID = id
+		}
+		ss.screeningCache.Save(screeningCase)
+		ss.mu.Unlock()
+	}
+	return screeningCase, nil
+}
+
+func (ss *ScreeningService) GetCase(id types.ScreeningCaseID) (*models.ScreeningCase, error) {
+	return ss.screeningCache.Get(id)
+}
+
+// FindCases returns the cases in the status, or every case for an empty status
+func (ss *ScreeningService) FindCases(status types.ScreeningStatus) ([]*models.ScreeningCase, error) {
+	switch status {
+	case "", types.ScreeningPending, types.ScreeningCleared, types.ScreeningConfirmed:
+	default:
+		return nil, errs.Newf(errs.InvalidArgument, "unknown status %q", status)
+	}
+	return ss.screeningCache.GetByStatus(status), nil
+}
+
+// Decisions returns the audit trail of every decision in the order they were made
+func (ss *ScreeningService) Decisions() []*models.ScreeningDecision {
+	return ss.screeningCache.GetDecisions()
+}
+
+func (ss *ScreeningService) decide(id types.ScreeningCaseID, decision *models.ScreeningDecision) (*models.ScreeningCase, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	screeningCase, err := ss.screeningCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if screeningCase.Status != types.ScreeningPending {
+		return nil, errs.Newf(errs.ScreeningCaseDecided, "case is %s", screeningCase.Status)
+	}
+
+	var release *models.BalanceChange
+	switch {
+	case screeningCase.Payment != nil:
+		release = &models.BalanceChange{AccountNumber: screeningCase.Payment.SenderAccount, Held: screeningCase.Payment.Amount.Neg(), Reason: types.FundsReleased}
+	case screeningCase.SplitPayment != nil:
+		release = &models.BalanceChange{AccountNumber: screeningCase.SplitPayment.SenderAccount, Held: screeningCase.SplitPayment.Amount.Neg(), Reason: types.FundsReleased}
+	}
+	if release != nil {
+		err = ss.accountCache.ApplyChanges([]*models.BalanceChange{release})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := ss.now()
+	screeningCase.Status = decision.Status
+	screeningCase.Reviewer = decision.Reviewer
+	screeningCase.Note = decision.Note
+	screeningCase.DecidedAt = now
+	ss.screeningCache.Save(screeningCase)
+	ss.screeningCache.AddDecision(&models.ScreeningDecision{
+		CaseID:        screeningCase.ID,
+		AccountNumber: screeningCase.AccountNumber,
+		EntityID:      screeningCase.Hit.EntityID,
+		Status:        decision.Status,
+		Reviewer:      decision.Reviewer,
+		Note:          decision.Note,
+		At:            now,
+	})
+	return screeningCase, nil
+}
+
+// screenParties returns the first account with a hit that was never decided for it and that hit,
+// or sanctions_match if a hit of any of the accounts was confirmed
+func (ss *ScreeningService) screenParties(accounts ...*models.Account) (*models.Account, *models.ScreeningHit, error) {
+	var undecided *models.Account
+	var undecidedHit *models.ScreeningHit
+	for _, account := range accounts {
+		for _, hit := range ss.Match(account.OwnerName, ss.paymentThreshold) {
+			decision := ss.screeningCache.LastDecision(account.AccountNumber, hit.EntityID)
+			switch {
+			case decision == nil && undecided == nil:
+				undecided, undecidedHit = account, hit
+			case decision != nil && decision.Status == types.ScreeningConfirmed:
+				return nil, nil, errs.Newf(errs.SanctionsMatch, "account %d matches sanctioned entity %s", account.AccountNumber, hit.EntityID)
+			}
+		}
+	}
+	return undecided, undecidedHit, nil
+}
+
+// pending returns the case of the account and the entity that waits for a decision, or nil if there is none
+func (ss *ScreeningService) pending(accountNumber types.AccountNumber, entityID string) *models.ScreeningCase {
+	for _, c := range ss.screeningCache.GetByStatus(types.ScreeningPending) {
+		if c.AccountNumber == accountNumber && c.Hit.EntityID == entityID {
+			return c
+		}
+	}
+	return nil
+}
+
+// underReview answers a payment waiting for the screening case
+func underReview(screeningCase *models.ScreeningCase) error {
+	if screeningCase.Payment != nil {
+		return errs.Newf(errs.PaymentUnderReview, "payment is held in screening case %d", screeningCase.ID).
+			With("caseId", screeningCase.ID)
+	}
+	return errs.Newf(errs.PaymentUnderReview, "the payment can be made again once screening case %d is cleared", screeningCase.ID).
+		With("caseId", screeningCase.ID)
+}
+
+// legalForms are the words of company names that do not tell companies apart
+var legalForms = map[string]bool{
+	"LTD": true, "LIMITED": true, "INC": true, "LLC": true, "CO": true, "CORP": true, "CORPORATION": true,
+	"COMPANY": true, "SA": true, "AS": true, "STI": true, "GMBH": true, "PLC": true, "AG": true,
+}
+
+// foldedLetters spells the letters with diacritics of the supported languages in plain latin letters
+var foldedLetters = strings.NewReplacer(
+	"Ç", "C", "Ğ", "G", "İ", "I", "Ö", "O", "Ş", "S", "Ü", "U",
+	"Á", "A", "À", "A", "Â", "A", "Ä", "A", "É", "E", "È", "E", "Ê", "E", "Í", "I", "Î", "I",
+	"Ñ", "N", "Ó", "O", "Ô", "O", "Ú", "U", "Û", "U",
+)
+
+// normalizedName is a name in upper case plain letters, joined in its own word order and in alphabetical order
+// so that "DOE, John" matches "John Doe"
+type normalizedName struct {
+	joined string
+	sorted string
+}
+
+func normalizeName(name string) *normalizedName {
+	upper := foldedLetters.Replace(strings.ToUpper(name))
+	words := strings.FieldsFunc(upper, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	kept := words[:0]
+	for _, w := range words {
+		if !legalForms[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		kept = words
+	}
+
+	sorted := append([]string(nil), kept...)
+	sort.Strings(sorted)
+	return &normalizedName{
+		joined: strings.Join(kept, " "),
+		sorted: strings.Join(sorted, " "),
+	}
+}
+
+// nameSimilarity scores two names between 0 and 1 with the Jaro-Winkler similarity,
+// the better of comparing them in their own word order and in alphabetical order
+func nameSimilarity(a *normalizedName, b *normalizedName) float64 {
+	return math.Max(jaroWinkler(a.joined, b.joined), jaroWinkler(a.sorted, b.sorted))
+}
+
+func jaroWinkler(a string, b string) float64 {
+	s, t := []rune(a), []rune(b)
+	if len(s) == 0 || len(t) == 0 {
+		return 0
+	}
+
+	window := int(math.Max(float64(len(s)), float64(len(t))))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	sMatched := make([]bool, len(s))
+	tMatched := make([]bool, len(t))
+	matches := 0
+	for i := range s {
+		from := int(math.Max(0, float64(i-window)))
+		to := int(math.Min(float64(len(t)), float64(i+window+1)))
+		for j := from; j < to; j++ {
+			if !tMatched[j] && s[i] == t[j] {
+				sMatched[i], tMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range s {
+		if !sMatched[i] {
+			continue
+		}
+		for !tMatched[j] {
+			j++
+		}
+		if s[i] != t[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s)) + m/float64(len(t)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(s) && prefix < len(t) && s[prefix] == t[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
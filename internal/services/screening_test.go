@@ -0,0 +1,554 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockScreeningCache struct {
+	cases     []*models.ScreeningCase
+	decisions []*models.ScreeningDecision
+}
+
+func (m *mockScreeningCache) Create(screeningCase *models.ScreeningCase) *models.ScreeningCase {
+	screeningCase.ID = types.ScreeningCaseID(len(m.cases) + 1)
+	m.cases = append(m.cases, screeningCase)
+	return screeningCase
+}
+
+func (m *mockScreeningCache) Get(id types.ScreeningCaseID) (*models.ScreeningCase, error) {
+	if id < 1 || int(id) > len(m.cases) {
+		return nil, errs.New(errs.ScreeningCaseNotFound)
+	}
+	return m.cases[id-1], nil
+}
+
+func (m *mockScreeningCache) Save(screeningCase *models.ScreeningCase) {}
+
+func (m *mockScreeningCache) GetByStatus(status types.ScreeningStatus) []*models.ScreeningCase {
+	var cases []*models.ScreeningCase
+	for _, c := range m.cases {
+		if status == "" || c.Status == status {
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
+func (m *mockScreeningCache) AddDecision(decision *models.ScreeningDecision) {
+	m.decisions = append(m.decisions, decision)
+}
+
+func (m *mockScreeningCache) GetDecisions() []*models.ScreeningDecision {
+	return m.decisions
+}
+
+func (m *mockScreeningCache) LastDecision(accountNumber types.AccountNumber, entityID string) *models.ScreeningDecision {
+	for i := len(m.decisions) - 1; i >= 0; i-- {
+		if m.decisions[i].AccountNumber == accountNumber && m.decisions[i].EntityID == entityID {
+			return m.decisions[i]
+		}
+	}
+	return nil
+}
+
+func TestScreeningService_Match(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	entities := []*models.SanctionedEntity{
+		{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+		{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+	}
+	payments := &[]*models.Payment{}
+	screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+	screeningService.UsePayments(&mockPaymentMaker{
+		NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+			*payments = append(*payments, payment)
+			return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+		},
+	})
+	screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	hits := screeningService.Match("Ahmet Berke", 0.9)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "101", hits[0].EntityID)
+	assert.Equal(t, float64(1), hits[0].Score)
+
+	hits = screeningService.Match("Ahmed Berke", 0.9)
+	assert.Equal(t, 1, len(hits))
+	assert.True(t, hits[0].Score < 1)
+
+	hits = screeningService.Match("Acme Shipping Ltd", 0.9)
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "202", hits[0].EntityID)
+
+	assert.Equal(t, 0, len(screeningService.Match("Robert Griesemer", 0.85)))
+	assert.InDelta(t, 0.961, jaroWinkler("MARTHA", "MARHTA"), 0.001)
+}
+
+func TestScreeningService_ScreenAccount(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	entities := []*models.SanctionedEntity{
+		{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+		{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+	}
+	payments := &[]*models.Payment{}
+	screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+	screeningService.UsePayments(&mockPaymentMaker{
+		NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+			*payments = append(*payments, payment)
+			return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+		},
+	})
+	screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	cases := screeningService.ScreenAccount(accounts[1], types.AccountOpening)
+	assert.Equal(t, 1, len(cases))
+	assert.Equal(t, types.ScreeningPending, cases[0].Status)
+	assert.Equal(t, types.AccountOpening, cases[0].Trigger)
+
+	// A hit waiting for review is not queued twice
+	cases = screeningService.ScreenAccount(accounts[1], types.AccountRenamed)
+	assert.Equal(t, 0, len(cases))
+
+	cases = screeningService.ScreenAccount(accounts[2], types.AccountOpening)
+	assert.Equal(t, 0, len(cases))
+}
+
+func TestScreeningService_ScreenPayment(t *testing.T) {
+	payment := &models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromInt(100)}
+
+	t.Run("HeldForReview", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+		err := screeningService.ScreenPayment(payment, accounts[1], accounts[2])
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.Equal(t, types.ScreeningCaseID(1), err.(*errs.Error).Extensions["caseId"])
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(100)))
+
+		screeningCase, err := screeningService.GetCase(1)
+		assert.NoError(t, err)
+		assert.Equal(t, types.PaymentScreen, screeningCase.Trigger)
+		assert.True(t, screeningCase.Payment.Amount.Equal(decimal.NewFromInt(100)))
+	})
+	t.Run("PendingCase", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		screeningService.ScreenAccount(accounts[1], types.AccountOpening)
+
+		// The hit already waits in the case of the account opening, so the payment waits for that case
+		err := screeningService.ScreenPayment(payment, accounts[1], accounts[2])
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.Equal(t, types.ScreeningCaseID(1), err.(*errs.Error).Extensions["caseId"])
+		assert.True(t, accounts[1].Held.IsZero())
+		err = screeningService.ScreenParties(accounts[1], accounts[2])
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.Equal(t, 1, len(screeningService.screeningCache.GetByStatus("")))
+	})
+	t.Run("NoHit", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		accounts[1].OwnerName = "Robert Griesemer"
+
+		err := screeningService.ScreenPayment(payment, accounts[1], accounts[2])
+		assert.NoError(t, err)
+		assert.True(t, accounts[1].Held.IsZero())
+	})
+	t.Run("InsufficientBalance", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+		err := screeningService.ScreenPayment(&models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromInt(600)}, accounts[1], accounts[2])
+		assert.True(t, errs.Is(err, errs.InsufficientBalance))
+		assert.Equal(t, 0, len(screeningService.screeningCache.GetByStatus("")))
+	})
+}
+
+func TestScreeningService_Decide(t *testing.T) {
+	payment := &models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromInt(100)}
+
+	t.Run("Cleared", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		_ = screeningService.ScreenPayment(payment, accounts[1], accounts[2])
+
+		screeningCase, err := screeningService.Decide(1, &models.ScreeningDecision{Status: types.ScreeningCleared, Reviewer: "compliance", Note: "different birth date"})
+		assert.NoError(t, err)
+		assert.Equal(t, types.ScreeningCleared, screeningCase.Status)
+		assert.Equal(t, types.TransactionID(1), screeningCase.PaymentID)
+		assert.Equal(t, 1, len(*payments))
+		assert.True(t, accounts[1].Held.IsZero())
+
+		decisions := screeningService.Decisions()
+		assert.Equal(t, 1, len(decisions))
+		assert.Equal(t, "101", decisions[0].EntityID)
+		assert.Equal(t, "compliance", decisions[0].Reviewer)
+
+		// A cleared hit is not held again
+		assert.NoError(t, screeningService.ScreenPayment(payment, accounts[1], accounts[2]))
+	})
+	t.Run("Confirmed", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		_ = screeningService.ScreenPayment(payment, accounts[1], accounts[2])
+
+		screeningCase, err := screeningService.Decide(1, &models.ScreeningDecision{Status: types.ScreeningConfirmed, Reviewer: "compliance"})
+		assert.NoError(t, err)
+		assert.Equal(t, types.ScreeningConfirmed, screeningCase.Status)
+		assert.Equal(t, 0, len(*payments))
+		assert.True(t, accounts[1].Held.IsZero())
+
+		err = screeningService.ScreenPayment(payment, accounts[2], accounts[1])
+		assert.True(t, errs.Is(err, errs.SanctionsMatch))
+		err = screeningService.ScreenParties(accounts[1])
+		assert.True(t, errs.Is(err, errs.SanctionsMatch))
+	})
+	t.Run("AlreadyDecided", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		_ = screeningService.ScreenPayment(payment, accounts[1], accounts[2])
+		_, _ = screeningService.Decide(1, &models.ScreeningDecision{Status: types.ScreeningCleared, Reviewer: "compliance"})
+
+		_, err := screeningService.Decide(1, &models.ScreeningDecision{Status: types.ScreeningConfirmed, Reviewer: "compliance"})
+		assert.True(t, errs.Is(err, errs.ScreeningCaseDecided))
+	})
+	t.Run("WithoutReviewer", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		screeningService.ScreenAccount(accounts[1], types.AccountOpening)
+
+		_, err := screeningService.Decide(1, &models.ScreeningDecision{Status: types.ScreeningCleared})
+		assert.True(t, errs.Is(err, errs.InvalidArgument))
+	})
+}
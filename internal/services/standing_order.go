@@ -0,0 +1,370 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"sync"
+	"time"
+)
+
+type StandingOrderService struct {
+	standingOrderCache standingOrderCache
+	accountCache       accountCache
+	payments           paymentMaker
+	policy             policyEvaluator
+	retry              RetryPolicy
+	now                func() time.Time
+
+	// mu serializes the runs and changes of standing orders so that an order cannot be paid twice
+	mu sync.Mutex
+}
+
+// RetryPolicy decides how a run failing for insufficient balance is retried,
+// it is retried MaxRetries times, Delay apart, before the run is given up
+type RetryPolicy struct {
+	MaxRetries int
+	Delay      time.Duration
+}
+
+type standingOrderCache interface {
+	Create(order *models.StandingOrder) *models.StandingOrder
+	Get(id types.StandingOrderID) (*models.StandingOrder, error)
+	GetAll(accountNumber types.AccountNumber) []*models.StandingOrder
+	GetActive() []*models.StandingOrder
+	Update(order *models.StandingOrder) error
+}
+
+type paymentMaker interface {
+	NewPayment(payment *models.Payment) (*models.Transaction, error)
+}
+
+func NewStandingOrderService(sc standingOrderCache, ac accountCache, payments paymentMaker, policy policyEvaluator, retry RetryPolicy) *StandingOrderService {
+	return &StandingOrderService{
+		standingOrderCache: sc,
+		accountCache:       ac,
+		payments:           payments,
+		policy:             policy,
+		retry:              retry,
+		now:                time.Now,
+	}
+}
+
+// Create schedules the standing order, a zero StartAt runs it with the next run of the scheduler
+func (ss *StandingOrderService) Create(order *models.StandingOrder) (*models.StandingOrder, error) {
+	now := ss.now()
+	if order.StartAt.IsZero() {
+		order.StartAt = now
+	}
+	if order.StartAt.Before(now) {
+		return nil, errs.Newf(errs.InvalidSchedule, "startAt cannot be in the past")
+	}
+
+	setScheduleDefaults(order)
+	err := ss.validate(order)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = types.StandingOrderActive
+	order.Occurrence = 0
+	order.Attempts = 0
+	order.ScheduledAt = time.Time{}
+	order.Executions = nil
+	order.CreatedAt = now
+	ss.schedule(order, now)
+	return ss.standingOrderCache.Create(order), nil
+}
+
+// Update replaces the payment and the schedule of an active standing order.
+// A zero StartAt keeps the current one, the order continues with its first run that is not in the past.
+func (ss *StandingOrderService) Update(id types.StandingOrderID, changes *models.StandingOrder) (*models.StandingOrder, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	order, err := ss.activeOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := ss.now()
+	if changes.StartAt.IsZero() {
+		changes.StartAt = order.StartAt
+	} else if !changes.StartAt.Equal(order.StartAt) && changes.StartAt.Before(now) {
+		return nil, errs.Newf(errs.InvalidSchedule, "startAt cannot be in the past")
+	}
+
+	setScheduleDefaults(changes)
+	err = ss.validate(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Payment = changes.Payment
+	order.Frequency = changes.Frequency
+	order.Interval = changes.Interval
+	order.Cron = changes.Cron
+	order.StartAt = changes.StartAt
+	order.EndAt = changes.EndAt
+	order.Occurrence = 0
+	order.ScheduledAt = time.Time{}
+	order.Attempts = 0
+	ss.schedule(order, now)
+	err = ss.standingOrderCache.Update(order)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// Cancel stops an active standing order, its executions are kept
+func (ss *StandingOrderService) Cancel(id types.StandingOrderID) (*models.StandingOrder, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	order, err := ss.activeOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = types.StandingOrderCancelled
+	err = ss.standingOrderCache.Update(order)
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (ss *StandingOrderService) Get(id types.StandingOrderID) (*models.StandingOrder, error) {
+	return ss.standingOrderCache.Get(id)
+}
+
+// FindByAccountNumber returns every standing order paying from the account
+func (ss *StandingOrderService) FindByAccountNumber(accountNumber types.AccountNumber) ([]*models.StandingOrder, error) {
+	_, err := ss.accountCache.Get(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	return ss.standingOrderCache.GetAll(accountNumber), nil
+}
+
+// RunDue runs every active standing order whose next run is due and returns how many were run
+func (ss *StandingOrderService) RunDue() int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ran := 0
+	now := ss.now()
+	for _, order := range ss.standingOrderCache.GetActive() {
+		if now.Before(order.NextRunAt) {
+			continue
+		}
+		ss.execute(order, now)
+		_ = ss.standingOrderCache.Update(order)
+		ran++
+	}
+	return ran
+}
+
+// execute pays the current run of the order and records its outcome,
+// runs failing for insufficient balance are retried as long as the retry policy allows
+func (ss *StandingOrderService) execute(order *models.StandingOrder, now time.Time) {
+	execution := &models.Execution{
+		ScheduledAt: order.ScheduledAt,
+		ExecutedAt:  now,
+		Attempt:     order.Attempts + 1,
+	}
+	order.Executions = append(order.Executions, execution)
+
+	// The payment is copied so that the instruction is not changed by the payment
+	payment := *order.Payment
+	transaction, err := ss.payments.NewPayment(&payment)
+	if err == nil {
+		execution.Outcome = types.ExecutionSucceeded
+		execution.TransactionID = transaction.ID
+		ss.advance(order, now, true)
+		return
+	}
+
+	code := errs.CodeOf(err)
+	if code == "" {
+		code = errs.Internal
+	}
+	execution.Error = string(code)
+
+	// A payment held for review is made by the review once it is approved, so the run is neither retried nor failed
+	if code == errs.PaymentUnderReview || code == errs.TransactionUnderReview {
+		execution.Outcome = types.ExecutionPending
+		ss.advance(order, now, true)
+		return
+	}
+
+	if code == errs.InsufficientBalance && execution.Attempt <= ss.retry.MaxRetries {
+		retryAt := now.Add(ss.retry.Delay)
+		// A retry is never run together with or after the next run
+		if order.Frequency == types.Once || retryAt.Before(followingRun(order)) {
+			execution.Outcome = types.ExecutionRetrying
+			order.Attempts = execution.Attempt
+			order.NextRunAt = retryAt
+			return
+		}
+	}
+
+	execution.Outcome = types.ExecutionFailed
+	ss.advance(order, now, false)
+}
+
+// advance moves the order past its current run
+func (ss *StandingOrderService) advance(order *models.StandingOrder, now time.Time, succeeded bool) {
+	order.Attempts = 0
+	if order.Frequency == types.Once {
+		order.Status = types.StandingOrderFailed
+		if succeeded {
+			order.Status = types.StandingOrderCompleted
+		}
+		return
+	}
+	order.Occurrence++
+	ss.schedule(order, now)
+}
+
+// schedule sets the next run of the order to its first run from its occurrence on that is not in the past,
+// runs missed while the scheduler was not running are skipped rather than paid at once
+func (ss *StandingOrderService) schedule(order *models.StandingOrder, now time.Time) {
+	var next time.Time
+	if order.Frequency == types.Cron {
+		from := order.StartAt
+		if !order.ScheduledAt.IsZero() && order.ScheduledAt.Add(time.Minute).After(from) {
+			from = order.ScheduledAt.Add(time.Minute)
+		}
+		if now.After(from) {
+			from = now
+		}
+		next = cronRunAt(order, from)
+	} else {
+		next = runAt(order, order.Occurrence)
+		for order.Frequency != types.Once && next.Before(now) {
+			order.Occurrence++
+			next = runAt(order, order.Occurrence)
+		}
+	}
+
+	if next.IsZero() || !order.EndAt.IsZero() && next.After(order.EndAt) {
+		order.Status = types.StandingOrderCompleted
+		return
+	}
+	order.ScheduledAt = next
+	order.NextRunAt = next
+}
+
+// followingRun returns the time of the run after the current one
+func followingRun(order *models.StandingOrder) time.Time {
+	if order.Frequency == types.Cron {
+		return cronRunAt(order, order.ScheduledAt.Add(time.Minute))
+	}
+	return runAt(order, order.Occurrence+1)
+}
+
+// runAt returns the time of the nth run of an order repeating by interval. Monthly runs keep the day of StartAt
+// and fall on the last day of shorter months.
+func runAt(order *models.StandingOrder, n int) time.Time {
+	switch order.Frequency {
+	case types.Daily:
+		return order.StartAt.AddDate(0, 0, n*order.Interval)
+	case types.Weekly:
+		return order.StartAt.AddDate(0, 0, 7*n*order.Interval)
+	case types.Monthly:
+		start := order.StartAt
+		firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(n*order.Interval), 1,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		day := start.Day()
+		if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+			day = lastDay
+		}
+		return firstOfMonth.AddDate(0, 0, day-1)
+	}
+	return order.StartAt
+}
+
+// cronRunAt returns the first run of a cron order at or after from, in the location of StartAt,
+// or the zero time if it has none
+func cronRunAt(order *models.StandingOrder, from time.Time) time.Time {
+	schedule, err := parseCron(order.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.next(from.In(order.StartAt.Location()))
+}
+
+// setScheduleDefaults repeats an order by interval every single day, week or month unless it has an interval
+func setScheduleDefaults(order *models.StandingOrder) {
+	if order.Frequency != types.Cron && order.Interval == 0 {
+		order.Interval = 1
+	}
+}
+
+func (ss *StandingOrderService) validate(order *models.StandingOrder) error {
+	if order.Payment == nil {
+		return errs.Newf(errs.InvalidArgument, "payment is required")
+	}
+	if !order.Payment.Amount.IsPositive() {
+		return errs.New(errs.InvalidAmount)
+	}
+
+	if !order.EndAt.IsZero() && order.EndAt.Before(order.StartAt) {
+		return errs.Newf(errs.InvalidSchedule, "endAt cannot be before startAt")
+	}
+	switch order.Frequency {
+	case types.Once, types.Daily, types.Weekly, types.Monthly:
+		if order.Cron != "" {
+			return errs.Newf(errs.InvalidSchedule, "cron can only be given with the cron frequency")
+		}
+		if order.Interval < 1 {
+			return errs.Newf(errs.InvalidSchedule, "interval must be at least 1")
+		}
+	case types.Cron:
+		if order.Interval != 0 {
+			return errs.Newf(errs.InvalidSchedule, "interval cannot be given with the cron frequency")
+		}
+		schedule, err := parseCron(order.Cron)
+		if err != nil {
+			return err
+		}
+		first := schedule.next(order.StartAt)
+		if first.IsZero() || !order.EndAt.IsZero() && first.After(order.EndAt) {
+			return errs.Newf(errs.InvalidSchedule, "cron does not run between startAt and endAt")
+		}
+	default:
+		return errs.Newf(errs.InvalidSchedule, "frequency must be one of once, daily, weekly, monthly or cron")
+	}
+
+	sender, err := ss.accountCache.Get(order.Payment.SenderAccount)
+	if err != nil {
+		return err
+	}
+	receiver, err := ss.accountCache.Get(order.Payment.ReceiverAccount)
+	if err != nil {
+		return err
+	}
+
+	// The order has to be payable when it is scheduled, balance and limits are checked with every run
+	err = ss.policy.Evaluate(types.Payment, sender, receiver)
+	if err != nil {
+		return err
+	}
+	if sender.CurrencyCode != receiver.CurrencyCode {
+		return errs.New(errs.CurrencyMismatch)
+	}
+	return nil
+}
+
+func (ss *StandingOrderService) activeOrder(id types.StandingOrderID) (*models.StandingOrder, error) {
+	order, err := ss.standingOrderCache.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != types.StandingOrderActive {
+		return nil, errs.Newf(errs.StandingOrderNotActive, "standing order is %s", order.Status)
+	}
+	return order, nil
+}
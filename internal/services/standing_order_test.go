@@ -0,0 +1,495 @@
+package services
+
+import (
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"github.com/ahmetberke/tringle-candidate-project/internal/models"
+	"github.com/ahmetberke/tringle-candidate-project/internal/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type mockStandingOrderCache struct {
+	orders []*models.StandingOrder
+}
+
+func (m *mockStandingOrderCache) Create(order *models.StandingOrder) *models.StandingOrder {
+	order.ID = types.StandingOrderID(len(m.orders) + 1)
+	m.orders = append(m.orders, order)
+	return order
+}
+
+func (m *mockStandingOrderCache) Get(id types.StandingOrderID) (*models.StandingOrder, error) {
+	if id < 1 || int(id) > len(m.orders) {
+		return nil, errs.New(errs.StandingOrderNotFound)
+	}
+	return m.orders[id-1], nil
+}
+
+func (m *mockStandingOrderCache) GetAll(accountNumber types.AccountNumber) []*models.StandingOrder {
+	var orders []*models.StandingOrder
+	for _, o := range m.orders {
+		if o.Payment.SenderAccount == accountNumber {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+func (m *mockStandingOrderCache) GetActive() []*models.StandingOrder {
+	var orders []*models.StandingOrder
+	for _, o := range m.orders {
+		if o.Status == types.StandingOrderActive {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
+
+func (m *mockStandingOrderCache) Update(order *models.StandingOrder) error {
+	if order.ID < 1 || int(order.ID) > len(m.orders) {
+		return errs.New(errs.StandingOrderNotFound)
+	}
+	m.orders[order.ID-1] = order
+	return nil
+}
+
+type mockPaymentMaker struct {
+	NewPaymentMock      func(payment *models.Payment) (*models.Transaction, error)
+	NewSplitPaymentMock func(split *models.SplitPayment) (*models.SplitPaymentResult, error)
+}
+
+func (m *mockPaymentMaker) NewPayment(payment *models.Payment) (*models.Transaction, error) {
+	return m.NewPaymentMock(payment)
+}
+
+func (m *mockPaymentMaker) NewSplitPayment(split *models.SplitPayment) (*models.SplitPaymentResult, error) {
+	return m.NewSplitPaymentMock(split)
+}
+
+func newTestStandingOrder(frequency types.Frequency) *models.StandingOrder {
+	return &models.StandingOrder{
+		Payment:   &models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromFloat(100)},
+		Frequency: frequency,
+	}
+}
+
+func TestStandingOrderService_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order, err := standingOrderService.Create(newTestStandingOrder(types.Monthly))
+		assert.NoError(t, err)
+		assert.Equal(t, types.StandingOrderActive, order.Status)
+		assert.Equal(t, 1, order.Interval)
+		assert.Equal(t, now, order.NextRunAt)
+	})
+	t.Run("InvalidFrequency", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		_, err := standingOrderService.Create(newTestStandingOrder("hourly"))
+		assert.True(t, errs.Is(err, errs.InvalidSchedule))
+	})
+	t.Run("Cron", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order := newTestStandingOrder(types.Cron)
+		order.Cron = "30 8 * * 1-5"
+		order, err := standingOrderService.Create(order)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, order.Interval)
+		// Monday January 31st has passed 08:30
+		assert.Equal(t, time.Date(2022, 2, 1, 8, 30, 0, 0, time.UTC), order.NextRunAt)
+	})
+	t.Run("InvalidCron", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		for _, cron := range []string{"", "61 * * * *", "0 0 30 2 *"} {
+			order := newTestStandingOrder(types.Cron)
+			order.Cron = cron
+			_, err := standingOrderService.Create(order)
+			assert.True(t, errs.Is(err, errs.InvalidSchedule), cron)
+		}
+
+		order := newTestStandingOrder(types.Daily)
+		order.Cron = "0 0 * * *"
+		_, err := standingOrderService.Create(order)
+		assert.True(t, errs.Is(err, errs.InvalidSchedule))
+	})
+	t.Run("StartInThePast", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order := newTestStandingOrder(types.Once)
+		order.StartAt = now.Add(-time.Hour)
+		_, err := standingOrderService.Create(order)
+		assert.True(t, errs.Is(err, errs.InvalidSchedule))
+	})
+	t.Run("CorporateSender", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order := newTestStandingOrder(types.Once)
+		order.Payment.SenderAccount, order.Payment.ReceiverAccount = 2, 1
+		_, err := standingOrderService.Create(order)
+		assert.True(t, errs.Is(err, errs.AccountTypeNotAllowed))
+	})
+}
+
+func TestStandingOrderService_RunDue(t *testing.T) {
+	t.Run("Monthly", func(t *testing.T) {
+		paid := 0
+		payments := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				paid++
+				return &models.Transaction{ID: types.TransactionID(paid)}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, payments,
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order, err := standingOrderService.Create(newTestStandingOrder(types.Monthly))
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, standingOrderService.RunDue())
+		assert.Equal(t, types.ExecutionSucceeded, order.Executions[0].Outcome)
+		assert.Equal(t, types.TransactionID(1), order.Executions[0].TransactionID)
+		// January 31st is followed by the last day of February
+		assert.Equal(t, time.Date(2022, 2, 28, 9, 0, 0, 0, time.UTC), order.NextRunAt)
+
+		assert.Equal(t, 0, standingOrderService.RunDue())
+
+		now = time.Date(2022, 2, 28, 9, 0, 0, 0, time.UTC)
+		assert.Equal(t, 1, standingOrderService.RunDue())
+		assert.Equal(t, time.Date(2022, 3, 31, 9, 0, 0, 0, time.UTC), order.NextRunAt)
+		assert.Equal(t, 2, paid)
+	})
+	t.Run("Cron", func(t *testing.T) {
+		paid := 0
+		payments := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				paid++
+				return &models.Transaction{ID: types.TransactionID(paid)}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, payments,
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order := newTestStandingOrder(types.Cron)
+		order.Cron = "30 8 * * 1-5"
+		order, err := standingOrderService.Create(order)
+		assert.NoError(t, err)
+
+		now = time.Date(2022, 2, 1, 8, 30, 0, 0, time.UTC)
+		assert.Equal(t, 1, standingOrderService.RunDue())
+		assert.Equal(t, time.Date(2022, 2, 1, 8, 30, 0, 0, time.UTC), order.Executions[0].ScheduledAt)
+		assert.Equal(t, time.Date(2022, 2, 2, 8, 30, 0, 0, time.UTC), order.NextRunAt)
+
+		// The runs missed until Friday evening are skipped and the weekend is not run
+		now = time.Date(2022, 2, 4, 20, 0, 0, 0, time.UTC)
+		assert.Equal(t, 1, standingOrderService.RunDue())
+		assert.Equal(t, time.Date(2022, 2, 7, 8, 30, 0, 0, time.UTC), order.NextRunAt)
+		assert.Equal(t, 2, paid)
+	})
+	t.Run("RetriesInsufficientBalance", func(t *testing.T) {
+		payments := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				return nil, errs.New(errs.InsufficientBalance)
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, payments,
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order, err := standingOrderService.Create(newTestStandingOrder(types.Daily))
+		assert.NoError(t, err)
+
+		standingOrderService.RunDue()
+		assert.Equal(t, types.ExecutionRetrying, order.Executions[0].Outcome)
+		assert.Equal(t, string(errs.InsufficientBalance), order.Executions[0].Error)
+		assert.Equal(t, now.Add(time.Hour), order.NextRunAt)
+
+		now = now.Add(time.Hour)
+		standingOrderService.RunDue()
+		now = now.Add(time.Hour)
+		standingOrderService.RunDue()
+
+		assert.Equal(t, 3, len(order.Executions))
+		assert.Equal(t, types.ExecutionFailed, order.Executions[2].Outcome)
+		assert.Equal(t, 3, order.Executions[2].Attempt)
+		assert.Equal(t, types.StandingOrderActive, order.Status)
+		assert.Equal(t, time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC), order.NextRunAt)
+	})
+	t.Run("OnceFails", func(t *testing.T) {
+		payments := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				return nil, errs.New(errs.LimitExceeded)
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, payments,
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order, err := standingOrderService.Create(newTestStandingOrder(types.Once))
+		assert.NoError(t, err)
+
+		standingOrderService.RunDue()
+		assert.Equal(t, types.ExecutionFailed, order.Executions[0].Outcome)
+		assert.Equal(t, types.StandingOrderFailed, order.Status)
+	})
+	t.Run("PendingReview", func(t *testing.T) {
+		payments := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				return nil, errs.New(errs.PaymentUnderReview)
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, payments,
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order, err := standingOrderService.Create(newTestStandingOrder(types.Monthly))
+		assert.NoError(t, err)
+
+		// The review makes the payment, the order goes on to its next run without a retry
+		standingOrderService.RunDue()
+		assert.Equal(t, types.ExecutionPending, order.Executions[0].Outcome)
+		assert.Equal(t, string(errs.PaymentUnderReview), order.Executions[0].Error)
+		assert.Equal(t, 0, order.Attempts)
+		assert.Equal(t, time.Date(2022, 2, 28, 9, 0, 0, 0, time.UTC), order.NextRunAt)
+		assert.Equal(t, types.StandingOrderActive, order.Status)
+	})
+	t.Run("CompletesAtEnd", func(t *testing.T) {
+		payments := &mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				return &models.Transaction{ID: 1}, nil
+			},
+		}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+		}
+		standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, payments,
+			NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+		now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+		standingOrderService.now = func() time.Time { return now }
+
+		order := newTestStandingOrder(types.Weekly)
+		order.EndAt = now.AddDate(0, 0, 10)
+		order, err := standingOrderService.Create(order)
+		assert.NoError(t, err)
+
+		standingOrderService.RunDue()
+		now = now.AddDate(0, 0, 7)
+		standingOrderService.RunDue()
+
+		assert.Equal(t, 2, len(order.Executions))
+		assert.Equal(t, types.StandingOrderCompleted, order.Status)
+	})
+}
+
+func TestStandingOrderService_Cancel(t *testing.T) {
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+	}
+	standingOrderService := NewStandingOrderService(&mockStandingOrderCache{}, mockAccountCach, &mockPaymentMaker{},
+		NewPolicyService(DefaultPolicyRules()), RetryPolicy{MaxRetries: 2, Delay: time.Hour})
+	now := time.Date(2022, 1, 31, 9, 0, 0, 0, time.UTC)
+	standingOrderService.now = func() time.Time { return now }
+
+	order, err := standingOrderService.Create(newTestStandingOrder(types.Daily))
+	assert.NoError(t, err)
+
+	order, err = standingOrderService.Cancel(order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.StandingOrderCancelled, order.Status)
+	assert.Equal(t, 0, standingOrderService.RunDue())
+
+	_, err = standingOrderService.Update(order.ID, newTestStandingOrder(types.Weekly))
+	assert.True(t, errs.Is(err, errs.StandingOrderNotActive))
+}
@@ -1,7 +1,7 @@
 package services
 
 import (
-	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
@@ -10,26 +10,220 @@ import (
 type TransactionService struct {
 	accountCache     accountCache
 	transactionCache transactionCache
+	policy           policyEvaluator
+	fraud            fraudScorer
+	paymentControls
+}
+
+// paymentControls are the limits, the sanctions screening and the fees that funds moved by any service go through,
+// every control is skipped when it is not configured
+type paymentControls struct {
+	limits    limitChecker
+	screening paymentScreener
+	fees      feeCalculator
+}
+
+// TransactionServiceOption configures the optional collaborators of the TransactionService
+type TransactionServiceOption func(ts *TransactionService)
+
+type limitChecker interface {
+	Check(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) error
+	Lock(accountNumber types.AccountNumber) func()
+}
+
+type policyEvaluator interface {
+	Evaluate(transactionType types.TransactionType, sender *models.Account, receiver *models.Account) error
+}
+
+// WithPolicy replaces the default transfer policy
+func WithPolicy(p policyEvaluator) TransactionServiceOption {
+	return func(ts *TransactionService) {
+		ts.policy = p
+	}
+}
+
+type feeCalculator interface {
+	Charges(transactionType types.TransactionType, sender *models.Account, receiver *models.Account, amount decimal.Decimal) ([]*models.FeeCharge, error)
+}
+
+// WithFees makes the service charge the configured fees with every transaction
+func WithFees(f feeCalculator) TransactionServiceOption {
+	return func(ts *TransactionService) {
+		ts.fees = f
+	}
+}
+
+// WithLimits makes the service consult the limits before every transaction
+func WithLimits(l limitChecker) TransactionServiceOption {
+	return func(ts *TransactionService) {
+		ts.limits = l
+	}
+}
+
+type paymentScreener interface {
+	ScreenPayment(payment *models.Payment, sender *models.Account, receiver *models.Account) error
+	ScreenSplitPayment(split *models.SplitPayment, total decimal.Decimal, sender *models.Account, receivers ...*models.Account) error
+	ScreenParties(accounts ...*models.Account) error
+	RejectConfirmed(accounts ...*models.Account) error
+}
+
+// WithPaymentScreening makes the service screen both sides of every payment against the sanctions list
+func WithPaymentScreening(s paymentScreener) TransactionServiceOption {
+	return func(ts *TransactionService) {
+		ts.screening = s
+	}
+}
+
+type fraudScorer interface {
+	ScorePayment(payment *models.Payment, sender *models.Account) error
+	ScoreSplitPayment(split *models.SplitPayment, total decimal.Decimal, sender *models.Account) error
+	ScoreWithdraw(withdraw *models.Withdraw, account *models.Account) error
+}
+
+// WithFraudScoring makes the service evaluate the fraud rules before every payment and withdrawal
+func WithFraudScoring(f fraudScorer) TransactionServiceOption {
+	return func(ts *TransactionService) {
+		ts.fraud = f
+	}
 }
 
 type transactionCache interface {
 	Create(transactionHistory *models.Transaction) *models.Transaction
 	AddAccount(accountNumber types.AccountNumber) error
 	GetAll(accountNumber types.AccountNumber) ([]*models.Transaction, error)
+	Search(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error)
 }
 
 func NewTransactionService(ac accountCache,
-	tc transactionCache) *TransactionService {
-	return &TransactionService{
+	tc transactionCache, options ...TransactionServiceOption) *TransactionService {
+	ts := &TransactionService{
 		accountCache:     ac,
 		transactionCache: tc,
+		policy:           NewPolicyService(DefaultPolicyRules()),
+	}
+	for _, option := range options {
+		option(ts)
+	}
+	return ts
+}
+
+// lockLimits locks the limits of the account until the returned function is called,
+// nothing is locked when no limits are configured
+func (pc *paymentControls) lockLimits(accountNumber types.AccountNumber) func() {
+	if pc.limits == nil {
+		return func() {}
+	}
+	return pc.limits.Lock(accountNumber)
+}
+
+// checkLimit returns nil when no limits are configured
+func (pc *paymentControls) checkLimit(account *models.Account, transactionType types.TransactionType, amount decimal.Decimal) error {
+	if pc.limits == nil {
+		return nil
+	}
+	return pc.limits.Check(account, transactionType, amount)
+}
+
+// spentAs returns the type a history line counts as against the limits and the fraud rules,
+// escrow fundings are payments kept in escrow until they are released
+func spentAs(t *models.Transaction) types.TransactionType {
+	if t.TransactionType == types.Escrow {
+		return types.Payment
+	}
+	return t.TransactionType
+}
+
+// screen returns nil when no screening is configured. Payments paid from a hold cannot be held again,
+// so only their parties are screened.
+func (ts *TransactionService) screen(payment *models.Payment, held decimal.Decimal, sender *models.Account, receiver *models.Account) error {
+	if ts.screening == nil {
+		return nil
+	}
+	if held.IsPositive() {
+		return ts.screening.ScreenParties(sender, receiver)
+	}
+	return ts.screening.ScreenPayment(payment, sender, receiver)
+}
+
+// screenSplit returns nil when no screening is configured, like screen only the parties of a split paid from a hold are screened
+func (ts *TransactionService) screenSplit(split *models.SplitPayment, total decimal.Decimal, held decimal.Decimal, sender *models.Account, receivers []*models.Account) error {
+	if ts.screening == nil {
+		return nil
+	}
+	if held.IsPositive() {
+		return ts.screening.ScreenParties(append([]*models.Account{sender}, receivers...)...)
+	}
+	return ts.screening.ScreenSplitPayment(split, total, sender, receivers...)
+}
+
+// screenParties returns nil when no screening is configured
+func (pc *paymentControls) screenParties(accounts ...*models.Account) error {
+	if pc.screening == nil {
+		return nil
+	}
+	return pc.screening.ScreenParties(accounts...)
+}
+
+// feeCharges returns the fees of the transaction and the balance changes that collect them,
+// both are empty when no fees are configured
+func (pc *paymentControls) feeCharges(transactionType types.TransactionType, sender *models.Account, receiver *models.Account, amount decimal.Decimal) ([]*models.FeeCharge, []*models.BalanceChange, error) {
+	if pc.fees == nil {
+		return nil, nil, nil
+	}
+
+	charges, err := pc.fees.Charges(transactionType, sender, receiver, amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes []*models.BalanceChange
+	for _, charge := range charges {
+		changes = append(changes,
+			&models.BalanceChange{AccountNumber: charge.Payer, Amount: charge.Amount.Neg(), RequireFunds: true, Reason: types.FeeCharged},
+			&models.BalanceChange{AccountNumber: charge.HouseAccount, Amount: charge.Amount, Reason: types.FeeCollected},
+		)
+	}
+	return charges, changes, nil
+}
+
+// recordFees writes a fee line to the history of the payer and of the house account
+// for every charge, linked to the transaction the fee was charged for
+func (pc *paymentControls) recordFees(tc transactionCache, parent *models.Transaction, charges []*models.FeeCharge) {
+	for _, charge := range charges {
+		tc.Create(&models.Transaction{
+			ParentID:        parent.ID,
+			AccountNumber:   charge.Payer,
+			Amount:          charge.Amount,
+			TransactionType: types.Fee,
+			Direction:       types.Debit,
+		})
+		tc.Create(&models.Transaction{
+			ParentID:        parent.ID,
+			AccountNumber:   charge.HouseAccount,
+			Amount:          charge.Amount,
+			TransactionType: types.Fee,
+			Direction:       types.Credit,
+		})
 	}
 }
 
 func (ts *TransactionService) NewPayment(payment *models.Payment) (*models.Transaction, error) {
+	return ts.pay(payment, decimal.Zero)
+}
+
+// NewHeldPayment pays from funds reserved by a hold, held is released
+// from the sender in the same update that pays the receiver
+func (ts *TransactionService) NewHeldPayment(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error) {
+	return ts.pay(payment, held)
+}
 
-	if payment.Amount.LessThan(decimal.NewFromInt(0)) {
-		return nil, errors.New("amount must be greater than 0")
+func (ts *TransactionService) pay(payment *models.Payment, held decimal.Decimal) (*models.Transaction, error) {
+
+	if !payment.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if err := payment.TransactionDetails.Validate(); err != nil {
+		return nil, err
 	}
 
 	_, err := ts.transactionCache.GetAll(payment.SenderAccount)
@@ -47,42 +241,209 @@ func (ts *TransactionService) NewPayment(payment *models.Payment) (*models.Trans
 		return nil, err
 	}
 
-	if sender.AccountType != types.Individual || reiever.AccountType != types.Corporate {
-		return nil, errors.New("sender must be individual and receiver must be corporate")
+	err = ts.policy.Evaluate(types.Payment, sender, reiever)
+	if err != nil {
+		return nil, err
 	}
 
 	if sender.CurrencyCode != reiever.CurrencyCode {
-		return nil, errors.New("the currency codes of the accounts are not the same")
+		return nil, errs.New(errs.CurrencyMismatch)
+	}
+
+	// The limits of the sender stay locked until the payment is in its history, where they are counted from
+	unlock := ts.lockLimits(sender.AccountNumber)
+	defer unlock()
+
+	err = ts.checkLimit(sender, types.Payment, payment.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.screen(payment, held, sender, reiever)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payments from a hold are not scored again, holds are scored when they are placed
+	// and the other held payments were approved by a fraud review
+	if ts.fraud != nil && !held.IsPositive() {
+		err = ts.fraud.ScorePayment(payment, sender)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	charges, feeChanges, err := ts.feeCharges(types.Payment, sender, reiever, payment.Amount)
+	if err != nil {
+		return nil, err
 	}
 
-	if sender.Balance.LessThan(payment.Amount) {
-		return nil, errors.New("insufficient balance")
+	// The payment and its fees are applied together so that a failing fee cannot leave a half-done payment
+	changes := append([]*models.BalanceChange{
+		{AccountNumber: sender.AccountNumber, Amount: payment.Amount.Neg(), Held: held.Neg(), RequireFunds: true, Reason: types.PaymentSent},
+		{AccountNumber: reiever.AccountNumber, Amount: payment.Amount, Reason: types.PaymentReceived},
+	}, feeChanges...)
+	var transaction *models.Transaction
+	err = ts.accountCache.Commit(changes, func() {
+		transaction = ts.transactionCache.Create(&models.Transaction{
+			AccountNumber:       sender.AccountNumber,
+			Amount:              payment.Amount,
+			TransactionType:     types.Payment,
+			Direction:           types.Debit,
+			CounterpartyAccount: reiever.AccountNumber,
+			TransactionDetails:  payment.TransactionDetails,
+		})
+		// The receiver gets its own line so that the balance of every account can be rebuilt from its history
+		ts.transactionCache.Create(&models.Transaction{
+			ParentID:            transaction.ID,
+			AccountNumber:       reiever.AccountNumber,
+			Amount:              payment.Amount,
+			TransactionType:     types.Payment,
+			Direction:           types.Credit,
+			CounterpartyAccount: sender.AccountNumber,
+			TransactionDetails:  payment.TransactionDetails,
+		})
+		ts.recordFees(ts.transactionCache, transaction, charges)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+
+}
+
+// NewSplitPayment pays every receiver of the split from the sender in a single update,
+// either every leg is paid or none of them. The sender gets one line of the whole amount
+// and every receiver a leg linked to it.
+func (ts *TransactionService) NewSplitPayment(split *models.SplitPayment) (*models.SplitPaymentResult, error) {
+	return ts.splitPay(split, decimal.Zero)
+}
+
+// NewHeldSplitPayment pays a split from funds reserved by a fraud review, held is released
+// from the sender in the same update that pays the receivers
+func (ts *TransactionService) NewHeldSplitPayment(split *models.SplitPayment, held decimal.Decimal) (*models.SplitPaymentResult, error) {
+	return ts.splitPay(split, held)
+}
+
+func (ts *TransactionService) splitPay(split *models.SplitPayment, held decimal.Decimal) (*models.SplitPaymentResult, error) {
+	if err := split.TransactionDetails.Validate(); err != nil {
+		return nil, err
 	}
 
-	err = ts.accountCache.UpdateBalance(sender.AccountNumber, sender.Balance.Sub(payment.Amount))
+	legs, err := split.LegAmounts()
 	if err != nil {
 		return nil, err
 	}
 
-	err = ts.accountCache.UpdateBalance(reiever.AccountNumber, reiever.Balance.Add(payment.Amount))
+	_, err = ts.transactionCache.GetAll(split.SenderAccount)
+	if err != nil {
+		_ = ts.transactionCache.AddAccount(split.SenderAccount)
+	}
+
+	sender, err := ts.accountCache.Get(split.SenderAccount)
 	if err != nil {
 		return nil, err
 	}
 
-	transaction := &models.Transaction{
-		AccountNumber:   sender.AccountNumber,
-		Amount:          payment.Amount,
-		TransactionType: types.Payment,
+	total := decimal.Zero
+	receivers := make([]*models.Account, 0, len(split.Receivers))
+	seen := make(map[types.AccountNumber]bool, len(split.Receivers))
+	var charges []*models.FeeCharge
+	var feeChanges []*models.BalanceChange
+	for i, r := range split.Receivers {
+		if r.AccountNumber == sender.AccountNumber || seen[r.AccountNumber] {
+			return nil, errs.Newf(errs.InvalidArgument, "account %d cannot be paid twice or by itself", r.AccountNumber)
+		}
+		seen[r.AccountNumber] = true
+
+		receiver, err := ts.accountCache.Get(r.AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		err = ts.policy.Evaluate(types.Payment, sender, receiver)
+		if err != nil {
+			return nil, err
+		}
+
+		if sender.CurrencyCode != receiver.CurrencyCode {
+			return nil, errs.New(errs.CurrencyMismatch)
+		}
+
+		legCharges, legChanges, err := ts.feeCharges(types.Payment, sender, receiver, legs[i])
+		if err != nil {
+			return nil, err
+		}
+		charges = append(charges, legCharges...)
+		feeChanges = append(feeChanges, legChanges...)
+
+		receivers = append(receivers, receiver)
+		total = total.Add(legs[i])
 	}
 
-	return ts.transactionCache.Create(transaction), nil
+	unlock := ts.lockLimits(sender.AccountNumber)
+	defer unlock()
 
+	err = ts.checkLimit(sender, types.Payment, total)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.screenSplit(split, total, held, sender, receivers)
+	if err != nil {
+		return nil, err
+	}
+
+	if ts.fraud != nil && !held.IsPositive() {
+		err = ts.fraud.ScoreSplitPayment(split, total, sender)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changes := []*models.BalanceChange{
+		{AccountNumber: sender.AccountNumber, Amount: total.Neg(), Held: held.Neg(), RequireFunds: true, Reason: types.PaymentSent},
+	}
+	for i, receiver := range receivers {
+		changes = append(changes, &models.BalanceChange{AccountNumber: receiver.AccountNumber, Amount: legs[i], Reason: types.PaymentReceived})
+	}
+	result := &models.SplitPaymentResult{}
+	err = ts.accountCache.Commit(append(changes, feeChanges...), func() {
+		result.Payment = ts.transactionCache.Create(&models.Transaction{
+			AccountNumber:      sender.AccountNumber,
+			Amount:             total,
+			TransactionType:    types.Payment,
+			Direction:          types.Debit,
+			TransactionDetails: split.TransactionDetails,
+		})
+		for i, receiver := range receivers {
+			result.Legs = append(result.Legs, ts.transactionCache.Create(&models.Transaction{
+				ParentID:            result.Payment.ID,
+				AccountNumber:       receiver.AccountNumber,
+				Amount:              legs[i],
+				TransactionType:     types.Payment,
+				Direction:           types.Credit,
+				CounterpartyAccount: sender.AccountNumber,
+				TransactionDetails:  split.TransactionDetails,
+			}))
+		}
+		ts.recordFees(ts.transactionCache, result.Payment, charges)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (ts *TransactionService) NewDeposit(deposit *models.Deposit) (*models.Transaction, error) {
 
-	if deposit.Amount.LessThan(decimal.NewFromInt(0)) {
-		return nil, errors.New("amount must be greater than 0")
+	if !deposit.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if err := deposit.TransactionDetails.Validate(); err != nil {
+		return nil, err
 	}
 
 	_, err := ts.transactionCache.GetAll(deposit.AccountNumber)
@@ -95,29 +456,64 @@ func (ts *TransactionService) NewDeposit(deposit *models.Deposit) (*models.Trans
 		return nil, err
 	}
 
-	if account.AccountType != types.Individual {
-		return nil, errors.New("account must be individual")
+	err = ts.policy.Evaluate(types.Deposit, account, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	err = ts.accountCache.UpdateBalance(account.AccountNumber, account.Balance.Add(deposit.Amount))
+	unlock := ts.lockLimits(account.AccountNumber)
+	defer unlock()
+
+	err = ts.checkLimit(account, types.Deposit, deposit.Amount)
 	if err != nil {
 		return nil, err
 	}
 
-	transaction := &models.Transaction{
-		AccountNumber:   account.AccountNumber,
-		Amount:          deposit.Amount,
-		TransactionType: types.Deposit,
+	charges, feeChanges, err := ts.feeCharges(types.Deposit, account, nil, deposit.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := append([]*models.BalanceChange{
+		{AccountNumber: account.AccountNumber, Amount: deposit.Amount, Reason: types.Deposited},
+	}, feeChanges...)
+	var transaction *models.Transaction
+	err = ts.accountCache.Commit(changes, func() {
+		transaction = ts.transactionCache.Create(&models.Transaction{
+			AccountNumber:      account.AccountNumber,
+			Amount:             deposit.Amount,
+			TransactionType:    types.Deposit,
+			Direction:          types.Credit,
+			Counterparty:       deposit.Counterparty,
+			TransactionDetails: deposit.TransactionDetails,
+		})
+		ts.recordFees(ts.transactionCache, transaction, charges)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return ts.transactionCache.Create(transaction), nil
+	return transaction, nil
 
 }
 
 func (ts *TransactionService) NewWithdraw(withdraw *models.Withdraw) (*models.Transaction, error) {
+	return ts.withdraw(withdraw, decimal.Zero)
+}
+
+// NewHeldWithdraw withdraws funds reserved by a fraud review, held is released
+// from the account in the same update that takes the amount
+func (ts *TransactionService) NewHeldWithdraw(withdraw *models.Withdraw, held decimal.Decimal) (*models.Transaction, error) {
+	return ts.withdraw(withdraw, held)
+}
 
-	if withdraw.Amount.LessThan(decimal.NewFromInt(0)) {
-		return nil, errors.New("amount must be greater than 0")
+func (ts *TransactionService) withdraw(withdraw *models.Withdraw, held decimal.Decimal) (*models.Transaction, error) {
+
+	if !withdraw.Amount.IsPositive() {
+		return nil, errs.New(errs.InvalidAmount)
+	}
+	if err := withdraw.TransactionDetails.Validate(); err != nil {
+		return nil, err
 	}
 
 	_, err := ts.transactionCache.GetAll(withdraw.AccountNumber)
@@ -130,29 +526,64 @@ func (ts *TransactionService) NewWithdraw(withdraw *models.Withdraw) (*models.Tr
 		return nil, err
 	}
 
-	if account.AccountType != types.Individual {
-		return nil, errors.New("account must be individual")
+	err = ts.policy.Evaluate(types.Withdraw, account, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if account.Balance.LessThan(withdraw.Amount) {
-		return nil, errors.New("insufficient balance")
+	unlock := ts.lockLimits(account.AccountNumber)
+	defer unlock()
+
+	err = ts.checkLimit(account, types.Withdraw, withdraw.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// A withdrawal has no counterparty to screen, but an account confirmed as a sanctions match cannot withdraw
+	if ts.screening != nil {
+		err = ts.screening.RejectConfirmed(account)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	err = ts.accountCache.UpdateBalance(account.AccountNumber, account.Balance.Sub(withdraw.Amount))
+	if ts.fraud != nil && !held.IsPositive() {
+		err = ts.fraud.ScoreWithdraw(withdraw, account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	charges, feeChanges, err := ts.feeCharges(types.Withdraw, account, nil, withdraw.Amount)
 	if err != nil {
 		return nil, err
 	}
 
-	transaction := &models.Transaction{
-		AccountNumber:   account.AccountNumber,
-		Amount:          withdraw.Amount,
-		TransactionType: types.Withdraw,
+	changes := append([]*models.BalanceChange{
+		{AccountNumber: account.AccountNumber, Amount: withdraw.Amount.Neg(), Held: held.Neg(), RequireFunds: true, Reason: types.Withdrawn},
+	}, feeChanges...)
+	var transaction *models.Transaction
+	err = ts.accountCache.Commit(changes, func() {
+		transaction = ts.transactionCache.Create(&models.Transaction{
+			AccountNumber:      account.AccountNumber,
+			Amount:             withdraw.Amount,
+			TransactionType:    types.Withdraw,
+			Direction:          types.Debit,
+			Counterparty:       withdraw.Counterparty,
+			TransactionDetails: withdraw.TransactionDetails,
+		})
+		ts.recordFees(ts.transactionCache, transaction, charges)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return ts.transactionCache.Create(transaction), nil
+	return transaction, nil
 
 }
 
-func (ts *TransactionService) GetTransactionHistory(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
-	return ts.transactionCache.GetAll(accountNumber)
+// GetTransactionHistory returns the lines in the history of the account that match the filter,
+// a nil filter returns the whole history
+func (ts *TransactionService) GetTransactionHistory(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	return ts.transactionCache.Search(accountNumber, filter)
 }
@@ -2,17 +2,23 @@ package services
 
 import (
 	"errors"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
 	"github.com/ahmetberke/tringle-candidate-project/internal/models"
 	"github.com/ahmetberke/tringle-candidate-project/internal/types"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type mockATransactionCache struct {
 	CreateMock     func(transactionHistory *models.Transaction) *models.Transaction
 	AddAccountMock func(accountNumber types.AccountNumber) error
 	GetAllMock     func(accountNumber types.AccountNumber) ([]*models.Transaction, error)
+	SearchMock     func(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error)
 }
 
 func (m *mockATransactionCache) Create(transactionHistory *models.Transaction) *models.Transaction {
@@ -27,6 +33,10 @@ func (m *mockATransactionCache) GetAll(accountNumber types.AccountNumber) ([]*mo
 	return m.GetAllMock(accountNumber)
 }
 
+func (m *mockATransactionCache) Search(accountNumber types.AccountNumber, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	return m.SearchMock(accountNumber, filter)
+}
+
 func TestTransactionService_NewPayment(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockAccountCach := mockAccountCache{
@@ -328,6 +338,829 @@ func TestTransactionService_NewPayment(t *testing.T) {
 		assert.Error(t, err)
 
 	})
+	t.Run("PeerToPeerAllowedByPolicy", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return &models.Account{
+					AccountNumber: accountNumber,
+					CurrencyCode:  types.TRY,
+					OwnerName:     "Ahmet Berke",
+					AccountType:   types.Individual,
+					Balance:       decimal.NewFromFloat(float64(500)),
+				}, nil
+			},
+			UpdateBalanceMock: func(accountNumber types.AccountNumber, balance decimal.Decimal) error {
+				return nil
+			},
+		}
+		mockTransactionCach := mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errors.New("this account has no transaction history")
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+			CreateMock: func(transactionHistory *models.Transaction) *models.Transaction {
+				return transactionHistory
+			},
+		}
+		policyService := NewPolicyService([]*models.PolicyRule{
+			{Name: "p2p", TransactionType: types.Payment, SenderType: types.Individual, ReceiverType: types.Individual},
+		})
+		transactionService := NewTransactionService(&mockAccountCach, &mockTransactionCach, WithPolicy(policyService))
+
+		payment := &models.Payment{
+			SenderAccount:   types.AccountNumber(1),
+			ReceiverAccount: types.AccountNumber(2),
+			Amount:          decimal.NewFromFloat(50),
+		}
+
+		_, err := transactionService.NewPayment(payment)
+		assert.NoError(t, err)
+	})
+	t.Run("WithFees", func(t *testing.T) {
+		var appliedChanges []*models.BalanceChange
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				if accountNumber == 1 {
+					return &models.Account{
+						AccountNumber: accountNumber,
+						CurrencyCode:  types.TRY,
+						OwnerName:     "Ahmet Berke",
+						AccountType:   types.Individual,
+						Balance:       decimal.NewFromFloat(float64(500)),
+					}, nil
+				}
+				return &models.Account{
+					AccountNumber: accountNumber,
+					CurrencyCode:  types.TRY,
+					OwnerName:     "Apple",
+					AccountType:   types.Corporate,
+				}, nil
+			},
+			CreateMock: func(account *models.Account) (*models.Account, error) {
+				account.AccountNumber = 3
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				appliedChanges = changes
+				return nil
+			},
+		}
+		var history []*models.Transaction
+		mockTransactionCach := mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errors.New("this account has no transaction history")
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+			CreateMock: func(transactionHistory *models.Transaction) *models.Transaction {
+				transactionHistory.ID = types.TransactionID(len(history) + 1)
+				history = append(history, transactionHistory)
+				return transactionHistory
+			},
+		}
+		feeService := NewFeeService([]*models.FeeSchedule{
+			{Name: "merchant", TransactionType: types.Payment, Payer: types.ReceiverPays, Percentage: decimal.NewFromFloat(2)},
+		}, &mockAccountCach, &mockSystemAccountCache{})
+		assert.NoError(t, feeService.OpenHouseAccounts([]types.Currency{types.TRY}))
+		transactionService := NewTransactionService(&mockAccountCach, &mockTransactionCach, WithFees(feeService))
+
+		payment := &models.Payment{
+			SenderAccount:   types.AccountNumber(1),
+			ReceiverAccount: types.AccountNumber(2),
+			Amount:          decimal.NewFromFloat(50),
+			TransactionDetails: models.TransactionDetails{
+				Description: "Order 1042",
+				Reference:   "E2E-1042",
+				Metadata:    map[string]string{"orderId": "1042"},
+			},
+		}
+
+		transaction, err := transactionService.NewPayment(payment)
+		assert.NoError(t, err)
+		assert.Equal(t, types.AccountNumber(2), transaction.CounterpartyAccount)
+		assert.Equal(t, "E2E-1042", transaction.Reference)
+
+		assert.Equal(t, 4, len(appliedChanges))
+		assert.Equal(t, types.AccountNumber(2), appliedChanges[2].AccountNumber)
+		assert.True(t, appliedChanges[2].Amount.Equal(decimal.NewFromFloat(-1)))
+		assert.Equal(t, types.AccountNumber(3), appliedChanges[3].AccountNumber)
+
+		assert.Equal(t, 4, len(history))
+		assert.Equal(t, types.AccountNumber(2), history[1].AccountNumber)
+		assert.Equal(t, types.Credit, history[1].Direction)
+		assert.Equal(t, transaction.ID, history[1].ParentID)
+		assert.Equal(t, types.AccountNumber(1), history[1].CounterpartyAccount)
+		assert.Equal(t, "1042", history[1].Metadata["orderId"])
+		assert.Equal(t, types.Fee, history[2].TransactionType)
+		assert.Equal(t, transaction.ID, history[2].ParentID)
+		assert.Equal(t, types.AccountNumber(3), history[3].AccountNumber)
+		assert.Equal(t, types.Credit, history[3].Direction)
+	})
+}
+
+func TestTransactionService_NewSplitPayment(t *testing.T) {
+	t.Run("Amounts", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Migros", AccountType: types.Corporate},
+			4: {AccountNumber: 4, CurrencyCode: types.TRY, OwnerName: "Ada Lovelace", AccountType: types.Individual},
+			5: {AccountNumber: 5, CurrencyCode: types.USD, OwnerName: "Amazon", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errs.New(errs.HistoryNotFound)
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		transactionService := NewTransactionService(accountCache, transactionCache)
+
+		result, err := transactionService.NewSplitPayment(&models.SplitPayment{
+			SenderAccount: 1,
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromFloat(120)},
+				{AccountNumber: 3, Amount: decimal.NewFromFloat(30)},
+			},
+			TransactionDetails: models.TransactionDetails{Reference: "ORDER-7"},
+		})
+		assert.NoError(t, err)
+		assert.True(t, result.Payment.Amount.Equal(decimal.NewFromFloat(150)))
+		assert.Equal(t, types.Debit, result.Payment.Direction)
+		assert.Equal(t, 2, len(result.Legs))
+		for _, leg := range result.Legs {
+			assert.Equal(t, result.Payment.ID, leg.ParentID)
+			assert.Equal(t, types.Credit, leg.Direction)
+			assert.Equal(t, "ORDER-7", leg.Reference)
+		}
+		assert.Equal(t, 3, len(*history))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(350)))
+		assert.True(t, accounts[2].Balance.Equal(decimal.NewFromFloat(120)))
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromFloat(30)))
+	})
+
+	t.Run("Percentages", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Migros", AccountType: types.Corporate},
+			4: {AccountNumber: 4, CurrencyCode: types.TRY, OwnerName: "Ada Lovelace", AccountType: types.Individual},
+			5: {AccountNumber: 5, CurrencyCode: types.USD, OwnerName: "Amazon", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errs.New(errs.HistoryNotFound)
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		transactionService := NewTransactionService(accountCache, transactionCache)
+
+		result, err := transactionService.NewSplitPayment(&models.SplitPayment{
+			SenderAccount: 1,
+			Amount:        decimal.NewFromFloat(100),
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Percentage: decimal.NewFromFloat(33.33)},
+				{AccountNumber: 3, Percentage: decimal.NewFromFloat(66.67)},
+			},
+		})
+		assert.NoError(t, err)
+		assert.True(t, result.Legs[0].Amount.Equal(decimal.NewFromFloat(33.33)))
+		assert.True(t, result.Legs[1].Amount.Equal(decimal.NewFromFloat(66.67)))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(400)))
+	})
+
+	t.Run("Remainder", func(t *testing.T) {
+		legs, err := (&models.SplitPayment{
+			Amount: decimal.NewFromFloat(100),
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Percentage: decimal.NewFromFloat(50)},
+				{AccountNumber: 3, Percentage: decimal.NewFromFloat(25)},
+				{AccountNumber: 4, Percentage: decimal.NewFromFloat(25)},
+			},
+		}).LegAmounts()
+		assert.NoError(t, err)
+		assert.True(t, legs[0].Equal(decimal.NewFromFloat(50)))
+
+		legs, err = (&models.SplitPayment{
+			Amount: decimal.NewFromFloat(10),
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Percentage: decimal.NewFromFloat(33.33)},
+				{AccountNumber: 3, Percentage: decimal.NewFromFloat(33.33)},
+				{AccountNumber: 4, Percentage: decimal.NewFromFloat(33.34)},
+			},
+		}).LegAmounts()
+		assert.NoError(t, err)
+		// Every leg rounds down to 3.33, the cent left over goes to the first receiver
+		assert.True(t, legs[0].Equal(decimal.NewFromFloat(3.34)))
+		assert.True(t, legs[2].Equal(decimal.NewFromFloat(3.33)))
+		total := decimal.Zero
+		for _, leg := range legs {
+			total = total.Add(leg)
+		}
+		assert.True(t, total.Equal(decimal.NewFromFloat(10)))
+	})
+
+	t.Run("InvalidSplit", func(t *testing.T) {
+		for _, split := range []*models.SplitPayment{
+			{SenderAccount: 1, Receivers: []*models.SplitReceiver{{AccountNumber: 2, Amount: decimal.NewFromFloat(10)}}},
+			{SenderAccount: 1, Amount: decimal.NewFromFloat(10), Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Percentage: decimal.NewFromFloat(50)},
+				{AccountNumber: 3, Percentage: decimal.NewFromFloat(40)},
+			}},
+			{SenderAccount: 1, Amount: decimal.NewFromFloat(10), Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Percentage: decimal.NewFromFloat(50)},
+				{AccountNumber: 3, Amount: decimal.NewFromFloat(5)},
+			}},
+			{SenderAccount: 1, Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromFloat(5)},
+				{AccountNumber: 2, Amount: decimal.NewFromFloat(5)},
+			}},
+		} {
+			history := &[]*models.Transaction{}
+			transactionCache := &mockATransactionCache{
+				GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+					return nil, errs.New(errs.HistoryNotFound)
+				},
+				AddAccountMock: func(accountNumber types.AccountNumber) error {
+					return nil
+				},
+				CreateMock: func(transaction *models.Transaction) *models.Transaction {
+					transaction.ID = types.TransactionID(len(*history) + 1)
+					*history = append(*history, transaction)
+					return transaction
+				},
+			}
+			accounts := map[types.AccountNumber]*models.Account{
+				1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+				2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+				3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Migros", AccountType: types.Corporate},
+				4: {AccountNumber: 4, CurrencyCode: types.TRY, OwnerName: "Ada Lovelace", AccountType: types.Individual},
+				5: {AccountNumber: 5, CurrencyCode: types.USD, OwnerName: "Amazon", AccountType: types.Corporate},
+			}
+			accountCache := &mockAccountCache{
+				GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+					account, ok := accounts[accountNumber]
+					if !ok {
+						return nil, errs.New(errs.AccountNotFound)
+					}
+					return account, nil
+				},
+				ApplyChangesMock: func(changes []*models.BalanceChange) error {
+					for _, change := range changes {
+						account := accounts[change.AccountNumber]
+						if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+							return errs.New(errs.InsufficientBalance)
+						}
+						account.Balance = account.Balance.Add(change.Amount)
+						account.Held = account.Held.Add(change.Held)
+					}
+					return nil
+				},
+			}
+			transactionService := NewTransactionService(accountCache, transactionCache)
+			_, err := transactionService.NewSplitPayment(split)
+			assert.True(t, errs.Is(err, errs.InvalidArgument))
+		}
+
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+			3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Migros", AccountType: types.Corporate},
+			4: {AccountNumber: 4, CurrencyCode: types.TRY, OwnerName: "Ada Lovelace", AccountType: types.Individual},
+			5: {AccountNumber: 5, CurrencyCode: types.USD, OwnerName: "Amazon", AccountType: types.Corporate},
+		}
+		accountCache := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		history := &[]*models.Transaction{}
+		transactionCache := &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errs.New(errs.HistoryNotFound)
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				transaction.ID = types.TransactionID(len(*history) + 1)
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}
+		transactionService := NewTransactionService(accountCache, transactionCache)
+		_, err := transactionService.NewSplitPayment(&models.SplitPayment{SenderAccount: 1, Amount: decimal.NewFromFloat(20), Receivers: []*models.SplitReceiver{
+			{AccountNumber: 2, Amount: decimal.NewFromFloat(5)},
+			{AccountNumber: 3, Amount: decimal.NewFromFloat(5)},
+		}})
+		assert.True(t, errs.Is(err, errs.InvalidAmount))
+	})
+
+	t.Run("NoLegPaidOnFailure", func(t *testing.T) {
+		for _, tc := range []struct {
+			name      string
+			receivers []*models.SplitReceiver
+			code      errs.Code
+		}{
+			{"InsufficientBalance", []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromFloat(400)},
+				{AccountNumber: 3, Amount: decimal.NewFromFloat(200)},
+			}, errs.InsufficientBalance},
+			{"AccountTypeNotAllowed", []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromFloat(10)},
+				{AccountNumber: 4, Amount: decimal.NewFromFloat(10)},
+			}, errs.AccountTypeNotAllowed},
+			{"CurrencyMismatch", []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromFloat(10)},
+				{AccountNumber: 5, Amount: decimal.NewFromFloat(10)},
+			}, errs.CurrencyMismatch},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				accounts := map[types.AccountNumber]*models.Account{
+					1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+					2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+					3: {AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Migros", AccountType: types.Corporate},
+					4: {AccountNumber: 4, CurrencyCode: types.TRY, OwnerName: "Ada Lovelace", AccountType: types.Individual},
+					5: {AccountNumber: 5, CurrencyCode: types.USD, OwnerName: "Amazon", AccountType: types.Corporate},
+				}
+				accountCache := &mockAccountCache{
+					GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+						account, ok := accounts[accountNumber]
+						if !ok {
+							return nil, errs.New(errs.AccountNotFound)
+						}
+						return account, nil
+					},
+					ApplyChangesMock: func(changes []*models.BalanceChange) error {
+						for _, change := range changes {
+							account := accounts[change.AccountNumber]
+							if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+								return errs.New(errs.InsufficientBalance)
+							}
+							account.Balance = account.Balance.Add(change.Amount)
+							account.Held = account.Held.Add(change.Held)
+						}
+						return nil
+					},
+				}
+				history := &[]*models.Transaction{}
+				transactionCache := &mockATransactionCache{
+					GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+						return nil, errs.New(errs.HistoryNotFound)
+					},
+					AddAccountMock: func(accountNumber types.AccountNumber) error {
+						return nil
+					},
+					CreateMock: func(transaction *models.Transaction) *models.Transaction {
+						transaction.ID = types.TransactionID(len(*history) + 1)
+						*history = append(*history, transaction)
+						return transaction
+					},
+				}
+				transactionService := NewTransactionService(accountCache, transactionCache)
+
+				_, err := transactionService.NewSplitPayment(&models.SplitPayment{SenderAccount: 1, Receivers: tc.receivers})
+				assert.True(t, errs.Is(err, tc.code))
+				assert.Equal(t, 0, len(*history))
+				assert.True(t, accounts[1].Balance.Equal(decimal.NewFromFloat(500)))
+				assert.True(t, accounts[2].Balance.IsZero())
+			})
+		}
+	})
+}
+
+func TestTransactionService_Screening(t *testing.T) {
+	t.Run("PaymentHeldForReview", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		history := &[]*models.Transaction{}
+		transactionService := NewTransactionService(screeningService.accountCache, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				*history = append(*history, transaction)
+				return transaction
+			},
+		}, WithPaymentScreening(screeningService))
+
+		_, err := transactionService.NewPayment(&models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromInt(100)})
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(500)))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(100)))
+		assert.Equal(t, 0, len(*history))
+
+		// A retry is answered with the waiting case and holds nothing again
+		_, err = transactionService.NewPayment(&models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.NewFromInt(100)})
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.Equal(t, types.ScreeningCaseID(1), err.(*errs.Error).Extensions["caseId"])
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(100)))
+		assert.Equal(t, 1, len(screeningService.screeningCache.GetByStatus("")))
+	})
+	t.Run("SplitPaymentHeldForReview", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		splits := &[]*models.SplitPayment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewSplitPaymentMock: func(split *models.SplitPayment) (*models.SplitPaymentResult, error) {
+				*splits = append(*splits, split)
+				return &models.SplitPaymentResult{Payment: &models.Transaction{ID: types.TransactionID(len(*splits))}}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		accounts[3] = &models.Account{AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Acme Shipping", AccountType: types.Corporate}
+		accounts[1].OwnerName = "Robert Griesemer"
+		transactionService := NewTransactionService(screeningService.accountCache, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, nil
+			},
+		}, WithPaymentScreening(screeningService))
+
+		_, err := transactionService.NewSplitPayment(&models.SplitPayment{
+			SenderAccount: 1,
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromInt(10)},
+				{AccountNumber: 3, Amount: decimal.NewFromInt(10)},
+			},
+		})
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(500)))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(20)))
+
+		_, err = transactionService.NewSplitPayment(&models.SplitPayment{
+			SenderAccount: 1,
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromInt(10)},
+				{AccountNumber: 3, Amount: decimal.NewFromInt(10)},
+			},
+		})
+		assert.True(t, errs.Is(err, errs.PaymentUnderReview))
+		assert.Equal(t, 1, len(screeningService.screeningCache.GetByStatus("")))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(20)))
+
+		// Clearing the case releases the held amount and pays the whole split
+		screeningCase := screeningService.screeningCache.GetByStatus("")[0]
+		assert.True(t, screeningCase.SplitPayment.Amount.Equal(decimal.NewFromInt(20)))
+		screeningCase, err = screeningService.Decide(screeningCase.ID, &models.ScreeningDecision{Status: types.ScreeningCleared, Reviewer: "compliance"})
+		assert.NoError(t, err)
+		assert.True(t, accounts[1].Held.IsZero())
+		assert.Equal(t, 1, len(*splits))
+		assert.Equal(t, 2, len((*splits)[0].Receivers))
+		assert.Equal(t, types.TransactionID(1), screeningCase.PaymentID)
+	})
+	t.Run("WithdrawConfirmedMatch", func(t *testing.T) {
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		entities := []*models.SanctionedEntity{
+			{ID: "101", Names: []string{"BERKE, Ahmet", "Ahmed Berk"}, Type: "individual", Programs: []string{"SDGT"}},
+			{ID: "202", Names: []string{"ACME SHIPPING CO."}, Programs: []string{"IRAN"}},
+		}
+		payments := &[]*models.Payment{}
+		screeningService := NewScreeningService(&mockScreeningCache{}, mockAccountCach, entities, 0.85, 0.9)
+		screeningService.UsePayments(&mockPaymentMaker{
+			NewPaymentMock: func(payment *models.Payment) (*models.Transaction, error) {
+				*payments = append(*payments, payment)
+				return &models.Transaction{ID: types.TransactionID(len(*payments))}, nil
+			},
+		})
+		screeningService.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+		transactionService := NewTransactionService(screeningService.accountCache, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				return transaction
+			},
+		}, WithPaymentScreening(screeningService))
+
+		// An undecided hit does not stop a withdrawal, a confirmed one does
+		_, err := transactionService.NewWithdraw(&models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(100)})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(screeningService.screeningCache.GetByStatus("")))
+
+		screeningService.ScreenAccount(accounts[1], types.AccountOpening)
+		_, err = screeningService.Decide(1, &models.ScreeningDecision{Status: types.ScreeningConfirmed, Reviewer: "compliance"})
+		assert.NoError(t, err)
+
+		_, err = transactionService.NewWithdraw(&models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(100)})
+		assert.True(t, errs.Is(err, errs.SanctionsMatch))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(400)))
+	})
+}
+
+func TestTransactionService_FraudScoring(t *testing.T) {
+	rules := []*models.FraudRule{
+		{Name: "frequent withdrawals", Kind: types.VelocityRule, TransactionType: types.Withdraw, Action: types.FraudReview, Window: time.Minute, MaxCount: 1},
+		{Name: "payment burst", Kind: types.VelocityRule, TransactionType: types.Payment, Action: types.FraudReview, Window: time.Minute, MaxCount: 1},
+	}
+
+	t.Run("WithdrawHeldForReview", func(t *testing.T) {
+		history := withdrawals(1, 10, time.Second)
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		transactionService := NewTransactionService(fraudService.accountCache, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				history = append(history, transaction)
+				transaction.ID = types.TransactionID(len(history))
+				return transaction
+			},
+		}, WithFraudScoring(fraudService))
+		fraudService.UseTransactions(transactionService)
+
+		_, err := transactionService.NewWithdraw(&models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(100)})
+		assert.True(t, errs.Is(err, errs.TransactionUnderReview))
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(500)))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(100)))
+		assert.Equal(t, 1, len(history))
+
+		// The approved withdrawal is made from the held funds without being scored again
+		alert, err := fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+		assert.Empty(t, alert.TransactionError)
+		assert.Equal(t, types.TransactionID(2), alert.TransactionID)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(400)))
+		assert.True(t, accounts[1].Held.IsZero())
+	})
+	t.Run("SplitPaymentHeldForReview", func(t *testing.T) {
+		history := []*models.Transaction{{AccountNumber: 1, Amount: decimal.NewFromInt(10), TransactionType: types.Payment, Direction: types.Debit, CreatedAt: fraudTestNow}}
+		accounts := map[types.AccountNumber]*models.Account{
+			1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+			2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+		}
+		mockAccountCach := &mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				account, ok := accounts[accountNumber]
+				if !ok {
+					return nil, errs.New(errs.AccountNotFound)
+				}
+				return account, nil
+			},
+			ApplyChangesMock: func(changes []*models.BalanceChange) error {
+				for _, change := range changes {
+					account := accounts[change.AccountNumber]
+					if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+						return errs.New(errs.InsufficientBalance)
+					}
+					account.Balance = account.Balance.Add(change.Amount)
+					account.Held = account.Held.Add(change.Held)
+				}
+				return nil
+			},
+		}
+		fraudService := NewFraudService(&mockFraudCache{}, mockAccountCach, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+		}, rules)
+		fraudService.now = func() time.Time { return fraudTestNow }
+		accounts[3] = &models.Account{AccountNumber: 3, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate}
+		transactionService := NewTransactionService(fraudService.accountCache, &mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return history, nil
+			},
+			CreateMock: func(transaction *models.Transaction) *models.Transaction {
+				history = append(history, transaction)
+				return transaction
+			},
+		}, WithFraudScoring(fraudService))
+		fraudService.UseTransactions(transactionService)
+
+		_, err := transactionService.NewSplitPayment(&models.SplitPayment{
+			SenderAccount: 1,
+			Receivers: []*models.SplitReceiver{
+				{AccountNumber: 2, Amount: decimal.NewFromInt(10)},
+				{AccountNumber: 3, Amount: decimal.NewFromInt(20)},
+			},
+		})
+		assert.True(t, errs.Is(err, errs.TransactionUnderReview))
+		assert.True(t, accounts[1].Held.Equal(decimal.NewFromInt(30)))
+
+		_, err = fraudService.Decide(1, &models.FraudDecision{Status: types.FraudAlertApproved, Reviewer: "risk"})
+		assert.NoError(t, err)
+		assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(470)))
+		assert.True(t, accounts[1].Held.IsZero())
+		assert.True(t, accounts[3].Balance.Equal(decimal.NewFromInt(20)))
+	})
+}
+
+func TestTransactionService_InvalidDetails(t *testing.T) {
+	transactionService := NewTransactionService(&mockAccountCache{}, &mockATransactionCache{})
+
+	_, err := transactionService.NewPayment(&models.Payment{
+		SenderAccount:      1,
+		ReceiverAccount:    2,
+		Amount:             decimal.NewFromFloat(50),
+		TransactionDetails: models.TransactionDetails{Reference: strings.Repeat("x", 36)},
+	})
+	assert.True(t, errs.Is(err, errs.InvalidArgument))
+
+	_, err = transactionService.NewDeposit(&models.Deposit{
+		AccountNumber:      1,
+		Amount:             decimal.NewFromFloat(50),
+		TransactionDetails: models.TransactionDetails{Metadata: map[string]string{"": "empty key"}},
+	})
+	assert.True(t, errs.Is(err, errs.InvalidArgument))
+}
+
+func TestTransactionService_ZeroAmount(t *testing.T) {
+	transactionService := NewTransactionService(&mockAccountCache{}, &mockATransactionCache{})
+
+	_, err := transactionService.NewPayment(&models.Payment{SenderAccount: 1, ReceiverAccount: 2, Amount: decimal.Zero})
+	assert.True(t, errs.Is(err, errs.InvalidAmount))
+
+	_, err = transactionService.NewDeposit(&models.Deposit{AccountNumber: 1, Amount: decimal.Zero})
+	assert.True(t, errs.Is(err, errs.InvalidAmount))
+
+	_, err = transactionService.NewWithdraw(&models.Withdraw{AccountNumber: 1, Amount: decimal.Zero})
+	assert.True(t, errs.Is(err, errs.InvalidAmount))
 }
 
 func TestTransactionService_NewDeposit(t *testing.T) {
@@ -520,4 +1353,118 @@ func TestTransactionService_NewWithdraw(t *testing.T) {
 		_, err := transactionService.NewWithdraw(withdraw)
 		assert.Error(t, err)
 	})
+	t.Run("LimitExceeded", func(t *testing.T) {
+		mockAccountCach := mockAccountCache{
+			GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+				return &models.Account{
+					AccountNumber: accountNumber,
+					CurrencyCode:  types.TRY,
+					OwnerName:     "Ahmet Berke",
+					AccountType:   types.Individual,
+					Balance:       decimal.NewFromFloat(float64(500)),
+				}, nil
+			},
+			UpdateBalanceMock: func(accountNumber types.AccountNumber, balance decimal.Decimal) error {
+				t.Error("balance must not be updated when the limit is exceeded")
+				return nil
+			},
+		}
+		mockTransactionCach := mockATransactionCache{
+			GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+				return nil, errors.New("this account has no transaction history")
+			},
+			AddAccountMock: func(accountNumber types.AccountNumber) error {
+				return nil
+			},
+		}
+		mockLimitCach := mockLimitCache{
+			FindMock: func(account *models.Account, transactionType types.TransactionType) *models.Limit {
+				return &models.Limit{PerTransaction: decimal.NewFromFloat(100)}
+			},
+		}
+		limitService := NewLimitService(&mockLimitCach, &mockAccountCach, &mockTransactionCach)
+		transactionService := NewTransactionService(&mockAccountCach, &mockTransactionCach, WithLimits(limitService))
+
+		withdraw := &models.Withdraw{
+			AccountNumber: types.AccountNumber(1),
+			Amount:        decimal.NewFromFloat(200),
+		}
+
+		_, err := transactionService.NewWithdraw(withdraw)
+		assert.True(t, errs.Is(err, errs.LimitExceeded))
+	})
+}
+
+func TestTransactionService_ConcurrentLimits(t *testing.T) {
+	var mu sync.Mutex
+	accounts := map[types.AccountNumber]*models.Account{
+		1: {AccountNumber: 1, CurrencyCode: types.TRY, OwnerName: "Ahmet Berke", AccountType: types.Individual, Balance: decimal.NewFromFloat(500)},
+		2: {AccountNumber: 2, CurrencyCode: types.TRY, OwnerName: "Apple", AccountType: types.Corporate},
+	}
+	mockAccountCach := &mockAccountCache{
+		GetMock: func(accountNumber types.AccountNumber) (*models.Account, error) {
+			account, ok := accounts[accountNumber]
+			if !ok {
+				return nil, errs.New(errs.AccountNotFound)
+			}
+			return account, nil
+		},
+		ApplyChangesMock: func(changes []*models.BalanceChange) error {
+			// A slow update leaves room for another withdrawal to be checked before this one is in the history
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			for _, change := range changes {
+				account := accounts[change.AccountNumber]
+				if change.RequireFunds && account.Balance.Add(change.Amount).Sub(account.Held.Add(change.Held)).IsNegative() {
+					return errs.New(errs.InsufficientBalance)
+				}
+				account.Balance = account.Balance.Add(change.Amount)
+				account.Held = account.Held.Add(change.Held)
+			}
+			return nil
+		},
+	}
+	var history []*models.Transaction
+	mockTransactionCach := &mockATransactionCache{
+		GetAllMock: func(accountNumber types.AccountNumber) ([]*models.Transaction, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return append([]*models.Transaction(nil), history...), nil
+		},
+		CreateMock: func(transaction *models.Transaction) *models.Transaction {
+			mu.Lock()
+			defer mu.Unlock()
+			transaction.CreatedAt = time.Now()
+			history = append(history, transaction)
+			return transaction
+		},
+	}
+	limitService := NewLimitService(&mockLimitCache{
+		FindMock: func(account *models.Account, transactionType types.TransactionType) *models.Limit {
+			return &models.Limit{TransactionType: types.Withdraw, DailyVolume: decimal.NewFromInt(100)}
+		},
+	}, mockAccountCach, mockTransactionCach)
+	transactionService := NewTransactionService(mockAccountCach, mockTransactionCach, WithLimits(limitService))
+
+	// Only five of the ten withdrawals fit in the daily volume, however they interleave
+	var wg sync.WaitGroup
+	var made int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transactionService.NewWithdraw(&models.Withdraw{AccountNumber: 1, Amount: decimal.NewFromInt(20)})
+			if err == nil {
+				atomic.AddInt32(&made, 1)
+			} else {
+				assert.True(t, errs.Is(err, errs.LimitExceeded))
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(5), made)
+	assert.Equal(t, 5, len(history))
+	assert.True(t, accounts[1].Balance.Equal(decimal.NewFromInt(400)))
 }
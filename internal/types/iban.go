@@ -0,0 +1,156 @@
+package types
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/ahmetberke/tringle-candidate-project/internal/errs"
+	"strconv"
+	"strings"
+)
+
+// BankCode is the five digit code of the bank in the IBANs of its accounts
+const BankCode = "00999"
+
+// MaxAccountNumber is the highest account number that fits in an IBAN
+const MaxAccountNumber AccountNumber = 9999999999999999
+
+const (
+	ibanCountry = "TR"
+	ibanLength  = 26
+	// ibanReserve is the reserve digit between the bank code and the account identifier of Turkish IBANs
+	ibanReserve = "0"
+	// ibanHalf splits the 16 digit account identifier into the two halves of the permutation
+	ibanHalf   = 100000000
+	ibanRounds = 8
+)
+
+// ibanKey keys the permutation that derives the account identifiers of IBANs from the account numbers
+var ibanKey = []byte("tringle")
+
+// SetIBANKey sets the secret the account identifiers of IBANs are derived with, an empty key keeps the default.
+// IBANs given out under one key are not read as the same accounts under another one.
+func SetIBANKey(key string) {
+	if key != "" {
+		ibanKey = []byte(key)
+	}
+}
+
+// IBAN returns the Turkish IBAN of the account: TR, two check digits, the bank code, the reserve digit
+// and the 16 digit account identifier. The identifier is a keyed permutation of the account number,
+// so the IBANs of accounts opened one after the other do not reveal how many accounts there are.
+func (a AccountNumber) IBAN() string {
+	bban := fmt.Sprintf("%s%s%016d", BankCode, ibanReserve, ibanIdentifier(int64(a)))
+	check := 98 - mod97(bban+ibanDigits(ibanCountry)+"00")
+	return fmt.Sprintf("%s%02d%s", ibanCountry, check, bban)
+}
+
+// ParseAccountNumber reads an account number written either as the internal number
+// or as the IBAN of the account, spaces in IBANs are ignored
+func ParseAccountNumber(s string) (AccountNumber, error) {
+	s = strings.TrimSpace(s)
+	if number, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return AccountNumber(number), nil
+	}
+
+	iban := strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	if len(iban) != ibanLength || !strings.HasPrefix(iban, ibanCountry) {
+		return 0, errs.Newf(errs.InvalidIBAN, "%q is neither an account number nor a %d character %s IBAN", s, ibanLength, ibanCountry)
+	}
+	for _, r := range iban[2:] {
+		if r < '0' || r > '9' {
+			return 0, errs.Newf(errs.InvalidIBAN, "%q has letters after the country code", s)
+		}
+	}
+	if mod97(iban[4:]+ibanDigits(iban[:4])) != 1 {
+		return 0, errs.Newf(errs.InvalidIBAN, "%q has wrong check digits", s)
+	}
+	if iban[4:9] != BankCode || iban[9:10] != ibanReserve {
+		return 0, errs.Newf(errs.InvalidIBAN, "%q is not an account of this bank", s)
+	}
+
+	identifier, err := strconv.ParseInt(iban[10:], 10, 64)
+	if err != nil {
+		return 0, errs.Newf(errs.InvalidIBAN, "%q has an account number out of range", s)
+	}
+	return AccountNumber(ibanAccountNumber(identifier)), nil
+}
+
+// ibanIdentifier permutes the 16 digit account number with a balanced Feistel network over its two 8 digit halves
+func ibanIdentifier(number int64) int64 {
+	left, right := number/ibanHalf, number%ibanHalf
+	for round := 0; round < ibanRounds; round++ {
+		left, right = right, (left+ibanRound(round, right))%ibanHalf
+	}
+	return left*ibanHalf + right
+}
+
+// ibanAccountNumber reverses ibanIdentifier
+func ibanAccountNumber(identifier int64) int64 {
+	left, right := identifier/ibanHalf, identifier%ibanHalf
+	for round := ibanRounds - 1; round >= 0; round-- {
+		left, right = (right-ibanRound(round, left)+ibanHalf)%ibanHalf, left
+	}
+	return left*ibanHalf + right
+}
+
+// ibanRound is the keyed round function of the permutation, it returns a value below ibanHalf
+func ibanRound(round int, half int64) int64 {
+	mac := hmac.New(sha256.New, ibanKey)
+	var input [9]byte
+	input[0] = byte(round)
+	binary.BigEndian.PutUint64(input[1:], uint64(half))
+	mac.Write(input[:])
+	return int64(binary.BigEndian.Uint64(mac.Sum(nil)) % ibanHalf)
+}
+
+// UnmarshalJSON accepts the account number as a number, or as a string holding either the number or the IBAN
+func (a *AccountNumber) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		number, err := ParseAccountNumber(s)
+		if err != nil {
+			return err
+		}
+		*a = number
+		return nil
+	}
+
+	number, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "account number %s is not an integer", data)
+	}
+	*a = AccountNumber(number)
+	return nil
+}
+
+// ibanDigits replaces the letters of the IBAN part with their two digit values, A is 10 and Z is 35
+func ibanDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteString(strconv.Itoa(int(r-'A') + 10))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// mod97 returns the remainder of the decimal digits divided by 97
+func mod97(digits string) int {
+	remainder := 0
+	for _, r := range digits {
+		remainder = (remainder*10 + int(r-'0')) % 97
+	}
+	return remainder
+}
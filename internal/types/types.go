@@ -2,6 +2,140 @@ package types
 
 type AccountNumber int64
 
+type TransactionID int64
+
+type HoldID int64
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldVoided   HoldStatus = "voided"
+	HoldExpired  HoldStatus = "expired"
+)
+
+type EscrowID int64
+
+// EscrowStatus is the state of an escrow, a funded escrow ends either released or refunded
+type EscrowStatus string
+
+const (
+	EscrowFunded   EscrowStatus = "funded"
+	EscrowReleased EscrowStatus = "released"
+	EscrowRefunded EscrowStatus = "refunded"
+)
+
+type InvoiceID int64
+
+// InvoiceStatus is the state of an invoice, an open invoice can be paid in parts until it is paid in full
+type InvoiceStatus string
+
+const (
+	InvoiceOpen      InvoiceStatus = "open"
+	InvoicePaid      InvoiceStatus = "paid"
+	InvoiceExpired   InvoiceStatus = "expired"
+	InvoiceCancelled InvoiceStatus = "cancelled"
+)
+
+type DisputeID int64
+
+// DisputeStatus is the state of a dispute, an open dispute ends either charged back or released
+type DisputeStatus string
+
+const (
+	DisputeOpen        DisputeStatus = "open"
+	DisputeChargedBack DisputeStatus = "charged_back"
+	DisputeReleased    DisputeStatus = "released"
+)
+
+type ScreeningCaseID int64
+
+// ScreeningStatus is the state of a screening case, a pending case is either cleared as a false positive
+// or confirmed as a match of the sanctions list by a reviewer
+type ScreeningStatus string
+
+const (
+	ScreeningPending   ScreeningStatus = "pending"
+	ScreeningCleared   ScreeningStatus = "cleared"
+	ScreeningConfirmed ScreeningStatus = "confirmed"
+)
+
+// ScreeningTrigger is what made a name be screened against the sanctions list
+type ScreeningTrigger string
+
+const (
+	AccountOpening ScreeningTrigger = "account_opening"
+	AccountRenamed ScreeningTrigger = "account_renamed"
+	PaymentScreen  ScreeningTrigger = "payment"
+)
+
+type FraudAlertID int64
+
+// FraudRuleKind is what a fraud rule looks at: how many transactions an account made lately,
+// an amount far above the usual ones of the account, or a large amount from a new account
+type FraudRuleKind string
+
+const (
+	VelocityRule      FraudRuleKind = "velocity"
+	UnusualAmountRule FraudRuleKind = "unusual_amount"
+	NewAccountRule    FraudRuleKind = "new_account"
+)
+
+// FraudAction is what happens to a transaction a fraud rule fires for, the strictest action of the fired rules is taken
+type FraudAction string
+
+const (
+	FraudAllow  FraudAction = "allow"
+	FraudReview FraudAction = "review"
+	FraudBlock  FraudAction = "block"
+)
+
+// FraudAlertStatus is the state of a fraud alert, a pending alert holds its transaction
+// until a reviewer approves or rejects it
+type FraudAlertStatus string
+
+const (
+	FraudAlertAllowed  FraudAlertStatus = "allowed"
+	FraudAlertPending  FraudAlertStatus = "pending"
+	FraudAlertBlocked  FraudAlertStatus = "blocked"
+	FraudAlertApproved FraudAlertStatus = "approved"
+	FraudAlertRejected FraudAlertStatus = "rejected"
+)
+
+type StandingOrderID int64
+
+// Frequency is how often a standing order repeats, Once runs it a single time
+// and Cron whenever its cron expression matches
+type Frequency string
+
+const (
+	Once    Frequency = "once"
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Cron    Frequency = "cron"
+)
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderActive    StandingOrderStatus = "active"
+	StandingOrderCompleted StandingOrderStatus = "completed"
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+	StandingOrderFailed    StandingOrderStatus = "failed"
+)
+
+// ExecutionOutcome is the result of a single run of a standing order
+type ExecutionOutcome string
+
+const (
+	ExecutionSucceeded ExecutionOutcome = "succeeded"
+	ExecutionRetrying  ExecutionOutcome = "retrying"
+	ExecutionPending   ExecutionOutcome = "pending"
+	ExecutionFailed    ExecutionOutcome = "failed"
+)
+
 type AccountType string
 
 const (
@@ -23,4 +157,115 @@ const (
 	Payment  TransactionType = "payment"
 	Deposit  TransactionType = "deposit"
 	Withdraw TransactionType = "withdraw"
+	Fee      TransactionType = "fee"
+	// OverdraftInterest is charged daily on negative balances
+	OverdraftInterest TransactionType = "overdraft_interest"
+	// Interest is the monthly posting of the interest earned by positive balances
+	Interest TransactionType = "interest"
+	// Adjustment is a corrective line written by the reconciliation so that the history adds up to the balance
+	Adjustment TransactionType = "adjustment"
+	// Transfer moves funds between the wallets of a customer, converted if their currencies differ
+	Transfer TransactionType = "transfer"
+	// Escrow moves funds into an escrow account and out of it to the receiver or back to the sender
+	Escrow TransactionType = "escrow"
+	// ProvisionalCredit pays the amount of a dispute back to the individual while it is open, and takes it back if it is lost
+	ProvisionalCredit TransactionType = "provisional_credit"
+	// Chargeback takes the amount of a dispute won by the individual from the merchant for good
+	Chargeback TransactionType = "chargeback"
+)
+
+// Direction tells whether a transaction added to or subtracted from the balance of its account
+type Direction string
+
+const (
+	Credit Direction = "credit"
+	Debit  Direction = "debit"
+)
+
+// FeePayer is the side of a transaction that pays its fee
+type FeePayer string
+
+const (
+	SenderPays   FeePayer = "sender"
+	ReceiverPays FeePayer = "receiver"
+)
+
+// DayCount is the convention the annual interest rate is divided into days with
+type DayCount string
+
+const (
+	Actual360 DayCount = "ACT/360"
+	Actual365 DayCount = "ACT/365"
+)
+
+// EventSequence orders the events of the event store
+type EventSequence int64
+
+// EventType is what happened to an account in an event,
+// RecordSaved events carry a record of another cache instead
+type EventType string
+
+const (
+	AccountOpened       EventType = "AccountOpened"
+	AccountClosed       EventType = "AccountClosed"
+	AccountUpdated      EventType = "AccountUpdated"
+	Deposited           EventType = "Deposited"
+	Withdrawn           EventType = "Withdrawn"
+	PaymentSent         EventType = "PaymentSent"
+	PaymentReceived     EventType = "PaymentReceived"
+	FeeCharged          EventType = "FeeCharged"
+	FeeCollected        EventType = "FeeCollected"
+	InterestCredited    EventType = "InterestCredited"
+	InterestDebited     EventType = "InterestDebited"
+	FundsHeld           EventType = "FundsHeld"
+	FundsReleased       EventType = "FundsReleased"
+	OverdraftLimitSet   EventType = "OverdraftLimitSet"
+	BalanceAdjusted     EventType = "BalanceAdjusted"
+	TransferSent        EventType = "TransferSent"
+	TransferReceived    EventType = "TransferReceived"
+	FundsEscrowed       EventType = "FundsEscrowed"
+	EscrowSettled       EventType = "EscrowSettled"
+	ProvisionalCredited EventType = "ProvisionalCredited"
+	ProvisionalReversed EventType = "ProvisionalReversed"
+	ChargedBack         EventType = "ChargedBack"
+	RecordSaved         EventType = "RecordSaved"
+)
+
+// RecordKind is the cache a record saved to the event store belongs to
+type RecordKind string
+
+const (
+	TransactionRecord       RecordKind = "transaction"
+	HistoryRecord           RecordKind = "history"
+	HoldRecord              RecordKind = "hold"
+	EscrowRecord            RecordKind = "escrow"
+	InvoiceRecord           RecordKind = "invoice"
+	DisputeRecord           RecordKind = "dispute"
+	StandingOrderRecord     RecordKind = "standingOrder"
+	ScreeningCaseRecord     RecordKind = "screeningCase"
+	ScreeningDecisionRecord RecordKind = "screeningDecision"
+	FraudAlertRecord        RecordKind = "fraudAlert"
+	LimitRecord             RecordKind = "limit"
+	CustomerRecord          RecordKind = "customer"
+	CheckpointRecord        RecordKind = "checkpoint"
+	SystemAccountRecord     RecordKind = "systemAccount"
+)
+
+// SystemAccountRole is what an account the bank opens for itself is used for
+type SystemAccountRole string
+
+const (
+	HouseRevenue  SystemAccountRole = "houseRevenue"
+	EscrowHolding SystemAccountRole = "escrowHolding"
+)
+
+type CustomerID int64
+
+// KYCStatus is how far the identity of a customer has been verified
+type KYCStatus string
+
+const (
+	KYCPending  KYCStatus = "pending"
+	KYCVerified KYCStatus = "verified"
+	KYCRejected KYCStatus = "rejected"
 )